@@ -0,0 +1,36 @@
+// Command fakegoogle serves an in-memory fake of the Gmail, Drive, Calendar,
+// Tasks and Sheets APIs for testing gog end to end:
+//
+//	fakegoogle -addr 127.0.0.1:8765 &
+//	GOG_API_ENDPOINT=http://127.0.0.1:8765 gog --account me@example.com tasks lists
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jibankumarpanda/gogcli.1/internal/fakegoogle"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:0", "listen address")
+	flag.Parse()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// Print the URL so scripts can pick up an ephemeral port.
+	fmt.Printf("http://%s\n", ln.Addr())
+
+	srv := &http.Server{Handler: fakegoogle.New(), ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.Serve(ln); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
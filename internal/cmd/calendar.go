@@ -32,6 +32,8 @@ type CalendarCmd struct {
 	FocusTime       CalendarFocusTimeCmd       `cmd:"" name:"focus-time" aliases:"focus" help:"Create a Focus Time block"`
 	OOO             CalendarOOOCmd             `cmd:"" name:"out-of-office" aliases:"ooo" help:"Create an Out of Office event"`
 	WorkingLocation CalendarWorkingLocationCmd `cmd:"" name:"working-location" aliases:"wl" help:"Set working location (home/office/custom)"`
+	Export          CalendarExportCmd          `cmd:"" name:"export" help:"Export events as an iCalendar (.ics) file"`
+	Import          CalendarImportCmd          `cmd:"" name:"import" help:"Import events from an iCalendar (.ics) file"`
 }
 
 type CalendarCalendarsCmd struct {
@@ -65,6 +67,10 @@ func (c *CalendarCalendarsCmd) Run(ctx context.Context, flags *RootFlags) error
 		return r.Items, r.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var items []*calendar.CalendarListEntry
 	nextPageToken := ""
 	if c.All {
@@ -147,6 +153,10 @@ func (c *CalendarAclCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return r.Items, r.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var items []*calendar.AclRule
 	nextPageToken := ""
 	if c.All {
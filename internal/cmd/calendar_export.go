@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type CalendarExportCmd struct {
+	CalendarID string `arg:"" name:"calendarId" optional:"" help:"Calendar ID or name (default: primary)"`
+	From       string `name:"from" help:"Start time (RFC3339, date, or relative: today, tomorrow, monday)"`
+	To         string `name:"to" help:"End time (RFC3339, date, or relative)"`
+	Query      string `name:"query" help:"Free text search"`
+	Out        string `name:"out" short:"o" help:"Write the .ics file here instead of stdout"`
+}
+
+func (c *CalendarExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		calendarID = primaryCalendarID
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+	cal, err := svc.Calendars.Get(calendarID).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	timeRange, err := ResolveTimeRange(ctx, svc, TimeRangeFlags{From: c.From, To: c.To})
+	if err != nil {
+		return err
+	}
+	from, to := timeRange.FormatRFC3339()
+
+	// Series are exported as their master event (RRULE) rather than expanded
+	// instances. Deleted occurrences are included so they become EXDATEs.
+	events, err := collectAllPages("", func(pageToken string) ([]*calendar.Event, string, error) {
+		call := svc.Events.List(calendarID).
+			TimeMin(from).
+			TimeMax(to).
+			SingleEvents(false).
+			ShowDeleted(true).
+			MaxResults(2500).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		if q := strings.TrimSpace(c.Query); q != "" {
+			call = call.Q(q)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	n, err := writeICSCalendar(&buf, cal.Summary, cal.TimeZone, account, events)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(c.Out) == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	outPath, err := config.ExpandPath(c.Out)
+	if err != nil {
+		return err
+	}
+	if mkErr := os.MkdirAll(filepath.Dir(outPath), 0o755); mkErr != nil {
+		return mkErr
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"path":       outPath,
+			"calendarId": calendarID,
+			"events":     n,
+			"from":       from,
+			"to":         to,
+		})
+	}
+	u.Out().Printf("Exported %d events to %s", n, outPath)
+	return nil
+}
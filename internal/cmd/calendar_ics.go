@@ -0,0 +1,866 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Minimal RFC 5545 support for `calendar export` / `calendar import`: enough to
+// carry VEVENTs (with recurrence, attendees, alarms, and extended properties)
+// between Google Calendar and other systems.
+
+const (
+	icsProdID          = "-//gogcli//gog calendar//EN"
+	icsDateLayout      = "20060102"
+	icsLocalLayout     = "20060102T150405"
+	icsUTCLayout       = "20060102T150405Z"
+	icsPrivatePropName = "X-GOG-PRIVATE-PROPERTY"
+	icsSharedPropName  = "X-GOG-SHARED-PROPERTY"
+	icsMaxLineOctets   = 75
+)
+
+type icsParam struct {
+	Name  string
+	Value string
+}
+
+type icsProp struct {
+	Name   string
+	Params []icsParam
+	Value  string
+}
+
+func (p icsProp) param(name string) string {
+	for _, prm := range p.Params {
+		if strings.EqualFold(prm.Name, name) {
+			return prm.Value
+		}
+	}
+	return ""
+}
+
+type icsComponent struct {
+	Name     string
+	Props    []icsProp
+	Children []*icsComponent
+}
+
+func (c *icsComponent) prop(name string) (icsProp, bool) {
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return icsProp{}, false
+}
+
+func (c *icsComponent) value(name string) string {
+	p, _ := c.prop(name)
+	return p.Value
+}
+
+// --- writing ---
+
+type icsWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *icsWriter) prop(name string, params []icsParam, value string) {
+	var line strings.Builder
+	line.WriteString(name)
+	for _, p := range params {
+		line.WriteByte(';')
+		line.WriteString(p.Name)
+		line.WriteByte('=')
+		line.WriteString(icsParamValue(p.Value))
+	}
+	line.WriteByte(':')
+	line.WriteString(value)
+	w.fold(line.String())
+}
+
+func (w *icsWriter) text(name string, value string) {
+	if value != "" {
+		w.prop(name, nil, icsEscapeText(value))
+	}
+}
+
+// fold writes a content line, folding at 75 octets without splitting UTF-8
+// sequences.
+func (w *icsWriter) fold(line string) {
+	limit := icsMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsMaxLineOctets - 1
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+func icsEscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+func icsParamValue(s string) string {
+	s = strings.ReplaceAll(s, `"`, "")
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// icsZoneSpan records the range of instants formatted in a zone so that the
+// emitted VTIMEZONE covers every transition those times need.
+type icsZoneSpan struct {
+	loc      *time.Location
+	min, max time.Time
+}
+
+type icsCalendarWriter struct {
+	events icsWriter
+	zones  map[string]*icsZoneSpan
+}
+
+func (cw *icsCalendarWriter) timeProp(name string, t *calendar.EventDateTime, fallbackTZ string) {
+	params, value, ok := cw.formatTime(t, fallbackTZ)
+	if ok {
+		cw.events.prop(name, params, value)
+	}
+}
+
+func (cw *icsCalendarWriter) formatTime(t *calendar.EventDateTime, fallbackTZ string) ([]icsParam, string, bool) {
+	if t == nil {
+		return nil, "", false
+	}
+	if t.Date != "" {
+		d, err := time.Parse("2006-01-02", t.Date)
+		if err != nil {
+			return nil, "", false
+		}
+		return []icsParam{{Name: "VALUE", Value: "DATE"}}, d.Format(icsDateLayout), true
+	}
+	if t.DateTime == "" {
+		return nil, "", false
+	}
+	instant, err := time.Parse(time.RFC3339, t.DateTime)
+	if err != nil {
+		return nil, "", false
+	}
+	tz := t.TimeZone
+	if tz == "" {
+		tz = fallbackTZ
+	}
+	if tz == "" || tz == "UTC" {
+		return nil, instant.UTC().Format(icsUTCLayout), true
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, instant.UTC().Format(icsUTCLayout), true
+	}
+	cw.noteZone(loc, instant)
+	return []icsParam{{Name: "TZID", Value: tz}}, instant.In(loc).Format(icsLocalLayout), true
+}
+
+func (cw *icsCalendarWriter) noteZone(loc *time.Location, t time.Time) {
+	if cw.zones == nil {
+		cw.zones = map[string]*icsZoneSpan{}
+	}
+	span, ok := cw.zones[loc.String()]
+	if !ok {
+		cw.zones[loc.String()] = &icsZoneSpan{loc: loc, min: t, max: t}
+		return
+	}
+	if t.Before(span.min) {
+		span.min = t
+	}
+	if t.After(span.max) {
+		span.max = t
+	}
+}
+
+func (cw *icsCalendarWriter) event(ev *calendar.Event, exdates []*calendar.EventDateTime, fallbackTZ, account string) {
+	w := &cw.events
+	w.prop("BEGIN", nil, "VEVENT")
+
+	uid := ev.ICalUID
+	if uid == "" {
+		uid = ev.Id + "@google.com"
+	}
+	w.text("UID", uid)
+	stamp := time.Now().UTC()
+	if updated, err := time.Parse(time.RFC3339, ev.Updated); err == nil {
+		stamp = updated.UTC()
+	}
+	w.prop("DTSTAMP", nil, stamp.Format(icsUTCLayout))
+	if created, err := time.Parse(time.RFC3339, ev.Created); err == nil {
+		w.prop("CREATED", nil, created.UTC().Format(icsUTCLayout))
+	}
+	if ev.Updated != "" {
+		w.prop("LAST-MODIFIED", nil, stamp.Format(icsUTCLayout))
+	}
+
+	tz := fallbackTZ
+	if ev.Start != nil && ev.Start.TimeZone != "" {
+		tz = ev.Start.TimeZone
+	}
+	cw.timeProp("DTSTART", ev.Start, tz)
+	// With endTimeUnspecified Google stores a placeholder end; omit DTEND.
+	if !ev.EndTimeUnspecified {
+		cw.timeProp("DTEND", ev.End, tz)
+	}
+	if ev.OriginalStartTime != nil {
+		cw.timeProp("RECURRENCE-ID", ev.OriginalStartTime, tz)
+	}
+	for _, rule := range ev.Recurrence {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			w.fold(rule)
+		}
+	}
+	for _, ex := range exdates {
+		cw.timeProp("EXDATE", ex, tz)
+	}
+
+	w.text("SUMMARY", ev.Summary)
+	w.text("DESCRIPTION", ev.Description)
+	w.text("LOCATION", ev.Location)
+	switch ev.Status {
+	case "confirmed", "tentative", "cancelled":
+		w.prop("STATUS", nil, strings.ToUpper(ev.Status))
+	}
+	if ev.Transparency == "transparent" {
+		w.prop("TRANSP", nil, "TRANSPARENT")
+	} else {
+		w.prop("TRANSP", nil, "OPAQUE")
+	}
+	switch ev.Visibility {
+	case "private", "public", "confidential":
+		w.prop("CLASS", nil, strings.ToUpper(ev.Visibility))
+	}
+	if ev.Sequence != 0 {
+		w.prop("SEQUENCE", nil, strconv.FormatInt(ev.Sequence, 10))
+	}
+	if ev.HangoutLink != "" {
+		w.text("X-GOOGLE-CONFERENCE", ev.HangoutLink)
+	}
+
+	if o := ev.Organizer; o != nil && o.Email != "" {
+		var params []icsParam
+		if o.DisplayName != "" {
+			params = append(params, icsParam{Name: "CN", Value: o.DisplayName})
+		}
+		w.prop("ORGANIZER", params, "mailto:"+o.Email)
+	}
+	for _, a := range ev.Attendees {
+		if a == nil || a.Email == "" {
+			continue
+		}
+		var params []icsParam
+		if a.DisplayName != "" {
+			params = append(params, icsParam{Name: "CN", Value: a.DisplayName})
+		}
+		if a.Resource {
+			params = append(params, icsParam{Name: "CUTYPE", Value: "RESOURCE"})
+		}
+		role := "REQ-PARTICIPANT"
+		if a.Optional {
+			role = "OPT-PARTICIPANT"
+		}
+		params = append(params,
+			icsParam{Name: "ROLE", Value: role},
+			icsParam{Name: "PARTSTAT", Value: icsPartStat(a.ResponseStatus)},
+		)
+		w.prop("ATTENDEE", params, "mailto:"+a.Email)
+	}
+
+	if ep := ev.ExtendedProperties; ep != nil {
+		writeICSExtendedProps(w, icsPrivatePropName, ep.Private)
+		writeICSExtendedProps(w, icsSharedPropName, ep.Shared)
+	}
+
+	if r := ev.Reminders; r != nil && !r.UseDefault {
+		for _, o := range r.Overrides {
+			if o == nil {
+				continue
+			}
+			w.prop("BEGIN", nil, "VALARM")
+			if o.Method == "email" {
+				w.prop("ACTION", nil, "EMAIL")
+				w.text("SUMMARY", firstNonEmpty(ev.Summary, "Reminder"))
+				w.text("DESCRIPTION", firstNonEmpty(ev.Summary, "Reminder"))
+				if account != "" {
+					w.prop("ATTENDEE", nil, "mailto:"+account)
+				}
+			} else {
+				w.prop("ACTION", nil, "DISPLAY")
+				w.text("DESCRIPTION", firstNonEmpty(ev.Summary, "Reminder"))
+			}
+			w.prop("TRIGGER", nil, fmt.Sprintf("-PT%dM", o.Minutes))
+			w.prop("END", nil, "VALARM")
+		}
+	}
+
+	w.prop("END", nil, "VEVENT")
+}
+
+func writeICSExtendedProps(w *icsWriter, name string, props map[string]string) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.text(name, k+"="+props[k])
+	}
+}
+
+func icsPartStat(responseStatus string) string {
+	switch responseStatus {
+	case "accepted":
+		return "ACCEPTED"
+	case "declined":
+		return "DECLINED"
+	case "tentative":
+		return "TENTATIVE"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// writeICSCalendar renders events as a VCALENDAR. Cancelled instances of
+// recurring events (how Google represents deleted occurrences) become EXDATEs
+// on their series instead of separate VEVENTs.
+func writeICSCalendar(out io.Writer, name, tz, account string, events []*calendar.Event) (int, error) {
+	exdates := map[string][]*calendar.EventDateTime{}
+	kept := make([]*calendar.Event, 0, len(events))
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		if ev.Status == "cancelled" {
+			if ev.RecurringEventId != "" && ev.OriginalStartTime != nil {
+				exdates[ev.RecurringEventId] = append(exdates[ev.RecurringEventId], ev.OriginalStartTime)
+			}
+			continue
+		}
+		kept = append(kept, ev)
+	}
+
+	cw := &icsCalendarWriter{}
+	for _, ev := range kept {
+		cw.event(ev, exdates[ev.Id], tz, account)
+	}
+
+	var head icsWriter
+	head.prop("BEGIN", nil, "VCALENDAR")
+	head.prop("VERSION", nil, "2.0")
+	head.prop("PRODID", nil, icsProdID)
+	head.prop("CALSCALE", nil, "GREGORIAN")
+	head.prop("METHOD", nil, "PUBLISH")
+	head.text("X-WR-CALNAME", name)
+	head.text("X-WR-TIMEZONE", tz)
+
+	zoneNames := make([]string, 0, len(cw.zones))
+	for n := range cw.zones {
+		zoneNames = append(zoneNames, n)
+	}
+	sort.Strings(zoneNames)
+	for _, n := range zoneNames {
+		writeICSTimezone(&head, cw.zones[n])
+	}
+
+	bw := bufio.NewWriter(out)
+	_, _ = bw.Write(head.buf.Bytes())
+	_, _ = bw.Write(cw.events.buf.Bytes())
+	_, _ = bw.WriteString("END:VCALENDAR\r\n")
+	return len(kept), bw.Flush()
+}
+
+// writeICSTimezone emits a VTIMEZONE listing each UTC offset transition from
+// the start of the earliest year used through the end of the latest one.
+func writeICSTimezone(w *icsWriter, span *icsZoneSpan) {
+	loc := span.loc
+	from := time.Date(span.min.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	to := time.Date(span.max.In(loc).Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	w.prop("BEGIN", nil, "VTIMEZONE")
+	w.text("TZID", loc.String())
+	w.text("X-LIC-LOCATION", loc.String())
+
+	t := from
+	abbr, offset := t.Zone()
+	writeICSObservance(w, t.IsDST(), abbr, offset, offset, t)
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.Before(to) {
+			break
+		}
+		next := end.In(loc)
+		nextAbbr, nextOffset := next.Zone()
+		// Observance DTSTART is local time under the offset being replaced.
+		writeICSObservance(w, next.IsDST(), nextAbbr, offset, nextOffset, end.In(time.FixedZone("", offset)))
+		t, offset = next, nextOffset
+	}
+	w.prop("END", nil, "VTIMEZONE")
+}
+
+func writeICSObservance(w *icsWriter, dst bool, abbr string, fromOffset, toOffset int, start time.Time) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	w.prop("BEGIN", nil, kind)
+	w.prop("DTSTART", nil, start.Format(icsLocalLayout))
+	w.prop("TZOFFSETFROM", nil, icsOffset(fromOffset))
+	w.prop("TZOFFSETTO", nil, icsOffset(toOffset))
+	w.text("TZNAME", abbr)
+	w.prop("END", nil, kind)
+}
+
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+	if sec := seconds % 60; sec != 0 {
+		s += fmt.Sprintf("%02d", sec)
+	}
+	return s
+}
+
+// --- reading ---
+
+// parseICS reads every component in r. The returned root holds the top-level
+// components (normally one or more VCALENDARs).
+func parseICS(r io.Reader) (*icsComponent, error) {
+	lines, err := icsUnfold(r)
+	if err != nil {
+		return nil, err
+	}
+	root := &icsComponent{}
+	stack := []*icsComponent{root}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseICSLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		top := stack[len(stack)-1]
+		switch p.Name {
+		case "BEGIN":
+			child := &icsComponent{Name: strings.ToUpper(p.Value)}
+			top.Children = append(top.Children, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || top.Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, p.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			top.Props = append(top.Props, p)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("unterminated %s", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+func icsUnfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+func parseICSLine(line string) (icsProp, error) {
+	var p icsProp
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("malformed content line %q", line)
+	}
+	p.Name = strings.ToUpper(line[:i])
+	rest := line[i:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return p, fmt.Errorf("malformed parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated quoted parameter in %q", line)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return p, fmt.Errorf("malformed parameter in %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		p.Params = append(p.Params, icsParam{Name: name, Value: value})
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return p, fmt.Errorf("missing value in %q", line)
+	}
+	p.Value = rest[1:]
+	return p, nil
+}
+
+func icsUnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// icsEvents converts every VEVENT in the parsed tree. Times with a TZID use
+// the matching Olson zone (or the VTIMEZONE's X-LIC-LOCATION); floating
+// times and unknown zones fall back to def.
+func icsEvents(root *icsComponent, def *time.Location) ([]*calendar.Event, error) {
+	var out []*calendar.Event
+	for _, cal := range root.Children {
+		if cal.Name != "VCALENDAR" {
+			continue
+		}
+		zones := map[string]*time.Location{}
+		for _, c := range cal.Children {
+			if c.Name != "VTIMEZONE" {
+				continue
+			}
+			tzid := c.value("TZID")
+			for _, candidate := range []string{c.value("X-LIC-LOCATION"), tzid} {
+				if loc, err := time.LoadLocation(strings.TrimPrefix(candidate, "/")); err == nil && candidate != "" {
+					zones[tzid] = loc
+					break
+				}
+			}
+		}
+		for _, c := range cal.Children {
+			if c.Name != "VEVENT" {
+				continue
+			}
+			ev, err := icsToEvent(c, zones, def)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: %w", c.value("UID"), err)
+			}
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func icsZone(tzid string, zones map[string]*time.Location, def *time.Location) *time.Location {
+	if tzid == "" {
+		return def
+	}
+	if loc, ok := zones[tzid]; ok {
+		return loc
+	}
+	if loc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+		return loc
+	}
+	return def
+}
+
+func icsParseTime(p icsProp, zones map[string]*time.Location, def *time.Location) (*calendar.EventDateTime, time.Time, error) {
+	v := strings.TrimSpace(p.Value)
+	if strings.EqualFold(p.param("VALUE"), "DATE") || len(v) == len(icsDateLayout) {
+		d, err := time.Parse(icsDateLayout, v)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid %s %q", p.Name, v)
+		}
+		return &calendar.EventDateTime{Date: d.Format("2006-01-02")}, d, nil
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse(icsUTCLayout, v)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid %s %q", p.Name, v)
+		}
+		return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339)}, t, nil
+	}
+	loc := icsZone(p.param("TZID"), zones, def)
+	t, err := time.ParseInLocation(icsLocalLayout, v, loc)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid %s %q", p.Name, v)
+	}
+	edt := &calendar.EventDateTime{DateTime: t.Format(time.RFC3339)}
+	if name := loc.String(); name != "UTC" && name != "Local" {
+		edt.TimeZone = name
+	}
+	return edt, t, nil
+}
+
+// parseICSDuration parses an RFC 5545 duration such as -PT15M or P1DT2H.
+func parseICSDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+	var d time.Duration
+	inTime, seen := false, false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		num = ""
+		seen = true
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+	}
+	if num != "" || !seen {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return sign * d, nil
+}
+
+func icsMailto(v string) string {
+	if len(v) >= 7 && strings.EqualFold(v[:7], "mailto:") {
+		return v[7:]
+	}
+	return v
+}
+
+func icsToEvent(c *icsComponent, zones map[string]*time.Location, def *time.Location) (*calendar.Event, error) {
+	ev := &calendar.Event{
+		ICalUID:     icsUnescapeText(c.value("UID")),
+		Summary:     icsUnescapeText(c.value("SUMMARY")),
+		Description: icsUnescapeText(c.value("DESCRIPTION")),
+		Location:    icsUnescapeText(c.value("LOCATION")),
+	}
+
+	startProp, ok := c.prop("DTSTART")
+	if !ok {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, startTime, err := icsParseTime(startProp, zones, def)
+	if err != nil {
+		return nil, err
+	}
+	ev.Start = start
+
+	switch endProp, hasEnd := c.prop("DTEND"); {
+	case hasEnd:
+		if ev.End, _, err = icsParseTime(endProp, zones, def); err != nil {
+			return nil, err
+		}
+	case c.value("DURATION") != "":
+		d, durErr := parseICSDuration(c.value("DURATION"))
+		if durErr != nil {
+			return nil, durErr
+		}
+		if start.Date != "" {
+			ev.End = &calendar.EventDateTime{Date: startTime.Add(d).Format("2006-01-02")}
+		} else {
+			ev.End = &calendar.EventDateTime{DateTime: startTime.Add(d).Format(time.RFC3339), TimeZone: start.TimeZone}
+		}
+	case start.Date != "":
+		ev.End = &calendar.EventDateTime{Date: startTime.AddDate(0, 0, 1).Format("2006-01-02")}
+	default:
+		ev.End = &calendar.EventDateTime{DateTime: start.DateTime, TimeZone: start.TimeZone}
+	}
+
+	if rid, ok := c.prop("RECURRENCE-ID"); ok {
+		if ev.OriginalStartTime, _, err = icsParseTime(rid, zones, def); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range c.Props {
+		switch p.Name {
+		case "RRULE", "EXRULE", "RDATE", "EXDATE":
+			ev.Recurrence = append(ev.Recurrence, icsRecurrenceLine(p, zones))
+		case "ORGANIZER":
+			ev.Organizer = &calendar.EventOrganizer{Email: icsMailto(p.Value), DisplayName: p.param("CN")}
+		case "ATTENDEE":
+			a := &calendar.EventAttendee{
+				Email:          icsMailto(p.Value),
+				DisplayName:    p.param("CN"),
+				Optional:       strings.EqualFold(p.param("ROLE"), "OPT-PARTICIPANT"),
+				Resource:       strings.EqualFold(p.param("CUTYPE"), "RESOURCE"),
+				ResponseStatus: "needsAction",
+			}
+			switch strings.ToUpper(p.param("PARTSTAT")) {
+			case "ACCEPTED":
+				a.ResponseStatus = "accepted"
+			case "DECLINED":
+				a.ResponseStatus = "declined"
+			case "TENTATIVE":
+				a.ResponseStatus = "tentative"
+			}
+			ev.Attendees = append(ev.Attendees, a)
+		case icsPrivatePropName, icsSharedPropName:
+			k, v, found := strings.Cut(icsUnescapeText(p.Value), "=")
+			if !found || k == "" {
+				continue
+			}
+			if ev.ExtendedProperties == nil {
+				ev.ExtendedProperties = &calendar.EventExtendedProperties{}
+			}
+			if p.Name == icsPrivatePropName {
+				if ev.ExtendedProperties.Private == nil {
+					ev.ExtendedProperties.Private = map[string]string{}
+				}
+				ev.ExtendedProperties.Private[k] = v
+			} else {
+				if ev.ExtendedProperties.Shared == nil {
+					ev.ExtendedProperties.Shared = map[string]string{}
+				}
+				ev.ExtendedProperties.Shared[k] = v
+			}
+		}
+	}
+
+	switch strings.ToUpper(c.value("STATUS")) {
+	case "CONFIRMED":
+		ev.Status = "confirmed"
+	case "TENTATIVE":
+		ev.Status = "tentative"
+	case "CANCELLED":
+		ev.Status = "cancelled"
+	}
+	switch strings.ToUpper(c.value("TRANSP")) {
+	case "TRANSPARENT":
+		ev.Transparency = "transparent"
+	case "OPAQUE":
+		ev.Transparency = "opaque"
+	}
+	switch strings.ToUpper(c.value("CLASS")) {
+	case "PRIVATE":
+		ev.Visibility = "private"
+	case "PUBLIC":
+		ev.Visibility = "public"
+	case "CONFIDENTIAL":
+		ev.Visibility = "confidential"
+	}
+	if seq := c.value("SEQUENCE"); seq != "" {
+		if n, convErr := strconv.ParseInt(seq, 10, 64); convErr == nil {
+			ev.Sequence = n
+		}
+	}
+
+	var overrides []*calendar.EventReminder
+	for _, alarm := range c.Children {
+		if alarm.Name != "VALARM" {
+			continue
+		}
+		trigger, ok := alarm.prop("TRIGGER")
+		if !ok || strings.EqualFold(trigger.param("VALUE"), "DATE-TIME") {
+			continue
+		}
+		d, durErr := parseICSDuration(trigger.Value)
+		if durErr != nil || d > 0 {
+			continue
+		}
+		method := "popup"
+		if strings.EqualFold(alarm.value("ACTION"), "EMAIL") {
+			method = "email"
+		}
+		overrides = append(overrides, &calendar.EventReminder{Method: method, Minutes: int64(-d / time.Minute)})
+	}
+	if len(overrides) > 0 {
+		// Google Calendar accepts at most five reminder overrides.
+		if len(overrides) > 5 {
+			overrides = overrides[:5]
+		}
+		ev.Reminders = &calendar.EventReminders{Overrides: overrides, ForceSendFields: []string{"UseDefault"}}
+	}
+	return ev, nil
+}
+
+// icsRecurrenceLine re-serializes a recurrence property for the Calendar API,
+// which takes RFC 5545 lines verbatim; TZIDs are mapped to Olson names.
+func icsRecurrenceLine(p icsProp, zones map[string]*time.Location) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	for _, prm := range p.Params {
+		v := prm.Value
+		if prm.Name == "TZID" {
+			if loc, ok := zones[v]; ok {
+				v = loc.String()
+			}
+		}
+		b.WriteString(";" + prm.Name + "=" + icsParamValue(v))
+	}
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
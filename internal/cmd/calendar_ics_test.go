@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestWriteICSCalendar_RoundTrip(t *testing.T) {
+	series := &calendar.Event{
+		Id:          "series1",
+		ICalUID:     "series1@google.com",
+		Summary:     "Standup; daily, all hands",
+		Description: strings.Repeat("Agenda item ünicode\n", 6),
+		Location:    "Room 1",
+		Status:      "confirmed",
+		Updated:     "2024-01-02T03:04:05Z",
+		Start:       &calendar.EventDateTime{DateTime: "2024-01-08T09:00:00-05:00", TimeZone: "America/New_York"},
+		End:         &calendar.EventDateTime{DateTime: "2024-01-08T09:15:00-05:00", TimeZone: "America/New_York"},
+		Recurrence:  []string{"RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		Organizer:   &calendar.EventOrganizer{Email: "boss@example.com", DisplayName: "Boss, The"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "a@example.com", ResponseStatus: "accepted"},
+			{Email: "b@example.com", Optional: true, ResponseStatus: "tentative", DisplayName: "B"},
+		},
+		Reminders: &calendar.EventReminders{Overrides: []*calendar.EventReminder{
+			{Method: "popup", Minutes: 10},
+			{Method: "email", Minutes: 1440},
+		}},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{"source": "crm", "ticket": "T-1"},
+			Shared:  map[string]string{"team": "core"},
+		},
+	}
+	cancelled := &calendar.Event{
+		Id:                "series1_20240115T140000Z",
+		Status:            "cancelled",
+		RecurringEventId:  "series1",
+		OriginalStartTime: &calendar.EventDateTime{DateTime: "2024-01-15T09:00:00-05:00", TimeZone: "America/New_York"},
+	}
+	allDay := &calendar.Event{
+		Id:           "holiday",
+		ICalUID:      "holiday@google.com",
+		Summary:      "Holiday",
+		Transparency: "transparent",
+		Visibility:   "private",
+		Start:        &calendar.EventDateTime{Date: "2024-07-04"},
+		End:          &calendar.EventDateTime{Date: "2024-07-05"},
+	}
+
+	var buf bytes.Buffer
+	n, err := writeICSCalendar(&buf, "Team", "America/New_York", "me@example.com", []*calendar.Event{series, cancelled, allDay})
+	if err != nil {
+		t.Fatalf("writeICSCalendar: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 VEVENTs, got %d", n)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n",
+		"DTSTART;TZID=America/New_York:20240108T090000\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n",
+		"EXDATE;TZID=America/New_York:20240115T090000\r\n",
+		"DTSTART;VALUE=DATE:20240704\r\n",
+		"SUMMARY:Standup\\; daily\\, all hands\r\n",
+		"ORGANIZER;CN=\"Boss, The\":mailto:boss@example.com\r\n",
+		"X-GOG-PRIVATE-PROPERTY:source=crm\r\n",
+		"TRIGGER:-PT1440M\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > icsMaxLineOctets {
+			t.Fatalf("line not folded (%d octets): %q", len(line), line)
+		}
+	}
+
+	root, err := parseICS(&buf)
+	if err != nil {
+		t.Fatalf("parseICS: %v", err)
+	}
+	events, err := icsEvents(root, time.UTC)
+	if err != nil {
+		t.Fatalf("icsEvents: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	got := events[0]
+	if got.ICalUID != series.ICalUID || got.Summary != series.Summary || got.Description != series.Description {
+		t.Fatalf("text fields mismatch: %+v", got)
+	}
+	if got.Start.DateTime != "2024-01-08T09:00:00-05:00" || got.Start.TimeZone != "America/New_York" {
+		t.Fatalf("unexpected start: %+v", got.Start)
+	}
+	if strings.Join(got.Recurrence, "|") != "RRULE:FREQ=WEEKLY;BYDAY=MO|EXDATE;TZID=America/New_York:20240115T090000" {
+		t.Fatalf("unexpected recurrence: %v", got.Recurrence)
+	}
+	if got.Organizer.DisplayName != "Boss, The" || len(got.Attendees) != 2 || !got.Attendees[1].Optional || got.Attendees[1].ResponseStatus != "tentative" {
+		t.Fatalf("unexpected people: %+v %+v", got.Organizer, got.Attendees)
+	}
+	if r := got.Reminders; r == nil || len(r.Overrides) != 2 || r.Overrides[1].Method != "email" || r.Overrides[1].Minutes != 1440 {
+		t.Fatalf("unexpected reminders: %+v", got.Reminders)
+	}
+	if ep := got.ExtendedProperties; ep == nil || ep.Private["ticket"] != "T-1" || ep.Shared["team"] != "core" {
+		t.Fatalf("unexpected extended properties: %+v", got.ExtendedProperties)
+	}
+	if h := events[1]; h.Start.Date != "2024-07-04" || h.End.Date != "2024-07-05" || h.Transparency != "transparent" || h.Visibility != "private" {
+		t.Fatalf("unexpected all-day event: %+v", h)
+	}
+}
+
+func TestICSEvents_ThirdParty(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTIMEZONE",
+		"TZID:Pacific Standard Time",
+		"X-LIC-LOCATION:America/Los_Angeles",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:abc",
+		"DTSTART;TZID=\"Pacific Standard Time\":20240301T100000",
+		"DURATION:PT1H30M",
+		"RRULE:FREQ=DAILY;COUNT=3",
+		"EXDATE;TZID=Pacific Standard Time:20240302T100000",
+		"SUMMARY:Long line that",
+		"  continues",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"TRIGGER;RELATED=START:-P1DT2H",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:floating",
+		"DTSTART:20240301T080000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\n")
+
+	root, err := parseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("parseICS: %v", err)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	events, err := icsEvents(root, berlin)
+	if err != nil {
+		t.Fatalf("icsEvents: %v", err)
+	}
+	ev := events[0]
+	if ev.Summary != "Long line that continues" {
+		t.Fatalf("unexpected summary: %q", ev.Summary)
+	}
+	if ev.Start.TimeZone != "America/Los_Angeles" || ev.Start.DateTime != "2024-03-01T10:00:00-08:00" || ev.End.DateTime != "2024-03-01T11:30:00-08:00" {
+		t.Fatalf("unexpected times: %+v %+v", ev.Start, ev.End)
+	}
+	if ev.Recurrence[1] != "EXDATE;TZID=America/Los_Angeles:20240302T100000" {
+		t.Fatalf("expected TZID mapped to Olson name, got %q", ev.Recurrence[1])
+	}
+	if ev.Reminders == nil || ev.Reminders.Overrides[0].Minutes != 26*60 {
+		t.Fatalf("unexpected reminders: %+v", ev.Reminders)
+	}
+	if f := events[1]; f.Start.DateTime != "2024-03-01T08:00:00+01:00" || f.End.DateTime != f.Start.DateTime {
+		t.Fatalf("expected floating time in default zone, got %+v %+v", f.Start, f.End)
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT15M":     15 * time.Minute,
+		"-PT15M":    -15 * time.Minute,
+		"P1W":       7 * 24 * time.Hour,
+		"P1DT2H3S":  26*time.Hour + 3*time.Second,
+		"+PT0S":     0,
+		"-P2DT30M":  -(48*time.Hour + 30*time.Minute),
+		"PT1H30M0S": 90 * time.Minute,
+	}
+	for in, want := range cases {
+		got, err := parseICSDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("parseICSDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "15M", "PT", "P1H", "PT5"} {
+		if _, err := parseICSDuration(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
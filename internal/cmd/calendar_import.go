@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type CalendarImportCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID or name"`
+	File       string `arg:"" name:"file" help:"iCalendar (.ics) file, or '-' for stdin"`
+	Update     bool   `name:"update" help:"Re-import events whose iCalUID already exists (updates them in place)"`
+}
+
+func (c *CalendarImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("empty calendarId")
+	}
+
+	var r io.Reader = os.Stdin
+	if c.File != "-" {
+		path, expandErr := config.ExpandPath(c.File)
+		if expandErr != nil {
+			return expandErr
+		}
+		f, openErr := os.Open(path) //nolint:gosec // user-provided path
+		if openErr != nil {
+			return openErr
+		}
+		defer f.Close()
+		r = f
+	}
+	root, err := parseICS(r)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", c.File, err)
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+	cal, err := svc.Calendars.Get(calendarID).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	// Floating times (no TZID) are interpreted in the target calendar's zone.
+	def := time.UTC
+	if loc, locErr := time.LoadLocation(cal.TimeZone); locErr == nil && cal.TimeZone != "" {
+		def = loc
+	}
+
+	events, err := icsEvents(root, def)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if ev.ICalUID == "" {
+			ev.ICalUID = syntheticICalUID(ev)
+		}
+	}
+	// Series before their modified instances.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OriginalStartTime == nil && events[j].OriginalStartTime != nil
+	})
+
+	existing := map[string]map[string]bool{}
+	exists := func(ev *calendar.Event) (bool, error) {
+		keys, ok := existing[ev.ICalUID]
+		if !ok {
+			keys = map[string]bool{}
+			items, listErr := collectAllPages("", func(pageToken string) ([]*calendar.Event, string, error) {
+				call := svc.Events.List(calendarID).ICalUID(ev.ICalUID).Context(ctx)
+				if pageToken != "" {
+					call = call.PageToken(pageToken)
+				}
+				resp, doErr := call.Do()
+				if doErr != nil {
+					return nil, "", doErr
+				}
+				return resp.Items, resp.NextPageToken, nil
+			})
+			if listErr != nil {
+				return false, listErr
+			}
+			for _, item := range items {
+				keys[icsInstanceKey(item.OriginalStartTime)] = true
+			}
+			existing[ev.ICalUID] = keys
+		}
+		return keys[icsInstanceKey(ev.OriginalStartTime)], nil
+	}
+
+	type pendingEvent struct {
+		event  *calendar.Event
+		exists bool
+	}
+	pending := make([]pendingEvent, 0, len(events))
+	skipped := 0
+	for _, ev := range events {
+		found, existsErr := exists(ev)
+		if existsErr != nil {
+			return existsErr
+		}
+		if found && !c.Update {
+			skipped++
+			continue
+		}
+		pending = append(pending, pendingEvent{event: ev, exists: found})
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.import", map[string]any{
+		"calendarId": calendarID,
+		"events":     len(events),
+		"import":     len(pending),
+		"skipped":    skipped,
+	}); err != nil {
+		return err
+	}
+
+	imported, updated, failed := 0, 0, 0
+	for _, p := range pending {
+		if _, importErr := svc.Events.Import(calendarID, p.event).Context(ctx).Do(); importErr != nil {
+			failed++
+			u.Err().Printf("import %s (%s): %v", p.event.ICalUID, p.event.Summary, importErr)
+			continue
+		}
+		if p.exists {
+			updated++
+		} else {
+			imported++
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"calendarId": calendarID,
+			"imported":   imported,
+			"updated":    updated,
+			"skipped":    skipped,
+			"failed":     failed,
+		}); err != nil {
+			return err
+		}
+	} else {
+		u.Out().Printf("Imported %d events (%d updated, %d already present, %d failed)", imported, updated, skipped, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d events failed to import", failed)
+	}
+	return nil
+}
+
+// icsInstanceKey identifies a series master ("") or one of its instances by
+// original start, normalized so API and file representations compare equal.
+func icsInstanceKey(orig *calendar.EventDateTime) string {
+	if orig == nil {
+		return ""
+	}
+	if orig.Date != "" {
+		return orig.Date
+	}
+	if t, err := time.Parse(time.RFC3339, orig.DateTime); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return orig.DateTime
+}
+
+// syntheticICalUID gives UID-less VEVENTs a stable UID so re-importing the
+// same file still dedupes.
+func syntheticICalUID(ev *calendar.Event) string {
+	h := sha256.New()
+	h.Write([]byte(ev.Summary))
+	if ev.Start != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(ev.Start.Date + ev.Start.DateTime))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(ev.Location))
+	return hex.EncodeToString(h.Sum(nil))[:32] + "@gogcli"
+}
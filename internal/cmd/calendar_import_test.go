@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func TestCalendarImportCmd_DedupesByICalUID(t *testing.T) {
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+
+	var (
+		mu       sync.Mutex
+		imported []*calendar.Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/events/import") && r.Method == http.MethodPost:
+			var ev calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&ev)
+			imported = append(imported, &ev)
+			_ = json.NewEncoder(w).Encode(ev)
+		case strings.HasSuffix(r.URL.Path, "/events") && r.Method == http.MethodGet:
+			var items []map[string]any
+			switch r.URL.Query().Get("iCalUID") {
+			case "existing@example.com":
+				items = append(items, map[string]any{"id": "e1", "iCalUID": "existing@example.com"})
+			case "series@example.com":
+				// Only the master exists; its moved instance does not.
+				items = append(items, map[string]any{"id": "s1", "iCalUID": "series@example.com"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case strings.HasSuffix(r.URL.Path, "/calendars/team@example.com") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "team@example.com", "summary": "Team", "timeZone": "Europe/Berlin"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := calendar.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newCalendarService = func(context.Context, string) (*calendar.Service, error) { return svc, nil }
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:series@example.com",
+		"RECURRENCE-ID;TZID=Europe/Berlin:20240108T090000",
+		"DTSTART;TZID=Europe/Berlin:20240108T110000",
+		"DTEND;TZID=Europe/Berlin:20240108T113000",
+		"SUMMARY:Moved standup",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:existing@example.com",
+		"DTSTART;VALUE=DATE:20240101",
+		"SUMMARY:Already there",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:new@example.com",
+		"DTSTART:20240102T100000",
+		"DTEND:20240102T110000",
+		"SUMMARY:Brand new",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	path := filepath.Join(t.TempDir(), "in.ics")
+	if writeErr := os.WriteFile(path, []byte(ics), 0o600); writeErr != nil {
+		t.Fatalf("write: %v", writeErr)
+	}
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+
+	out := captureStdout(t, func() {
+		cmd := &CalendarImportCmd{CalendarID: "team@example.com", File: path}
+		if runErr := cmd.Run(ctx, &RootFlags{Account: "a@b.com"}); runErr != nil {
+			t.Fatalf("Run: %v", runErr)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	if parsed["imported"] != float64(2) || parsed["skipped"] != float64(1) || parsed["failed"] != float64(0) {
+		t.Fatalf("unexpected result: %v", parsed)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("expected 2 imports, got %d", len(imported))
+	}
+	floating := imported[0]
+	if imported[0].ICalUID == "series@example.com" {
+		floating = imported[1]
+	}
+	if floating.ICalUID != "new@example.com" || floating.Start.DateTime != "2024-01-02T10:00:00+01:00" || floating.Start.TimeZone != "Europe/Berlin" {
+		t.Fatalf("expected floating time in calendar zone, got %+v", floating.Start)
+	}
+	for _, ev := range imported {
+		if ev.ICalUID == "series@example.com" && (ev.OriginalStartTime == nil || ev.OriginalStartTime.DateTime != "2024-01-08T09:00:00+01:00") {
+			t.Fatalf("expected instance original start, got %+v", ev.OriginalStartTime)
+		}
+	}
+}
@@ -48,6 +48,12 @@ func listCalendarEvents(ctx context.Context, svc *calendar.Service, calendarID,
 		return resp.Items, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, allPages, page, failEmpty, fetch, func(events []*calendar.Event) error {
+			return writeNDJSON(ctx, wrapEventsWithDays(events))
+		})
+	}
+
 	var items []*calendar.Event
 	nextPageToken := ""
 	if allPages {
@@ -126,6 +132,8 @@ func listSelectedCalendarsEvents(ctx context.Context, svc *calendar.Service, cal
 func listCalendarIDsEvents(ctx context.Context, svc *calendar.Service, calendarIDs []string, from, to string, maxResults int64, page string, allPages bool, failEmpty bool, query, privatePropFilter, sharedPropFilter, fields string, showWeekday bool) error {
 	u := ui.FromContext(ctx)
 	all := []*eventWithCalendar{}
+	streaming := outfmt.IsNDJSON(ctx)
+	streamed := 0
 	for _, calID := range calendarIDs {
 		calID = strings.TrimSpace(calID)
 		if calID == "" {
@@ -139,6 +147,28 @@ func listCalendarIDsEvents(ctx context.Context, svc *calendar.Service, calendarI
 			return resp.Items, resp.NextPageToken, nil
 		}
 
+		if streaming {
+			// Stream each calendar's pages as they arrive; failures are reported per calendar.
+			visit := func(events []*calendar.Event) error {
+				wrapped := wrapEventsWithCalendar(calID, events)
+				streamed += len(wrapped)
+				return writeNDJSON(ctx, wrapped)
+			}
+			var err error
+			if allPages {
+				err = walkPages(page, fetch, visit)
+			} else {
+				var events []*calendar.Event
+				if events, _, err = fetch(page); err == nil {
+					err = visit(events)
+				}
+			}
+			if err != nil {
+				u.Err().Printf("calendar %s: %v", calID, err)
+			}
+			continue
+		}
+
 		var events []*calendar.Event
 		var err error
 		if allPages {
@@ -156,21 +186,14 @@ func listCalendarIDsEvents(ctx context.Context, svc *calendar.Service, calendarI
 			}
 		}
 
-		for _, e := range events {
-			startDay, endDay := eventDaysOfWeek(e)
-			evTimezone := eventTimezone(e)
-			startLocal := formatEventLocal(e.Start, nil)
-			endLocal := formatEventLocal(e.End, nil)
-			all = append(all, &eventWithCalendar{
-				Event:          e,
-				CalendarID:     calID,
-				StartDayOfWeek: startDay,
-				EndDayOfWeek:   endDay,
-				Timezone:       evTimezone,
-				StartLocal:     startLocal,
-				EndLocal:       endLocal,
-			})
+		all = append(all, wrapEventsWithCalendar(calID, events)...)
+	}
+
+	if streaming {
+		if streamed == 0 {
+			return failEmptyExit(failEmpty)
 		}
+		return nil
 	}
 
 	if outfmt.IsJSON(ctx) {
@@ -185,6 +208,26 @@ func listCalendarIDsEvents(ctx context.Context, svc *calendar.Service, calendarI
 	return renderCalendarEventsTable(ctx, all, "", true, showWeekday, failEmpty, false)
 }
 
+func wrapEventsWithCalendar(calID string, events []*calendar.Event) []*eventWithCalendar {
+	out := make([]*eventWithCalendar, 0, len(events))
+	for _, e := range events {
+		startDay, endDay := eventDaysOfWeek(e)
+		evTimezone := eventTimezone(e)
+		startLocal := formatEventLocal(e.Start, nil)
+		endLocal := formatEventLocal(e.End, nil)
+		out = append(out, &eventWithCalendar{
+			Event:          e,
+			CalendarID:     calID,
+			StartDayOfWeek: startDay,
+			EndDayOfWeek:   endDay,
+			Timezone:       evTimezone,
+			StartLocal:     startLocal,
+			EndLocal:       endLocal,
+		})
+	}
+	return out
+}
+
 func renderCalendarEventsTable(ctx context.Context, events []*eventWithCalendar, nextPageToken string, includeCalendar, showWeekday, failEmpty bool, printPageHint bool) error {
 	u := ui.FromContext(ctx)
 	if len(events) == 0 {
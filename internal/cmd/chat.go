@@ -1,8 +1,11 @@
 package cmd
 
 type ChatCmd struct {
-	Spaces   ChatSpacesCmd   `cmd:"" name:"spaces" help:"Chat spaces"`
-	Messages ChatMessagesCmd `cmd:"" name:"messages" help:"Chat messages"`
-	Threads  ChatThreadsCmd  `cmd:"" name:"threads" help:"Chat threads"`
-	DM       ChatDMCmd       `cmd:"" name:"dm" help:"Direct messages"`
+	Spaces      ChatSpacesCmd      `cmd:"" name:"spaces" help:"Chat spaces"`
+	Members     ChatMembersCmd     `cmd:"" name:"members" help:"Chat space members"`
+	Messages    ChatMessagesCmd    `cmd:"" name:"messages" help:"Chat messages"`
+	Reactions   ChatReactionsCmd   `cmd:"" name:"reactions" help:"Chat message reactions"`
+	Attachments ChatAttachmentsCmd `cmd:"" name:"attachments" help:"Chat message attachments"`
+	Threads     ChatThreadsCmd     `cmd:"" name:"threads" help:"Chat threads"`
+	DM          ChatDMCmd          `cmd:"" name:"dm" help:"Direct messages"`
 }
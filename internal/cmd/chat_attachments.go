@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/chat/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type ChatAttachmentsCmd struct {
+	Download ChatAttachmentsDownloadCmd `cmd:"" name:"download" aliases:"get,dl" help:"Download uploaded attachments from a message"`
+}
+
+type ChatAttachmentsDownloadCmd struct {
+	Target    string        `arg:"" name:"attachmentOrMessage" help:"Attachment (spaces/.../messages/.../attachments/...) or message name (downloads all of its uploads)"`
+	OutputDir OutputDirFlag `embed:""`
+}
+
+const defaultChatAttachmentFilename = "attachment.bin"
+
+type chatAttachmentDownload struct {
+	Attachment  string `json:"attachment"`
+	Path        string `json:"path,omitempty"`
+	Bytes       int64  `json:"bytes"`
+	ContentType string `json:"contentType,omitempty"`
+	DriveFileID string `json:"driveFileId,omitempty"`
+	Skipped     string `json:"skipped,omitempty"`
+}
+
+func (c *ChatAttachmentsDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	target := strings.TrimSpace(c.Target)
+	single := strings.Contains(target, "/attachments/")
+	if !single {
+		name, err := normalizeChatMessage(target)
+		if err != nil {
+			return usage(err.Error())
+		}
+		target = name
+	} else if !strings.HasPrefix(target, "spaces/") {
+		return usagef("invalid attachment %q", target)
+	}
+
+	dir := "."
+	if strings.TrimSpace(c.OutputDir.Dir) != "" {
+		expanded, err := config.ExpandPath(c.OutputDir.Dir)
+		if err != nil {
+			return err
+		}
+		dir = filepath.Clean(expanded)
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.attachments.download", map[string]any{
+		"target":  target,
+		"out_dir": dir,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	var attachments []*chat.Attachment
+	if single {
+		a, getErr := svc.Spaces.Messages.Attachments.Get(target).Context(ctx).Do()
+		if getErr != nil {
+			return getErr
+		}
+		attachments = []*chat.Attachment{a}
+	} else {
+		msg, getErr := svc.Spaces.Messages.Get(target).Context(ctx).Do()
+		if getErr != nil {
+			return getErr
+		}
+		attachments = msg.Attachment
+	}
+	if len(attachments) == 0 {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"downloads": []chatAttachmentDownload{}})
+		}
+		u.Err().Println("No attachments")
+		return nil
+	}
+
+	downloads := make([]chatAttachmentDownload, 0, len(attachments))
+	used := map[string]bool{}
+	for _, a := range attachments {
+		if a == nil {
+			continue
+		}
+		item := chatAttachmentDownload{Attachment: a.Name, ContentType: a.ContentType}
+		if a.AttachmentDataRef == nil || a.AttachmentDataRef.ResourceName == "" {
+			// Drive-backed attachments have no media to download via Chat.
+			if a.DriveDataRef != nil {
+				item.DriveFileID = a.DriveDataRef.DriveFileId
+				item.Skipped = "drive file (use gog drive download)"
+			} else {
+				item.Skipped = "no downloadable content"
+			}
+			downloads = append(downloads, item)
+			continue
+		}
+
+		path := filepath.Join(dir, uniqueChatAttachmentName(used, sanitizeAttachmentFilename(a.ContentName, defaultChatAttachmentFilename)))
+		n, dlErr := downloadChatAttachment(ctx, svc, a.AttachmentDataRef.ResourceName, path)
+		if dlErr != nil {
+			return fmt.Errorf("download %s: %w", a.Name, dlErr)
+		}
+		item.Path = path
+		item.Bytes = n
+		downloads = append(downloads, item)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"downloads": downloads})
+	}
+	for _, d := range downloads {
+		if d.Skipped != "" {
+			u.Err().Printf("skipped %s: %s %s", d.Attachment, d.Skipped, d.DriveFileID)
+			continue
+		}
+		u.Out().Printf("path\t%s", d.Path)
+		u.Out().Printf("bytes\t%d", d.Bytes)
+	}
+	return nil
+}
+
+func downloadChatAttachment(ctx context.Context, svc *chat.Service, resourceName, path string) (int64, error) {
+	resp, err := svc.Media.Download(resourceName).Context(ctx).Download()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// uniqueChatAttachmentName keeps same-named uploads from overwriting each
+// other within one download run ("a.png", "a_2.png", ...).
+func uniqueChatAttachmentName(used map[string]bool, name string) string {
+	candidate := name
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -117,3 +119,88 @@ func sanitizeChatText(s string) string {
 	replacer := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
 	return replacer.Replace(s)
 }
+
+func normalizeChatMessage(resource string) (string, error) {
+	name := strings.TrimSpace(resource)
+	if name == "" {
+		return "", fmt.Errorf("required: message")
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) != 4 || parts[0] != "spaces" || parts[2] != "messages" || parts[1] == "" || parts[3] == "" {
+		return "", fmt.Errorf("invalid message %q (expected spaces/<space>/messages/<message>)", name)
+	}
+	return name, nil
+}
+
+// readChatCards parses a cards v2 spec. It accepts a bare card, a cardWithId,
+// a list of either, or an object with a "cardsV2" list; missing card IDs are
+// filled in as card-1, card-2, ...
+func readChatCards(spec string) ([]*chat.CardWithId, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	b, err := resolveInlineOrFileBytes(spec)
+	if err != nil {
+		return nil, fmt.Errorf("read --cards-json: %w", err)
+	}
+	cards, err := parseChatCards(b)
+	if err != nil {
+		return nil, usagef("invalid --cards-json: %v", err)
+	}
+	return cards, nil
+}
+
+func parseChatCards(b []byte) ([]*chat.CardWithId, error) {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty cards spec")
+	}
+
+	var raw []json.RawMessage
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, err
+		}
+	} else {
+		var wrapper struct {
+			CardsV2 []json.RawMessage `json:"cardsV2"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, err
+		}
+		raw = wrapper.CardsV2
+		if raw == nil {
+			raw = []json.RawMessage{trimmed}
+		}
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no cards")
+	}
+
+	cards := make([]*chat.CardWithId, 0, len(raw))
+	for i, item := range raw {
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return nil, fmt.Errorf("card %d: %w", i+1, err)
+		}
+		card := &chat.CardWithId{}
+		if _, ok := probe["card"]; ok {
+			if err := json.Unmarshal(item, card); err != nil {
+				return nil, fmt.Errorf("card %d: %w", i+1, err)
+			}
+		} else {
+			card.Card = &chat.GoogleAppsCardV1Card{}
+			if err := json.Unmarshal(item, card.Card); err != nil {
+				return nil, fmt.Errorf("card %d: %w", i+1, err)
+			}
+		}
+		if card.Card == nil {
+			return nil, fmt.Errorf("card %d: missing card", i+1)
+		}
+		if card.CardId == "" {
+			card.CardId = fmt.Sprintf("card-%d", i+1)
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/chat/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type ChatMembersCmd struct {
+	List    ChatMembersListCmd    `cmd:"" name:"list" aliases:"ls" help:"List space members"`
+	Add     ChatMembersAddCmd     `cmd:"" name:"add" aliases:"invite" help:"Add members to a space"`
+	Remove  ChatMembersRemoveCmd  `cmd:"" name:"remove" aliases:"delete,rm" help:"Remove a member from a space"`
+	SetRole ChatMembersSetRoleCmd `cmd:"" name:"set-role" aliases:"role" help:"Change a member's role (member|manager|assistant-manager)"`
+}
+
+type ChatMembersListCmd struct {
+	Space       string `arg:"" name:"space" help:"Space name (spaces/...)"`
+	Max         int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Page        string `name:"page" aliases:"cursor" help:"Page token"`
+	All         bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty   bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+	Filter      string `name:"filter" help:"Membership filter (e.g. role = \"ROLE_MANAGER\")"`
+	ShowGroups  bool   `name:"show-groups" help:"Include Google Group memberships"`
+	ShowInvited bool   `name:"show-invited" help:"Include invited memberships"`
+}
+
+func (c *ChatMembersListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	fetch := func(pageToken string) ([]*chat.Membership, string, error) {
+		call := svc.Spaces.Members.List(space).PageSize(c.Max).Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		if filter := strings.TrimSpace(c.Filter); filter != "" {
+			call = call.Filter(filter)
+		}
+		if c.ShowGroups {
+			call = call.ShowGroups(true)
+		}
+		if c.ShowInvited {
+			call = call.ShowInvited(true)
+		}
+		resp, callErr := call.Do()
+		if callErr != nil {
+			return nil, "", callErr
+		}
+		return resp.Memberships, resp.NextPageToken, nil
+	}
+
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*chat.Membership) error {
+			return writeNDJSON(ctx, chatMemberItems(page))
+		})
+	}
+
+	var members []*chat.Membership
+	nextPageToken := ""
+	if c.All {
+		members, err = collectAllPages(c.Page, fetch)
+	} else {
+		members, nextPageToken, err = fetch(c.Page)
+	}
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		items := chatMemberItems(members)
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"members":       items,
+			"nextPageToken": nextPageToken,
+		}); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+
+	if len(members) == 0 {
+		u.Err().Println("No members")
+		return failEmptyExit(c.FailEmpty)
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "RESOURCE\tMEMBER\tNAME\tROLE\tSTATE")
+	for _, item := range chatMemberItems(members) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.Resource,
+			item.Member,
+			sanitizeTab(item.DisplayName),
+			item.Role,
+			item.State,
+		)
+	}
+	printNextPageHint(u, nextPageToken)
+	return nil
+}
+
+type ChatMembersAddCmd struct {
+	Space   string   `arg:"" name:"space" help:"Space name (spaces/...)"`
+	Members []string `arg:"" name:"member" help:"Members to add (email or users/...; comma-separated ok)"`
+	Role    string   `name:"role" help:"Role for the new members: member|manager" default:"member"`
+}
+
+func (c *ChatMembersAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+	users := make([]string, 0, len(c.Members))
+	for _, member := range parseCommaArgs(c.Members) {
+		users = append(users, normalizeUser(member))
+	}
+	if len(users) == 0 {
+		return usage("required: member")
+	}
+	role, err := chatMemberRole(c.Role)
+	if err != nil {
+		return err
+	}
+	if role == "ROLE_ASSISTANT_MANAGER" {
+		return usage("--role assistant-manager can only be set on existing members (use set-role)")
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.members.add", map[string]any{
+		"space":   space,
+		"members": users,
+		"role":    role,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	added := make([]*chat.Membership, 0, len(users))
+	for _, user := range users {
+		m, addErr := addChatMember(ctx, svc, space, user, role)
+		if addErr != nil {
+			return fmt.Errorf("add %s: %w", user, addErr)
+		}
+		added = append(added, m)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"members": chatMemberItems(added)})
+	}
+	for _, item := range chatMemberItems(added) {
+		u.Out().Printf("added\t%s\t%s", item.Resource, item.Role)
+	}
+	return nil
+}
+
+type ChatMembersRemoveCmd struct {
+	Space  string `arg:"" name:"space" help:"Space name (spaces/...)"`
+	Member string `arg:"" name:"member" help:"Member (email, users/..., or spaces/.../members/...)"`
+}
+
+func (c *ChatMembersRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+	name, err := normalizeChatMember(space, c.Member)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	if err = confirmDestructive(ctx, flags, fmt.Sprintf("remove %s from %s", name, space)); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.Spaces.Members.Delete(name).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u, kv("removed", true), kv("member", name))
+}
+
+type ChatMembersSetRoleCmd struct {
+	Space  string `arg:"" name:"space" help:"Space name (spaces/...)"`
+	Member string `arg:"" name:"member" help:"Member (email, users/..., or spaces/.../members/...)"`
+	Role   string `arg:"" name:"role" help:"member|manager|assistant-manager"`
+}
+
+func (c *ChatMembersSetRoleCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+	name, err := normalizeChatMember(space, c.Member)
+	if err != nil {
+		return usage(err.Error())
+	}
+	role, err := chatMemberRole(c.Role)
+	if err != nil {
+		return err
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.members.set_role", map[string]any{
+		"member": name,
+		"role":   role,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Spaces.Members.Patch(name, &chat.Membership{Role: role}).UpdateMask("role").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"member": resp})
+	}
+	u.Out().Printf("resource\t%s", resp.Name)
+	u.Out().Printf("role\t%s", resp.Role)
+	return nil
+}
+
+func addChatMember(ctx context.Context, svc *chat.Service, space, user, role string) (*chat.Membership, error) {
+	m := &chat.Membership{Member: &chat.User{Name: user, Type: "HUMAN"}}
+	if role != "" && role != "ROLE_MEMBER" {
+		m.Role = role
+	}
+	return svc.Spaces.Members.Create(space, m).Context(ctx).Do()
+}
+
+// normalizeChatMember builds a membership name. The Chat API accepts a user's
+// email in place of the numeric member ID.
+func normalizeChatMember(space, member string) (string, error) {
+	member = strings.TrimSpace(member)
+	if member == "" {
+		return "", fmt.Errorf("required: member")
+	}
+	if strings.HasPrefix(member, "spaces/") {
+		if !strings.Contains(member, "/members/") {
+			return "", fmt.Errorf("invalid member %q", member)
+		}
+		return member, nil
+	}
+	member = strings.TrimPrefix(member, "users/")
+	if strings.Contains(member, "/") {
+		return "", fmt.Errorf("invalid member %q", member)
+	}
+	return space + "/members/" + member, nil
+}
+
+func chatMemberRole(role string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "", "member", "role_member":
+		return "ROLE_MEMBER", nil
+	case "manager", "role_manager":
+		return "ROLE_MANAGER", nil
+	case "assistant-manager", "assistant_manager", "role_assistant_manager":
+		return "ROLE_ASSISTANT_MANAGER", nil
+	default:
+		return "", usagef("invalid role %q (expected member|manager|assistant-manager)", role)
+	}
+}
+
+type chatMemberItem struct {
+	Resource    string `json:"resource"`
+	Member      string `json:"member,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Role        string `json:"role,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+func chatMemberItems(members []*chat.Membership) []chatMemberItem {
+	items := make([]chatMemberItem, 0, len(members))
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+		item := chatMemberItem{Resource: m.Name, Role: m.Role, State: m.State}
+		switch {
+		case m.Member != nil:
+			item.Member = m.Member.Name
+			item.DisplayName = m.Member.DisplayName
+			item.Type = m.Member.Type
+		case m.GroupMember != nil:
+			item.Member = m.GroupMember.Name
+			item.Type = "GROUP"
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -13,8 +13,11 @@ import (
 )
 
 type ChatMessagesCmd struct {
-	List ChatMessagesListCmd `cmd:"" name:"list" aliases:"ls" help:"List messages"`
-	Send ChatMessagesSendCmd `cmd:"" name:"send" aliases:"create,post" help:"Send a message"`
+	List   ChatMessagesListCmd   `cmd:"" name:"list" aliases:"ls" help:"List messages"`
+	Send   ChatMessagesSendCmd   `cmd:"" name:"send" aliases:"create,post" help:"Send a message"`
+	Get    ChatMessagesGetCmd    `cmd:"" name:"get" aliases:"show,info" help:"Get a message"`
+	Update ChatMessagesUpdateCmd `cmd:"" name:"update" aliases:"edit" help:"Update a message's text or cards"`
+	Delete ChatMessagesDeleteCmd `cmd:"" name:"delete" aliases:"rm,del" help:"Delete a message"`
 }
 
 type ChatMessagesListCmd struct {
@@ -88,6 +91,12 @@ func (c *ChatMessagesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Messages, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*chat.Message) error {
+			return writeNDJSON(ctx, chatMessageItems(page))
+		})
+	}
+
 	var messages []*chat.Message
 	nextPageToken := ""
 	if c.All {
@@ -105,26 +114,7 @@ func (c *ChatMessagesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource   string `json:"resource"`
-			Sender     string `json:"sender,omitempty"`
-			Text       string `json:"text,omitempty"`
-			CreateTime string `json:"createTime,omitempty"`
-			Thread     string `json:"thread,omitempty"`
-		}
-		items := make([]item, 0, len(messages))
-		for _, msg := range messages {
-			if msg == nil {
-				continue
-			}
-			items = append(items, item{
-				Resource:   msg.Name,
-				Sender:     chatMessageSender(msg),
-				Text:       chatMessageText(msg),
-				CreateTime: msg.CreateTime,
-				Thread:     chatMessageThread(msg),
-			})
-		}
+		items := chatMessageItems(messages)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"messages":      items,
 			"nextPageToken": nextPageToken,
@@ -161,9 +151,10 @@ func (c *ChatMessagesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type ChatMessagesSendCmd struct {
-	Space  string `arg:"" name:"space" help:"Space name (spaces/...)"`
-	Text   string `name:"text" help:"Message text (required)"`
-	Thread string `name:"thread" help:"Reply to thread (spaces/.../threads/...)"`
+	Space     string `arg:"" name:"space" help:"Space name (spaces/...)"`
+	Text      string `name:"text" help:"Message text (required unless --cards-json is set)"`
+	CardsJSON string `name:"cards-json" help:"Cards v2 JSON (inline, @file, or - for stdin): a card, a cardWithId, a list of them, or {\"cardsV2\": [...]}"`
+	Thread    string `name:"thread" help:"Reply to thread (spaces/.../threads/...)"`
 }
 
 func (c *ChatMessagesSendCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -174,11 +165,15 @@ func (c *ChatMessagesSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	text := strings.TrimSpace(c.Text)
-	if text == "" {
-		return usage("required: --text")
+	cards, err := readChatCards(c.CardsJSON)
+	if err != nil {
+		return err
+	}
+	if text == "" && len(cards) == 0 {
+		return usage("required: --text or --cards-json")
 	}
 
-	message := &chat.Message{Text: text}
+	message := &chat.Message{Text: text, CardsV2: cards}
 	thread := strings.TrimSpace(c.Thread)
 	threadName := ""
 	if thread != "" {
@@ -193,6 +188,7 @@ func (c *ChatMessagesSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if dryRunErr := dryRunExit(ctx, flags, "chat.messages.send", map[string]any{
 		"space":                        space,
 		"text":                         text,
+		"cards":                        len(cards),
 		"thread":                       threadName,
 		"thread_raw":                   thread,
 		"reply_fallback_to_new_thread": thread != "",
@@ -239,3 +235,200 @@ func (c *ChatMessagesSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 	return nil
 }
+
+type ChatMessagesGetCmd struct {
+	Message string `arg:"" name:"message" help:"Message name (spaces/.../messages/...)"`
+}
+
+func (c *ChatMessagesGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name, err := normalizeChatMessage(c.Message)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	msg, err := svc.Spaces.Messages.Get(name).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"message": msg})
+	}
+
+	u.Out().Printf("resource\t%s", msg.Name)
+	if sender := chatMessageSender(msg); sender != "" {
+		u.Out().Printf("sender\t%s", sender)
+	}
+	u.Out().Printf("created\t%s", msg.CreateTime)
+	if msg.LastUpdateTime != "" {
+		u.Out().Printf("updated\t%s", msg.LastUpdateTime)
+	}
+	if thread := chatMessageThread(msg); thread != "" {
+		u.Out().Printf("thread\t%s", thread)
+	}
+	if len(msg.CardsV2) > 0 {
+		u.Out().Printf("cards\t%d", len(msg.CardsV2))
+	}
+	for _, a := range msg.Attachment {
+		if a == nil {
+			continue
+		}
+		u.Out().Printf("attachment\t%s\t%s\t%s", a.Name, sanitizeTab(a.ContentName), a.ContentType)
+	}
+	if text := chatMessageText(msg); text != "" {
+		u.Out().Println("")
+		u.Out().Println(text)
+	}
+	return nil
+}
+
+type ChatMessagesUpdateCmd struct {
+	Message   string `arg:"" name:"message" help:"Message name (spaces/.../messages/...)"`
+	Text      string `name:"text" help:"New message text"`
+	CardsJSON string `name:"cards-json" help:"Replacement cards v2 JSON (inline, @file, or - for stdin)"`
+}
+
+func (c *ChatMessagesUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name, err := normalizeChatMessage(c.Message)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	text := strings.TrimSpace(c.Text)
+	cards, err := readChatCards(c.CardsJSON)
+	if err != nil {
+		return err
+	}
+	mask := make([]string, 0, 2)
+	if text != "" {
+		mask = append(mask, "text")
+	}
+	if len(cards) > 0 {
+		mask = append(mask, "cards_v2")
+	}
+	if len(mask) == 0 {
+		return usage("required: --text or --cards-json")
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.messages.update", map[string]any{
+		"message":     name,
+		"text":        text,
+		"cards":       len(cards),
+		"update_mask": strings.Join(mask, ","),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Spaces.Messages.Patch(name, &chat.Message{Text: text, CardsV2: cards}).
+		UpdateMask(strings.Join(mask, ",")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"message": resp})
+	}
+	u.Out().Printf("resource\t%s", resp.Name)
+	if resp.LastUpdateTime != "" {
+		u.Out().Printf("updated\t%s", resp.LastUpdateTime)
+	}
+	return nil
+}
+
+type ChatMessagesDeleteCmd struct {
+	Message     string `arg:"" name:"message" help:"Message name (spaces/.../messages/...)"`
+	WithReplies bool   `name:"with-replies" help:"Also delete threaded replies"`
+}
+
+func (c *ChatMessagesDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name, err := normalizeChatMessage(c.Message)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	action := fmt.Sprintf("delete chat message %s", name)
+	if c.WithReplies {
+		action += " and its replies"
+	}
+	if err = confirmDestructive(ctx, flags, action); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	call := svc.Spaces.Messages.Delete(name).Context(ctx)
+	if c.WithReplies {
+		call = call.Force(true)
+	}
+	if _, err := call.Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u, kv("deleted", true), kv("message", name))
+}
+
+type chatMessageItem struct {
+	Resource   string `json:"resource"`
+	Sender     string `json:"sender,omitempty"`
+	Text       string `json:"text,omitempty"`
+	CreateTime string `json:"createTime,omitempty"`
+	Thread     string `json:"thread,omitempty"`
+}
+
+func chatMessageItems(messages []*chat.Message) []chatMessageItem {
+	items := make([]chatMessageItem, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		items = append(items, chatMessageItem{
+			Resource:   msg.Name,
+			Sender:     chatMessageSender(msg),
+			Text:       chatMessageText(msg),
+			CreateTime: msg.CreateTime,
+			Thread:     chatMessageThread(msg),
+		})
+	}
+	return items
+}
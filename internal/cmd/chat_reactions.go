@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/chat/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type ChatReactionsCmd struct {
+	Add    ChatReactionsAddCmd    `cmd:"" name:"add" aliases:"create,react" help:"Add a reaction to a message"`
+	List   ChatReactionsListCmd   `cmd:"" name:"list" aliases:"ls" help:"List reactions on a message"`
+	Remove ChatReactionsRemoveCmd `cmd:"" name:"remove" aliases:"delete,rm" help:"Remove a reaction"`
+}
+
+type ChatReactionsAddCmd struct {
+	Message string `arg:"" name:"message" help:"Message name (spaces/.../messages/...)"`
+	Emoji   string `arg:"" name:"emoji" help:"Unicode emoji (e.g. 👍) or custom emoji name (customEmojis/...)"`
+}
+
+func (c *ChatReactionsAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	message, err := normalizeChatMessage(c.Message)
+	if err != nil {
+		return usage(err.Error())
+	}
+	emoji := chatEmoji(c.Emoji)
+	if emoji == nil {
+		return usage("required: emoji")
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.reactions.add", map[string]any{
+		"message": message,
+		"emoji":   strings.TrimSpace(c.Emoji),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Spaces.Messages.Reactions.Create(message, &chat.Reaction{Emoji: emoji}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"reaction": resp})
+	}
+	u.Out().Printf("resource\t%s", resp.Name)
+	u.Out().Printf("emoji\t%s", chatEmojiLabel(resp.Emoji))
+	return nil
+}
+
+type ChatReactionsListCmd struct {
+	Message   string `arg:"" name:"message" help:"Message name (spaces/.../messages/...)"`
+	Emoji     string `name:"emoji" help:"Only reactions with this unicode emoji"`
+	User      string `name:"user" help:"Only reactions by this user (users/... or ID)"`
+	Max       int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Page      string `name:"page" aliases:"cursor" help:"Page token"`
+	All       bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+}
+
+func (c *ChatReactionsListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	message, err := normalizeChatMessage(c.Message)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	filters := make([]string, 0, 2)
+	if emoji := strings.TrimSpace(c.Emoji); emoji != "" {
+		filters = append(filters, fmt.Sprintf("emoji.unicode = %q", emoji))
+	}
+	if user := normalizeUser(c.User); user != "" {
+		filters = append(filters, fmt.Sprintf("user.name = %q", user))
+	}
+	filter := strings.Join(filters, " AND ")
+
+	fetch := func(pageToken string) ([]*chat.Reaction, string, error) {
+		call := svc.Spaces.Messages.Reactions.List(message).
+			PageSize(c.Max).
+			Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		if filter != "" {
+			call = call.Filter(filter)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Reactions, resp.NextPageToken, nil
+	}
+
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*chat.Reaction) error {
+			return writeNDJSON(ctx, chatReactionItems(page))
+		})
+	}
+
+	var reactions []*chat.Reaction
+	nextPageToken := ""
+	if c.All {
+		reactions, err = collectAllPages(c.Page, fetch)
+	} else {
+		reactions, nextPageToken, err = fetch(c.Page)
+	}
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		items := chatReactionItems(reactions)
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"reactions":     items,
+			"nextPageToken": nextPageToken,
+		}); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+
+	if len(reactions) == 0 {
+		u.Err().Println("No reactions")
+		return failEmptyExit(c.FailEmpty)
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "RESOURCE\tEMOJI\tUSER")
+	for _, item := range chatReactionItems(reactions) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", item.Resource, item.Emoji, item.User)
+	}
+	printNextPageHint(u, nextPageToken)
+	return nil
+}
+
+type ChatReactionsRemoveCmd struct {
+	Reaction string `arg:"" name:"reaction" help:"Reaction name (spaces/.../messages/.../reactions/...)"`
+}
+
+func (c *ChatReactionsRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name := strings.TrimSpace(c.Reaction)
+	if name == "" {
+		return usage("required: reaction")
+	}
+	if !strings.HasPrefix(name, "spaces/") || !strings.Contains(name, "/reactions/") {
+		return usagef("invalid reaction %q (expected spaces/.../messages/.../reactions/...)", name)
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.reactions.remove", map[string]any{
+		"reaction": name,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.Spaces.Messages.Reactions.Delete(name).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u, kv("removed", true), kv("reaction", name))
+}
+
+type chatReactionItem struct {
+	Resource string `json:"resource"`
+	Emoji    string `json:"emoji"`
+	User     string `json:"user,omitempty"`
+}
+
+func chatReactionItems(reactions []*chat.Reaction) []chatReactionItem {
+	items := make([]chatReactionItem, 0, len(reactions))
+	for _, r := range reactions {
+		if r == nil {
+			continue
+		}
+		item := chatReactionItem{Resource: r.Name, Emoji: chatEmojiLabel(r.Emoji)}
+		if r.User != nil {
+			item.User = r.User.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func chatEmoji(value string) *chat.Emoji {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return nil
+	case strings.HasPrefix(value, "customEmojis/"):
+		return &chat.Emoji{CustomEmoji: &chat.CustomEmoji{Name: value}}
+	default:
+		return &chat.Emoji{Unicode: value}
+	}
+}
+
+func chatEmojiLabel(emoji *chat.Emoji) string {
+	switch {
+	case emoji == nil:
+		return ""
+	case emoji.Unicode != "":
+		return emoji.Unicode
+	case emoji.CustomEmoji != nil && emoji.CustomEmoji.EmojiName != "":
+		return emoji.CustomEmoji.EmojiName
+	case emoji.CustomEmoji != nil:
+		return emoji.CustomEmoji.Name
+	}
+	return ""
+}
@@ -16,6 +16,10 @@ type ChatSpacesCmd struct {
 	List   ChatSpacesListCmd   `cmd:"" name:"list" aliases:"ls" help:"List spaces"`
 	Find   ChatSpacesFindCmd   `cmd:"" name:"find" aliases:"search,query" help:"Find spaces by display name"`
 	Create ChatSpacesCreateCmd `cmd:"" name:"create" aliases:"add,new" help:"Create a space"`
+	Get    ChatSpacesGetCmd    `cmd:"" name:"get" aliases:"show,info" help:"Get a space"`
+	Update ChatSpacesUpdateCmd `cmd:"" name:"update" aliases:"edit" help:"Update a space's name, description or history"`
+	Delete ChatSpacesDeleteCmd `cmd:"" name:"delete" aliases:"rm,del" help:"Delete a space"`
+	Setup  ChatSpacesSetupCmd  `cmd:"" name:"setup" aliases:"sync" help:"Create or sync a space's members from a Google Group"`
 }
 
 type ChatSpacesListCmd struct {
@@ -52,6 +56,12 @@ func (c *ChatSpacesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Spaces, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*chat.Space) error {
+			return writeNDJSON(ctx, chatSpaceItems(page))
+		})
+	}
+
 	var spaces []*chat.Space
 	nextPageToken := ""
 	if c.All {
@@ -69,26 +79,7 @@ func (c *ChatSpacesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource    string `json:"resource"`
-			Name        string `json:"name,omitempty"`
-			SpaceType   string `json:"type,omitempty"`
-			SpaceURI    string `json:"uri,omitempty"`
-			ThreadState string `json:"threading,omitempty"`
-		}
-		items := make([]item, 0, len(spaces))
-		for _, space := range spaces {
-			if space == nil {
-				continue
-			}
-			items = append(items, item{
-				Resource:    space.Name,
-				Name:        space.DisplayName,
-				SpaceType:   chatSpaceType(space),
-				SpaceURI:    space.SpaceUri,
-				ThreadState: space.SpaceThreadingState,
-			})
-		}
+		items := chatSpaceItems(spaces)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"spaces":        items,
 			"nextPageToken": nextPageToken,
@@ -297,3 +288,200 @@ func (c *ChatSpacesCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 	return nil
 }
+
+type ChatSpacesGetCmd struct {
+	Space string `arg:"" name:"space" help:"Space name (spaces/...)"`
+}
+
+func (c *ChatSpacesGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Spaces.Get(space).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"space": resp})
+	}
+	u.Out().Printf("resource\t%s", resp.Name)
+	if resp.DisplayName != "" {
+		u.Out().Printf("name\t%s", resp.DisplayName)
+	}
+	u.Out().Printf("type\t%s", chatSpaceType(resp))
+	if resp.SpaceUri != "" {
+		u.Out().Printf("uri\t%s", resp.SpaceUri)
+	}
+	if resp.SpaceDetails != nil && resp.SpaceDetails.Description != "" {
+		u.Out().Printf("description\t%s", sanitizeTab(resp.SpaceDetails.Description))
+	}
+	if resp.SpaceHistoryState != "" {
+		u.Out().Printf("history\t%s", resp.SpaceHistoryState)
+	}
+	if resp.MembershipCount != nil {
+		u.Out().Printf("members\t%d", resp.MembershipCount.JoinedDirectHumanUserCount)
+	}
+	if resp.CreateTime != "" {
+		u.Out().Printf("created\t%s", resp.CreateTime)
+	}
+	return nil
+}
+
+type ChatSpacesUpdateCmd struct {
+	Space       string  `arg:"" name:"space" help:"Space name (spaces/...)"`
+	DisplayName string  `name:"name" aliases:"display-name" help:"New display name"`
+	Description *string `name:"description" help:"New description (empty clears)"`
+	Guidelines  *string `name:"guidelines" help:"New guidelines/rules (empty clears)"`
+	History     string  `name:"history" help:"Message history: on|off"`
+}
+
+func (c *ChatSpacesUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+
+	patch := &chat.Space{}
+	mask := make([]string, 0, 3)
+	if name := strings.TrimSpace(c.DisplayName); name != "" {
+		patch.DisplayName = name
+		mask = append(mask, "display_name")
+	}
+	if c.Description != nil || c.Guidelines != nil {
+		patch.SpaceDetails = &chat.SpaceDetails{}
+		if c.Description != nil {
+			patch.SpaceDetails.Description = *c.Description
+			patch.SpaceDetails.ForceSendFields = append(patch.SpaceDetails.ForceSendFields, "Description")
+		}
+		if c.Guidelines != nil {
+			patch.SpaceDetails.Guidelines = *c.Guidelines
+			patch.SpaceDetails.ForceSendFields = append(patch.SpaceDetails.ForceSendFields, "Guidelines")
+		}
+		mask = append(mask, "space_details")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.History)) {
+	case "":
+	case "on":
+		patch.SpaceHistoryState = "HISTORY_ON"
+		mask = append(mask, "space_history_state")
+	case "off":
+		patch.SpaceHistoryState = "HISTORY_OFF"
+		mask = append(mask, "space_history_state")
+	default:
+		return usagef("invalid --history %q (expected on|off)", c.History)
+	}
+	if len(mask) == 0 {
+		return usage("nothing to update (use --name, --description, --guidelines or --history)")
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "chat.spaces.update", map[string]any{
+		"space":       space,
+		"update_mask": strings.Join(mask, ","),
+		"patch":       patch,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.Spaces.Patch(space, patch).UpdateMask(strings.Join(mask, ",")).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"space": resp})
+	}
+	u.Out().Printf("resource\t%s", resp.Name)
+	if resp.DisplayName != "" {
+		u.Out().Printf("name\t%s", resp.DisplayName)
+	}
+	return nil
+}
+
+type ChatSpacesDeleteCmd struct {
+	Space string `arg:"" name:"space" help:"Space name (spaces/...)"`
+}
+
+func (c *ChatSpacesDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	space, err := normalizeSpace(c.Space)
+	if err != nil {
+		return usage("required: space")
+	}
+
+	if err = confirmDestructive(ctx, flags, fmt.Sprintf("delete chat space %s and all of its messages", space)); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.Spaces.Delete(space).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u, kv("deleted", true), kv("space", space))
+}
+
+type chatSpaceItem struct {
+	Resource    string `json:"resource"`
+	Name        string `json:"name,omitempty"`
+	SpaceType   string `json:"type,omitempty"`
+	SpaceURI    string `json:"uri,omitempty"`
+	ThreadState string `json:"threading,omitempty"`
+}
+
+func chatSpaceItems(spaces []*chat.Space) []chatSpaceItem {
+	items := make([]chatSpaceItem, 0, len(spaces))
+	for _, space := range spaces {
+		if space == nil {
+			continue
+		}
+		items = append(items, chatSpaceItem{
+			Resource:    space.Name,
+			Name:        space.DisplayName,
+			SpaceType:   chatSpaceType(space),
+			SpaceURI:    space.SpaceUri,
+			ThreadState: space.SpaceThreadingState,
+		})
+	}
+	return items
+}
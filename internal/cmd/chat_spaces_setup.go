@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type ChatSpacesSetupCmd struct {
+	Space     string `arg:"" name:"space" help:"Existing space (spaces/...) or display name of a new space"`
+	FromGroup string `name:"from-group" required:"" help:"Google Group email whose (transitive) user members belong in the space"`
+	Prune     bool   `name:"prune" help:"Remove plain members (ROLE_MEMBER) who are no longer in the group"`
+}
+
+type chatSpaceSyncResult struct {
+	Space     string   `json:"space"`
+	Created   bool     `json:"created"`
+	Group     string   `json:"group"`
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+func (c *ChatSpacesSetupCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	target := strings.TrimSpace(c.Space)
+	if target == "" {
+		return usage("required: space")
+	}
+	group := strings.TrimSpace(c.FromGroup)
+	if group == "" {
+		return usage("required: --from-group")
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if err = requireWorkspaceAccount(account); err != nil {
+		return err
+	}
+
+	cloudSvc, err := newCloudIdentityService(ctx, account)
+	if err != nil {
+		return wrapCloudIdentityError(err, account)
+	}
+	emails, err := collectGroupMemberEmails(ctx, cloudSvc, group)
+	if err != nil {
+		return fmt.Errorf("failed to list group members: %w", wrapCloudIdentityError(err, account))
+	}
+
+	svc, err := newChatService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	existing := strings.HasPrefix(target, "spaces/")
+	plan := chatSpaceMemberPlan{Add: emails}
+	if existing {
+		if plan, err = planChatSpaceMembers(ctx, svc, account, target, emails, c.Prune); err != nil {
+			return err
+		}
+	}
+
+	removals := make([]string, 0, len(plan.Remove))
+	for _, m := range plan.Remove {
+		removals = append(removals, m.Member.Name)
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "chat.spaces.setup", map[string]any{
+		"space":  target,
+		"group":  group,
+		"prune":  c.Prune,
+		"add":    plan.Add,
+		"remove": removals,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+	if len(plan.Remove) > 0 {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("remove members not in %s from %s", group, target)); err != nil {
+			return err
+		}
+	}
+
+	result := chatSpaceSyncResult{Group: group, Added: []string{}, Removed: []string{}, Unchanged: plan.Unchanged}
+	if existing {
+		result.Space = target
+	} else {
+		created, createErr := svc.Spaces.Setup(&chat.SetUpSpaceRequest{
+			Space: &chat.Space{SpaceType: "SPACE", DisplayName: target},
+		}).Context(ctx).Do()
+		if createErr != nil {
+			return createErr
+		}
+		result.Space = created.Name
+		result.Created = true
+	}
+
+	if err := applyChatSpaceMembers(ctx, svc, plan, &result); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, result)
+	}
+	u.Out().Printf("space\t%s", result.Space)
+	u.Out().Printf("created\t%t", result.Created)
+	for _, email := range result.Added {
+		u.Out().Printf("added\t%s", email)
+	}
+	for _, member := range result.Removed {
+		u.Out().Printf("removed\t%s", member)
+	}
+	u.Out().Printf("unchanged\t%d", result.Unchanged)
+	return nil
+}
+
+type chatSpaceMemberPlan struct {
+	Add       []string
+	Remove    []*chat.Membership
+	Unchanged int
+}
+
+// planChatSpaceMembers works out which group emails are missing from an
+// existing space and, with prune, which plain members are not in the group.
+// Listed memberships only carry numeric user IDs, so each email is resolved
+// through its member alias first. It makes no changes.
+func planChatSpaceMembers(ctx context.Context, svc *chat.Service, account, space string, emails []string, prune bool) (chatSpaceMemberPlan, error) {
+	plan := chatSpaceMemberPlan{Add: []string{}}
+	keep := map[string]bool{}
+	for _, email := range emails {
+		m, err := svc.Spaces.Members.Get(space + "/members/" + email).Context(ctx).Do()
+		switch {
+		case err == nil && m.Member != nil:
+			keep[m.Member.Name] = true
+			plan.Unchanged++
+		case err != nil && !isNotFoundAPIError(err):
+			return plan, fmt.Errorf("lookup %s: %w", email, err)
+		default:
+			plan.Add = append(plan.Add, email)
+		}
+	}
+
+	if !prune {
+		return plan, nil
+	}
+
+	// Never prune the caller, even when they are a plain member.
+	if self, err := svc.Spaces.Members.Get(space + "/members/" + account).Context(ctx).Do(); err == nil && self.Member != nil {
+		keep[self.Member.Name] = true
+	}
+
+	members, err := collectAllPages("", func(pageToken string) ([]*chat.Membership, string, error) {
+		call := svc.Spaces.Members.List(space).PageSize(1000).Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, callErr := call.Do()
+		if callErr != nil {
+			return nil, "", callErr
+		}
+		return resp.Memberships, resp.NextPageToken, nil
+	})
+	if err != nil {
+		return plan, err
+	}
+	for _, m := range members {
+		if m == nil || m.Member == nil || m.Member.Type != "HUMAN" || m.Role != "ROLE_MEMBER" || keep[m.Member.Name] {
+			continue
+		}
+		plan.Remove = append(plan.Remove, m)
+	}
+	return plan, nil
+}
+
+// applyChatSpaceMembers adds and removes the planned members of result.Space.
+func applyChatSpaceMembers(ctx context.Context, svc *chat.Service, plan chatSpaceMemberPlan, result *chatSpaceSyncResult) error {
+	for _, email := range plan.Add {
+		if _, err := addChatMember(ctx, svc, result.Space, normalizeUser(email), "ROLE_MEMBER"); err != nil {
+			var gerr *googleapi.Error
+			if errors.As(err, &gerr) && gerr.Code == http.StatusConflict {
+				result.Unchanged++
+				continue
+			}
+			return fmt.Errorf("add %s: %w", email, err)
+		}
+		result.Added = append(result.Added, email)
+	}
+	for _, m := range plan.Remove {
+		if _, err := svc.Spaces.Members.Delete(m.Name).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("remove %s: %w", m.Name, err)
+		}
+		result.Removed = append(result.Removed, m.Member.Name)
+	}
+	return nil
+}
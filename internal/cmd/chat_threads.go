@@ -59,6 +59,14 @@ func (c *ChatThreadsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Messages, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		// Threads are deduped across pages; the first (newest) message wins.
+		seen := make(map[string]bool)
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*chat.Message) error {
+			return writeNDJSON(ctx, chatThreadItems(dedupeChatThreads(page, seen)))
+		})
+	}
+
 	var messages []*chat.Message
 	nextPageToken := ""
 	if c.All {
@@ -75,37 +83,10 @@ func (c *ChatThreadsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 	}
 
-	threads := make([]*chatMessageThreadItem, 0, len(messages))
-	seen := make(map[string]bool)
-	for _, msg := range messages {
-		if msg == nil {
-			continue
-		}
-		threadName := chatMessageThread(msg)
-		if threadName == "" {
-			continue
-		}
-		if seen[threadName] {
-			continue
-		}
-		seen[threadName] = true
-		threads = append(threads, &chatMessageThreadItem{message: msg, thread: threadName})
-	}
+	threads := dedupeChatThreads(messages, make(map[string]bool))
 
 	if outfmt.IsJSON(ctx) {
-		items := make([]map[string]any, 0, len(threads))
-		for _, item := range threads {
-			if item == nil || item.message == nil {
-				continue
-			}
-			items = append(items, map[string]any{
-				"thread":     item.thread,
-				"message":    item.message.Name,
-				"sender":     chatMessageSender(item.message),
-				"text":       chatMessageText(item.message),
-				"createTime": item.message.CreateTime,
-			})
-		}
+		items := chatThreadItems(threads)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"threads":       items,
 			"nextPageToken": nextPageToken,
@@ -146,3 +127,39 @@ type chatMessageThreadItem struct {
 	thread  string
 	message *chat.Message
 }
+
+func dedupeChatThreads(messages []*chat.Message, seen map[string]bool) []*chatMessageThreadItem {
+	threads := make([]*chatMessageThreadItem, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		threadName := chatMessageThread(msg)
+		if threadName == "" {
+			continue
+		}
+		if seen[threadName] {
+			continue
+		}
+		seen[threadName] = true
+		threads = append(threads, &chatMessageThreadItem{message: msg, thread: threadName})
+	}
+	return threads
+}
+
+func chatThreadItems(threads []*chatMessageThreadItem) []map[string]any {
+	items := make([]map[string]any, 0, len(threads))
+	for _, item := range threads {
+		if item == nil || item.message == nil {
+			continue
+		}
+		items = append(items, map[string]any{
+			"thread":     item.thread,
+			"message":    item.message.Name,
+			"sender":     chatMessageSender(item.message),
+			"text":       chatMessageText(item.message),
+			"createTime": item.message.CreateTime,
+		})
+	}
+	return items
+}
@@ -69,6 +69,10 @@ func (c *ClassroomAnnouncementsListCmd) Run(ctx context.Context, flags *RootFlag
 		return resp.Announcements, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var announcements []*classroom.Announcement
 	nextPageToken := ""
 	if c.All {
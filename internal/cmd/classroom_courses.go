@@ -72,6 +72,10 @@ func (c *ClassroomCoursesListCmd) Run(ctx context.Context, flags *RootFlags) err
 		return resp.Courses, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var courses []*classroom.Course
 	nextPageToken := ""
 	if c.All {
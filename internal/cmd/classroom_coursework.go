@@ -72,6 +72,24 @@ func (c *ClassroomCourseworkListCmd) Run(ctx context.Context, flags *RootFlags)
 		return resp.CourseWork, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) && c.All {
+		topic := strings.TrimSpace(c.Topic)
+		err := streamPagesNDJSON(ctx, true, c.Page, c.FailEmpty, func(page string) ([]*classroom.CourseWork, string, error) {
+			items, next, fetchErr := fetch(page)
+			if fetchErr != nil || topic == "" {
+				return items, next, fetchErr
+			}
+			filtered := items[:0]
+			for _, work := range items {
+				if work != nil && work.TopicId == topic {
+					filtered = append(filtered, work)
+				}
+			}
+			return filtered, next, nil
+		})
+		return wrapClassroomError(err)
+	}
+
 	var coursework []*classroom.CourseWork
 	var nextPageToken string
 	if c.All {
@@ -111,6 +129,9 @@ func (c *ClassroomCourseworkListCmd) Run(ctx context.Context, flags *RootFlags)
 		}
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return writeNDJSONPage(ctx, coursework, nextPageToken, c.FailEmpty)
+	}
 	if outfmt.IsJSON(ctx) {
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"coursework":    coursework,
@@ -58,6 +58,10 @@ func (c *ClassroomGuardiansListCmd) Run(ctx context.Context, flags *RootFlags) e
 		return resp.Guardians, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var guardians []*classroom.Guardian
 	nextPageToken := ""
 	if c.All {
@@ -245,6 +249,10 @@ func (c *ClassroomGuardianInvitesListCmd) Run(ctx context.Context, flags *RootFl
 		return resp.GuardianInvitations, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var invitations []*classroom.GuardianInvitation
 	nextPageToken := ""
 	if c.All {
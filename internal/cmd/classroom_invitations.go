@@ -60,6 +60,10 @@ func (c *ClassroomInvitationsListCmd) Run(ctx context.Context, flags *RootFlags)
 		return resp.Invitations, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var invitations []*classroom.Invitation
 	nextPageToken := ""
 	if c.All {
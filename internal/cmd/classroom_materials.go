@@ -71,6 +71,24 @@ func (c *ClassroomMaterialsListCmd) Run(ctx context.Context, flags *RootFlags) e
 		return resp.CourseWorkMaterial, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) && c.All {
+		topic := strings.TrimSpace(c.Topic)
+		err := streamPagesNDJSON(ctx, true, c.Page, c.FailEmpty, func(page string) ([]*classroom.CourseWorkMaterial, string, error) {
+			items, next, fetchErr := fetch(page)
+			if fetchErr != nil || topic == "" {
+				return items, next, fetchErr
+			}
+			filtered := items[:0]
+			for _, material := range items {
+				if material != nil && material.TopicId == topic {
+					filtered = append(filtered, material)
+				}
+			}
+			return filtered, next, nil
+		})
+		return wrapClassroomError(err)
+	}
+
 	var materials []*classroom.CourseWorkMaterial
 	var nextPageToken string
 	if c.All {
@@ -110,6 +128,9 @@ func (c *ClassroomMaterialsListCmd) Run(ctx context.Context, flags *RootFlags) e
 		}
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return writeNDJSONPage(ctx, materials, nextPageToken, c.FailEmpty)
+	}
 	if outfmt.IsJSON(ctx) {
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"materials":     materials,
@@ -55,6 +55,10 @@ func (c *ClassroomStudentsListCmd) Run(ctx context.Context, flags *RootFlags) er
 		return resp.Students, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var students []*classroom.Student
 	nextPageToken := ""
 	if c.All {
@@ -287,6 +291,10 @@ func (c *ClassroomTeachersListCmd) Run(ctx context.Context, flags *RootFlags) er
 		return resp.Teachers, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var teachers []*classroom.Teacher
 	nextPageToken := ""
 	if c.All {
@@ -497,56 +505,82 @@ func (c *ClassroomRosterCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return wrapClassroomError(err)
 	}
 
+	fetchStudents := func(pageToken string) ([]*classroom.Student, string, error) {
+		call := svc.Courses.Students.List(courseID).PageSize(c.Max).Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, callErr := call.Do()
+		if callErr != nil {
+			return nil, "", wrapClassroomError(callErr)
+		}
+		return resp.Students, resp.NextPageToken, nil
+	}
+	fetchTeachers := func(pageToken string) ([]*classroom.Teacher, string, error) {
+		call := svc.Courses.Teachers.List(courseID).PageSize(c.Max).Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, callErr := call.Do()
+		if callErr != nil {
+			return nil, "", wrapClassroomError(callErr)
+		}
+		return resp.Teachers, resp.NextPageToken, nil
+	}
+
+	if outfmt.IsNDJSON(ctx) {
+		// Stream each role in turn; --fail-empty applies to the whole roster.
+		count := 0
+		if includeTeachers {
+			if err := streamPages(ctx, c.All, c.Page, false, fetchTeachers, func(page []*classroom.Teacher) error {
+				count += len(page)
+				return writeNDJSON(ctx, classroomTeacherItems(page))
+			}); err != nil {
+				return err
+			}
+		}
+		if includeStudents {
+			if err := streamPages(ctx, c.All, c.Page, false, fetchStudents, func(page []*classroom.Student) error {
+				count += len(page)
+				return writeNDJSON(ctx, classroomStudentItems(page))
+			}); err != nil {
+				return err
+			}
+		}
+		if count == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+
 	var students []*classroom.Student
 	var teachers []*classroom.Teacher
 	studentsNextPageToken := ""
 	teachersNextPageToken := ""
 
 	if includeStudents {
-		fetch := func(pageToken string) ([]*classroom.Student, string, error) {
-			call := svc.Courses.Students.List(courseID).PageSize(c.Max).Context(ctx)
-			if strings.TrimSpace(pageToken) != "" {
-				call = call.PageToken(pageToken)
-			}
-			resp, callErr := call.Do()
-			if callErr != nil {
-				return nil, "", wrapClassroomError(callErr)
-			}
-			return resp.Students, resp.NextPageToken, nil
-		}
 		if c.All {
-			all, collectErr := collectAllPages(c.Page, fetch)
+			all, collectErr := collectAllPages(c.Page, fetchStudents)
 			if collectErr != nil {
 				return collectErr
 			}
 			students = all
 		} else {
-			students, studentsNextPageToken, err = fetch(c.Page)
+			students, studentsNextPageToken, err = fetchStudents(c.Page)
 			if err != nil {
 				return err
 			}
 		}
 	}
 	if includeTeachers {
-		fetch := func(pageToken string) ([]*classroom.Teacher, string, error) {
-			call := svc.Courses.Teachers.List(courseID).PageSize(c.Max).Context(ctx)
-			if strings.TrimSpace(pageToken) != "" {
-				call = call.PageToken(pageToken)
-			}
-			resp, callErr := call.Do()
-			if callErr != nil {
-				return nil, "", wrapClassroomError(callErr)
-			}
-			return resp.Teachers, resp.NextPageToken, nil
-		}
 		if c.All {
-			all, collectErr := collectAllPages(c.Page, fetch)
+			all, collectErr := collectAllPages(c.Page, fetchTeachers)
 			if collectErr != nil {
 				return collectErr
 			}
 			teachers = all
 		} else {
-			teachers, teachersNextPageToken, err = fetch(c.Page)
+			teachers, teachersNextPageToken, err = fetchTeachers(c.Page)
 			if err != nil {
 				return err
 			}
@@ -626,3 +660,35 @@ func (c *ClassroomRosterCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 	return nil
 }
+
+// classroomRosterItem is one --ndjson roster line; both roles stream as one
+// list, so each entry carries its role.
+type classroomRosterItem struct {
+	Role              string                 `json:"role"`
+	CourseID          string                 `json:"courseId,omitempty"`
+	UserID            string                 `json:"userId"`
+	Profile           *classroom.UserProfile `json:"profile,omitempty"`
+	StudentWorkFolder *classroom.DriveFolder `json:"studentWorkFolder,omitempty"`
+}
+
+func classroomTeacherItems(teachers []*classroom.Teacher) []classroomRosterItem {
+	items := make([]classroomRosterItem, 0, len(teachers))
+	for _, t := range teachers {
+		if t == nil {
+			continue
+		}
+		items = append(items, classroomRosterItem{Role: "teacher", CourseID: t.CourseId, UserID: t.UserId, Profile: t.Profile})
+	}
+	return items
+}
+
+func classroomStudentItems(students []*classroom.Student) []classroomRosterItem {
+	items := make([]classroomRosterItem, 0, len(students))
+	for _, s := range students {
+		if s == nil {
+			continue
+		}
+		items = append(items, classroomRosterItem{Role: "student", CourseID: s.CourseId, UserID: s.UserId, Profile: s.Profile, StudentWorkFolder: s.StudentWorkFolder})
+	}
+	return items
+}
@@ -86,6 +86,10 @@ func (c *ClassroomSubmissionsListCmd) Run(ctx context.Context, flags *RootFlags)
 		return resp.StudentSubmissions, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var submissions []*classroom.StudentSubmission
 	nextPageToken := ""
 	if c.All {
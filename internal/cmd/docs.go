@@ -22,7 +22,7 @@ import (
 var newDocsService = googleapi.NewDocs
 
 type DocsCmd struct {
-	Export      DocsExportCmd      `cmd:"" name:"export" aliases:"download,dl" help:"Export a Google Doc (pdf|docx|txt)"`
+	Export      DocsExportCmd      `cmd:"" name:"export" aliases:"download,dl" help:"Export a Google Doc (pdf|docx|txt|md)"`
 	Info        DocsInfoCmd        `cmd:"" name:"info" aliases:"get,show" help:"Get Google Doc metadata"`
 	Create      DocsCreateCmd      `cmd:"" name:"create" aliases:"add,new" help:"Create a Google Doc"`
 	Copy        DocsCopyCmd        `cmd:"" name:"copy" aliases:"cp,duplicate" help:"Copy a Google Doc"`
@@ -34,14 +34,27 @@ type DocsCmd struct {
 	Delete      DocsDeleteCmd      `cmd:"" name:"delete" help:"Delete text range from document"`
 	FindReplace DocsFindReplaceCmd `cmd:"" name:"find-replace" help:"Find and replace text in document"`
 	Update      DocsUpdateCmd      `cmd:"" name:"update" help:"Update content in a Google Doc"`
+	Section     DocsSectionCmd     `cmd:"" name:"section" help:"Read or edit a section by its heading"`
+	Render      DocsRenderCmd      `cmd:"" name:"render" help:"Copy a template doc and fill {{placeholders}}, {{#if}} blocks, repeated table rows and images from JSON"`
 }
 type DocsExportCmd struct {
-	DocID  string         `arg:"" name:"docId" help:"Doc ID"`
-	Output OutputPathFlag `embed:""`
-	Format string         `name:"format" help:"Export format: pdf|docx|txt" default:"pdf"`
+	DocID       string         `arg:"" name:"docId" help:"Doc ID"`
+	Output      OutputPathFlag `embed:""`
+	Format      string         `name:"format" help:"Export format: pdf|docx|txt|md (md: --out - for stdout)" default:"pdf"`
+	Tab         string         `name:"tab" help:"Export only this tab (title or ID); md only"`
+	Suggestions string         `name:"suggestions" help:"Suggested edits in md: none|accepted|inline (CriticMarkup)" enum:"none,accepted,inline" default:"none"`
 }
 
 func (c *DocsExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if strings.EqualFold(strings.TrimSpace(c.Format), "md") {
+		return exportDocsMarkdown(ctx, flags, c.DocID, c.Output.Path, docsMarkdownExportOptions{
+			Tab:         strings.TrimSpace(c.Tab),
+			Suggestions: c.Suggestions,
+		})
+	}
+	if strings.TrimSpace(c.Tab) != "" || c.Suggestions != docsSuggestionsNone {
+		return usage("--tab and --suggestions require --format md")
+	}
 	return exportViaDrive(ctx, flags, exportViaDriveOptions{
 		ArgName:       "docId",
 		ExpectedMime:  "application/vnd.google-apps.document",
@@ -322,6 +335,7 @@ type DocsUpdateCmd struct {
 	Content     string `name:"content" help:"Text content to insert (mutually exclusive with --content-file)"`
 	ContentFile string `name:"content-file" help:"File containing text content to insert"`
 	Format      string `name:"format" help:"Content format: plain|markdown" default:"plain"`
+	Markdown    bool   `name:"markdown" help:"Shorthand for --format markdown"`
 	Append      bool   `name:"append" help:"Append to end of document instead of replacing all content"`
 	Debug       bool   `name:"debug" help:"Enable debug output for markdown formatter"`
 }
@@ -366,6 +380,9 @@ func (c *DocsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if format == "" {
 		format = docsContentFormatPlain
 	}
+	if c.Markdown {
+		format = docsContentFormatMarkdown
+	}
 	switch format {
 	case docsContentFormatPlain, docsContentFormatMarkdown:
 	default:
@@ -407,10 +424,12 @@ func (c *DocsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	var textToInsert string
 	var formattingRequests []*docs.Request
 	var tables []TableData
+	var footnotes []FootnoteData
 
 	if format == docsContentFormatMarkdown {
-		elements := ParseMarkdown(content)
-		formattingRequests, textToInsert, tables = MarkdownToDocsRequests(elements, baseIndex)
+		plan := planMarkdownDocs(ParseMarkdown(content), baseIndex)
+		formattingRequests, textToInsert = plan.Requests, plan.Text
+		tables, footnotes = plan.Tables, plan.Footnotes
 	} else {
 		textToInsert = content
 	}
@@ -460,19 +479,8 @@ func (c *DocsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return fmt.Errorf("update document: %w", err)
 	}
 
-	if len(tables) > 0 {
-		tableInserter := NewTableInserter(svc, id)
-		tableOffset := int64(0)
-		for _, table := range tables {
-			tableIndex := table.StartIndex + tableOffset
-			tableEnd, err := tableInserter.InsertNativeTable(ctx, tableIndex, table.Cells)
-			if err != nil {
-				return fmt.Errorf("insert native table: %w", err)
-			}
-			if tableEnd > tableIndex {
-				tableOffset += (tableEnd - tableIndex) - 1
-			}
-		}
+	if err := insertMarkdownObjects(ctx, svc, id, "", tables, footnotes); err != nil {
+		return err
 	}
 
 	if outfmt.IsJSON(ctx) {
@@ -556,6 +564,37 @@ func (c *DocsCatCmd) runWithTabs(ctx context.Context, svc *docs.Service, id stri
 	return nil
 }
 
+// insertMarkdownObjects creates the footnotes and native tables of a markdown
+// plan once its text has been written. Footnotes go in before tables: their
+// indices are relative to the text as inserted, and each one shifts later
+// content by a single character.
+func insertMarkdownObjects(ctx context.Context, svc *docs.Service, docID, tabID string, tables []TableData, footnotes []FootnoteData) error {
+	if len(footnotes) > 0 {
+		footnoteInserter := NewFootnoteInserter(svc, docID)
+		footnoteInserter.tabID = tabID
+		if err := footnoteInserter.InsertFootnotes(ctx, footnotes); err != nil {
+			return fmt.Errorf("insert footnotes: %w", err)
+		}
+	}
+
+	if len(tables) > 0 {
+		tableInserter := NewTableInserter(svc, docID)
+		tableInserter.tabID = tabID
+		tableOffset := int64(0)
+		for _, table := range tables {
+			tableIndex := shiftForFootnotes(table.StartIndex, footnotes) + tableOffset
+			tableEnd, err := tableInserter.InsertNativeTable(ctx, tableIndex, table.Cells, table.Align)
+			if err != nil {
+				return fmt.Errorf("insert native table: %w", err)
+			}
+			if tableEnd > tableIndex {
+				tableOffset += (tableEnd - tableIndex) - 1
+			}
+		}
+	}
+	return nil
+}
+
 type DocsListTabsCmd struct {
 	DocID string `arg:"" name:"docId" help:"Doc ID"`
 }
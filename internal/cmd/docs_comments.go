@@ -67,6 +67,15 @@ func (c *DocsCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Comments, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) && c.All {
+		return streamPages(ctx, true, c.Page, c.FailEmpty, fetch, func(page []*drive.Comment) error {
+			if !c.IncludeResolved {
+				page = filterOpenComments(page)
+			}
+			return writeNDJSON(ctx, page)
+		})
+	}
+
 	var comments []*drive.Comment
 	nextPageToken := ""
 	if c.All {
@@ -111,6 +120,9 @@ func (c *DocsCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		comments = filterOpenComments(comments)
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return writeNDJSONPage(ctx, comments, nextPageToken, c.FailEmpty)
+	}
 	if outfmt.IsJSON(ctx) {
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"docId":         docID,
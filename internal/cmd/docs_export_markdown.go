@@ -0,0 +1,583 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+// Suggested-edit handling for markdown export.
+const (
+	docsSuggestionsNone     = "none"
+	docsSuggestionsAccepted = "accepted"
+	docsSuggestionsInline   = "inline"
+)
+
+type docsMarkdownExportOptions struct {
+	Tab         string
+	Suggestions string
+}
+
+// exportDocsMarkdown writes a Google Doc as markdown. Inline images are
+// downloaded into a "<name>_images" directory next to the output file; with
+// --out - the markdown goes to stdout and images keep their (short-lived)
+// content URLs.
+func exportDocsMarkdown(ctx context.Context, flags *RootFlags, id string, outPath string, opts docsMarkdownExportOptions) error {
+	u := ui.FromContext(ctx)
+
+	id = normalizeGoogleID(strings.TrimSpace(id))
+	if id == "" {
+		return usage("empty docId")
+	}
+	outPath = strings.TrimSpace(outPath)
+	if outPath != "" && outPath != "-" {
+		expanded, err := config.ExpandPath(outPath)
+		if err != nil {
+			return err
+		}
+		outPath = expanded
+	}
+
+	viewMode := "PREVIEW_WITHOUT_SUGGESTIONS"
+	switch opts.Suggestions {
+	case docsSuggestionsAccepted:
+		viewMode = "PREVIEW_SUGGESTIONS_ACCEPTED"
+	case docsSuggestionsInline:
+		viewMode = "SUGGESTIONS_INLINE"
+	}
+
+	if err := dryRunExit(ctx, flags, "docs.export", map[string]any{
+		"id":          id,
+		"out":         outPath,
+		"format":      "md",
+		"tab":         opts.Tab,
+		"suggestions": opts.Suggestions,
+	}); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newDocsService(ctx, account)
+	if err != nil {
+		return err
+	}
+	doc, err := svc.Documents.Get(id).
+		IncludeTabsContent(true).
+		SuggestionsViewMode(viewMode).
+		Context(ctx).
+		Do()
+	if err != nil {
+		if isDocsNotFound(err) {
+			return fmt.Errorf("doc not found or not a Google Doc (id=%s)", id)
+		}
+		return err
+	}
+	if doc == nil {
+		return errors.New("doc not found")
+	}
+
+	tabs := flattenTabs(doc.Tabs)
+	if opts.Tab != "" {
+		tab := findTab(tabs, opts.Tab)
+		if tab == nil {
+			return fmt.Errorf("tab not found: %s", opts.Tab)
+		}
+		tabs = []*docs.Tab{tab}
+	}
+	if len(tabs) == 0 {
+		// Responses without tab content carry the body at the top level.
+		tabs = []*docs.Tab{{DocumentTab: &docs.DocumentTab{
+			Body:              doc.Body,
+			Footnotes:         doc.Footnotes,
+			InlineObjects:     doc.InlineObjects,
+			Lists:             doc.Lists,
+			PositionedObjects: doc.PositionedObjects,
+		}}}
+	}
+
+	destPath := ""
+	if outPath != "-" {
+		destPath, err = resolveDriveDownloadDestPath(&drive.File{Id: doc.DocumentId, Name: doc.Title}, outPath)
+		if err != nil {
+			return err
+		}
+		destPath = replaceExt(destPath, ".md")
+	}
+
+	images := 0
+	var imageErr error
+	imageDir := strings.TrimSuffix(destPath, filepath.Ext(destPath)) + "_images"
+	image := func(objectID, contentURI string) string {
+		if destPath == "" || contentURI == "" || imageErr != nil {
+			return contentURI
+		}
+		name := sanitizeAttachmentFilename(objectID, "image")
+		contentType, dlErr := downloadURLToFile(ctx, contentURI, filepath.Join(imageDir, name))
+		if dlErr != nil {
+			imageErr = fmt.Errorf("download image %s: %w", objectID, dlErr)
+			return contentURI
+		}
+		if ext := imageExtension(contentType); ext != "" {
+			if renameErr := os.Rename(filepath.Join(imageDir, name), filepath.Join(imageDir, name+ext)); renameErr == nil {
+				name += ext
+			}
+		}
+		images++
+		return filepath.ToSlash(filepath.Join(filepath.Base(imageDir), name))
+	}
+
+	var parts []string
+	for _, tab := range tabs {
+		if tab == nil || tab.DocumentTab == nil {
+			continue
+		}
+		r := newDocsMarkdownRenderer(tab.DocumentTab, opts.Suggestions == docsSuggestionsInline, image)
+		md := r.render()
+		if len(tabs) > 1 {
+			md = fmt.Sprintf("<!-- tab: %s -->\n\n%s", tabTitle(tab), md)
+		}
+		parts = append(parts, strings.TrimRight(md, "\n"))
+	}
+	if imageErr != nil {
+		return imageErr
+	}
+	md := strings.Join(parts, "\n\n") + "\n"
+
+	if destPath == "" {
+		_, err = os.Stdout.WriteString(md)
+		return err
+	}
+	if err := writeFileAtomic(destPath, []byte(md)); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": destPath, "size": len(md), "tabs": len(parts), "images": images})
+	}
+	u.Out().Printf("path\t%s", destPath)
+	u.Out().Printf("size\t%s", formatDriveSize(int64(len(md))))
+	if images > 0 {
+		u.Out().Printf("images\t%d (%s)", images, imageDir)
+	}
+	return nil
+}
+
+// docsMarkdownRenderer converts one document tab's structure to markdown.
+type docsMarkdownRenderer struct {
+	tab         *docs.DocumentTab
+	suggestions bool
+	image       func(objectID, contentURI string) string
+
+	footnotes []string          // rendered "[^n]: ..." lines in reference order
+	seen      map[string]string // footnote ID -> label
+	counters  map[string]int    // "listId/level" -> last number used
+}
+
+func newDocsMarkdownRenderer(tab *docs.DocumentTab, suggestions bool, image func(objectID, contentURI string) string) *docsMarkdownRenderer {
+	return &docsMarkdownRenderer{
+		tab:         tab,
+		suggestions: suggestions,
+		image:       image,
+		seen:        map[string]string{},
+		counters:    map[string]int{},
+	}
+}
+
+func (r *docsMarkdownRenderer) render() string {
+	var content []*docs.StructuralElement
+	if r.tab.Body != nil {
+		content = r.tab.Body.Content
+	}
+	out := r.renderBlocks(content)
+	if len(r.footnotes) > 0 {
+		out += "\n\n" + strings.Join(r.footnotes, "\n")
+	}
+	return out
+}
+
+// docsBlockKind groups consecutive paragraphs that must not be separated by
+// blank lines (list items, code lines).
+type docsBlockKind int
+
+const (
+	docsBlockText docsBlockKind = iota
+	docsBlockList
+	docsBlockCode
+)
+
+func (r *docsMarkdownRenderer) renderBlocks(content []*docs.StructuralElement) string {
+	var blocks []string
+	var run []string
+	runKind := docsBlockText
+	listIndent := []int{}
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runKind == docsBlockCode {
+			blocks = append(blocks, "```\n"+strings.Join(run, "\n")+"\n```")
+		} else {
+			blocks = append(blocks, strings.Join(run, "\n"))
+		}
+		run = nil
+	}
+	push := func(kind docsBlockKind, line string) {
+		if kind != runKind || kind == docsBlockText {
+			flush()
+		}
+		if kind != docsBlockList {
+			listIndent = listIndent[:0]
+		}
+		runKind = kind
+		run = append(run, line)
+	}
+
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		switch {
+		case el.Paragraph != nil:
+			p := el.Paragraph
+			if isDocsHorizontalRule(p) {
+				push(docsBlockText, "---")
+				continue
+			}
+			text, code := r.renderParagraphText(p, isDocsHeading(p))
+			if text == "" && len(p.PositionedObjectIds) == 0 {
+				continue
+			}
+			switch {
+			case p.Bullet != nil:
+				push(docsBlockList, r.listItem(p.Bullet, text, &listIndent))
+			case isDocsHeading(p):
+				// A trailing "#" would read back as a closing sequence.
+				if strings.HasSuffix(text, "#") {
+					text = text[:len(text)-1] + `\#`
+				}
+				push(docsBlockText, docsHeadingPrefix(p.ParagraphStyle.NamedStyleType)+text)
+			case code:
+				push(docsBlockCode, text)
+			case text != "":
+				push(docsBlockText, escapeMarkdownLineStart(text))
+			}
+			for _, objID := range p.PositionedObjectIds {
+				if img := r.positionedImage(objID); img != "" {
+					push(docsBlockText, img)
+				}
+			}
+		case el.Table != nil:
+			flush()
+			if table := r.renderTable(el.Table); table != "" {
+				blocks = append(blocks, table)
+			}
+			runKind = docsBlockText
+		}
+	}
+	flush()
+	return strings.Join(blocks, "\n\n")
+}
+
+// renderParagraphText renders a paragraph's inline content. code reports
+// whether every text run is set in a monospace font.
+func (r *docsMarkdownRenderer) renderParagraphText(p *docs.Paragraph, plainStyles bool) (string, bool) {
+	var sb, plain strings.Builder
+	code := true
+	hasText := false
+	for _, pe := range p.Elements {
+		if pe == nil {
+			continue
+		}
+		switch {
+		case pe.TextRun != nil:
+			content := strings.TrimSuffix(pe.TextRun.Content, "\n")
+			content = strings.ReplaceAll(content, "\v", " ")
+			if content == "" {
+				continue
+			}
+			style := pe.TextRun.TextStyle
+			if style == nil {
+				style = &docs.TextStyle{}
+			}
+			monospace := style.WeightedFontFamily != nil && isMonospaceFont(style.WeightedFontFamily.FontFamily)
+			if strings.TrimSpace(content) != "" {
+				hasText = true
+				code = code && monospace
+			}
+			plain.WriteString(content)
+			var md string
+			if plainStyles {
+				md = markdownInline(content, false, false, false, docsLinkURL(style.Link))
+			} else {
+				md = markdownInline(content, style.Bold, style.Italic, monospace, docsLinkURL(style.Link))
+			}
+			sb.WriteString(r.suggestionMarkup(md, pe.TextRun.SuggestedInsertionIds, pe.TextRun.SuggestedDeletionIds))
+		case pe.InlineObjectElement != nil:
+			code = false
+			sb.WriteString(r.inlineImage(pe.InlineObjectElement.InlineObjectId))
+		case pe.FootnoteReference != nil:
+			sb.WriteString(r.footnoteRef(pe.FootnoteReference))
+		case pe.Person != nil && pe.Person.PersonProperties != nil:
+			code = false
+			pp := pe.Person.PersonProperties
+			name := pp.Name
+			if name == "" {
+				name = pp.Email
+			}
+			sb.WriteString(markdownInline(name, false, false, false, "mailto:"+pp.Email))
+		case pe.RichLink != nil && pe.RichLink.RichLinkProperties != nil:
+			code = false
+			rl := pe.RichLink.RichLinkProperties
+			title := rl.Title
+			if title == "" {
+				title = rl.Uri
+			}
+			sb.WriteString(markdownInline(title, false, false, false, rl.Uri))
+		}
+	}
+	if code && hasText {
+		return plain.String(), true
+	}
+	return sb.String(), false
+}
+
+// suggestionMarkup wraps suggested insertions and deletions in CriticMarkup
+// when exporting with --suggestions inline.
+func (r *docsMarkdownRenderer) suggestionMarkup(md string, insertions, deletions []string) string {
+	if !r.suggestions {
+		return md
+	}
+	switch {
+	case len(deletions) > 0:
+		return "{--" + md + "--}"
+	case len(insertions) > 0:
+		return "{++" + md + "++}"
+	}
+	return md
+}
+
+func (r *docsMarkdownRenderer) listItem(b *docs.Bullet, text string, indent *[]int) string {
+	level := int(b.NestingLevel)
+	ordered := false
+	start := int64(1)
+	if list, ok := r.tab.Lists[b.ListId]; ok && list.ListProperties != nil && level < len(list.ListProperties.NestingLevels) {
+		nl := list.ListProperties.NestingLevels[level]
+		if nl != nil {
+			ordered = isDocsOrderedGlyph(nl.GlyphType)
+			if nl.StartNumber > 0 {
+				start = nl.StartNumber
+			}
+		}
+	}
+
+	// Deeper levels restart numbering when a shallower item appears.
+	key := fmt.Sprintf("%s/%d", b.ListId, level)
+	for k := range r.counters {
+		if prefix, lvl, ok := strings.Cut(k, "/"); ok && prefix == b.ListId {
+			if n, err := strconv.Atoi(lvl); err == nil && n > level {
+				delete(r.counters, k)
+			}
+		}
+	}
+
+	marker := "-"
+	if ordered {
+		n, ok := r.counters[key]
+		if !ok {
+			n = int(start) - 1
+		}
+		n++
+		r.counters[key] = n
+		marker = strconv.Itoa(n) + "."
+	}
+
+	// Indent under the parent item's content, as CommonMark expects.
+	for len(*indent) < level {
+		*indent = append(*indent, 2)
+	}
+	*indent = (*indent)[:level]
+	pad := 0
+	for _, w := range *indent {
+		pad += w
+	}
+	*indent = append(*indent, len(marker)+1)
+	return strings.Repeat(" ", pad) + marker + " " + text
+}
+
+func (r *docsMarkdownRenderer) renderTable(table *docs.Table) string {
+	var rows [][]string
+	cols := 0
+	for _, row := range table.TableRows {
+		if row == nil {
+			continue
+		}
+		var cells []string
+		for _, cell := range row.TableCells {
+			var parts []string
+			if cell != nil {
+				for _, el := range cell.Content {
+					if el == nil || el.Paragraph == nil {
+						continue
+					}
+					if text, _ := r.renderParagraphText(el.Paragraph, false); text != "" {
+						parts = append(parts, text)
+					}
+				}
+			}
+			cells = append(cells, strings.Join(parts, "<br>"))
+		}
+		cols = max(cols, len(cells))
+		rows = append(rows, cells)
+	}
+	return renderMarkdownTable(rows, cols)
+}
+
+func (r *docsMarkdownRenderer) inlineImage(objectID string) string {
+	obj, ok := r.tab.InlineObjects[objectID]
+	if !ok || obj.InlineObjectProperties == nil {
+		return ""
+	}
+	return r.embeddedImage(objectID, obj.InlineObjectProperties.EmbeddedObject)
+}
+
+func (r *docsMarkdownRenderer) positionedImage(objectID string) string {
+	obj, ok := r.tab.PositionedObjects[objectID]
+	if !ok || obj.PositionedObjectProperties == nil {
+		return ""
+	}
+	return r.embeddedImage(objectID, obj.PositionedObjectProperties.EmbeddedObject)
+}
+
+func (r *docsMarkdownRenderer) embeddedImage(objectID string, eo *docs.EmbeddedObject) string {
+	if eo == nil || eo.ImageProperties == nil {
+		return ""
+	}
+	src := eo.ImageProperties.ContentUri
+	if r.image != nil {
+		src = r.image(objectID, src)
+	}
+	if src == "" {
+		return ""
+	}
+	alt := eo.Description
+	if alt == "" {
+		alt = eo.Title
+	}
+	return fmt.Sprintf("![%s](%s)", alt, src)
+}
+
+func (r *docsMarkdownRenderer) footnoteRef(ref *docs.FootnoteReference) string {
+	if label, ok := r.seen[ref.FootnoteId]; ok {
+		return "[^" + label + "]"
+	}
+	label := ref.FootnoteNumber
+	if label == "" {
+		label = strconv.Itoa(len(r.seen) + 1)
+	}
+	r.seen[ref.FootnoteId] = label
+
+	var parts []string
+	if fn, ok := r.tab.Footnotes[ref.FootnoteId]; ok {
+		for _, el := range fn.Content {
+			if el == nil || el.Paragraph == nil {
+				continue
+			}
+			if text, _ := r.renderParagraphText(el.Paragraph, false); strings.TrimSpace(text) != "" {
+				parts = append(parts, strings.TrimSpace(text))
+			}
+		}
+	}
+	r.footnotes = append(r.footnotes, "[^"+label+"]: "+strings.Join(parts, " "))
+	return "[^" + label + "]"
+}
+
+func docsLinkURL(link *docs.Link) string {
+	if link == nil {
+		return ""
+	}
+	if link.Url != "" {
+		return link.Url
+	}
+	if link.HeadingId != "" {
+		return "#" + link.HeadingId
+	}
+	return ""
+}
+
+func isDocsHeading(p *docs.Paragraph) bool {
+	return p.ParagraphStyle != nil && docsHeadingPrefix(p.ParagraphStyle.NamedStyleType) != ""
+}
+
+func docsHeadingPrefix(namedStyle string) string {
+	switch namedStyle {
+	case "TITLE":
+		return "# "
+	case "SUBTITLE":
+		return "## "
+	}
+	if n, ok := strings.CutPrefix(namedStyle, "HEADING_"); ok {
+		if level, err := strconv.Atoi(n); err == nil && level >= 1 && level <= 6 {
+			return strings.Repeat("#", level) + " "
+		}
+	}
+	return ""
+}
+
+func isDocsHorizontalRule(p *docs.Paragraph) bool {
+	hasRule := false
+	for _, pe := range p.Elements {
+		if pe == nil {
+			continue
+		}
+		if pe.HorizontalRule != nil {
+			hasRule = true
+			continue
+		}
+		if pe.TextRun != nil && strings.TrimSpace(pe.TextRun.Content) == "" {
+			continue
+		}
+		return false
+	}
+	return hasRule
+}
+
+func imageExtension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case mimePNG:
+		return extPNG
+	case imageMimeJPEG:
+		return imageExtJPG
+	case imageMimeGIF:
+		return imageExtGIF
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	}
+	return ""
+}
+
+func isDocsOrderedGlyph(glyphType string) bool {
+	switch glyphType {
+	case "DECIMAL", "ZERO_DECIMAL", "UPPER_ALPHA", "ALPHA", "UPPER_ROMAN", "ROMAN":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/option"
+)
+
+func docsTestPara(style string, bullet *docs.Bullet, elems ...*docs.ParagraphElement) *docs.StructuralElement {
+	p := &docs.Paragraph{Elements: elems, Bullet: bullet}
+	if style != "" {
+		p.ParagraphStyle = &docs.ParagraphStyle{NamedStyleType: style}
+	}
+	return &docs.StructuralElement{Paragraph: p}
+}
+
+func docsTestRun(content string, style *docs.TextStyle) *docs.ParagraphElement {
+	return &docs.ParagraphElement{TextRun: &docs.TextRun{Content: content, TextStyle: style}}
+}
+
+func TestDocsMarkdownRenderer(t *testing.T) {
+	mono := &docs.TextStyle{WeightedFontFamily: &docs.WeightedFontFamily{FontFamily: "Courier New"}}
+	tab := &docs.DocumentTab{
+		Lists: map[string]docs.List{
+			"ol": {ListProperties: &docs.ListProperties{NestingLevels: []*docs.NestingLevel{{GlyphType: "DECIMAL"}, {GlyphSymbol: "●"}}}},
+		},
+		InlineObjects: map[string]docs.InlineObject{
+			"img1": {InlineObjectProperties: &docs.InlineObjectProperties{EmbeddedObject: &docs.EmbeddedObject{
+				Description:     "diagram",
+				ImageProperties: &docs.ImageProperties{ContentUri: "https://x.test/img1"},
+			}}},
+		},
+		Footnotes: map[string]docs.Footnote{
+			"fn1": {Content: []*docs.StructuralElement{docsTestPara("", nil, docsTestRun("Source: Q3 report\n", nil))}},
+		},
+		Body: &docs.Body{Content: []*docs.StructuralElement{
+			{SectionBreak: &docs.SectionBreak{}},
+			docsTestPara("HEADING_1", nil, docsTestRun("Plan\n", &docs.TextStyle{Bold: true})),
+			docsTestPara("NORMAL_TEXT", nil,
+				docsTestRun("We ", nil),
+				docsTestRun("must", &docs.TextStyle{Bold: true, Italic: true}),
+				docsTestRun(" read ", nil),
+				docsTestRun("the docs", &docs.TextStyle{Link: &docs.Link{Url: "https://x.test"}}),
+				&docs.ParagraphElement{FootnoteReference: &docs.FootnoteReference{FootnoteId: "fn1", FootnoteNumber: "1"}},
+				docsTestRun(" and ", nil),
+				&docs.ParagraphElement{TextRun: &docs.TextRun{Content: "old", SuggestedDeletionIds: []string{"s1"}}},
+				&docs.ParagraphElement{TextRun: &docs.TextRun{Content: "new", SuggestedInsertionIds: []string{"s2"}}},
+				docsTestRun("\n", nil),
+			),
+			docsTestPara("NORMAL_TEXT", &docs.Bullet{ListId: "ol"}, docsTestRun("first\n", nil)),
+			docsTestPara("NORMAL_TEXT", &docs.Bullet{ListId: "ol", NestingLevel: 1}, docsTestRun("detail\n", nil)),
+			docsTestPara("NORMAL_TEXT", &docs.Bullet{ListId: "ol"}, docsTestRun("second\n", nil)),
+			docsTestPara("NORMAL_TEXT", nil, docsTestRun("make test\n", mono)),
+			docsTestPara("NORMAL_TEXT", nil, docsTestRun("  go vet\n", mono)),
+			docsTestPara("NORMAL_TEXT", nil, &docs.ParagraphElement{HorizontalRule: &docs.HorizontalRule{}}, docsTestRun("\n", nil)),
+			{Table: &docs.Table{TableRows: []*docs.TableRow{
+				{TableCells: []*docs.TableCell{
+					{Content: []*docs.StructuralElement{docsTestPara("", nil, docsTestRun("Name\n", nil))}},
+					{Content: []*docs.StructuralElement{docsTestPara("", nil, docsTestRun("Notes\n", nil))}},
+				}},
+				{TableCells: []*docs.TableCell{
+					{Content: []*docs.StructuralElement{docsTestPara("", nil, docsTestRun("a|b\n", nil))}},
+					{Content: []*docs.StructuralElement{docsTestPara("", nil, docsTestRun("x\n", nil)), docsTestPara("", nil, docsTestRun("y\n", nil))}},
+				}},
+			}}},
+			docsTestPara("NORMAL_TEXT", nil, &docs.ParagraphElement{InlineObjectElement: &docs.InlineObjectElement{InlineObjectId: "img1"}}, docsTestRun("\n", nil)),
+		}},
+	}
+
+	r := newDocsMarkdownRenderer(tab, true, func(id, uri string) string { return "doc_images/" + id + ".png" })
+	got := r.render()
+	want := strings.Join([]string{
+		"# Plan",
+		"We ***must*** read [the docs](https://x.test)[^1] and {--old--}{++new++}",
+		"1. first\n   - detail\n2. second",
+		"```\nmake test\n  go vet\n```",
+		"---",
+		"| Name | Notes |\n| --- | --- |\n| a\\|b | x<br>y |",
+		"![diagram](doc_images/img1.png)",
+		"[^1]: Source: Q3 report",
+	}, "\n\n")
+	if got != want {
+		t.Fatalf("markdown mismatch:\n%s\n--- want:\n%s", got, want)
+	}
+}
+
+func TestDocsMarkdownRenderer_EscapesRoundTrip(t *testing.T) {
+	texts := []string{
+		"# not a heading",
+		"> not a quote",
+		"- not a list",
+		"1. not a list",
+		"---",
+		"a *b* _c_ `d` [e](f) g|h ~~i~~ back\\slash",
+	}
+	content := []*docs.StructuralElement{docsTestPara("HEADING_2", nil, docsTestRun("C #\n", nil))}
+	for _, text := range texts {
+		content = append(content, docsTestPara("NORMAL_TEXT", nil, docsTestRun(text+"\n", nil)))
+	}
+	content = append(content, docsTestPara("NORMAL_TEXT", nil, docsTestRun("2 * 3 = 6", &docs.TextStyle{Bold: true}), docsTestRun("\n", nil)))
+
+	r := newDocsMarkdownRenderer(&docs.DocumentTab{Body: &docs.Body{Content: content}}, false, nil)
+	md := r.render()
+	elements := ParseMarkdown(md)
+	if len(elements) != len(texts)+2 {
+		t.Fatalf("expected %d elements, got %+v\nmarkdown:\n%s", len(texts)+2, elements, md)
+	}
+	if _, plain := ParseInlineFormatting(elements[0].Content); elements[0].Type != MDHeading2 || plain != "C #" {
+		t.Fatalf("heading = %+v (%q)", elements[0], plain)
+	}
+	for i, want := range texts {
+		el := elements[i+1]
+		if styles, plain := ParseInlineFormatting(el.Content); el.Type != MDParagraph || plain != want || len(styles) != 0 {
+			t.Errorf("element %d = %+v, plain %q styles %+v, want paragraph %q", i+1, el, plain, styles, want)
+		}
+	}
+	styles, plain := ParseInlineFormatting(elements[len(elements)-1].Content)
+	if plain != "2 * 3 = 6" || len(styles) != 1 || !styles[0].Bold || styles[0].End != 9 {
+		t.Fatalf("bold run = %q %+v", plain, styles)
+	}
+}
+
+func TestDocsExport_Markdown(t *testing.T) {
+	origDocs := newDocsService
+	t.Cleanup(func() { newDocsService = origDocs })
+
+	var viewMode string
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/img/kix.1":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("PNG"))
+		case strings.HasPrefix(r.URL.Path, "/v1/documents/doc1"):
+			viewMode = r.URL.Query().Get("suggestionsViewMode")
+			w.Header().Set("Content-Type", "application/json")
+			tab := func(id, title, text string, extra map[string]any) map[string]any {
+				body := []any{map[string]any{"paragraph": map[string]any{"elements": []any{
+					map[string]any{"textRun": map[string]any{"content": text + "\n"}},
+				}}}}
+				if extra != nil {
+					body = append(body, extra)
+				}
+				return map[string]any{
+					"tabProperties": map[string]any{"tabId": id, "title": title},
+					"documentTab": map[string]any{
+						"body": map[string]any{"content": body},
+						"inlineObjects": map[string]any{"kix.1": map[string]any{"inlineObjectProperties": map[string]any{"embeddedObject": map[string]any{
+							"imageProperties": map[string]any{"contentUri": srvURL + "/img/kix.1"},
+						}}}},
+					},
+				}
+			}
+			img := map[string]any{"paragraph": map[string]any{"elements": []any{
+				map[string]any{"inlineObjectElement": map[string]any{"inlineObjectId": "kix.1"}},
+			}}}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"documentId": "doc1",
+				"title":      "Runbook",
+				"tabs":       []any{tab("t.0", "Intro", "hello", img), tab("t.1", "Ops", "pager", nil)},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	svc, err := docs.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(srv.Client()), option.WithEndpoint(srv.URL+"/"))
+	if err != nil {
+		t.Fatalf("docs.NewService: %v", err)
+	}
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return svc, nil }
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "runbook.md")
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "docs", "export", "doc1", "--format", "md", "--out", out}); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	})
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "<!-- tab: Intro -->\n\nhello\n\n![](runbook_images/kix.1.png)\n\n<!-- tab: Ops -->\n\npager\n"
+	if string(data) != want || viewMode != "PREVIEW_WITHOUT_SUGGESTIONS" {
+		t.Fatalf("unexpected export (viewMode=%q):\n%q", viewMode, data)
+	}
+	if img, err := os.ReadFile(filepath.Join(dir, "runbook_images", "kix.1.png")); err != nil || string(img) != "PNG" {
+		t.Fatalf("image = %q, %v", img, err)
+	}
+
+	stdout := captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "docs", "export", "doc1", "--format", "md", "--out", "-", "--tab", "ops", "--suggestions", "accepted"}); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	})
+	if stdout != "pager\n" || viewMode != "PREVIEW_SUGGESTIONS_ACCEPTED" {
+		t.Fatalf("unexpected stdout export %q (viewMode=%q)", stdout, viewMode)
+	}
+
+	if err := Execute([]string{"--account", "a@b.com", "docs", "export", "doc1", "--tab", "ops"}); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for --tab without md, got %v", err)
+	}
+}
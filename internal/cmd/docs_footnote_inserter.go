@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// FootnoteInserter creates footnotes at given indices and fills in their text
+type FootnoteInserter struct {
+	svc   *docs.Service
+	docID string
+	tabID string // optional; empty targets the first tab
+}
+
+func NewFootnoteInserter(svc *docs.Service, docID string) *FootnoteInserter {
+	return &FootnoteInserter{
+		svc:   svc,
+		docID: docID,
+	}
+}
+
+// InsertFootnotes creates each footnote and writes its content (inline
+// markdown). Footnotes are created from the end of the document backwards so
+// the reference character each one adds does not shift the ones still to come.
+func (fi *FootnoteInserter) InsertFootnotes(ctx context.Context, footnotes []FootnoteData) error {
+	ordered := append([]FootnoteData(nil), footnotes...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index > ordered[j].Index })
+
+	for _, fn := range ordered {
+		createReq := []*docs.Request{{
+			CreateFootnote: &docs.CreateFootnoteRequest{
+				Location: &docs.Location{Index: fn.Index},
+			},
+		}}
+		setDocsRequestsTab(createReq, fi.tabID)
+		resp, err := fi.svc.Documents.BatchUpdate(fi.docID, &docs.BatchUpdateDocumentRequest{
+			Requests: createReq,
+		}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("create footnote: %w", err)
+		}
+		if len(resp.Replies) == 0 || resp.Replies[0].CreateFootnote == nil {
+			return fmt.Errorf("create footnote: no footnote id in response")
+		}
+		footnoteID := resp.Replies[0].CreateFootnote.FootnoteId
+
+		styles, text := ParseInlineFormatting(fn.Content)
+		if text == "" {
+			continue
+		}
+		// A new footnote holds a single space before its paragraph end.
+		requests := []*docs.Request{{
+			InsertText: &docs.InsertTextRequest{
+				Location: &docs.Location{SegmentId: footnoteID, Index: 1},
+				Text:     text,
+			},
+		}}
+		for _, style := range styles {
+			if req := buildTextStyleRequest(style, 1); req != nil {
+				req.UpdateTextStyle.Range.SegmentId = footnoteID
+				requests = append(requests, req)
+			}
+		}
+		setDocsRequestsTab(requests, fi.tabID)
+		if _, err := fi.svc.Documents.BatchUpdate(fi.docID, &docs.BatchUpdateDocumentRequest{
+			Requests: requests,
+		}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("insert footnote text: %w", err)
+		}
+	}
+	return nil
+}
+
+// shiftForFootnotes returns index moved past the reference characters of any
+// footnotes created before it.
+func shiftForFootnotes(index int64, footnotes []FootnoteData) int64 {
+	shifted := index
+	for _, fn := range footnotes {
+		if fn.Index < index {
+			shifted++
+		}
+	}
+	return shifted
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func TestDocsUpdate_MarkdownFootnotesBeforeTables(t *testing.T) {
+	origDocs := newDocsService
+	t.Cleanup(func() { newDocsService = origDocs })
+
+	var batches []docs.BatchUpdateDocumentRequest
+	docSvc, cleanup := newDocsServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/documents/"):
+			cell := func(start int64) map[string]any {
+				return map[string]any{"content": []any{map[string]any{"startIndex": start}}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"documentId": "doc1",
+				"body": map[string]any{"content": []any{
+					map[string]any{"startIndex": 1, "endIndex": 8},
+					map[string]any{"startIndex": 8, "endIndex": 20, "table": map[string]any{"tableRows": []any{
+						map[string]any{"tableCells": []any{cell(10), cell(12)}},
+						map[string]any{"tableCells": []any{cell(15), cell(17)}},
+					}}},
+				}},
+			})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":batchUpdate"):
+			var req docs.BatchUpdateDocumentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode batchUpdate: %v", err)
+			}
+			batches = append(batches, req)
+			if req.Requests[0].CreateFootnote != nil {
+				_ = json.NewEncoder(w).Encode(map[string]any{"replies": []any{
+					map[string]any{"createFootnote": map[string]any{"footnoteId": "fn1"}},
+				}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"documentId": "doc1"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return docSvc, nil }
+
+	content := "Intro[^a]\n\n| Name | Size |\n| :-- | --: |\n| **a** | 1 |\n\n[^a]: See *appendix*."
+	flags := &RootFlags{Account: "a@b.com"}
+	if err := runKong(t, &DocsUpdateCmd{}, []string{"doc1", "--content", content, "--markdown"}, newDocsCmdContext(t), flags); err != nil {
+		t.Fatalf("docs update: %v", err)
+	}
+
+	if len(batches) < 5 {
+		t.Fatalf("expected at least 5 batch updates, got %d", len(batches))
+	}
+	if fn := batches[1].Requests[0].CreateFootnote; fn == nil || fn.Location.Index != 6 {
+		t.Fatalf("expected footnote at index 6, got %#v", batches[1].Requests[0])
+	}
+	text := batches[2].Requests[0].InsertText
+	if text == nil || text.Location.SegmentId != "fn1" || text.Location.Index != 1 || text.Text != "See appendix." {
+		t.Fatalf("unexpected footnote text request: %#v", batches[2].Requests[0])
+	}
+	if style := batches[2].Requests[1].UpdateTextStyle; style == nil || style.Range.SegmentId != "fn1" || !style.TextStyle.Italic {
+		t.Fatalf("unexpected footnote style request: %#v", batches[2].Requests[1])
+	}
+	// The table placeholder sat at 7 in the inserted text; the footnote
+	// reference pushes it to 8.
+	if table := batches[3].Requests[0].InsertTable; table == nil || table.Location.Index != 8 || table.Rows != 2 || table.Columns != 2 {
+		t.Fatalf("unexpected insert table request: %#v", batches[3].Requests[0])
+	}
+	if shade := batches[4].Requests[0].UpdateTableCellStyle; shade == nil || shade.TableRange.TableCellLocation.TableStartLocation.Index != 8 {
+		t.Fatalf("expected header shading, got %#v", batches[4].Requests[0])
+	}
+
+	var sawBoldCell, sawEndAlign bool
+	for _, batch := range batches[5:] {
+		for _, req := range batch.Requests {
+			if req.InsertText != nil && req.InsertText.Text == "a" && len(batch.Requests) > 1 && batch.Requests[1].UpdateTextStyle != nil {
+				sawBoldCell = batch.Requests[1].UpdateTextStyle.TextStyle.Bold
+			}
+			if req.UpdateParagraphStyle != nil && req.UpdateParagraphStyle.ParagraphStyle.Alignment == "END" {
+				sawEndAlign = true
+			}
+		}
+	}
+	if !sawBoldCell || !sawEndAlign {
+		t.Fatalf("expected inline bold and END alignment in table cells (bold=%v end=%v)", sawBoldCell, sawEndAlign)
+	}
+}
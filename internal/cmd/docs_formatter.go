@@ -14,214 +14,207 @@ var debugMarkdown = false
 type TableData struct {
 	StartIndex int64
 	Cells      [][]string
+	Align      []string // per column: START, CENTER, END or "" (default)
 }
 
+// FootnoteData represents a footnote to be created once the text is in place
+type FootnoteData struct {
+	Index   int64  // document index of the footnote reference
+	Content string // footnote text (inline markdown)
+}
+
+// markdownDocsPlan holds everything needed to write parsed markdown into a
+// document: the text to insert, the batch requests that style it, and the
+// tables and footnotes that have to be created in follow-up calls.
+type markdownDocsPlan struct {
+	Text      string
+	Requests  []*docs.Request
+	Tables    []TableData
+	Footnotes []FootnoteData
+}
+
+const (
+	markdownCodeFont      = "Courier New"
+	markdownQuoteIndentPt = 36
+)
+
+var (
+	markdownCodeShading = &docs.OptionalColor{Color: &docs.Color{RgbColor: &docs.RgbColor{Red: 0.95, Green: 0.95, Blue: 0.95}}}
+	markdownRuleColor   = &docs.OptionalColor{Color: &docs.Color{RgbColor: &docs.RgbColor{Red: 0.75, Green: 0.75, Blue: 0.75}}}
+)
+
 // MarkdownToDocsRequests converts parsed markdown elements to Google Docs batch
 // update requests. baseIndex is the insertion location in the document.
 // Returns: requests, plainText, tableData (for native table insertion)
 func MarkdownToDocsRequests(elements []MarkdownElement, baseIndex int64) ([]*docs.Request, string, []TableData) {
-	var requests []*docs.Request
+	plan := planMarkdownDocs(elements, baseIndex)
+	return plan.Requests, plan.Text, plan.Tables
+}
+
+// planMarkdownDocs converts parsed markdown elements into a markdownDocsPlan.
+//
+// Lists are real Docs lists: nested items are written with one leading tab
+// per level and turned into bullets by CreateParagraphBullets requests at the
+// end of the batch, bottom-up so each request's range is still valid. Those
+// requests strip the tabs, so table and footnote indices are reported as they
+// will be after the batch has run.
+func planMarkdownDocs(elements []MarkdownElement, baseIndex int64) markdownDocsPlan {
+	var plan markdownDocsPlan
 	var plainText strings.Builder
-	var tables []TableData
 	charOffset := baseIndex
+	removedTabs := int64(0)
 
 	if debugMarkdown {
 		fmt.Printf("[DEBUG] Starting MarkdownToDocsRequests with %d elements\n", len(elements))
 	}
 
+	footnotes := make(map[string]string)
 	for _, el := range elements {
-		startOffset := charOffset
+		if el.Type == MDFootnote {
+			footnotes[el.FootnoteID] = el.Content
+		}
+	}
 
-		switch el.Type {
-		case MDHeading1, MDHeading2, MDHeading3, MDHeading4, MDHeading5, MDHeading6:
-			// Parse inline formatting for heading content
-			styles, strippedContent := ParseInlineFormatting(el.Content)
+	// writeParagraph adds one paragraph of inline markdown (after an optional
+	// tab prefix) and returns its range and inline style requests.
+	writeParagraph := func(content, tabs string) (int64, int64, []*docs.Request) {
+		styles, strippedContent := ParseInlineFormatting(content)
+		// Hard breaks become soft line breaks within the paragraph.
+		strippedContent = strings.ReplaceAll(strippedContent, "\n", "\v")
 
-			if debugMarkdown {
-				fmt.Printf("[DEBUG] Heading: content=%q stripped=%q styles=%d\n", el.Content, strippedContent, len(styles))
-			}
+		if debugMarkdown {
+			fmt.Printf("[PARAGRAPH] Content: %q -> stripped=%q styles=%d\n", content, strippedContent, len(styles))
+		}
 
-			if debugMarkdown {
-				fmt.Printf("[HEADING] Content: %q\n", el.Content)
-				fmt.Printf("  Stripped: %q (len=%d)\n", strippedContent, len(strippedContent))
-				fmt.Printf("  Styles: %v\n", styles)
+		start := charOffset
+		textStart := start + utf16Len(tabs)
+		plainText.WriteString(tabs + strippedContent + "\n")
+		charOffset += utf16Len(tabs + strippedContent + "\n")
+		removedTabs += utf16Len(tabs)
+
+		var requests []*docs.Request
+		for _, style := range styles {
+			if style.Footnote != "" {
+				if body, ok := footnotes[style.Footnote]; ok {
+					plan.Footnotes = append(plan.Footnotes, FootnoteData{
+						Index:   textStart + style.Start - removedTabs,
+						Content: body,
+					})
+				}
+				continue
+			}
+			if req := buildTextStyleRequest(style, textStart); req != nil {
+				requests = append(requests, req)
 			}
+		}
+		return start, charOffset, requests
+	}
 
-			// Add stripped heading text with newline
-			plainText.WriteString(strippedContent)
-			plainText.WriteString("\n")
-			charOffset += utf16Len(strippedContent + "\n")
-
-			// Apply heading style
-			headingStyle := getHeadingStyle(el.Type)
-			requests = append(requests, &docs.Request{
-				UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
-					Range: &docs.Range{
-						StartIndex: startOffset,
-						EndIndex:   charOffset,
-					},
-					ParagraphStyle: &docs.ParagraphStyle{
-						NamedStyleType: headingStyle,
-					},
-					Fields: "namedStyleType",
-				},
-			})
+	var bullets []*docs.Request
+	var list *docs.CreateParagraphBulletsRequest
+	endList := func() {
+		if list != nil {
+			bullets = append(bullets, &docs.Request{CreateParagraphBullets: list})
+			list = nil
+		}
+	}
 
-			// Apply inline text styles
-			for _, style := range styles {
-				textStyleReq := buildTextStyleRequest(style, startOffset)
-				if textStyleReq != nil {
-					if debugMarkdown {
-						fmt.Printf("  Style request: [%d, %d]\n",
-							textStyleReq.UpdateTextStyle.Range.StartIndex,
-							textStyleReq.UpdateTextStyle.Range.EndIndex)
-					}
-					requests = append(requests, textStyleReq)
-				}
-			}
+	for _, el := range elements {
+		if el.Type != MDListItem && el.Type != MDNumberedList && el.Type != MDTaskItem {
+			endList()
+		}
+
+		switch el.Type {
+		case MDHeading1, MDHeading2, MDHeading3, MDHeading4, MDHeading5, MDHeading6:
+			start, end, styles := writeParagraph(el.Content, "")
+			plan.Requests = append(plan.Requests, paragraphStyleRequest(start, end, &docs.ParagraphStyle{
+				NamedStyleType: getHeadingStyle(el.Type),
+			}, "namedStyleType"))
+			plan.Requests = append(plan.Requests, styles...)
 
 		case MDCodeBlock:
-			// Add code block text (no inline formatting in code blocks)
+			// Code blocks keep their text verbatim: no inline formatting
+			start := charOffset
 			codeContent := el.Content + "\n"
 			plainText.WriteString(codeContent)
 			charOffset += utf16Len(codeContent)
 
-			// Apply monospace font to entire code block
-			requests = append(requests, &docs.Request{
-				UpdateTextStyle: &docs.UpdateTextStyleRequest{
-					Range: &docs.Range{
-						StartIndex: startOffset,
-						EndIndex:   charOffset,
-					},
-					TextStyle: &docs.TextStyle{
-						WeightedFontFamily: &docs.WeightedFontFamily{
-							FontFamily: "Courier New",
-							Weight:     400,
+			plan.Requests = append(plan.Requests,
+				paragraphStyleRequest(start, charOffset, &docs.ParagraphStyle{
+					Shading: &docs.Shading{BackgroundColor: markdownCodeShading},
+				}, "shading.backgroundColor"),
+				&docs.Request{
+					UpdateTextStyle: &docs.UpdateTextStyleRequest{
+						Range: &docs.Range{
+							StartIndex: start,
+							EndIndex:   charOffset,
 						},
-						BackgroundColor: &docs.OptionalColor{
-							Color: &docs.Color{
-								RgbColor: &docs.RgbColor{
-									Red:   0.95,
-									Green: 0.95,
-									Blue:  0.95,
-								},
+						TextStyle: &docs.TextStyle{
+							WeightedFontFamily: &docs.WeightedFontFamily{
+								FontFamily: markdownCodeFont,
+								Weight:     400,
 							},
 						},
+						Fields: "weightedFontFamily",
 					},
-					Fields: "weightedFontFamily,backgroundColor",
-				},
-			})
+				})
 
 		case MDBlockquote:
-			// Parse inline formatting for blockquote content
-			styles, strippedContent := ParseInlineFormatting(el.Content)
-
-			if debugMarkdown {
-				fmt.Printf("[BLOCKQUOTE] Content: %q -> stripped=%q\n", el.Content, strippedContent)
-			}
-
-			// Add stripped blockquote text
-			plainText.WriteString(strippedContent)
-			plainText.WriteString("\n")
-			charOffset += utf16Len(strippedContent + "\n")
-
-			// Apply blockquote style (indent)
-			requests = append(requests, &docs.Request{
-				UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
-					Range: &docs.Range{
-						StartIndex: startOffset,
-						EndIndex:   charOffset,
-					},
-					ParagraphStyle: &docs.ParagraphStyle{
-						IndentStart: &docs.Dimension{
-							Magnitude: 36,
-							Unit:      "PT",
-						},
-					},
-					Fields: "indentStart",
+			start, end, styles := writeParagraph(el.Content, "")
+			indent := &docs.Dimension{Magnitude: markdownQuoteIndentPt * float64(el.Level+1), Unit: "PT"}
+			plan.Requests = append(plan.Requests, paragraphStyleRequest(start, end, &docs.ParagraphStyle{
+				IndentStart:     indent,
+				IndentFirstLine: indent,
+				BorderLeft: &docs.ParagraphBorder{
+					Color:     markdownRuleColor,
+					DashStyle: "SOLID",
+					Padding:   &docs.Dimension{Magnitude: 8, Unit: "PT"},
+					Width:     &docs.Dimension{Magnitude: 3, Unit: "PT"},
 				},
-			})
-
-			// Apply inline text styles
-			for _, style := range styles {
-				textStyleReq := buildTextStyleRequest(style, startOffset)
-				if textStyleReq != nil {
-					if debugMarkdown {
-						fmt.Printf("  Style request: [%d, %d] (base=%d, style=[%d,%d])\n",
-							textStyleReq.UpdateTextStyle.Range.StartIndex,
-							textStyleReq.UpdateTextStyle.Range.EndIndex,
-							startOffset, style.Start, style.End)
-					}
-					requests = append(requests, textStyleReq)
-				}
-			}
-
-		case MDListItem, MDNumberedList:
-			// Parse inline formatting for list item content
-			styles, strippedContent := ParseInlineFormatting(el.Content)
-
-			if debugMarkdown {
-				fmt.Printf("[LIST] Content: %q -> stripped=%q styles=%d\n", el.Content, strippedContent, len(styles))
+			}, "indentStart,indentFirstLine,borderLeft"))
+			plan.Requests = append(plan.Requests, styles...)
+
+		case MDListItem, MDNumberedList, MDTaskItem:
+			preset := markdownBulletPreset(el.Type)
+			// A top-level item of a different kind starts a new list.
+			if list != nil && el.Level == 0 && list.BulletPreset != preset {
+				endList()
 			}
-
-			// Add list item with prefix
-			prefix := "• "
-			if el.Type == MDNumberedList {
-				prefix = "1. "
-			}
-			prefixLen := utf16Len(prefix)
-			plainText.WriteString(prefix)
-			plainText.WriteString(strippedContent)
-			plainText.WriteString("\n")
-			charOffset += prefixLen + utf16Len(strippedContent+"\n")
-
-			// Apply inline text styles (offset by prefix length)
-			for _, style := range styles {
-				textStyleReq := buildTextStyleRequest(style, startOffset+prefixLen)
-				if textStyleReq != nil {
-					requests = append(requests, textStyleReq)
+			start, end, styles := writeParagraph(el.Content, strings.Repeat("\t", el.Level))
+			if list == nil {
+				list = &docs.CreateParagraphBulletsRequest{
+					Range:        &docs.Range{StartIndex: start},
+					BulletPreset: preset,
 				}
 			}
+			list.Range.EndIndex = end
+			plan.Requests = append(plan.Requests, styles...)
+
+			// Docs has no API for ticking a checkbox; strike done tasks through.
+			if el.Type == MDTaskItem && el.Checked && end-1 > start+int64(el.Level) {
+				plan.Requests = append(plan.Requests, buildTextStyleRequest(TextStyle{
+					Strikethrough: true,
+					End:           end - 1 - start - int64(el.Level),
+				}, start+int64(el.Level)))
+			}
 
 		case MDHorizontalRule:
-			// Add horizontal rule as a separator line using ASCII dashes
-			separator := strings.Repeat("-", 40)
-			plainText.WriteString(separator)
+			// An empty paragraph with a bottom border
+			start := charOffset
 			plainText.WriteString("\n")
-			charOffset += utf16Len(separator + "\n")
+			charOffset += utf16Len("\n")
+			plan.Requests = append(plan.Requests, paragraphStyleRequest(start, charOffset, &docs.ParagraphStyle{
+				BorderBottom: &docs.ParagraphBorder{
+					Color:     markdownRuleColor,
+					DashStyle: "SOLID",
+					Padding:   &docs.Dimension{Magnitude: 1, Unit: "PT"},
+					Width:     &docs.Dimension{Magnitude: 1, Unit: "PT"},
+				},
+			}, "borderBottom"))
 
 		case MDParagraph:
-			// Parse inline formatting for paragraph content
-			styles, strippedContent := ParseInlineFormatting(el.Content)
-
-			if debugMarkdown {
-				fmt.Printf("[PARAGRAPH] Content: %q\n", el.Content)
-				fmt.Printf("  Stripped: %q (len=%d)\n", strippedContent, len(strippedContent))
-				fmt.Printf("  Styles: %v\n", styles)
-				fmt.Printf("  startOffset: %d, len+1: %d\n", startOffset, len(strippedContent)+1)
-			}
-
-			// Add stripped paragraph text
-			plainText.WriteString(strippedContent)
-			plainText.WriteString("\n")
-			charOffset += utf16Len(strippedContent + "\n")
-
-			if debugMarkdown {
-				fmt.Printf("  charOffset after: %d, plainText.Len: %d\n", charOffset, plainText.Len())
-			}
-
-			// Apply inline text styles
-			for _, style := range styles {
-				textStyleReq := buildTextStyleRequest(style, startOffset)
-				if textStyleReq != nil {
-					if debugMarkdown {
-						fmt.Printf("  Style request: [%d, %d]\n",
-							textStyleReq.UpdateTextStyle.Range.StartIndex,
-							textStyleReq.UpdateTextStyle.Range.EndIndex)
-					}
-					requests = append(requests, textStyleReq)
-				}
-			}
+			_, _, styles := writeParagraph(el.Content, "")
+			plan.Requests = append(plan.Requests, styles...)
 
 		case MDEmptyLine:
 			// Add empty line
@@ -230,41 +223,103 @@ func MarkdownToDocsRequests(elements []MarkdownElement, baseIndex int64) ([]*doc
 
 		case MDTable:
 			// Handle markdown table - save for native insertion
-			if len(el.TableCells) == 0 {
-				continue
-			}
-
-			rows := len(el.TableCells)
-			cols := len(el.TableCells[0])
-			if rows == 0 || cols == 0 {
+			if len(el.TableCells) == 0 || len(el.TableCells[0]) == 0 {
 				continue
 			}
 
 			if debugMarkdown {
-				fmt.Printf("[TABLE] %d rows x %d cols at offset %d - saving for native insertion\n", rows, cols, charOffset)
+				fmt.Printf("[TABLE] %d rows x %d cols at offset %d - saving for native insertion\n", len(el.TableCells), len(el.TableCells[0]), charOffset)
 			}
 
-			// Save table data for native insertion
-			tables = append(tables, TableData{
-				StartIndex: charOffset,
+			plan.Tables = append(plan.Tables, TableData{
+				StartIndex: charOffset - removedTabs,
 				Cells:      el.TableCells,
+				Align:      el.TableAlign,
 			})
 
 			// Add a placeholder newline (table will be inserted here)
 			plainText.WriteString("\n")
 			charOffset += utf16Len("\n")
+
+		case MDFootnote:
+			// Definitions are attached to their references above
 		}
 	}
+	endList()
+
+	// Apply bullets last and bottom-up: each request removes the leading tabs
+	// of its own paragraphs, which would shift any range that follows it.
+	for i := len(bullets) - 1; i >= 0; i-- {
+		plan.Requests = append(plan.Requests, bullets[i])
+	}
+	plan.Text = plainText.String()
 
 	if debugMarkdown {
 		fmt.Printf("\n[FINAL] plainText length: %d\n", plainText.Len())
 		fmt.Printf("[FINAL] Final charOffset: %d\n", charOffset)
-		fmt.Printf("[FINAL] Total requests: %d\n", len(requests))
-		fmt.Printf("[FINAL] Total tables: %d\n", len(tables))
-		fmt.Printf("\n[FINAL] plainText content:\n%s\n[END]\n", plainText.String())
+		fmt.Printf("[FINAL] Total requests: %d\n", len(plan.Requests))
+		fmt.Printf("[FINAL] Total tables: %d, footnotes: %d\n", len(plan.Tables), len(plan.Footnotes))
+		fmt.Printf("\n[FINAL] plainText content:\n%s\n[END]\n", plan.Text)
+	}
+
+	return plan
+}
+
+func markdownBulletPreset(elType MarkdownElementType) string {
+	switch elType {
+	case MDNumberedList:
+		return "NUMBERED_DECIMAL_ALPHA_ROMAN"
+	case MDTaskItem:
+		return "BULLET_CHECKBOX"
+	default:
+		return "BULLET_DISC_CIRCLE_SQUARE"
 	}
+}
+
+// setDocsRequestsTab points every range and location in requests at tabID.
+// An empty tabID leaves them on the document's first tab.
+func setDocsRequestsTab(requests []*docs.Request, tabID string) {
+	if tabID == "" {
+		return
+	}
+	for _, req := range requests {
+		switch {
+		case req.InsertText != nil && req.InsertText.Location != nil:
+			req.InsertText.Location.TabId = tabID
+		case req.DeleteContentRange != nil && req.DeleteContentRange.Range != nil:
+			req.DeleteContentRange.Range.TabId = tabID
+		case req.UpdateTextStyle != nil && req.UpdateTextStyle.Range != nil:
+			req.UpdateTextStyle.Range.TabId = tabID
+		case req.UpdateParagraphStyle != nil && req.UpdateParagraphStyle.Range != nil:
+			req.UpdateParagraphStyle.Range.TabId = tabID
+		case req.CreateParagraphBullets != nil && req.CreateParagraphBullets.Range != nil:
+			req.CreateParagraphBullets.Range.TabId = tabID
+		case req.DeleteParagraphBullets != nil && req.DeleteParagraphBullets.Range != nil:
+			req.DeleteParagraphBullets.Range.TabId = tabID
+		case req.InsertTable != nil && req.InsertTable.Location != nil:
+			req.InsertTable.Location.TabId = tabID
+		case req.CreateFootnote != nil && req.CreateFootnote.Location != nil:
+			req.CreateFootnote.Location.TabId = tabID
+		case req.UpdateTableCellStyle != nil && req.UpdateTableCellStyle.TableRange != nil &&
+			req.UpdateTableCellStyle.TableRange.TableCellLocation != nil &&
+			req.UpdateTableCellStyle.TableRange.TableCellLocation.TableStartLocation != nil:
+			req.UpdateTableCellStyle.TableRange.TableCellLocation.TableStartLocation.TabId = tabID
+		}
+	}
+}
 
-	return requests, plainText.String(), tables
+// paragraphStyleRequest creates a paragraph style update for [start, end)
+func paragraphStyleRequest(start, end int64, style *docs.ParagraphStyle, fields string) *docs.Request {
+	return &docs.Request{
+		UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+			Range: &docs.Range{
+				StartIndex: start,
+				EndIndex:   end,
+			},
+			ParagraphStyle: style,
+			Fields:         fields,
+		},
+	}
 }
 
 // buildTextStyleRequest creates a text style update request from a TextStyle
@@ -285,9 +340,13 @@ func buildTextStyleRequest(style TextStyle, baseOffset int64) *docs.Request {
 		textStyle.Italic = true
 		fields = append(fields, "italic")
 	}
+	if style.Strikethrough {
+		textStyle.Strikethrough = true
+		fields = append(fields, "strikethrough")
+	}
 	if style.Code {
 		textStyle.WeightedFontFamily = &docs.WeightedFontFamily{
-			FontFamily: "Courier New",
+			FontFamily: markdownCodeFont,
 			Weight:     400,
 		}
 		fields = append(fields, "weightedFontFamily")
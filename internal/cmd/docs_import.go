@@ -229,19 +229,11 @@ func pathWithinDir(path string, dir string) bool {
 }
 
 // buildImageInsertRequests creates the Docs API batch update requests to replace
-// placeholder text with inline images. Requests are ordered in reverse index order
-// so earlier positions are not invalidated as the document is modified.
+// placeholder text with inline images.
 func buildImageInsertRequests(placeholders map[string]docRange, images []markdownImage, imageURLs map[int]string) []*docs.Request {
-	// Collect entries sorted by start index descending.
-	type entry struct {
-		image markdownImage
-		dr    docRange
-		url   string
-	}
-	var entries []entry
+	var places []docsImagePlacement
 	for _, img := range images {
-		ph := img.placeholder()
-		dr, ok := placeholders[ph]
+		dr, ok := placeholders[img.placeholder()]
 		if !ok {
 			continue
 		}
@@ -249,34 +241,39 @@ func buildImageInsertRequests(placeholders map[string]docRange, images []markdow
 		if !ok {
 			continue
 		}
-		entries = append(entries, entry{image: img, dr: dr, url: u})
+		places = append(places, docsImagePlacement{
+			rng: &docs.Range{StartIndex: dr.startIndex, EndIndex: dr.endIndex},
+			uri: u,
+		})
 	}
+	return docsImageRequests(places)
+}
 
-	// Sort by start index descending; process from end of document to start.
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].dr.startIndex > entries[j].dr.startIndex
-	})
+// docsImagePlacement is an inline image that takes the place of a document
+// range. The range's SegmentId and TabId carry over to the image.
+type docsImagePlacement struct {
+	rng  *docs.Range
+	uri  string
+	size *docs.Size // nil keeps the image's own size
+}
 
-	reqs := make([]*docs.Request, 0, len(entries)*2)
-	for _, e := range entries {
-		// First delete the placeholder text.
-		reqs = append(reqs, &docs.Request{
-			DeleteContentRange: &docs.DeleteContentRangeRequest{
-				Range: &docs.Range{
-					StartIndex: e.dr.startIndex,
-					EndIndex:   e.dr.endIndex,
-				},
-			},
-		})
-		// Then insert the image at that position.
-		reqs = append(reqs, &docs.Request{
-			InsertInlineImage: &docs.InsertInlineImageRequest{
-				Uri: e.url,
-				Location: &docs.Location{
-					Index: e.dr.startIndex,
-				},
-			},
-		})
+// docsImageRequests deletes each placement's range (when not empty) and
+// inserts its image there. Requests are ordered in reverse index order so
+// earlier positions are not invalidated as the document is modified.
+func docsImageRequests(places []docsImagePlacement) []*docs.Request {
+	sort.SliceStable(places, func(i, j int) bool { return places[i].rng.StartIndex > places[j].rng.StartIndex })
+	reqs := make([]*docs.Request, 0, len(places)*2)
+	for _, p := range places {
+		if p.rng.EndIndex > p.rng.StartIndex {
+			reqs = append(reqs, &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{
+				Range: &docs.Range{StartIndex: p.rng.StartIndex, EndIndex: p.rng.EndIndex, SegmentId: p.rng.SegmentId, TabId: p.rng.TabId},
+			}})
+		}
+		reqs = append(reqs, &docs.Request{InsertInlineImage: &docs.InsertInlineImageRequest{
+			Uri:        p.uri,
+			ObjectSize: p.size,
+			Location:   &docs.Location{Index: p.rng.StartIndex, SegmentId: p.rng.SegmentId, TabId: p.rng.TabId},
+		}})
 	}
 	return reqs
 }
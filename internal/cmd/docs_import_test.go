@@ -686,6 +686,28 @@ func TestBuildImageInsertRequests_InsertLocationMatchesStart(t *testing.T) {
 	}
 }
 
+func TestDocsImageRequests_SegmentTabAndSize(t *testing.T) {
+	size := &docs.Size{Width: &docs.Dimension{Magnitude: 120, Unit: "PT"}}
+	reqs := docsImageRequests([]docsImagePlacement{
+		{rng: &docs.Range{StartIndex: 5, EndIndex: 5, TabId: "t.1"}, uri: "https://x.com/a.png"},
+		{rng: &docs.Range{StartIndex: 20, EndIndex: 30, SegmentId: "kix.h1", TabId: "t.1"}, uri: "https://x.com/b.png", size: size},
+	})
+	if len(reqs) != 3 {
+		t.Fatalf("expected delete+insert for the range and a bare insert, got %d", len(reqs))
+	}
+	del := reqs[0].DeleteContentRange
+	if del == nil || del.Range.SegmentId != "kix.h1" || del.Range.TabId != "t.1" || del.Range.StartIndex != 20 {
+		t.Fatalf("unexpected delete: %+v", reqs[0])
+	}
+	ins := reqs[1].InsertInlineImage
+	if ins == nil || ins.Uri != "https://x.com/b.png" || ins.ObjectSize != size || ins.Location.SegmentId != "kix.h1" || ins.Location.TabId != "t.1" {
+		t.Fatalf("unexpected insert: %+v", reqs[1])
+	}
+	if bare := reqs[2].InsertInlineImage; bare == nil || bare.Location.Index != 5 || bare.Location.TabId != "t.1" || bare.ObjectSize != nil {
+		t.Fatalf("unexpected bare insert: %+v", reqs[2])
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Round-trip: extract then find placeholders
 // ---------------------------------------------------------------------------
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // MarkdownElementType represents the type of markdown element
@@ -32,26 +33,37 @@ const (
 	MDParagraph
 	MDEmptyLine
 	MDTable
+	MDTaskItem
+	MDFootnote
 )
 
+// maxMarkdownListLevel is the deepest nesting level Google Docs lists support.
+const maxMarkdownListLevel = 8
+
 // MarkdownElement represents a parsed markdown element
 type MarkdownElement struct {
 	Type       MarkdownElementType
 	Content    string
 	Children   []MarkdownElement
 	URL        string     // for links
-	Level      int        // for headings and lists
+	Level      int        // for headings, list nesting and blockquote depth (0-based)
 	TableCells [][]string // for tables: rows of cells
+	TableAlign []string   // for tables: START, CENTER, END or "" per column
+	Language   string     // for fenced code blocks
+	Checked    bool       // for task list items
+	FootnoteID string     // for footnote definitions
 }
 
 // TextStyle represents text formatting
 type TextStyle struct {
-	Bold   bool
-	Italic bool
-	Code   bool
-	Link   string
-	Start  int64
-	End    int64
+	Bold          bool
+	Italic        bool
+	Code          bool
+	Strikethrough bool
+	Link          string
+	Footnote      string // footnote reference id; zero-width (Start == End)
+	Start         int64
+	End           int64
 }
 
 // ParagraphStyle represents paragraph-level formatting
@@ -66,113 +78,156 @@ func utf16Len(s string) int64 {
 	return int64(len(utf16.Encode([]rune(s))))
 }
 
-// ParseMarkdown parses markdown text into structured elements
+var (
+	mdFenceRe       = regexp.MustCompile("^([ \t]*)(`{3,}|~{3,})[ \t]*([^`\\s]*)[^`]*$")
+	mdListItemRe    = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	mdTaskRe        = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+(.*))?$`)
+	mdFootnoteDefRe = regexp.MustCompile(`^\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+	mdSetextRe      = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdHTMLCommentRe = regexp.MustCompile(`^[ \t]*<!--.*-->[ \t]*$`)
+	mdTableBreakRe  = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// ParseMarkdown parses markdown text into structured elements. It covers the
+// CommonMark block structure (ATX and setext headings, fenced code, nested
+// lists, multi-line blockquotes, paragraphs with soft and hard breaks) plus
+// the GFM extensions we rely on: tables with alignment, task lists and
+// footnote definitions.
 func ParseMarkdown(text string) []MarkdownElement {
 	var elements []MarkdownElement
-	lines := strings.Split(text, "\n")
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
 
-	inCodeBlock := false
-	var codeBlockContent strings.Builder
+	var para []string     // pending paragraph lines
+	var listIndents []int // marker indents of the open list levels
+	lastItem := -1        // index of the list item that takes continuation lines
+	blank := false        // a blank line was seen since the last block
+
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		elements = append(elements, MarkdownElement{
+			Type:    MDParagraph,
+			Content: joinMarkdownLines(para),
+		})
+		para = nil
+	}
+	endList := func() {
+		listIndents = nil
+		lastItem = -1
+	}
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
+		trimmed := strings.TrimSpace(line)
 
-		// Handle code blocks
-		if strings.HasPrefix(line, "```") {
-			if inCodeBlock {
-				// End code block
-				elements = append(elements, MarkdownElement{
-					Type:    MDCodeBlock,
-					Content: codeBlockContent.String(),
-				})
-				codeBlockContent.Reset()
-				inCodeBlock = false
-			} else {
-				// Start code block
-				inCodeBlock = true
-			}
+		// Empty line
+		if trimmed == "" {
+			flushPara()
+			blank = true
 			continue
 		}
+		indent := markdownIndent(line)
 
-		if inCodeBlock {
-			if codeBlockContent.Len() > 0 {
-				codeBlockContent.WriteString("\n")
+		// Fenced code block (``` or ~~~, optionally with a language)
+		if m := mdFenceRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			if indent == 0 {
+				endList()
 			}
-			codeBlockContent.WriteString(line)
+			fence := m[2]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if t := strings.TrimSpace(lines[i]); len(t) >= len(fence) && strings.Trim(t, fence[:1]) == "" {
+					break
+				}
+				code = append(code, trimMarkdownIndent(lines[i], indent))
+			}
+			elements = append(elements, MarkdownElement{
+				Type:     MDCodeBlock,
+				Content:  strings.Join(code, "\n"),
+				Language: m[3],
+			})
+			blank = false
 			continue
 		}
 
-		// Empty line
-		if strings.TrimSpace(line) == "" {
+		// Single-line HTML comments (template hints) are dropped
+		if mdHTMLCommentRe.MatchString(line) {
+			flushPara()
 			continue
 		}
 
-		// Horizontal rule
-		if isHorizontalRule(line) {
+		// Setext heading: a paragraph underlined with === or ---
+		if len(para) > 0 && mdSetextRe.MatchString(line) {
+			headingType := MDHeading2
+			if trimmed[0] == '=' {
+				headingType = MDHeading1
+			}
 			elements = append(elements, MarkdownElement{
-				Type: MDHorizontalRule,
+				Type:    headingType,
+				Content: joinMarkdownLines(para),
 			})
+			para = nil
 			continue
 		}
 
-		// Headings
-		if headingLevel, content := parseHeading(line); headingLevel > 0 {
-			headingType := MDHeading1
-			switch headingLevel {
-			case 1:
-				headingType = MDHeading1
-			case 2:
-				headingType = MDHeading2
-			case 3:
-				headingType = MDHeading3
-			case 4:
-				headingType = MDHeading4
-			case 5:
-				headingType = MDHeading5
-			case 6:
-				headingType = MDHeading6
-			}
+		// Horizontal rule
+		if indent < 4 && isHorizontalRule(line) {
+			flushPara()
+			endList()
 			elements = append(elements, MarkdownElement{
-				Type:    headingType,
-				Content: content,
+				Type: MDHorizontalRule,
 			})
 			continue
 		}
 
-		// Blockquote
-		if strings.HasPrefix(line, "> ") {
-			content := strings.TrimPrefix(line, "> ")
-			if debugMarkdown {
-				fmt.Printf("[PARSE] Blockquote detected: %q -> %q\n", line, content)
-			}
+		// Headings
+		if headingLevel, content := parseHeading(trimmed); indent < 4 && headingLevel > 0 {
+			flushPara()
+			endList()
 			elements = append(elements, MarkdownElement{
-				Type:    MDBlockquote,
+				Type:    MDHeading1 + MarkdownElementType(headingLevel-1),
 				Content: content,
 			})
 			continue
 		}
 
-		// Numbered list
-		if match := regexp.MustCompile(`^(\d+)\.\s+(.+)`).FindStringSubmatch(line); match != nil {
+		// Footnote definition, with indented continuation lines
+		if m := mdFootnoteDefRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			endList()
+			content := strings.TrimSpace(m[2])
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" && markdownIndent(lines[i+1]) >= 2 {
+				i++
+				content += " " + strings.TrimSpace(lines[i])
+			}
 			elements = append(elements, MarkdownElement{
-				Type:    MDNumberedList,
-				Content: match[2],
+				Type:       MDFootnote,
+				Content:    content,
+				FootnoteID: m[1],
 			})
 			continue
 		}
 
-		// Bullet list
-		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
-			content := strings.TrimPrefix(strings.TrimPrefix(line, "- "), "* ")
-			elements = append(elements, MarkdownElement{
-				Type:    MDListItem,
-				Content: content,
-			})
+		// Blockquote: consecutive "> " lines form paragraphs; ">>" nests
+		if strings.HasPrefix(trimmed, ">") {
+			flushPara()
+			endList()
+			var quoteLines []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoteLines = append(quoteLines, strings.TrimSpace(lines[i]))
+			}
+			i--
+			if debugMarkdown {
+				fmt.Printf("[PARSE] Blockquote detected: %q\n", quoteLines)
+			}
+			elements = append(elements, parseBlockquote(quoteLines)...)
 			continue
 		}
 
 		// Table detection - line starts with | and has multiple |
-		if strings.HasPrefix(line, "|") && strings.Count(line, "|") >= 2 {
+		if strings.HasPrefix(trimmed, "|") && strings.Count(trimmed, "|") >= 2 {
 			if debugMarkdown {
 				fmt.Printf("[TABLE DEBUG] Found potential table row: %q\n", line)
 				if i+1 < len(lines) {
@@ -181,14 +236,13 @@ func ParseMarkdown(text string) []MarkdownElement {
 			}
 			// Check if next line is separator (|---|---| pattern)
 			if i+1 < len(lines) && isTableSeparator(lines[i+1]) {
-				if debugMarkdown {
-					fmt.Printf("[TABLE DEBUG] Parsing table starting at line %d\n", i)
-				}
-				// Parse table
+				flushPara()
+				endList()
 				tableCells := parseMarkdownTable(lines[i:])
 				elements = append(elements, MarkdownElement{
 					Type:       MDTable,
 					TableCells: tableCells,
+					TableAlign: parseTableAlign(lines[i+1]),
 				})
 				// Skip all table lines
 				i += len(tableCells) // loop increment handles separator line offset
@@ -196,16 +250,143 @@ func ParseMarkdown(text string) []MarkdownElement {
 			}
 		}
 
+		// List items: -, * and + bullets, 1. and 1) numbers, [ ] / [x] tasks
+		if m := mdListItemRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			markerIndent := markdownIndent(m[1])
+			for len(listIndents) > 0 && markerIndent < listIndents[len(listIndents)-1] {
+				listIndents = listIndents[:len(listIndents)-1]
+			}
+			if len(listIndents) == 0 || markerIndent >= listIndents[len(listIndents)-1]+2 {
+				listIndents = append(listIndents, markerIndent)
+			}
+			el := MarkdownElement{
+				Type:    MDListItem,
+				Content: strings.TrimSpace(m[3]),
+				Level:   min(len(listIndents)-1, maxMarkdownListLevel),
+			}
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				el.Type = MDNumberedList
+			}
+			if tm := mdTaskRe.FindStringSubmatch(el.Content); tm != nil {
+				el.Type = MDTaskItem
+				el.Checked = tm[1] != " "
+				el.Content = strings.TrimSpace(tm[2])
+			}
+			elements = append(elements, el)
+			lastItem = len(elements) - 1
+			blank = false
+			continue
+		}
+
+		// Continuation of the current list item: lazy lines join the item,
+		// indented lines after a blank line become a line break inside it.
+		if lastItem >= 0 && (!blank || indent >= 2) {
+			sep := " "
+			if blank {
+				sep = "\n"
+			}
+			elements[lastItem].Content += sep + trimmed
+			blank = false
+			continue
+		}
+
 		// Regular paragraph
-		elements = append(elements, MarkdownElement{
-			Type:    MDParagraph,
-			Content: line,
-		})
+		endList()
+		para = append(para, line)
+		blank = false
 	}
+	flushPara()
 
 	return elements
 }
 
+// joinMarkdownLines joins paragraph lines with spaces (soft breaks), keeping
+// a newline where a line ends in two spaces or a backslash (hard breaks).
+func joinMarkdownLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		content := strings.TrimSpace(line)
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(content, "\\")
+		if hardBreak {
+			content = strings.TrimSuffix(content, "\\")
+		}
+		b.WriteString(content)
+		if i < len(lines)-1 {
+			if hardBreak {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+	}
+	return b.String()
+}
+
+// markdownIndent returns the column width of a line's leading whitespace,
+// counting tabs to the next multiple of 4.
+func markdownIndent(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4 - n%4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// trimMarkdownIndent removes up to n columns of leading whitespace.
+func trimMarkdownIndent(line string, n int) string {
+	col := 0
+	for i, c := range line {
+		if col >= n || (c != ' ' && c != '\t') {
+			return line[i:]
+		}
+		col = markdownIndent(line[:i+1])
+	}
+	return ""
+}
+
+// parseBlockquote turns "> " lines into one element per quoted paragraph,
+// with Level holding the nesting depth.
+func parseBlockquote(lines []string) []MarkdownElement {
+	var elements []MarkdownElement
+	var cur []string
+	curDepth := 0
+	flush := func() {
+		if len(cur) > 0 {
+			elements = append(elements, MarkdownElement{
+				Type:    MDBlockquote,
+				Content: joinMarkdownLines(cur),
+				Level:   curDepth - 1,
+			})
+		}
+		cur = nil
+	}
+	for _, line := range lines {
+		depth := 0
+		for strings.HasPrefix(line, ">") {
+			depth++
+			line = strings.TrimLeft(strings.TrimPrefix(line, ">"), " ")
+		}
+		if strings.TrimSpace(line) == "" || depth != curDepth {
+			flush()
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		curDepth = depth
+		cur = append(cur, line)
+	}
+	flush()
+	return elements
+}
+
 // isTableSeparator checks if a line is a markdown table separator (|---|---|)
 func isTableSeparator(line string) bool {
 	trimmed := strings.TrimSpace(line)
@@ -239,6 +420,25 @@ func isTableSeparator(line string) bool {
 	return len(segments) > 1
 }
 
+// parseTableAlign reads per-column alignment from a separator row
+// (:--- start, :---: center, ---: end).
+func parseTableAlign(separator string) []string {
+	cells := parseTableRow(strings.TrimSpace(separator))
+	align := make([]string, len(cells))
+	for i, cell := range cells {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			align[i] = "CENTER"
+		case right:
+			align[i] = "END"
+		case left:
+			align[i] = "START"
+		}
+	}
+	return align
+}
+
 // parseMarkdownTable parses a markdown table into rows of cells
 func parseMarkdownTable(lines []string) [][]string {
 	var rows [][]string
@@ -266,19 +466,34 @@ func parseMarkdownTable(lines []string) [][]string {
 	return rows
 }
 
-// parseTableRow parses a single table row into cells
+// parseTableRow parses a single table row into cells. Escaped pipes (\|)
+// stay inside their cell, escaped backslashes pass through for the inline
+// parser, and <br> becomes a line break.
 func parseTableRow(line string) []string {
 	// Remove outer pipes
-	trimmed := strings.Trim(line, "|")
-
-	// Split by |
-	parts := strings.Split(trimmed, "|")
+	trimmed := strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(trimmed, "|") && !strings.HasSuffix(trimmed, `\|`) {
+		trimmed = trimmed[:len(trimmed)-1]
+	}
 
-	cells := make([]string, 0, len(parts))
-	for _, part := range parts {
-		cell := strings.TrimSpace(part)
-		cells = append(cells, cell)
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case trimmed[i] == '\\' && i+1 < len(trimmed) && trimmed[i+1] == '\\':
+			cell.WriteString(`\\`)
+			i++
+		case trimmed[i] == '\\' && i+1 < len(trimmed) && trimmed[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case trimmed[i] == '|':
+			cells = append(cells, strings.TrimSpace(mdTableBreakRe.ReplaceAllString(cell.String(), "\n")))
+			cell.Reset()
+		default:
+			cell.WriteByte(trimmed[i])
+		}
 	}
+	cells = append(cells, strings.TrimSpace(mdTableBreakRe.ReplaceAllString(cell.String(), "\n")))
 
 	return cells
 }
@@ -287,17 +502,48 @@ func parseTableRow(line string) []string {
 const inlineTypeCode = "code"
 
 type InlineMatch struct {
-	Start   int
-	End     int
-	Content string
-	Type    string
-	URL     string
+	Start    int
+	End      int
+	Content  string
+	Type     string
+	URL      string
+	Footnote string
+}
+
+var (
+	mdAutolinkRe     = regexp.MustCompile(`<(https?://[^>\s]+|mailto:[^>\s]+)>`)
+	mdBareURLRe      = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	mdFootnoteRefRe  = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	mdStrikeRe       = regexp.MustCompile(`~~([^~]+)~~`)
+	mdUnderBoldRe    = regexp.MustCompile(`__([^_]+)__`)
+	mdUnderItalicRe  = regexp.MustCompile(`_([^_]+)_`)
+	mdWordBoundaryRe = regexp.MustCompile(`[\p{L}\p{N}_]`)
+)
+
+// overlapsInline reports whether [start,end) overlaps an existing match.
+func overlapsInline(matches []InlineMatch, start, end int) bool {
+	for _, m := range matches {
+		if start < m.End && end > m.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// isIntrawordUnderscore reports whether an underscore match at [start,end)
+// touches a letter or digit, in which case it is part of a word like snake_case.
+func isIntrawordUnderscore(text string, start, end int) bool {
+	if start > 0 && mdWordBoundaryRe.MatchString(text[start-1:start]) {
+		return true
+	}
+	return end < len(text) && mdWordBoundaryRe.MatchString(text[end:end+1])
 }
 
 // ParseInlineFormatting parses inline markdown formatting within text
 // Returns styles with indices relative to the stripped plain text (UTF-16 code units)
 func ParseInlineFormatting(text string) ([]TextStyle, string) {
 	var matches []InlineMatch
+	text = maskMarkdownEscapes(text)
 
 	// Find all links [text](url)
 	linkRegex := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
@@ -317,11 +563,67 @@ func ParseInlineFormatting(text string) ([]TextStyle, string) {
 		matches = append(matches, InlineMatch{
 			Start:   idx[0],
 			End:     idx[1],
-			Content: text[idx[2]:idx[3]],
+			Content: unmaskMarkdownEscapes(text[idx[2]:idx[3]], true),
 			Type:    inlineTypeCode,
 		})
 	}
 
+	// Find autolinks <https://...>
+	for _, idx := range mdAutolinkRe.FindAllStringSubmatchIndex(text, -1) {
+		if overlapsInline(matches, idx[0], idx[1]) {
+			continue
+		}
+		url := text[idx[2]:idx[3]]
+		matches = append(matches, InlineMatch{
+			Start:   idx[0],
+			End:     idx[1],
+			Content: strings.TrimPrefix(url, "mailto:"),
+			Type:    "link",
+			URL:     url,
+		})
+	}
+
+	// Find bare URLs (GFM autolink extension), minus trailing punctuation
+	for _, idx := range mdBareURLRe.FindAllStringIndex(text, -1) {
+		end := idx[0] + len(strings.TrimRight(text[idx[0]:idx[1]], ".,;:!?*_~'\""))
+		if overlapsInline(matches, idx[0], end) {
+			continue
+		}
+		matches = append(matches, InlineMatch{
+			Start:   idx[0],
+			End:     end,
+			Content: text[idx[0]:end],
+			Type:    "link",
+			URL:     text[idx[0]:end],
+		})
+	}
+
+	// Find footnote references [^id]; they leave no text behind
+	for _, idx := range mdFootnoteRefRe.FindAllStringSubmatchIndex(text, -1) {
+		if overlapsInline(matches, idx[0], idx[1]) {
+			continue
+		}
+		matches = append(matches, InlineMatch{
+			Start:    idx[0],
+			End:      idx[1],
+			Type:     "footnote",
+			Footnote: text[idx[2]:idx[3]],
+		})
+	}
+
+	// Find strikethrough ~~text~~
+	for _, idx := range mdStrikeRe.FindAllStringSubmatchIndex(text, -1) {
+		if overlapsInline(matches, idx[0], idx[1]) {
+			continue
+		}
+		matches = append(matches, InlineMatch{
+			Start:   idx[0],
+			End:     idx[1],
+			Content: text[idx[2]:idx[3]],
+			Type:    "strikethrough",
+		})
+	}
+
 	// Find bold-italic ***text***
 	biRegex := regexp.MustCompile(`\*\*\*([^*]+)\*\*\*`)
 	for _, idx := range biRegex.FindAllStringSubmatchIndex(text, -1) {
@@ -392,6 +694,25 @@ func ParseInlineFormatting(text string) ([]TextStyle, string) {
 		}
 	}
 
+	// Find underscore emphasis __bold__ and _italic_, ignoring snake_case
+	for _, re := range []*regexp.Regexp{mdUnderBoldRe, mdUnderItalicRe} {
+		kind := "bold"
+		if re == mdUnderItalicRe {
+			kind = "italic"
+		}
+		for _, idx := range re.FindAllStringSubmatchIndex(text, -1) {
+			if overlapsInline(matches, idx[0], idx[1]) || isIntrawordUnderscore(text, idx[0], idx[1]) {
+				continue
+			}
+			matches = append(matches, InlineMatch{
+				Start:   idx[0],
+				End:     idx[1],
+				Content: text[idx[2]:idx[3]],
+				Type:    kind,
+			})
+		}
+	}
+
 	// Sort matches by start position
 	for i := 0; i < len(matches)-1; i++ {
 		for j := i + 1; j < len(matches); j++ {
@@ -432,36 +753,72 @@ func ParseInlineFormatting(text string) ([]TextStyle, string) {
 	}
 
 	positionMap[len(text)] = strippedUTF16Len
-	strippedText := stripped.String()
+	strippedText := unmaskMarkdownEscapes(stripped.String(), false)
 
 	// Convert matches to styles with stripped UTF-16 positions
 	styles := make([]TextStyle, 0, len(matches))
 	for _, m := range matches {
 		styles = append(styles, TextStyle{
-			Start:  positionMap[m.Start],
-			End:    positionMap[m.End],
-			Bold:   m.Type == "bold" || m.Type == "bolditalic",
-			Italic: m.Type == "italic" || m.Type == "bolditalic",
-			Code:   m.Type == inlineTypeCode,
-			Link:   m.URL,
+			Start:         positionMap[m.Start],
+			End:           positionMap[m.End],
+			Bold:          m.Type == "bold" || m.Type == "bolditalic",
+			Italic:        m.Type == "italic" || m.Type == "bolditalic",
+			Code:          m.Type == inlineTypeCode,
+			Strikethrough: m.Type == "strikethrough",
+			Link:          unmaskMarkdownEscapes(m.URL, false),
+			Footnote:      unmaskMarkdownEscapes(m.Footnote, false),
 		})
 	}
 
 	return styles, strippedText
 }
 
-// nextRune returns the first rune and its byte size from a string
-func nextRune(s string) (string, int) {
-	for i, r := range s {
-		if i > 0 {
-			return s[:i], i
+// mdEscapeBase is where backslash-escaped ASCII punctuation is parked while
+// inline patterns are matched. One private-use rune per escaped character
+// keeps it out of every pattern and leaves UTF-16 offsets unchanged.
+const mdEscapeBase = 0xE000
+
+func isMarkdownEscapable(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func maskMarkdownEscapes(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && isMarkdownEscapable(text[i+1]) {
+			b.WriteRune(mdEscapeBase + rune(text[i+1]))
+			i++
+			continue
 		}
-		if len(s) == 1 {
-			return s, 1
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// unmaskMarkdownEscapes restores escaped characters. Code spans keep the
+// backslash, since escapes don't apply inside them.
+func unmaskMarkdownEscapes(text string, keepBackslash bool) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r >= mdEscapeBase && r < mdEscapeBase+0x80 && isMarkdownEscapable(byte(r-mdEscapeBase)) {
+			if keepBackslash {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(byte(r - mdEscapeBase))
+			continue
 		}
-		_ = r
+		b.WriteRune(r)
 	}
-	return "", 0
+	return b.String()
+}
+
+// nextRune returns the first rune and its byte size from a string
+func nextRune(s string) (string, int) {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size], size
 }
 
 func parseHeading(line string) (int, string) {
@@ -470,7 +827,12 @@ func parseHeading(line string) (int, string) {
 	if match == nil {
 		return 0, ""
 	}
-	return len(match[1]), match[2]
+	// Drop an optional closing sequence: "## Title ##"
+	content := strings.TrimSpace(match[2])
+	if trimmed := strings.TrimRight(content, "#"); trimmed != content && (trimmed == "" || strings.HasSuffix(trimmed, " ")) {
+		content = strings.TrimSpace(trimmed)
+	}
+	return len(match[1]), content
 }
 
 func isHorizontalRule(line string) bool {
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata golden files")
+
+// TestMarkdownToDocs_Golden converts every testdata/docs_markdown/*.md file
+// and compares the resulting text, requests, tables and footnotes against the
+// matching .golden file. Run with -update to regenerate after an intended
+// change, then review the diff.
+func TestMarkdownToDocs_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "docs_markdown", "*.md"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden inputs found")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".md")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+			got, err := json.MarshalIndent(planMarkdownDocs(ParseMarkdown(string(src)), 1), "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got = append(got, '\n')
+
+			goldenPath := strings.TrimSuffix(input, ".md") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, got, 0o600); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("read golden (run with -update to create): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s does not match %s; run with -update and review the diff:\n%s", input, goldenPath, got)
+			}
+		})
+	}
+}
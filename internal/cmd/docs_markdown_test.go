@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -105,6 +107,24 @@ func TestParseInlineFormatting(t *testing.T) {
 			expectedText:  "Just plain text",
 			expectedCount: 0,
 		},
+		{
+			name:          "backslash escapes",
+			input:         `\*not italic\* \[x\](y) a\\b **2 \* 3**`,
+			expectedText:  `*not italic* [x](y) a\b 2 * 3`,
+			expectedCount: 1,
+		},
+		{
+			name:          "escapes stay literal in code",
+			input:         "`a\\*b`",
+			expectedText:  `a\*b`,
+			expectedCount: 1,
+		},
+		{
+			name:          "trailing multibyte rune",
+			input:         "café",
+			expectedText:  "café",
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -181,3 +201,91 @@ func TestParseMarkdown_TableDoesNotSkipFollowingLine(t *testing.T) {
 		t.Fatalf("second element = %#v, want paragraph 'After table'", got[1])
 	}
 }
+
+func TestParseMarkdown_CommonMarkBlocks(t *testing.T) {
+	input := strings.Join([]string{
+		"Title",
+		"=====",
+		"first line",
+		"second line  ",
+		"after break",
+		"",
+		"1. one",
+		"   - nested *a*",
+		"     continued",
+		"     + deeper",
+		"2. two",
+		"- [x] done",
+		"- [ ] todo",
+		"",
+		"~~~python extra",
+		"print(1)",
+		"~~~",
+		"> quoted",
+		"> still quoted",
+		">",
+		"> > nested",
+		"## Closing ##",
+		"[^n]: note text",
+		"  more note",
+	}, "\n")
+
+	got := ParseMarkdown(input)
+	want := []MarkdownElement{
+		{Type: MDHeading1, Content: "Title"},
+		{Type: MDParagraph, Content: "first line second line\nafter break"},
+		{Type: MDNumberedList, Content: "one"},
+		{Type: MDListItem, Content: "nested *a* continued", Level: 1},
+		{Type: MDListItem, Content: "deeper", Level: 2},
+		{Type: MDNumberedList, Content: "two"},
+		{Type: MDTaskItem, Content: "done", Checked: true},
+		{Type: MDTaskItem, Content: "todo"},
+		{Type: MDCodeBlock, Content: "print(1)", Language: "python"},
+		{Type: MDBlockquote, Content: "quoted still quoted"},
+		{Type: MDBlockquote, Content: "nested", Level: 1},
+		{Type: MDHeading2, Content: "Closing"},
+		{Type: MDFootnote, Content: "note text more note", FootnoteID: "n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMarkdown mismatch:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestParseMarkdown_TableAlignAndEscapes(t *testing.T) {
+	got := ParseMarkdown("| a | b | c |\n| :-- | :-: | --: |\n| x \\| y | 1<br>2 | |")
+	if len(got) != 1 || got[0].Type != MDTable {
+		t.Fatalf("expected one table, got %+v", got)
+	}
+	if !reflect.DeepEqual(got[0].TableAlign, []string{"START", "CENTER", "END"}) {
+		t.Fatalf("align = %q", got[0].TableAlign)
+	}
+	if !reflect.DeepEqual(got[0].TableCells[1], []string{"x | y", "1\n2", ""}) {
+		t.Fatalf("row = %q", got[0].TableCells[1])
+	}
+}
+
+func TestParseInlineFormatting_GFM(t *testing.T) {
+	styles, text := ParseInlineFormatting("~~old~~ __new__ see[^1] <https://x.test> and https://y.test. keep snake_case")
+	if text != "old new see https://x.test and https://y.test. keep snake_case" {
+		t.Fatalf("text = %q", text)
+	}
+	want := []TextStyle{
+		{Strikethrough: true, Start: 0, End: 3},
+		{Bold: true, Start: 4, End: 7},
+		{Footnote: "1", Start: 11, End: 11},
+		{Link: "https://x.test", Start: 12, End: 26},
+		{Link: "https://y.test", Start: 31, End: 45},
+	}
+	if !reflect.DeepEqual(styles, want) {
+		t.Fatalf("styles = %+v", styles)
+	}
+}
+
+func TestMarkdownToHTML_NestedListsAndTasks(t *testing.T) {
+	got := markdownToHTML("- a\n  1. b\n- [x] c\n\nx~~y~~[^1]\n\n[^1]: z")
+	want := "<ul>\n<li>a<ol>\n<li>b</li>\n</ol>\n</li>\n<li>☑ c</li>\n</ul>\n" +
+		"<p>x<s>y</s><sup>1</sup></p>\n<p><sup>1</sup> z</p>\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,775 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+// DocsRenderCmd copies a template doc and fills it from --data, which uses the
+// same shape as slides fill:
+//
+//	{"customer": {"name": "Acme"}}      -> {{customer.name}} in the body, headers, footers and every tab
+//	{"vip": true}                       -> {{#if vip}}…{{/if}} is kept; a falsy value removes the block
+//	{"logo": {"image": "./logo.png"}}   -> the named range "logo" becomes the image
+//	{"items": [{"sku": "A"}, ...]}      -> table rows mentioning {{items.…}} repeat per item
+type DocsRenderCmd struct {
+	TemplateID string `arg:"" name:"templateDocId" help:"Template document ID"`
+	Data       string `name:"data" required:"" help:"Variables JSON file (or inline JSON, @file, - for stdin)"`
+	Title      string `name:"title" required:"" help:"Title of the rendered copy"`
+	Parent     string `name:"parent" help:"Destination folder ID"`
+}
+
+var docsIfMarkerRe = regexp.MustCompile(`\{\{\s*(?:#if\s+([^\s{}]+)|/if)\s*\}\}`)
+
+func (c *DocsRenderCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	templateID := normalizeGoogleID(strings.TrimSpace(c.TemplateID))
+	if templateID == "" {
+		return usage("empty templateDocId")
+	}
+	title := strings.TrimSpace(c.Title)
+	if title == "" {
+		return usage("empty --title")
+	}
+
+	raw, err := readSlidesFillData(c.Data)
+	if err != nil {
+		return err
+	}
+	data, err := parseSlidesFillData(raw)
+	if err != nil {
+		return usagef("invalid --data: %v", err)
+	}
+	for _, key := range sortedKeys(data.Lists) {
+		for _, item := range data.Lists[key] {
+			if len(item.Images) > 0 {
+				return usagef("invalid --data: %s: images are not supported in repeated rows", key)
+			}
+		}
+	}
+	parent := normalizeGoogleID(strings.TrimSpace(c.Parent))
+
+	if dryRunErr := dryRunExit(ctx, flags, "docs.render", map[string]any{
+		"template_id": templateID,
+		"title":       title,
+		"parent":      parent,
+		"text_keys":   sortedKeys(data.Text),
+		"image_keys":  sortedKeys(data.Images),
+		"list_keys":   sortedKeys(data.Lists),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	driveSvc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+	docsSvc, err := newDocsService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	// Upload local images before copying so a bad path doesn't leave a stray doc.
+	imageURLs, uploaded, err := resolveFillImageURLs(ctx, driveSvc, data.imageSources())
+	defer func() { cleanupDriveFileIDsBestEffort(ctx, driveSvc, uploaded) }()
+	if err != nil {
+		return err
+	}
+
+	created, err := copyDriveFile(ctx, driveSvc, copyViaDriveOptions{
+		ExpectedMime: "application/vnd.google-apps.document",
+		KindLabel:    "Google Doc",
+	}, templateID, title, parent)
+	if err != nil {
+		return fmt.Errorf("copy template: %w", err)
+	}
+
+	r := &docsRenderer{
+		svc:         docsSvc,
+		docID:       created.Id,
+		data:        data,
+		imageURLs:   imageURLs,
+		used:        map[string]bool{},
+		occurrences: map[string]int64{},
+	}
+	if err := r.render(ctx); err != nil {
+		// A half-rendered copy is worse than none.
+		deleteDriveFileBestEffort(ctx, driveSvc, created.Id)
+		return fmt.Errorf("render: %w", err)
+	}
+
+	var total int64
+	for _, n := range r.occurrences {
+		total += n
+	}
+	unused := r.unusedKeys()
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			strFile:         created,
+			"replacements":  total,
+			"repeatedRows":  r.repeatedRows,
+			"removedBlocks": r.removedBlocks,
+			"images":        r.images,
+			"unused":        unused,
+		})
+	}
+	u.Out().Printf("id\t%s", created.Id)
+	u.Out().Printf("name\t%s", created.Name)
+	if created.WebViewLink != "" {
+		u.Out().Printf("link\t%s", created.WebViewLink)
+	}
+	u.Out().Printf("replacements\t%d", total)
+	if r.repeatedRows > 0 {
+		u.Out().Printf("repeated_rows\t%d", r.repeatedRows)
+	}
+	if r.removedBlocks > 0 {
+		u.Out().Printf("removed_blocks\t%d", r.removedBlocks)
+	}
+	if r.images > 0 {
+		u.Out().Printf("images\t%d", r.images)
+	}
+	for _, key := range unused {
+		if _, ok := data.Images[key]; ok {
+			u.Err().Printf("warning: named range %q not found in template", key)
+			continue
+		}
+		u.Err().Printf("warning: {{%s}} not found in template", key)
+	}
+	return nil
+}
+
+// docsRenderer fills a copied template in passes: conditional blocks, repeated
+// table rows, then images and text. Each index-based pass works from a fresh
+// read of the document.
+type docsRenderer struct {
+	svc       *docs.Service
+	docID     string
+	data      slidesFillData
+	imageURLs map[string]string
+
+	used          map[string]bool
+	occurrences   map[string]int64
+	removedBlocks int
+	repeatedRows  int
+	images        int
+}
+
+func (r *docsRenderer) render(ctx context.Context) error {
+	doc, err := r.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reqs []*docs.Request
+	for _, seg := range docsRenderSegments(doc) {
+		segReqs, segErr := r.conditionalRequests(seg)
+		if segErr != nil {
+			return segErr
+		}
+		reqs = append(reqs, segReqs...)
+	}
+	if len(reqs) > 0 {
+		if _, err := r.batch(ctx, reqs); err != nil {
+			return fmt.Errorf("remove conditional blocks: %w", err)
+		}
+		if doc, err = r.fetch(ctx); err != nil {
+			return err
+		}
+	}
+
+	rows, err := findDocsRepeatRows(doc, r.data.Lists)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		if _, err := r.batch(ctx, insertDocsRepeatRowRequests(rows)); err != nil {
+			return fmt.Errorf("insert table rows: %w", err)
+		}
+		if doc, err = r.fetch(ctx); err != nil {
+			return err
+		}
+		fill, err := r.fillRepeatRowRequests(doc, rows)
+		if err != nil {
+			return err
+		}
+		if _, err := r.batch(ctx, fill); err != nil {
+			return fmt.Errorf("fill table rows: %w", err)
+		}
+		if len(r.data.Images) > 0 {
+			if doc, err = r.fetch(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	reqs = r.imageRequests(doc)
+	keys := make([]string, len(reqs))
+	for _, key := range sortedKeys(r.data.Text) {
+		reqs = append(reqs, &docs.Request{ReplaceAllText: &docs.ReplaceAllTextRequest{
+			ContainsText: &docs.SubstringMatchCriteria{Text: "{{" + key + "}}", MatchCase: true},
+			ReplaceText:  r.data.Text[key],
+		}})
+		keys = append(keys, key)
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	resp, err := r.batch(ctx, reqs)
+	if err != nil {
+		return fmt.Errorf("replace placeholders: %w", err)
+	}
+	for i, reply := range resp.Replies {
+		if i < len(keys) && keys[i] != "" && reply != nil && reply.ReplaceAllText != nil {
+			r.occurrences[keys[i]] += reply.ReplaceAllText.OccurrencesChanged
+		}
+	}
+	return nil
+}
+
+func (r *docsRenderer) fetch(ctx context.Context) (*docs.Document, error) {
+	doc, err := r.svc.Documents.Get(r.docID).
+		IncludeTabsContent(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("doc not found")
+	}
+	return doc, nil
+}
+
+func (r *docsRenderer) batch(ctx context.Context, reqs []*docs.Request) (*docs.BatchUpdateDocumentResponse, error) {
+	return r.svc.Documents.BatchUpdate(r.docID, &docs.BatchUpdateDocumentRequest{
+		Requests: reqs,
+	}).Context(ctx).Do()
+}
+
+// unusedKeys lists data keys the template never referenced, in key order.
+func (r *docsRenderer) unusedKeys() []string {
+	unused := make([]string, 0)
+	for _, key := range sortedKeys(r.data.Text) {
+		if r.occurrences[key] == 0 && !r.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	for _, key := range sortedKeys(r.data.Images) {
+		if !r.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	for _, key := range sortedKeys(r.data.Lists) {
+		if !r.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// docsRenderSegment is one independently indexed piece of a document: a tab
+// body, header, footer or footnote.
+type docsRenderSegment struct {
+	TabID     string
+	SegmentID string
+	Content   []*docs.StructuralElement
+}
+
+// docsRenderSegments lists every segment of every tab. Responses without tab
+// content fall back to the top-level body, headers, footers and footnotes.
+func docsRenderSegments(doc *docs.Document) []docsRenderSegment {
+	tabs := flattenTabs(doc.Tabs)
+	if len(tabs) == 0 {
+		tabs = []*docs.Tab{{DocumentTab: &docs.DocumentTab{
+			Body:      doc.Body,
+			Headers:   doc.Headers,
+			Footers:   doc.Footers,
+			Footnotes: doc.Footnotes,
+		}}}
+	}
+
+	var segs []docsRenderSegment
+	for _, tab := range tabs {
+		dt := tab.DocumentTab
+		if dt == nil {
+			continue
+		}
+		tabID := ""
+		if tab.TabProperties != nil {
+			tabID = tab.TabProperties.TabId
+		}
+		if dt.Body != nil {
+			segs = append(segs, docsRenderSegment{TabID: tabID, Content: dt.Body.Content})
+		}
+		for _, id := range sortedKeys(dt.Headers) {
+			segs = append(segs, docsRenderSegment{TabID: tabID, SegmentID: id, Content: dt.Headers[id].Content})
+		}
+		for _, id := range sortedKeys(dt.Footers) {
+			segs = append(segs, docsRenderSegment{TabID: tabID, SegmentID: id, Content: dt.Footers[id].Content})
+		}
+		for _, id := range sortedKeys(dt.Footnotes) {
+			segs = append(segs, docsRenderSegment{TabID: tabID, SegmentID: id, Content: dt.Footnotes[id].Content})
+		}
+	}
+	return segs
+}
+
+// docsSegmentText is the concatenated text of a segment with enough position
+// data to map byte offsets back to document indices.
+type docsSegmentText struct {
+	text strings.Builder
+	runs []docsTextRunPos
+	// fixed marks paragraph ends that cannot be deleted: the last paragraph of
+	// a body or cell, and paragraphs directly before a table.
+	fixed map[int]bool
+}
+
+type docsTextRunPos struct {
+	offset int
+	start  int64
+	text   string
+}
+
+func newDocsSegmentText(content []*docs.StructuralElement) *docsSegmentText {
+	t := &docsSegmentText{fixed: map[int]bool{}}
+	t.walk(content)
+	return t
+}
+
+func (t *docsSegmentText) walk(content []*docs.StructuralElement) {
+	for i, el := range content {
+		if el == nil {
+			continue
+		}
+		if el.Paragraph != nil {
+			for _, pe := range el.Paragraph.Elements {
+				if pe == nil || pe.TextRun == nil || pe.TextRun.Content == "" {
+					continue
+				}
+				t.runs = append(t.runs, docsTextRunPos{offset: t.text.Len(), start: pe.StartIndex, text: pe.TextRun.Content})
+				t.text.WriteString(pe.TextRun.Content)
+			}
+			last := i == len(content)-1 || content[i+1] == nil || content[i+1].Paragraph == nil
+			if last && strings.HasSuffix(t.text.String(), "\n") {
+				t.fixed[t.text.Len()-1] = true
+			}
+		}
+		if el.Table != nil {
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					t.walk(cell.Content)
+				}
+			}
+		}
+	}
+}
+
+// index maps a byte offset to a document index. Offsets on a run boundary
+// resolve to the start of the next run, or with end set, the end of the
+// previous one.
+func (t *docsSegmentText) index(offset int, end bool) int64 {
+	for i, run := range t.runs {
+		runEnd := run.offset + len(run.text)
+		if offset < run.offset || offset > runEnd || (offset == runEnd && !end && i < len(t.runs)-1) {
+			continue
+		}
+		return run.start + utf16Len(run.text[:offset-run.offset])
+	}
+	return 0
+}
+
+// lineRange widens [start, end) to take the following newline when the range
+// covers a whole paragraph, so removed markers don't leave blank lines.
+func (t *docsSegmentText) lineRange(start, end int) (int, int) {
+	text := t.text.String()
+	atLineStart := start == 0 || text[start-1] == '\n'
+	if atLineStart && end < len(text) && text[end] == '\n' && !t.fixed[end] {
+		return start, end + 1
+	}
+	return start, end
+}
+
+type docsIfBlock struct {
+	key      string
+	open     [2]int
+	close    [2]int
+	children []*docsIfBlock
+	parent   *docsIfBlock
+}
+
+// conditionalRequests deletes false {{#if key}}…{{/if}} blocks and the
+// markers of true ones, from the end of the segment backwards.
+func (r *docsRenderer) conditionalRequests(seg docsRenderSegment) ([]*docs.Request, error) {
+	st := newDocsSegmentText(seg.Content)
+	text := st.text.String()
+	matches := docsIfMarkerRe.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	root := &docsIfBlock{}
+	cur := root
+	for _, m := range matches {
+		if m[2] >= 0 {
+			block := &docsIfBlock{key: text[m[2]:m[3]], open: [2]int{m[0], m[1]}, parent: cur}
+			cur.children = append(cur.children, block)
+			cur = block
+			continue
+		}
+		if cur == root {
+			return nil, fmt.Errorf("template has {{/if}} without a matching {{#if}}")
+		}
+		cur.close = [2]int{m[0], m[1]}
+		cur = cur.parent
+	}
+	if cur != root {
+		return nil, fmt.Errorf("template has {{#if %s}} without a closing {{/if}}", cur.key)
+	}
+
+	var ranges [][2]int
+	var visit func(blocks []*docsIfBlock)
+	visit = func(blocks []*docsIfBlock) {
+		for _, b := range blocks {
+			r.used[b.key] = true
+			if !docsRenderTruthy(r.data, b.key) {
+				start, end := st.lineRange(b.open[0], b.close[1])
+				ranges = append(ranges, [2]int{start, end})
+				r.removedBlocks++
+				continue
+			}
+			start, end := st.lineRange(b.open[0], b.open[1])
+			ranges = append(ranges, [2]int{start, end})
+			visit(b.children)
+			start, end = st.lineRange(b.close[0], b.close[1])
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	visit(root.children)
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] > ranges[j][0] })
+	reqs := make([]*docs.Request, 0, len(ranges))
+	for _, rng := range ranges {
+		reqs = append(reqs, &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{
+			Range: &docs.Range{
+				StartIndex: st.index(rng[0], false),
+				EndIndex:   st.index(rng[1], true),
+				SegmentId:  seg.SegmentID,
+				TabId:      seg.TabID,
+			},
+		}})
+	}
+	return reqs, nil
+}
+
+// docsRenderTruthy reports whether {{#if key}} keeps its block: non-empty
+// text other than "false" or "0", an image, a non-empty list, or an object
+// with at least one field.
+func docsRenderTruthy(data slidesFillData, key string) bool {
+	if v, ok := data.Text[key]; ok {
+		return v != "" && v != "false" && v != "0"
+	}
+	if _, ok := data.Images[key]; ok {
+		return true
+	}
+	if items, ok := data.Lists[key]; ok {
+		return len(items) > 0
+	}
+	prefix := key + "."
+	for k := range data.Text {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	for k := range data.Images {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// docsRepeatRow is a table row whose placeholders name a list key. Row is the
+// template row's index in the template; Shift counts the rows inserted above
+// it by other repeat rows in the same table.
+type docsRepeatRow struct {
+	TabID      string
+	SegmentID  string
+	TableStart int64
+	Row        int
+	Key        string
+	Count      int
+	Shift      int
+}
+
+func findDocsRepeatRows(doc *docs.Document, lists map[string][]slidesFillItem) ([]*docsRepeatRow, error) {
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	var rows []*docsRepeatRow
+	var walk func(seg docsRenderSegment, content []*docs.StructuralElement) error
+	walk = func(seg docsRenderSegment, content []*docs.StructuralElement) error {
+		for _, el := range content {
+			if el == nil || el.Table == nil {
+				continue
+			}
+			for ri, row := range el.Table.TableRows {
+				text := docsRowText(row)
+				key := ""
+				for _, k := range sortedKeys(lists) {
+					if strings.Contains(text, "{{"+k+"}}") || strings.Contains(text, "{{"+k+".") {
+						if key != "" {
+							return usagef("table row %d repeats over both %q and %q", ri+1, key, k)
+						}
+						key = k
+					}
+				}
+				if key != "" {
+					rows = append(rows, &docsRepeatRow{
+						TabID:      seg.TabID,
+						SegmentID:  seg.SegmentID,
+						TableStart: el.StartIndex,
+						Row:        ri,
+						Key:        key,
+						Count:      len(lists[key]),
+					})
+					continue
+				}
+				for _, cell := range row.TableCells {
+					if err := walk(seg, cell.Content); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	for _, seg := range docsRenderSegments(doc) {
+		if err := walk(seg, seg.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	// Later tables and lower rows first, so earlier positions stay valid.
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].TableStart != rows[j].TableStart {
+			return rows[i].TableStart > rows[j].TableStart
+		}
+		return rows[i].Row > rows[j].Row
+	})
+	return rows, nil
+}
+
+func docsRowText(row *docs.TableRow) string {
+	var sb strings.Builder
+	for _, cell := range row.TableCells {
+		for _, el := range cell.Content {
+			if el != nil && el.Paragraph != nil {
+				sb.WriteString(docsParagraphText(el.Paragraph))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// insertDocsRepeatRowRequests adds Count empty rows below each template row
+// and sets each row's Shift.
+func insertDocsRepeatRowRequests(rows []*docsRepeatRow) []*docs.Request {
+	var reqs []*docs.Request
+	for _, row := range rows {
+		for i := 0; i < row.Count; i++ {
+			reqs = append(reqs, &docs.Request{InsertTableRow: &docs.InsertTableRowRequest{
+				TableCellLocation: docsRepeatCellLocation(row, row.Row),
+				InsertBelow:       true,
+			}})
+		}
+	}
+	// Rows inserted above a template row in the same table push it down.
+	for _, row := range rows {
+		for _, other := range rows {
+			if other != row && other.TableStart == row.TableStart && other.TabID == row.TabID &&
+				other.SegmentID == row.SegmentID && other.Row < row.Row {
+				row.Shift += other.Count
+			}
+		}
+	}
+	return reqs
+}
+
+func docsRepeatCellLocation(row *docsRepeatRow, index int) *docs.TableCellLocation {
+	return &docs.TableCellLocation{
+		TableStartLocation: &docs.Location{Index: row.TableStart, SegmentId: row.SegmentID, TabId: row.TabID},
+		RowIndex:           int64(index),
+	}
+}
+
+// fillRepeatRowRequests copies each template cell's text, with the item's
+// values substituted and the original run styles, into the rows inserted
+// below it, then deletes the template row.
+func (r *docsRenderer) fillRepeatRowRequests(doc *docs.Document, rows []*docsRepeatRow) ([]*docs.Request, error) {
+	tables := map[string]*docs.Table{}
+	var walk func(seg docsRenderSegment, content []*docs.StructuralElement)
+	walk = func(seg docsRenderSegment, content []*docs.StructuralElement) {
+		for _, el := range content {
+			if el == nil || el.Table == nil {
+				continue
+			}
+			tables[fmt.Sprintf("%s/%s/%d", seg.TabID, seg.SegmentID, el.StartIndex)] = el.Table
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					walk(seg, cell.Content)
+				}
+			}
+		}
+	}
+	for _, seg := range docsRenderSegments(doc) {
+		walk(seg, seg.Content)
+	}
+
+	var reqs []*docs.Request
+	for _, row := range rows {
+		table := tables[fmt.Sprintf("%s/%s/%d", row.TabID, row.SegmentID, row.TableStart)]
+		tmplIndex := row.Row + row.Shift
+		if table == nil || tmplIndex+row.Count >= len(table.TableRows) {
+			return nil, fmt.Errorf("table at index %d changed while rendering", row.TableStart)
+		}
+		tmpl := table.TableRows[tmplIndex]
+		items := r.data.Lists[row.Key]
+		for i := row.Count - 1; i >= 0; i-- {
+			target := table.TableRows[tmplIndex+1+i]
+			pairs := make([]string, 0, len(items[i].Text)*2)
+			for _, k := range sortedKeys(items[i].Text) {
+				pairs = append(pairs, "{{"+k+"}}", items[i].Text[k])
+			}
+			replacer := strings.NewReplacer(pairs...)
+			for c := len(tmpl.TableCells) - 1; c >= 0; c-- {
+				if c >= len(target.TableCells) {
+					continue
+				}
+				reqs = append(reqs, docsFillCellRequests(tmpl.TableCells[c], target.TableCells[c], replacer, row)...)
+			}
+		}
+		reqs = append(reqs, &docs.Request{DeleteTableRow: &docs.DeleteTableRowRequest{
+			TableCellLocation: docsRepeatCellLocation(row, tmplIndex),
+		}})
+		r.used[row.Key] = true
+		r.repeatedRows += row.Count
+	}
+	return reqs, nil
+}
+
+func docsFillCellRequests(tmpl, target *docs.TableCell, replacer *strings.Replacer, row *docsRepeatRow) []*docs.Request {
+	if len(target.Content) == 0 {
+		return nil
+	}
+	type piece struct {
+		text  string
+		style *docs.TextStyle
+	}
+	var pieces []piece
+	for _, el := range tmpl.Content {
+		if el == nil || el.Paragraph == nil {
+			continue
+		}
+		for _, pe := range el.Paragraph.Elements {
+			if pe != nil && pe.TextRun != nil {
+				pieces = append(pieces, piece{text: replacer.Replace(pe.TextRun.Content), style: pe.TextRun.TextStyle})
+			}
+		}
+	}
+	// The new cell already ends with its own paragraph break.
+	if n := len(pieces); n > 0 {
+		pieces[n-1].text = strings.TrimSuffix(pieces[n-1].text, "\n")
+	}
+
+	var text strings.Builder
+	for _, p := range pieces {
+		text.WriteString(p.text)
+	}
+	if text.Len() == 0 {
+		return nil
+	}
+
+	at := target.Content[0].StartIndex
+	reqs := []*docs.Request{{InsertText: &docs.InsertTextRequest{
+		Location: &docs.Location{Index: at, SegmentId: row.SegmentID, TabId: row.TabID},
+		Text:     text.String(),
+	}}}
+	offset := at
+	for _, p := range pieces {
+		n := utf16Len(p.text)
+		if n > 0 && p.style != nil {
+			reqs = append(reqs, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+				Range:     &docs.Range{StartIndex: offset, EndIndex: offset + n, SegmentId: row.SegmentID, TabId: row.TabID},
+				TextStyle: p.style,
+				Fields:    "*",
+			}})
+		}
+		offset += n
+	}
+	return reqs
+}
+
+// imageRequests replaces the content of each named range whose name is an
+// image key with that image, from the end of the document backwards.
+func (r *docsRenderer) imageRequests(doc *docs.Document) []*docs.Request {
+	if len(r.data.Images) == 0 {
+		return nil
+	}
+	var places []docsImagePlacement
+	collect := func(tabID string, named map[string]docs.NamedRanges) {
+		for _, key := range sortedKeys(r.data.Images) {
+			group, ok := named[key]
+			if !ok {
+				continue
+			}
+			for _, nr := range group.NamedRanges {
+				if nr == nil {
+					continue
+				}
+				for _, rng := range nr.Ranges {
+					if rng == nil {
+						continue
+					}
+					if rng.TabId == "" {
+						rng.TabId = tabID
+					}
+					places = append(places, docsImagePlacement{rng: rng, uri: r.imageURLs[r.data.Images[key]]})
+					r.used[key] = true
+					r.images++
+				}
+			}
+		}
+	}
+	tabs := flattenTabs(doc.Tabs)
+	if len(tabs) == 0 {
+		collect("", doc.NamedRanges)
+	}
+	for _, tab := range tabs {
+		if tab.DocumentTab == nil {
+			continue
+		}
+		tabID := ""
+		if tab.TabProperties != nil {
+			tabID = tab.TabProperties.TabId
+		}
+		collect(tabID, tab.DocumentTab.NamedRanges)
+	}
+
+	return docsImageRequests(places)
+}
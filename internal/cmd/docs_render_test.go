@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/option"
+)
+
+func docsRenderPara(start int64, runs ...map[string]any) map[string]any {
+	para := map[string]any{"startIndex": start}
+	elements := make([]any, 0, len(runs))
+	for _, run := range runs {
+		elements = append(elements, map[string]any{"startIndex": start, "textRun": run})
+		start += utf16Len(run["content"].(string))
+	}
+	para["paragraph"] = map[string]any{"elements": elements}
+	return para
+}
+
+func TestDocsRenderConditionalRequests(t *testing.T) {
+	text := func(s string) map[string]any { return map[string]any{"content": s} }
+	var content []*docs.StructuralElement
+	raw, _ := json.Marshal([]any{
+		docsRenderPara(1, text("Hi {{name}}\n")),
+		docsRenderPara(13, text("{{#if vip}}\n")),
+		docsRenderPara(25, text("VIP perks\n")),
+		docsRenderPara(35, text("{{/if}}\n")),
+		docsRenderPara(43, text("{{#if trial}}Trial{{/if}}"), text(" end\n")),
+		docsRenderPara(73, text("bye\n")),
+	})
+	if err := json.Unmarshal(raw, &content); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	data, err := parseSlidesFillData([]byte(`{"vip": true, "trial": false}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	r := &docsRenderer{data: data, used: map[string]bool{}}
+	reqs, err := r.conditionalRequests(docsRenderSegment{TabID: "t.0", Content: content})
+	if err != nil {
+		t.Fatalf("conditionalRequests: %v", err)
+	}
+	want := [][2]int64{{43, 68}, {35, 43}, {13, 25}}
+	if len(reqs) != len(want) {
+		t.Fatalf("expected %d deletes, got %d", len(want), len(reqs))
+	}
+	for i, w := range want {
+		rng := reqs[i].DeleteContentRange.Range
+		if rng.StartIndex != w[0] || rng.EndIndex != w[1] || rng.TabId != "t.0" {
+			t.Fatalf("delete %d: got [%d,%d) tab %q, want %v", i, rng.StartIndex, rng.EndIndex, rng.TabId, w)
+		}
+	}
+	if r.removedBlocks != 1 || !r.used["vip"] || !r.used["trial"] {
+		t.Fatalf("unexpected bookkeeping: removed=%d used=%v", r.removedBlocks, r.used)
+	}
+
+	if _, err := r.conditionalRequests(docsRenderSegment{Content: content[:2]}); err == nil || !strings.Contains(err.Error(), "closing {{/if}}") {
+		t.Fatalf("expected unclosed block error, got %v", err)
+	}
+}
+
+func TestDocsRenderTruthy(t *testing.T) {
+	data, err := parseSlidesFillData([]byte(`{"a": "x", "b": "", "c": 0, "d": [], "e": [1], "f": {"g": "y"}, "h": null}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": false, "d": false, "e": true, "f": true, "h": false, "missing": false} {
+		if got := docsRenderTruthy(data, key); got != want {
+			t.Errorf("truthy(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestDocsRender_RepeatsTableRows(t *testing.T) {
+	cell := func(start int64, runs ...map[string]any) map[string]any {
+		return map[string]any{"content": []any{docsRenderPara(start, runs...)}}
+	}
+	row := func(cells ...any) map[string]any { return map[string]any{"tableCells": cells} }
+	bold := map[string]any{"content": "{{items.sku}}\n", "textStyle": map[string]any{"bold": true}}
+	empty := map[string]any{"content": "\n"}
+	header := map[string]any{"content": "SKU\n"}
+	tabDoc := func(rows ...any) map[string]any {
+		return map[string]any{
+			"documentId": "copy1",
+			"tabs": []any{map[string]any{
+				"tabProperties": map[string]any{"tabId": "t.0"},
+				"documentTab": map[string]any{"body": map[string]any{"content": []any{
+					map[string]any{"startIndex": 1, "table": map[string]any{"tableRows": rows}},
+				}}},
+			}},
+		}
+	}
+	docs1 := tabDoc(row(cell(3, header)), row(cell(9, bold)))
+	docs2 := tabDoc(row(cell(3, header)), row(cell(9, bold)), row(cell(25, empty)), row(cell(28, empty)))
+
+	fetches := 0
+	var batches []docs.BatchUpdateDocumentRequest
+	svc, cleanup := newDocsServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/documents/"):
+			fetches++
+			if fetches == 1 {
+				_ = json.NewEncoder(w).Encode(docs1)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(docs2)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":batchUpdate"):
+			var req docs.BatchUpdateDocumentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode batchUpdate: %v", err)
+			}
+			batches = append(batches, req)
+			replies := make([]any, len(req.Requests))
+			for i, rq := range req.Requests {
+				replies[i] = map[string]any{}
+				if rq.ReplaceAllText != nil {
+					replies[i] = map[string]any{"replaceAllText": map[string]any{"occurrencesChanged": 2}}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"documentId": "copy1", "replies": replies})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	data, err := parseSlidesFillData([]byte(`{"items": [{"sku": "A"}, {"sku": "B"}], "total": "9"}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	r := &docsRenderer{svc: svc, docID: "copy1", data: data, used: map[string]bool{}, occurrences: map[string]int64{}}
+	if err := r.render(context.Background()); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("expected 3 batch updates, got %d", len(batches))
+	}
+	inserts := batches[0].Requests
+	if len(inserts) != 2 || inserts[0].InsertTableRow == nil || !inserts[0].InsertTableRow.InsertBelow ||
+		inserts[0].InsertTableRow.TableCellLocation.RowIndex != 1 || inserts[0].InsertTableRow.TableCellLocation.TableStartLocation.TabId != "t.0" {
+		t.Fatalf("unexpected row inserts: %#v", inserts)
+	}
+
+	fill := batches[1].Requests
+	if len(fill) != 5 {
+		t.Fatalf("expected 5 fill requests, got %d", len(fill))
+	}
+	if ins := fill[0].InsertText; ins == nil || ins.Location.Index != 28 || ins.Text != "B" {
+		t.Fatalf("unexpected last-row insert: %#v", fill[0])
+	}
+	if st := fill[1].UpdateTextStyle; st == nil || !st.TextStyle.Bold || st.Range.StartIndex != 28 || st.Range.EndIndex != 29 {
+		t.Fatalf("expected copied bold style, got %#v", fill[1])
+	}
+	if ins := fill[2].InsertText; ins == nil || ins.Location.Index != 25 || ins.Text != "A" {
+		t.Fatalf("unexpected first-row insert: %#v", fill[2])
+	}
+	if del := fill[4].DeleteTableRow; del == nil || del.TableCellLocation.RowIndex != 1 {
+		t.Fatalf("expected template row delete, got %#v", fill[4])
+	}
+
+	replace := batches[2].Requests
+	if len(replace) != 1 || replace[0].ReplaceAllText.ContainsText.Text != "{{total}}" || replace[0].ReplaceAllText.ReplaceText != "9" {
+		t.Fatalf("unexpected replacements: %#v", replace)
+	}
+	if r.repeatedRows != 2 || r.occurrences["total"] != 2 {
+		t.Fatalf("unexpected counts: rows=%d occurrences=%v", r.repeatedRows, r.occurrences)
+	}
+	if unused := r.unusedKeys(); len(unused) != 0 {
+		t.Fatalf("unexpected unused keys: %v", unused)
+	}
+}
+
+func TestDocsRenderImageRequests_NamedRanges(t *testing.T) {
+	doc := &docs.Document{Tabs: []*docs.Tab{{
+		TabProperties: &docs.TabProperties{TabId: "t.1"},
+		DocumentTab: &docs.DocumentTab{NamedRanges: map[string]docs.NamedRanges{
+			"logo": {NamedRanges: []*docs.NamedRange{{Ranges: []*docs.Range{
+				{StartIndex: 5, EndIndex: 9},
+				{StartIndex: 2, EndIndex: 2, SegmentId: "kix.h1"},
+			}}}},
+		}},
+	}}}
+	data, err := parseSlidesFillData([]byte(`{"logo": {"image": "https://x.test/logo.png"}, "chart": {"image": "https://x.test/c.png"}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	r := &docsRenderer{data: data, imageURLs: map[string]string{"https://x.test/logo.png": "https://x.test/logo.png"}, used: map[string]bool{}}
+
+	reqs := r.imageRequests(doc)
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(reqs))
+	}
+	if del := reqs[0].DeleteContentRange; del == nil || del.Range.StartIndex != 5 || del.Range.EndIndex != 9 || del.Range.TabId != "t.1" {
+		t.Fatalf("unexpected delete: %#v", reqs[0])
+	}
+	if img := reqs[1].InsertInlineImage; img == nil || img.Location.Index != 5 || img.Uri != "https://x.test/logo.png" {
+		t.Fatalf("unexpected image: %#v", reqs[1])
+	}
+	if img := reqs[2].InsertInlineImage; img == nil || img.Location.SegmentId != "kix.h1" || img.Location.Index != 2 {
+		t.Fatalf("expected empty header range to insert only, got %#v", reqs[2])
+	}
+	if r.images != 2 {
+		t.Fatalf("images = %d", r.images)
+	}
+	if unused := r.unusedKeys(); len(unused) != 1 || unused[0] != "chart" {
+		t.Fatalf("unused = %v", unused)
+	}
+}
+
+func TestDocsRender_DeletesCopyWhenRenderFails(t *testing.T) {
+	origDocs := newDocsService
+	t.Cleanup(func() { newDocsService = origDocs })
+	deleted := useTemplateCopyDriveTest(t, "application/vnd.google-apps.document")
+
+	docsSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 500, "message": "backend"}})
+	}))
+	defer docsSrv.Close()
+	docsSvc, err := docs.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(docsSrv.Client()), option.WithEndpoint(docsSrv.URL+"/"))
+	if err != nil {
+		t.Fatalf("docs.NewService: %v", err)
+	}
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return docsSvc, nil }
+
+	_ = captureStderr(t, func() {
+		if err := Execute([]string{"--json", "--retries", "0", "--account", "a@b.com", "docs", "render", "tmpl1", "--data", `{"name":"Acme"}`, "--title", "Acme"}); err == nil {
+			t.Fatalf("expected render to fail")
+		}
+	})
+	if !*deleted {
+		t.Fatalf("expected the half-rendered copy to be deleted")
+	}
+}
@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type DocsSectionCmd struct {
+	Get     DocsSectionGetCmd     `cmd:"" name:"get" aliases:"show,cat" help:"Print the body of a section"`
+	Replace DocsSectionReplaceCmd `cmd:"" name:"replace" aliases:"set" help:"Replace the body of a section"`
+	Append  DocsSectionAppendCmd  `cmd:"" name:"append" help:"Append content to the end of a section"`
+	Delete  DocsSectionDeleteCmd  `cmd:"" name:"delete" aliases:"rm" help:"Delete a section (heading and body)"`
+}
+
+// DocsSectionTarget picks a section by its heading text.
+type DocsSectionTarget struct {
+	DocID   string `arg:"" name:"docId" help:"Doc ID"`
+	Heading string `name:"heading" required:"" help:"Heading text (case-insensitive, exact match)"`
+	Level   int    `name:"level" help:"Only match headings of this level (1-6)"`
+	Nth     int    `name:"nth" help:"Use the Nth matching heading" default:"1"`
+	Tab     string `name:"tab" help:"Tab title or ID (default: first tab)"`
+}
+
+// DocsSectionInput is the content shared by replace and append.
+type DocsSectionInput struct {
+	Content  string `arg:"" optional:"" name:"content" help:"Content to write (or use --file / stdin)"`
+	File     string `name:"file" short:"f" help:"Read content from file (use - for stdin)"`
+	Format   string `name:"format" help:"Content format: plain|markdown" enum:"plain,markdown" default:"plain"`
+	Markdown bool   `name:"markdown" help:"Shorthand for --format markdown"`
+}
+
+// docsSection is a heading and the body that follows it, up to the next
+// heading of the same or a higher level (or the end of the tab).
+type docsSection struct {
+	TabID      string
+	Heading    string
+	Level      int
+	StartIndex int64 // start of the heading paragraph
+	BodyStart  int64 // first index after the heading paragraph
+	EndIndex   int64 // start of the next section, or the end of the body
+	BodyEnd    int64 // end index of the whole tab body
+	Content    []*docs.StructuralElement
+}
+
+func (s DocsSectionTarget) validate() (string, error) {
+	id := normalizeGoogleID(strings.TrimSpace(s.DocID))
+	if id == "" {
+		return "", usage("empty docId")
+	}
+	if strings.TrimSpace(s.Heading) == "" {
+		return "", usage("empty --heading")
+	}
+	if s.Level < 0 || s.Level > 6 {
+		return "", usage("--level must be between 1 and 6")
+	}
+	if s.Nth < 1 {
+		return "", usage("--nth must be >= 1")
+	}
+	return id, nil
+}
+
+func (s DocsSectionTarget) dryRunParams(id string) map[string]any {
+	return map[string]any{
+		"id":      id,
+		"heading": strings.TrimSpace(s.Heading),
+		"level":   s.Level,
+		"nth":     s.Nth,
+		"tab":     strings.TrimSpace(s.Tab),
+	}
+}
+
+// load fetches the document and locates the selected section.
+func (s DocsSectionTarget) load(ctx context.Context, svc *docs.Service, id string) (*docs.DocumentTab, *docsSection, error) {
+	doc, err := svc.Documents.Get(id).
+		IncludeTabsContent(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		if isDocsNotFound(err) {
+			return nil, nil, fmt.Errorf("doc not found or not a Google Doc (id=%s)", id)
+		}
+		return nil, nil, err
+	}
+	if doc == nil {
+		return nil, nil, errors.New("doc not found")
+	}
+
+	var tab *docs.DocumentTab
+	tabID := ""
+	tabs := flattenTabs(doc.Tabs)
+	switch {
+	case strings.TrimSpace(s.Tab) != "":
+		found := findTab(tabs, s.Tab)
+		if found == nil {
+			return nil, nil, fmt.Errorf("tab not found: %s", s.Tab)
+		}
+		tab = found.DocumentTab
+		if found.TabProperties != nil {
+			tabID = found.TabProperties.TabId
+		}
+	case len(tabs) > 0:
+		tab = tabs[0].DocumentTab
+	default:
+		// Responses without tab content carry the body at the top level.
+		tab = &docs.DocumentTab{Body: doc.Body, Lists: doc.Lists, Footnotes: doc.Footnotes, InlineObjects: doc.InlineObjects}
+	}
+	if tab == nil || tab.Body == nil {
+		return nil, nil, errors.New("document has no body")
+	}
+
+	section, err := findDocsSection(tab.Body.Content, s.Heading, s.Level, s.Nth)
+	if err != nil {
+		return nil, nil, err
+	}
+	section.TabID = tabID
+	return tab, section, nil
+}
+
+// findDocsSection finds the nth heading whose text matches heading
+// (case-insensitive) and, when level > 0, whose level matches.
+func findDocsSection(content []*docs.StructuralElement, heading string, level, nth int) (*docsSection, error) {
+	want := strings.ToLower(strings.TrimSpace(heading))
+	var bodyEnd int64
+	if len(content) > 0 && content[len(content)-1] != nil {
+		bodyEnd = content[len(content)-1].EndIndex
+	}
+
+	matches := 0
+	for i, el := range content {
+		headingLevel := docsParagraphHeadingLevel(el)
+		if headingLevel == 0 || (level > 0 && headingLevel != level) {
+			continue
+		}
+		text := strings.TrimSpace(docsParagraphText(el.Paragraph))
+		if strings.ToLower(text) != want {
+			continue
+		}
+		matches++
+		if matches < nth {
+			continue
+		}
+
+		section := &docsSection{
+			Heading:    text,
+			Level:      headingLevel,
+			StartIndex: el.StartIndex,
+			BodyStart:  el.EndIndex,
+			EndIndex:   bodyEnd,
+			BodyEnd:    bodyEnd,
+		}
+		for _, next := range content[i+1:] {
+			if nextLevel := docsParagraphHeadingLevel(next); nextLevel > 0 && nextLevel <= headingLevel {
+				section.EndIndex = next.StartIndex
+				break
+			}
+			section.Content = append(section.Content, next)
+		}
+		return section, nil
+	}
+
+	if matches > 0 {
+		return nil, fmt.Errorf("heading %q matched %d time(s), fewer than --nth %d", heading, matches, nth)
+	}
+	return nil, fmt.Errorf("heading not found: %q", heading)
+}
+
+// docsParagraphHeadingLevel returns 1-6 for HEADING_1..HEADING_6 paragraphs
+// and 0 for anything else.
+func docsParagraphHeadingLevel(el *docs.StructuralElement) int {
+	if el == nil || el.Paragraph == nil || el.Paragraph.ParagraphStyle == nil {
+		return 0
+	}
+	n, ok := strings.CutPrefix(el.Paragraph.ParagraphStyle.NamedStyleType, "HEADING_")
+	if !ok {
+		return 0
+	}
+	level, err := strconv.Atoi(n)
+	if err != nil || level < 1 || level > 6 {
+		return 0
+	}
+	return level
+}
+
+func docsParagraphText(p *docs.Paragraph) string {
+	var sb strings.Builder
+	for _, pe := range p.Elements {
+		if pe != nil && pe.TextRun != nil {
+			sb.WriteString(pe.TextRun.Content)
+		}
+	}
+	return sb.String()
+}
+
+type DocsSectionGetCmd struct {
+	Target DocsSectionTarget `embed:""`
+	Format string            `name:"format" help:"Output format: text|md" enum:"text,md" default:"text"`
+}
+
+func (c *DocsSectionGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	id, err := c.Target.validate()
+	if err != nil {
+		return err
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newDocsService(ctx, account)
+	if err != nil {
+		return err
+	}
+	tab, section, err := c.Target.load(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+
+	var body string
+	if c.Format == "md" {
+		sub := *tab
+		sub.Body = &docs.Body{Content: section.Content}
+		body = newDocsMarkdownRenderer(&sub, false, func(_, contentURI string) string { return contentURI }).render()
+		if body != "" {
+			body += "\n"
+		}
+	} else {
+		var buf bytes.Buffer
+		for _, el := range section.Content {
+			appendDocsElementText(&buf, 0, el)
+		}
+		body = buf.String()
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"documentId": id,
+			"section":    docsSectionJSON(section),
+			"format":     c.Format,
+			"content":    body,
+		})
+	}
+	_, err = io.WriteString(os.Stdout, body)
+	return err
+}
+
+type DocsSectionReplaceCmd struct {
+	Target DocsSectionTarget `embed:""`
+	Input  DocsSectionInput  `embed:""`
+}
+
+func (c *DocsSectionReplaceCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runDocsSectionWrite(ctx, flags, c.Target, c.Input, true)
+}
+
+type DocsSectionAppendCmd struct {
+	Target DocsSectionTarget `embed:""`
+	Input  DocsSectionInput  `embed:""`
+}
+
+func (c *DocsSectionAppendCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runDocsSectionWrite(ctx, flags, c.Target, c.Input, false)
+}
+
+// runDocsSectionWrite replaces (or appends to) a section's body. The new text
+// is reset to normal paragraphs without bullets before any markdown
+// formatting is applied, since inserted paragraphs otherwise inherit the
+// style of the heading that follows them.
+func runDocsSectionWrite(ctx context.Context, flags *RootFlags, target DocsSectionTarget, in DocsSectionInput, replace bool) error {
+	u := ui.FromContext(ctx)
+
+	id, err := target.validate()
+	if err != nil {
+		return err
+	}
+	content, err := resolveContentInput(in.Content, in.File)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		return usage("no content provided (use argument, --file, or stdin)")
+	}
+	format := in.Format
+	if in.Markdown {
+		format = docsContentFormatMarkdown
+	}
+
+	op := "docs.section.append"
+	if replace {
+		op = "docs.section.replace"
+	}
+	params := target.dryRunParams(id)
+	params["format"] = format
+	params["bytes"] = len(content)
+	if dryErr := dryRunExit(ctx, flags, op, params); dryErr != nil {
+		return dryErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newDocsService(ctx, account)
+	if err != nil {
+		return err
+	}
+	_, section, err := target.load(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+
+	var requests []*docs.Request
+	insertAt := section.EndIndex
+	bodyEnd := section.BodyEnd
+	if replace {
+		insertAt = section.BodyStart
+		// The final newline of the body can never be deleted.
+		if end := min(section.EndIndex, section.BodyEnd-1); end > section.BodyStart {
+			requests = append(requests, &docs.Request{
+				DeleteContentRange: &docs.DeleteContentRangeRequest{
+					Range: &docs.Range{StartIndex: section.BodyStart, EndIndex: end},
+				},
+			})
+			bodyEnd -= end - section.BodyStart
+		}
+	}
+
+	var plan markdownDocsPlan
+	if format == docsContentFormatMarkdown {
+		plan = planMarkdownDocs(ParseMarkdown(content), insertAt)
+	} else {
+		plan.Text = content
+		if !strings.HasSuffix(plan.Text, "\n") {
+			plan.Text += "\n"
+		}
+	}
+	if plan.Text == "" {
+		return usage("content has nothing to insert")
+	}
+
+	// At the very end of the body there is no following paragraph to insert
+	// in front of, so split the last paragraph instead: "\n" + text, reusing
+	// the body's final newline.
+	text := plan.Text
+	at := insertAt
+	styleEnd := insertAt + utf16Len(plan.Text)
+	if insertAt >= bodyEnd {
+		at = bodyEnd - 1
+		text = "\n" + strings.TrimSuffix(text, "\n")
+		styleEnd--
+	}
+	requests = append(requests,
+		&docs.Request{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: at}, Text: text}},
+		paragraphStyleRequest(insertAt, styleEnd, &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"}, "namedStyleType"),
+		&docs.Request{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: &docs.Range{StartIndex: insertAt, EndIndex: styleEnd}}},
+	)
+	requests = append(requests, plan.Requests...)
+	setDocsRequestsTab(requests, section.TabID)
+
+	if _, err = svc.Documents.BatchUpdate(id, &docs.BatchUpdateDocumentRequest{
+		Requests: requests,
+	}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("update section: %w", err)
+	}
+	if err := insertMarkdownObjects(ctx, svc, id, section.TabID, plan.Tables, plan.Footnotes); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"documentId": id,
+			"section":    docsSectionJSON(section),
+			"replaced":   replace,
+			"inserted":   utf16Len(plan.Text),
+			"atIndex":    insertAt,
+		})
+	}
+	action := "Appended to"
+	if replace {
+		action = "Replaced"
+	}
+	u.Out().Printf("%s section %q (%s)", action, section.Heading, id)
+	return nil
+}
+
+type DocsSectionDeleteCmd struct {
+	Target      DocsSectionTarget `embed:""`
+	KeepHeading bool              `name:"keep-heading" help:"Delete only the body and keep the heading"`
+}
+
+func (c *DocsSectionDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	id, err := c.Target.validate()
+	if err != nil {
+		return err
+	}
+	what := "section"
+	if c.KeepHeading {
+		what = "body of section"
+	}
+	if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("delete %s %q in doc %s", what, strings.TrimSpace(c.Target.Heading), id)); confirmErr != nil {
+		return confirmErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newDocsService(ctx, account)
+	if err != nil {
+		return err
+	}
+	_, section, err := c.Target.load(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+
+	start := section.StartIndex
+	if c.KeepHeading {
+		start = section.BodyStart
+	}
+	end := min(section.EndIndex, section.BodyEnd-1)
+	deleted := max(end-start, 0)
+	if deleted > 0 {
+		requests := []*docs.Request{{
+			DeleteContentRange: &docs.DeleteContentRangeRequest{
+				Range: &docs.Range{StartIndex: start, EndIndex: end},
+			},
+		}}
+		setDocsRequestsTab(requests, section.TabID)
+		if _, err = svc.Documents.BatchUpdate(id, &docs.BatchUpdateDocumentRequest{
+			Requests: requests,
+		}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("delete section: %w", err)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"documentId": id,
+			"section":    docsSectionJSON(section),
+			"deleted":    deleted,
+		})
+	}
+	u.Out().Printf("Deleted %s %q (%d characters)", what, section.Heading, deleted)
+	return nil
+}
+
+func docsSectionJSON(s *docsSection) map[string]any {
+	m := map[string]any{
+		"heading":        s.Heading,
+		"level":          s.Level,
+		"startIndex":     s.StartIndex,
+		"bodyStartIndex": s.BodyStart,
+		"endIndex":       s.EndIndex,
+	}
+	if s.TabID != "" {
+		m["tabId"] = s.TabID
+	}
+	return m
+}
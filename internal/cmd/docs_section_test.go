@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func docsSectionTestDoc() map[string]any {
+	para := func(start, end int64, style, text string) map[string]any {
+		return map[string]any{
+			"startIndex": start,
+			"endIndex":   end,
+			"paragraph": map[string]any{
+				"paragraphStyle": map[string]any{"namedStyleType": style},
+				"elements":       []any{map[string]any{"textRun": map[string]any{"content": text}}},
+			},
+		}
+	}
+	return map[string]any{
+		"documentId": "doc1",
+		"tabs": []any{map[string]any{
+			"tabProperties": map[string]any{"tabId": "t.0", "title": "Main"},
+			"documentTab": map[string]any{"body": map[string]any{"content": []any{
+				map[string]any{"endIndex": 1, "sectionBreak": map[string]any{}},
+				para(1, 8, "HEADING_1", "Weekly\n"),
+				para(8, 15, "HEADING_2", "Status\n"),
+				para(15, 19, "NORMAL_TEXT", "old\n"),
+				para(19, 26, "HEADING_3", "Detail\n"),
+				para(26, 28, "NORMAL_TEXT", "x\n"),
+				para(28, 34, "HEADING_2", "Risks\n"),
+				para(34, 39, "NORMAL_TEXT", "none\n"),
+			}}},
+		}},
+	}
+}
+
+func runDocsSectionTest(t *testing.T, cmd any, args []string) []docs.BatchUpdateDocumentRequest {
+	t.Helper()
+	origDocs := newDocsService
+	t.Cleanup(func() { newDocsService = origDocs })
+
+	var batches []docs.BatchUpdateDocumentRequest
+	docSvc, cleanup := newDocsServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/documents/"):
+			_ = json.NewEncoder(w).Encode(docsSectionTestDoc())
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":batchUpdate"):
+			var req docs.BatchUpdateDocumentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode batchUpdate: %v", err)
+			}
+			batches = append(batches, req)
+			_ = json.NewEncoder(w).Encode(map[string]any{"documentId": "doc1"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	t.Cleanup(cleanup)
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return docSvc, nil }
+
+	if err := runKong(t, cmd, args, newDocsCmdContext(t), &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+		t.Fatalf("run %v: %v", args, err)
+	}
+	return batches
+}
+
+func TestFindDocsSection_StopsAtSameOrHigherLevel(t *testing.T) {
+	var doc docs.Document
+	data, _ := json.Marshal(docsSectionTestDoc())
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	content := doc.Tabs[0].DocumentTab.Body.Content
+
+	section, err := findDocsSection(content, " status ", 0, 1)
+	if err != nil {
+		t.Fatalf("findDocsSection: %v", err)
+	}
+	if section.Level != 2 || section.BodyStart != 15 || section.EndIndex != 28 || len(section.Content) != 3 {
+		t.Fatalf("unexpected section: %+v", section)
+	}
+	if _, err := findDocsSection(content, "Status", 3, 1); err == nil {
+		t.Fatal("expected no match for --level 3")
+	}
+	if _, err := findDocsSection(content, "Status", 0, 2); err == nil || !strings.Contains(err.Error(), "--nth") {
+		t.Fatalf("expected --nth error, got %v", err)
+	}
+}
+
+func TestDocsSectionReplace_Markdown(t *testing.T) {
+	batches := runDocsSectionTest(t, &DocsSectionReplaceCmd{}, []string{"doc1", "--heading", "Status", "--markdown", "**green**"})
+	if len(batches) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(batches))
+	}
+	reqs := batches[0].Requests
+	if del := reqs[0].DeleteContentRange; del == nil || del.Range.StartIndex != 15 || del.Range.EndIndex != 28 {
+		t.Fatalf("unexpected delete: %#v", reqs[0])
+	}
+	if ins := reqs[1].InsertText; ins == nil || ins.Location.Index != 15 || ins.Text != "green\n" {
+		t.Fatalf("unexpected insert: %#v", reqs[1])
+	}
+	if ps := reqs[2].UpdateParagraphStyle; ps == nil || ps.ParagraphStyle.NamedStyleType != "NORMAL_TEXT" || ps.Range.EndIndex != 21 {
+		t.Fatalf("expected NORMAL_TEXT reset, got %#v", reqs[2])
+	}
+	if bold := reqs[4].UpdateTextStyle; bold == nil || !bold.TextStyle.Bold || bold.Range.StartIndex != 15 || bold.Range.EndIndex != 20 {
+		t.Fatalf("unexpected bold: %#v", reqs[4])
+	}
+}
+
+func TestDocsSectionAppend_AtEndOfDocument(t *testing.T) {
+	batches := runDocsSectionTest(t, &DocsSectionAppendCmd{}, []string{"doc1", "--heading", "risks", "--tab", "Main", "more"})
+	reqs := batches[0].Requests
+	ins := reqs[0].InsertText
+	if ins == nil || ins.Location.Index != 38 || ins.Text != "\nmore" || ins.Location.TabId != "t.0" {
+		t.Fatalf("unexpected insert: %#v", reqs[0])
+	}
+	if rng := reqs[1].UpdateParagraphStyle.Range; rng.StartIndex != 39 || rng.EndIndex != 43 || rng.TabId != "t.0" {
+		t.Fatalf("unexpected reset range: %#v", rng)
+	}
+}
+
+func TestDocsSectionDelete(t *testing.T) {
+	batches := runDocsSectionTest(t, &DocsSectionDeleteCmd{}, []string{"doc1", "--heading", "Status"})
+	if del := batches[0].Requests[0].DeleteContentRange; del == nil || del.Range.StartIndex != 8 || del.Range.EndIndex != 28 {
+		t.Fatalf("unexpected delete: %#v", batches[0].Requests[0])
+	}
+
+	batches = runDocsSectionTest(t, &DocsSectionDeleteCmd{}, []string{"doc1", "--heading", "Risks", "--keep-heading"})
+	if del := batches[0].Requests[0].DeleteContentRange; del == nil || del.Range.StartIndex != 34 || del.Range.EndIndex != 38 {
+		t.Fatalf("unexpected delete: %#v", batches[0].Requests[0])
+	}
+}
+
+func TestDocsSectionGet_Markdown(t *testing.T) {
+	out := captureStdout(t, func() {
+		_ = runDocsSectionTest(t, &DocsSectionGetCmd{}, []string{"doc1", "--heading", "Status", "--format", "md"})
+	})
+	if out != "old\n\n### Detail\n\nx\n" {
+		t.Fatalf("unexpected markdown: %q", out)
+	}
+}
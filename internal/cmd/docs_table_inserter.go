@@ -11,6 +11,7 @@ import (
 type TableInserter struct {
 	svc   *docs.Service
 	docID string
+	tabID string // optional; empty targets the first tab
 }
 
 func NewTableInserter(svc *docs.Service, docID string) *TableInserter {
@@ -20,9 +21,11 @@ func NewTableInserter(svc *docs.Service, docID string) *TableInserter {
 	}
 }
 
-// InsertNativeTable inserts a native Google Docs table and populates it with content
+// InsertNativeTable inserts a native Google Docs table and populates it with content.
+// Cells may contain inline markdown; the header row is bolded and shaded and
+// align sets each column's paragraph alignment (START, CENTER, END or "").
 // Returns the end index of the table after insertion
-func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64, cells [][]string) (int64, error) {
+func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64, cells [][]string, align []string) (int64, error) {
 	if len(cells) == 0 || len(cells[0]) == 0 {
 		return tableIndex, nil
 	}
@@ -41,6 +44,7 @@ func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64
 		},
 	}
 
+	setDocsRequestsTab([]*docs.Request{insertTableReq}, ti.tabID)
 	_, err := ti.svc.Documents.BatchUpdate(ti.docID, &docs.BatchUpdateDocumentRequest{
 		Requests: []*docs.Request{insertTableReq},
 	}).Context(ctx).Do()
@@ -49,21 +53,43 @@ func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64
 	}
 
 	// Step 2: Fetch the document to get cell indices
-	doc, err := ti.svc.Documents.Get(ti.docID).Context(ctx).Do()
+	doc, err := ti.svc.Documents.Get(ti.docID).IncludeTabsContent(ti.tabID != "").Context(ctx).Do()
 	if err != nil {
 		return tableIndex, fmt.Errorf("get document after table insert: %w", err)
 	}
 
 	// Step 3: Find the table in the document and get cell indices
-	cellIndices, tableEndIndex, err := ti.getTableCellIndices(doc, tableIndex, rows, cols)
+	cellIndices, tableStartIndex, tableEndIndex, err := ti.getTableCellIndices(doc, tableIndex, rows, cols)
 	if err != nil {
 		return tableEndIndex, err
 	}
 
-	// Step 4: Insert text into each cell
+	// Step 4: Shade the header row (cell styles don't move any indices)
+	shadeReq := []*docs.Request{{
+		UpdateTableCellStyle: &docs.UpdateTableCellStyleRequest{
+			TableRange: &docs.TableRange{
+				TableCellLocation: &docs.TableCellLocation{
+					TableStartLocation: &docs.Location{Index: tableStartIndex},
+				},
+				RowSpan:    1,
+				ColumnSpan: cols,
+			},
+			TableCellStyle: &docs.TableCellStyle{BackgroundColor: markdownCodeShading},
+			Fields:         "backgroundColor",
+		},
+	}}
+	setDocsRequestsTab(shadeReq, ti.tabID)
+	_, err = ti.svc.Documents.BatchUpdate(ti.docID, &docs.BatchUpdateDocumentRequest{
+		Requests: shadeReq,
+	}).Context(ctx).Do()
+	if err != nil {
+		return tableEndIndex, fmt.Errorf("style table header: %w", err)
+	}
+
+	// Step 5: Insert text into each cell
 	for rowIdx := 0; rowIdx < len(cells); rowIdx++ {
-		for colIdx := 0; colIdx < len(cells[rowIdx]); colIdx++ {
-			cellContent := cells[rowIdx][colIdx]
+		for colIdx := 0; colIdx < len(cells[rowIdx]) && colIdx < int(cols); colIdx++ {
+			styles, cellContent := ParseInlineFormatting(cells[rowIdx][colIdx])
 			if cellContent == "" {
 				continue
 			}
@@ -72,39 +98,45 @@ func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64
 			if cellIdx == 0 {
 				continue
 			}
+			cellLen := utf16Len(cellContent)
 
 			// Insert text into cell
-			insertTextReq := &docs.Request{
+			requests := []*docs.Request{{
 				InsertText: &docs.InsertTextRequest{
 					Location: &docs.Location{
 						Index: cellIdx,
 					},
 					Text: cellContent,
 				},
-			}
+			}}
 
 			// Make text bold if it's a header row
-			var boldReq *docs.Request
 			if rowIdx == 0 {
-				boldReq = &docs.Request{
+				requests = append(requests, &docs.Request{
 					UpdateTextStyle: &docs.UpdateTextStyleRequest{
 						Range: &docs.Range{
 							StartIndex: cellIdx,
-							EndIndex:   cellIdx + utf16Len(cellContent),
+							EndIndex:   cellIdx + cellLen,
 						},
 						TextStyle: &docs.TextStyle{
 							Bold: true,
 						},
 						Fields: "bold",
 					},
+				})
+			}
+			for _, style := range styles {
+				if req := buildTextStyleRequest(style, cellIdx); req != nil {
+					requests = append(requests, req)
 				}
 			}
-
-			requests := []*docs.Request{insertTextReq}
-			if boldReq != nil {
-				requests = append(requests, boldReq)
+			if colIdx < len(align) && align[colIdx] != "" {
+				requests = append(requests, paragraphStyleRequest(cellIdx, cellIdx+cellLen, &docs.ParagraphStyle{
+					Alignment: align[colIdx],
+				}, "alignment"))
 			}
 
+			setDocsRequestsTab(requests, ti.tabID)
 			_, err := ti.svc.Documents.BatchUpdate(ti.docID, &docs.BatchUpdateDocumentRequest{
 				Requests: requests,
 			}).Context(ctx).Do()
@@ -113,32 +145,41 @@ func (ti *TableInserter) InsertNativeTable(ctx context.Context, tableIndex int64
 			}
 
 			// Update indices for subsequent cells (they shift by the content length)
-			ti.updateIndicesAfter(cellIdx, utf16Len(cellContent), cellIndices, &tableEndIndex)
+			ti.updateIndicesAfter(cellIdx, cellLen, cellIndices, &tableEndIndex)
 		}
 	}
 
 	return tableEndIndex, nil
 }
 
-// getTableCellIndices extracts the start index for each cell in a table
-func (ti *TableInserter) getTableCellIndices(doc *docs.Document, tableStartIndex int64, rows, cols int64) ([][]int64, int64, error) {
+// getTableCellIndices extracts the start index for each cell in a table,
+// along with the table's own start and end index
+func (ti *TableInserter) getTableCellIndices(doc *docs.Document, tableStartIndex int64, rows, cols int64) ([][]int64, int64, int64, error) {
 	cellIndices := make([][]int64, rows)
 	for i := range cellIndices {
 		cellIndices[i] = make([]int64, cols)
 	}
 
-	var tableEndIndex int64
+	var foundStartIndex, tableEndIndex int64
 
 	// Find the table in the document
-	if doc.Body == nil {
-		return cellIndices, tableEndIndex, fmt.Errorf("document body is nil")
+	body := doc.Body
+	if ti.tabID != "" {
+		body = nil
+		if tab := findTab(flattenTabs(doc.Tabs), ti.tabID); tab != nil && tab.DocumentTab != nil {
+			body = tab.DocumentTab.Body
+		}
+	}
+	if body == nil {
+		return cellIndices, foundStartIndex, tableEndIndex, fmt.Errorf("document body is nil")
 	}
 
 	// Look for table element starting near tableStartIndex
-	for _, element := range doc.Body.Content {
+	for _, element := range body.Content {
 		if element.Table != nil {
 			// Check if this is our table (starts near the expected index)
 			if element.StartIndex >= tableStartIndex-2 && element.StartIndex <= tableStartIndex+2 {
+				foundStartIndex = element.StartIndex
 				tableEndIndex = element.EndIndex
 
 				// Extract cell indices from table
@@ -162,10 +203,10 @@ func (ti *TableInserter) getTableCellIndices(doc *docs.Document, tableStartIndex
 	}
 
 	if tableEndIndex == 0 {
-		return cellIndices, tableEndIndex, fmt.Errorf("table not found near index %d", tableStartIndex)
+		return cellIndices, foundStartIndex, tableEndIndex, fmt.Errorf("table not found near index %d", tableStartIndex)
 	}
 
-	return cellIndices, tableEndIndex, nil
+	return cellIndices, foundStartIndex, tableEndIndex, nil
 }
 
 // updateIndicesAfter updates cell indices after text insertion
@@ -37,6 +37,8 @@ const (
 	driveMimeGoogleSheet   = "application/vnd.google-apps.spreadsheet"
 	driveMimeGoogleSlides  = "application/vnd.google-apps.presentation"
 	driveMimeGoogleDrawing = "application/vnd.google-apps.drawing"
+	driveMimeFolder        = "application/vnd.google-apps.folder"
+	driveMimeShortcut      = "application/vnd.google-apps.shortcut"
 	mimePDF                = "application/pdf"
 	mimeCSV                = "text/csv"
 	mimeDocx               = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
@@ -77,18 +79,20 @@ type DriveCmd struct {
 	URL         DriveURLCmd         `cmd:"" name:"url" help:"Print web URLs for files"`
 	Comments    DriveCommentsCmd    `cmd:"" name:"comments" help:"Manage comments on files"`
 	Drives      DriveDrivesCmd      `cmd:"" name:"drives" help:"List shared drives (Team Drives)"`
+	Sync        DriveSyncCmd        `cmd:"" name:"sync" help:"Sync a local directory with a Drive folder (push, pull or both)"`
 }
 
 type DriveLsCmd struct {
 	Max       int64  `name:"max" aliases:"limit" help:"Max results" default:"20"`
 	Page      string `name:"page" aliases:"cursor" help:"Page token"`
+	All       bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
 	Query     string `name:"query" help:"Drive query filter"`
 	Parent    string `name:"parent" help:"Folder ID to list (default: root)"`
 	AllDrives bool   `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
 }
 
 func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -104,51 +108,8 @@ func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	q := buildDriveListQuery(folderID, c.Query)
-
-	call := svc.Files.List().
-		Q(q).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		OrderBy("modifiedTime desc")
-	call = driveFilesListCallWithDriveSupport(call, c.AllDrives)
-
-	resp, err := call.
-		Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return err
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
-			"files":         resp.Files,
-			"nextPageToken": resp.NextPageToken,
-		})
-	}
-
-	if len(resp.Files) == 0 {
-		u.Err().Println("No files")
-		return nil
-	}
-
-	w, flush := tableWriter(ctx)
-	defer flush()
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSIZE\tMODIFIED")
-	for _, f := range resp.Files {
-		fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\n",
-			f.Id,
-			f.Name,
-			driveType(f.MimeType),
-			formatDriveSize(f.Size),
-			formatDateTime(f.ModifiedTime),
-		)
-	}
-	printNextPageHint(u, resp.NextPageToken)
-	return nil
+	fetch := driveFilesFetcher(ctx, svc, buildDriveListQuery(folderID, c.Query), c.Max, c.AllDrives)
+	return listDriveFiles(ctx, fetch, c.Page, c.All, c.FailEmpty, "No files")
 }
 
 type DriveSearchCmd struct {
@@ -156,11 +117,12 @@ type DriveSearchCmd struct {
 	RawQuery  bool     `name:"raw-query" aliases:"raw" help:"Treat query as Drive query language (pass through; may error if invalid)"`
 	Max       int64    `name:"max" aliases:"limit" help:"Max results" default:"20"`
 	Page      string   `name:"page" aliases:"cursor" help:"Page token"`
+	All       bool     `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty bool     `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
 	AllDrives bool     `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
 }
 
 func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
-	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
 		return err
@@ -175,37 +137,75 @@ func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	call := svc.Files.List().
-		Q(buildDriveSearchQuery(query, c.RawQuery)).
-		PageSize(c.Max).
-		PageToken(c.Page).
-		OrderBy("modifiedTime desc")
-	call = driveFilesListCallWithDriveSupport(call, c.AllDrives)
+	fetch := driveFilesFetcher(ctx, svc, buildDriveSearchQuery(query, c.RawQuery), c.Max, c.AllDrives)
+	return listDriveFiles(ctx, fetch, c.Page, c.All, c.FailEmpty, "No results")
+}
 
-	resp, err := call.
-		Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return err
+func driveFilesFetcher(ctx context.Context, svc *drive.Service, q string, maxResults int64, allDrives bool) func(string) ([]*drive.File, string, error) {
+	return func(pageToken string) ([]*drive.File, string, error) {
+		call := svc.Files.List().
+			Q(q).
+			PageSize(maxResults).
+			PageToken(pageToken).
+			OrderBy("modifiedTime desc")
+		call = driveFilesListCallWithDriveSupport(call, allDrives)
+
+		resp, err := call.
+			Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Files, resp.NextPageToken, nil
 	}
+}
 
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
-			"files":         resp.Files,
-			"nextPageToken": resp.NextPageToken,
-		})
+func listDriveFiles(ctx context.Context, fetch func(string) ([]*drive.File, string, error), page string, allPages, failEmpty bool, emptyMsg string) error {
+	u := ui.FromContext(ctx)
+
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, allPages, page, failEmpty, fetch)
 	}
 
-	if len(resp.Files) == 0 {
-		u.Err().Println("No results")
+	var files []*drive.File
+	nextPageToken := ""
+	if allPages {
+		all, err := collectAllPages(page, fetch)
+		if err != nil {
+			return err
+		}
+		files = all
+	} else {
+		var err error
+		files, nextPageToken, err = fetch(page)
+		if err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"files":         files,
+			"nextPageToken": nextPageToken,
+		}); err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return failEmptyExit(failEmpty)
+		}
 		return nil
 	}
 
+	if len(files) == 0 {
+		u.Err().Println(emptyMsg)
+		return failEmptyExit(failEmpty)
+	}
+
 	w, flush := tableWriter(ctx)
 	defer flush()
 	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSIZE\tMODIFIED")
-	for _, f := range resp.Files {
+	for _, f := range files {
 		fmt.Fprintf(
 			w,
 			"%s\t%s\t%s\t%s\t%s\n",
@@ -216,7 +216,7 @@ func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 			formatDateTime(f.ModifiedTime),
 		)
 	}
-	printNextPageHint(u, resp.NextPageToken)
+	printNextPageHint(u, nextPageToken)
 	return nil
 }
 
@@ -73,6 +73,10 @@ func (c *DriveCommentsListCmd) Run(ctx context.Context, flags *RootFlags) error
 		return resp.Comments, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var comments []*drive.Comment
 	nextPageToken := ""
 	if c.All {
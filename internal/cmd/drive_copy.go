@@ -53,23 +53,42 @@ func copyViaDrive(ctx context.Context, flags *RootFlags, opts copyViaDriveOption
 		return err
 	}
 
+	created, err := copyDriveFile(ctx, svc, opts, id, name, parent)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{strFile: created})
+	}
+	u.Out().Printf("id\t%s", created.Id)
+	u.Out().Printf("name\t%s", created.Name)
+	u.Out().Printf("mime\t%s", created.MimeType)
+	if created.WebViewLink != "" {
+		u.Out().Printf("link\t%s", created.WebViewLink)
+	}
+	return nil
+}
+
+// copyDriveFile copies id after checking it has the expected mime type.
+func copyDriveFile(ctx context.Context, svc *drive.Service, opts copyViaDriveOptions, id, name, parent string) (*drive.File, error) {
 	meta, err := svc.Files.Get(id).
 		SupportsAllDrives(true).
 		Fields("id, name, mimeType").
 		Context(ctx).
 		Do()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if meta == nil {
-		return errors.New("file not found")
+		return nil, errors.New("file not found")
 	}
 	if opts.ExpectedMime != "" && meta.MimeType != opts.ExpectedMime {
 		label := strings.TrimSpace(opts.KindLabel)
 		if label == "" {
 			label = "expected type"
 		}
-		return fmt.Errorf("file is not a %s (mimeType=%q)", label, meta.MimeType)
+		return nil, fmt.Errorf("file is not a %s (mimeType=%q)", label, meta.MimeType)
 	}
 
 	req := &drive.File{Name: name}
@@ -83,20 +102,10 @@ func copyViaDrive(ctx context.Context, flags *RootFlags, opts copyViaDriveOption
 		Context(ctx).
 		Do()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if created == nil {
-		return errors.New("copy failed")
-	}
-
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{strFile: created})
+		return nil, errors.New("copy failed")
 	}
-	u.Out().Printf("id\t%s", created.Id)
-	u.Out().Printf("name\t%s", created.Name)
-	u.Out().Printf("mime\t%s", created.MimeType)
-	if created.WebViewLink != "" {
-		u.Out().Printf("link\t%s", created.WebViewLink)
-	}
-	return nil
+	return created, nil
 }
@@ -51,6 +51,10 @@ func (c *DriveDrivesCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Drives, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var drives []*drive.Drive
 	nextPageToken := ""
 	if c.All {
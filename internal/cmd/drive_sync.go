@@ -0,0 +1,724 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Drive reports md5Checksum; used for change detection only
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	driveSyncModePush = "push"
+	driveSyncModePull = "pull"
+	driveSyncModeBoth = "both"
+
+	driveSyncConflictSkip   = "skip"
+	driveSyncConflictLocal  = "local"
+	driveSyncConflictRemote = "remote"
+	driveSyncConflictNewer  = "newer"
+
+	driveSyncActionUpload      = "upload"
+	driveSyncActionUpdate      = "update"
+	driveSyncActionDownload    = "download"
+	driveSyncActionDeleteLocal = "delete-local"
+	driveSyncActionTrashRemote = "trash-remote"
+	driveSyncActionConflict    = "conflict"
+	driveSyncActionSkip        = "skip"
+
+	driveSyncStateVersion = 1
+)
+
+type DriveSyncCmd struct {
+	LocalDir string `arg:"" name:"localDir" help:"Local directory"`
+	FolderID string `arg:"" name:"folderId" help:"Drive folder ID"`
+	Mode     string `name:"mode" help:"Sync direction: push (local -> Drive), pull (Drive -> local), both" default:"both" enum:"push,pull,both"`
+	Conflict string `name:"conflict" help:"When both sides changed: skip|local|remote|newer" default:"skip" enum:"skip,local,remote,newer"`
+	Delete   bool   `name:"delete" help:"Propagate deletions (remote files are moved to trash, never deleted forever)"`
+	State    string `name:"state" help:"State file path (default: gogcli state dir)"`
+}
+
+// driveSyncState records what both sides looked like after the last sync, so
+// a missing file can be told apart from a new one and edits on both sides
+// surface as conflicts.
+type driveSyncState struct {
+	Version   int                            `json:"version"`
+	Account   string                         `json:"account"`
+	LocalDir  string                         `json:"localDir"`
+	FolderID  string                         `json:"folderId"`
+	UpdatedAt string                         `json:"updatedAt,omitempty"`
+	Files     map[string]driveSyncStateEntry `json:"files"`
+}
+
+type driveSyncStateEntry struct {
+	FileID         string `json:"fileId"`
+	LocalMD5       string `json:"localMd5"`
+	RemoteMD5      string `json:"remoteMd5,omitempty"`
+	RemoteModified string `json:"remoteModified,omitempty"`
+}
+
+type driveSyncLocalFile struct {
+	AbsPath string
+	MD5     string
+	ModTime time.Time
+}
+
+type driveSyncRemoteFile struct {
+	File     *drive.File
+	ParentID string
+	// Native Google files (Docs/Sheets/Slides/Drawings) have no md5Checksum
+	// and can only be exported, never pushed back.
+	Native bool
+}
+
+type driveSyncAction struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	FileID string `json:"fileId,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (c *DriveSyncCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	localDir := strings.TrimSpace(c.LocalDir)
+	if localDir == "" {
+		return usage("empty localDir")
+	}
+	localDir, err = config.ExpandPath(localDir)
+	if err != nil {
+		return err
+	}
+	localDir, err = filepath.Abs(localDir)
+	if err != nil {
+		return err
+	}
+	folderID := strings.TrimSpace(c.FolderID)
+	if folderID == "" {
+		return usage("empty folderId")
+	}
+	mode := strings.ToLower(strings.TrimSpace(c.Mode))
+	if mode == "" {
+		mode = driveSyncModeBoth
+	}
+	conflict := strings.ToLower(strings.TrimSpace(c.Conflict))
+	if conflict == "" {
+		conflict = driveSyncConflictSkip
+	}
+
+	statePath := strings.TrimSpace(c.State)
+	if statePath == "" {
+		statePath, err = driveSyncStatePath(account, folderID, localDir)
+	} else {
+		statePath, err = config.ExpandPath(statePath)
+	}
+	if err != nil {
+		return err
+	}
+	state, err := loadDriveSyncState(statePath)
+	if err != nil {
+		return err
+	}
+
+	// A missing local dir is fine for a first pull, but with sync state it
+	// would read as "everything was deleted locally".
+	if info, statErr := os.Stat(localDir); statErr != nil {
+		if !errors.Is(statErr, os.ErrNotExist) || mode == driveSyncModePush || len(state.Files) > 0 {
+			return statErr
+		}
+	} else if !info.IsDir() {
+		return usagef("not a directory: %s", localDir)
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	remote, remoteDirs, err := scanDriveSyncRemote(ctx, svc, folderID)
+	if err != nil {
+		return err
+	}
+	local, err := scanDriveSyncLocal(localDir, statePath)
+	if err != nil {
+		return err
+	}
+
+	plan, inSync := planDriveSync(local, remote, state.Files, mode, conflict, c.Delete)
+
+	if err := dryRunExit(ctx, flags, "drive.sync", map[string]any{
+		"localDir": localDir,
+		"folderId": folderID,
+		"mode":     mode,
+		"actions":  plan,
+	}); err != nil {
+		return err
+	}
+
+	state.Version = driveSyncStateVersion
+	state.Account = account
+	state.LocalDir = localDir
+	state.FolderID = folderID
+	if state.Files == nil {
+		state.Files = map[string]driveSyncStateEntry{}
+	}
+	for _, p := range inSync {
+		state.Files[p] = driveSyncEntryFor(local[p], remote[p])
+	}
+
+	run := &driveSyncRunner{
+		svc:        svc,
+		localDir:   localDir,
+		folderID:   folderID,
+		remoteDirs: remoteDirs,
+		local:      local,
+		remote:     remote,
+		state:      &state,
+	}
+	failed := 0
+	for i := range plan {
+		if execErr := run.apply(ctx, &plan[i]); execErr != nil {
+			plan[i].Error = execErr.Error()
+			failed++
+		}
+	}
+	for p := range state.Files {
+		if _, ok := local[p]; ok {
+			continue
+		}
+		if _, ok := remote[p]; ok {
+			continue
+		}
+		if !driveSyncPlanTouches(plan, p) {
+			// Gone on both sides; nothing left to track.
+			delete(state.Files, p)
+		}
+	}
+
+	state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if saveErr := saveDriveSyncState(statePath, state); saveErr != nil {
+		return saveErr
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"localDir": localDir,
+			"folderId": folderID,
+			"mode":     mode,
+			"actions":  plan,
+			"inSync":   len(inSync),
+			"failed":   failed,
+		}); err != nil {
+			return err
+		}
+	} else if len(plan) == 0 {
+		u.Err().Println("Already in sync")
+	} else {
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "ACTION\tPATH\tDETAIL")
+		for _, a := range plan {
+			detail := a.Reason
+			if a.Error != "" {
+				detail = "error: " + a.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", a.Action, a.Path, detail)
+		}
+		flush()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("drive sync: %d of %d actions failed", failed, len(plan))
+	}
+	return nil
+}
+
+func driveSyncStatePath(account, folderID, localDir string) (string, error) {
+	dir, err := config.EnsureDriveSyncDir()
+	if err != nil {
+		return "", err
+	}
+	// Folder IDs are case-sensitive, so they go into the hash rather than the name.
+	sum := sha256.Sum256([]byte(folderID + "\x00" + localDir))
+	name := fmt.Sprintf("%s-%s.json", sanitizeAccountForPath(account), hex.EncodeToString(sum[:8]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadDriveSyncState(path string) (driveSyncState, error) {
+	var state driveSyncState
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("read sync state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveDriveSyncState(path string, state driveSyncState) error {
+	payload, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// scanDriveSyncLocal walks localDir and hashes every regular file. Paths are
+// slash-separated and relative to localDir.
+func scanDriveSyncLocal(localDir, statePath string) (map[string]driveSyncLocalFile, error) {
+	out := map[string]driveSyncLocalFile{}
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && p == localDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() || p == statePath || p == statePath+".tmp" {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum, err := fileMD5(p)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = driveSyncLocalFile{AbsPath: p, MD5: sum, ModTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func fileMD5(p string) (string, error) {
+	f, err := os.Open(p) //nolint:gosec // path comes from walking the sync dir
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New() //nolint:gosec // Drive reports md5Checksum
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// scanDriveSyncRemote lists folderID recursively. Native Google files are keyed
+// by their export name (e.g. "Report.pdf"), matching what a pull writes.
+func scanDriveSyncRemote(ctx context.Context, svc *drive.Service, folderID string) (map[string]driveSyncRemoteFile, map[string]string, error) {
+	files := map[string]driveSyncRemoteFile{}
+	dirs := map[string]string{"": folderID}
+	u := ui.FromContext(ctx)
+
+	var walk func(parentID, prefix string) error
+	walk = func(parentID, prefix string) error {
+		fetch := func(pageToken string) ([]*drive.File, string, error) {
+			call := svc.Files.List().
+				Q(buildDriveListQuery(parentID, "")).
+				PageSize(1000).
+				PageToken(pageToken)
+			call = driveFilesListCallWithDriveSupport(call, true)
+			resp, err := call.
+				Fields("nextPageToken, files(id, name, mimeType, md5Checksum, modifiedTime, size)").
+				Context(ctx).
+				Do()
+			if err != nil {
+				return nil, "", err
+			}
+			return resp.Files, resp.NextPageToken, nil
+		}
+		children, err := collectAllPages("", fetch)
+		if err != nil {
+			return err
+		}
+		for _, f := range children {
+			if f == nil || f.Name == "" {
+				continue
+			}
+			if strings.Contains(f.Name, "/") || f.Name == "." || f.Name == ".." {
+				if u != nil {
+					u.Err().Printf("skipping %q: name cannot be used as a local path", path.Join(prefix, f.Name))
+				}
+				continue
+			}
+			rel := path.Join(prefix, f.Name)
+			switch {
+			case f.MimeType == driveMimeFolder:
+				if _, dup := dirs[rel]; dup {
+					continue
+				}
+				dirs[rel] = f.Id
+				if err := walk(f.Id, rel); err != nil {
+					return err
+				}
+				continue
+			case f.MimeType == driveMimeShortcut:
+				continue
+			}
+			native := strings.HasPrefix(f.MimeType, "application/vnd.google-apps.")
+			if native {
+				switch f.MimeType {
+				case driveMimeGoogleDoc, driveMimeGoogleSheet, driveMimeGoogleSlides, driveMimeGoogleDrawing:
+					rel = replaceExt(rel, driveExportExtension(driveExportMimeType(f.MimeType)))
+				default:
+					// Forms, Sites, Maps, ... have no file export.
+					continue
+				}
+			}
+			if _, dup := files[rel]; dup {
+				if u != nil {
+					u.Err().Printf("skipping duplicate Drive name %q (id %s)", rel, f.Id)
+				}
+				continue
+			}
+			files[rel] = driveSyncRemoteFile{File: f, ParentID: parentID, Native: native}
+		}
+		return nil
+	}
+	if err := walk(folderID, ""); err != nil {
+		return nil, nil, err
+	}
+	return files, dirs, nil
+}
+
+func driveSyncRemoteChanged(r driveSyncRemoteFile, s driveSyncStateEntry) bool {
+	if s.FileID != "" && s.FileID != r.File.Id {
+		return true
+	}
+	if r.Native || r.File.Md5Checksum == "" {
+		return r.File.ModifiedTime != s.RemoteModified
+	}
+	return r.File.Md5Checksum != s.RemoteMD5
+}
+
+func driveSyncEntryFor(l driveSyncLocalFile, r driveSyncRemoteFile) driveSyncStateEntry {
+	entry := driveSyncStateEntry{LocalMD5: l.MD5}
+	if r.File != nil {
+		entry.FileID = r.File.Id
+		entry.RemoteMD5 = r.File.Md5Checksum
+		entry.RemoteModified = r.File.ModifiedTime
+	}
+	return entry
+}
+
+// planDriveSync decides what to do for every path seen locally, remotely or in
+// the previous state. It also returns paths that are identical on both sides
+// so their state entries can be refreshed without any transfer.
+func planDriveSync(local map[string]driveSyncLocalFile, remote map[string]driveSyncRemoteFile, prev map[string]driveSyncStateEntry, mode, conflict string, propagateDeletes bool) ([]driveSyncAction, []string) {
+	paths := map[string]struct{}{}
+	for p := range local {
+		paths[p] = struct{}{}
+	}
+	for p := range remote {
+		paths[p] = struct{}{}
+	}
+	for p := range prev {
+		paths[p] = struct{}{}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	push := mode != driveSyncModePull
+	pull := mode != driveSyncModePush
+
+	var (
+		plan   []driveSyncAction
+		inSync []string
+	)
+	add := func(action, p, fileID, reason string) {
+		plan = append(plan, driveSyncAction{Action: action, Path: p, FileID: fileID, Reason: reason})
+	}
+
+	for _, p := range sorted {
+		l, hasL := local[p]
+		r, hasR := remote[p]
+		s, hasS := prev[p]
+		localChanged := hasL && (!hasS || l.MD5 != s.LocalMD5)
+		remoteChanged := hasR && (!hasS || driveSyncRemoteChanged(r, s))
+
+		switch {
+		case hasL && hasR:
+			fileID := r.File.Id
+			if !r.Native && r.File.Md5Checksum != "" && l.MD5 == r.File.Md5Checksum {
+				if !hasS || localChanged || remoteChanged {
+					inSync = append(inSync, p)
+				}
+				continue
+			}
+			if !localChanged && !remoteChanged {
+				continue
+			}
+			winner := ""
+			switch {
+			case localChanged && remoteChanged:
+				switch mode {
+				case driveSyncModePush:
+					winner = "local"
+				case driveSyncModePull:
+					winner = "remote"
+				default:
+					winner = resolveDriveSyncConflict(conflict, l, r)
+				}
+			case localChanged:
+				if push {
+					winner = "local"
+				} else {
+					add(driveSyncActionSkip, p, fileID, "local changes are not pushed in pull mode")
+					continue
+				}
+			default:
+				if pull {
+					winner = "remote"
+				} else {
+					add(driveSyncActionSkip, p, fileID, "remote changes are not pulled in push mode")
+					continue
+				}
+			}
+			switch winner {
+			case "local":
+				if r.Native {
+					add(driveSyncActionSkip, p, fileID, "native Google file is export-only")
+					continue
+				}
+				add(driveSyncActionUpdate, p, fileID, "")
+			case "remote":
+				add(driveSyncActionDownload, p, fileID, "")
+			default:
+				add(driveSyncActionConflict, p, fileID, "changed on both sides")
+			}
+
+		case hasL:
+			if !hasS {
+				if push {
+					add(driveSyncActionUpload, p, "", "")
+				}
+				continue
+			}
+			// Previously synced, now gone from Drive.
+			switch {
+			case mode == driveSyncModePush || (push && localChanged):
+				add(driveSyncActionUpload, p, "", "missing in Drive")
+			case localChanged:
+				add(driveSyncActionConflict, p, s.FileID, "deleted in Drive but changed locally")
+			case propagateDeletes:
+				add(driveSyncActionDeleteLocal, p, s.FileID, "deleted in Drive")
+			default:
+				add(driveSyncActionSkip, p, s.FileID, "deleted in Drive (use --delete to remove locally)")
+			}
+
+		case hasR:
+			if !hasS {
+				if pull {
+					add(driveSyncActionDownload, p, r.File.Id, "")
+				}
+				continue
+			}
+			// Previously synced, now gone locally.
+			switch {
+			case mode == driveSyncModePull || (pull && remoteChanged):
+				add(driveSyncActionDownload, p, r.File.Id, "missing locally")
+			case remoteChanged:
+				add(driveSyncActionConflict, p, r.File.Id, "deleted locally but changed in Drive")
+			case propagateDeletes:
+				add(driveSyncActionTrashRemote, p, r.File.Id, "deleted locally")
+			default:
+				add(driveSyncActionSkip, p, r.File.Id, "deleted locally (use --delete to trash in Drive)")
+			}
+		}
+	}
+	return plan, inSync
+}
+
+func resolveDriveSyncConflict(policy string, l driveSyncLocalFile, r driveSyncRemoteFile) string {
+	switch policy {
+	case driveSyncConflictLocal:
+		return "local"
+	case driveSyncConflictRemote:
+		return "remote"
+	case driveSyncConflictNewer:
+		remoteTime, err := time.Parse(time.RFC3339, r.File.ModifiedTime)
+		if err != nil {
+			return ""
+		}
+		if l.ModTime.After(remoteTime) {
+			return "local"
+		}
+		return "remote"
+	default:
+		return ""
+	}
+}
+
+func driveSyncPlanTouches(plan []driveSyncAction, p string) bool {
+	for _, a := range plan {
+		if a.Path == p {
+			return true
+		}
+	}
+	return false
+}
+
+type driveSyncRunner struct {
+	svc        *drive.Service
+	localDir   string
+	folderID   string
+	remoteDirs map[string]string
+	local      map[string]driveSyncLocalFile
+	remote     map[string]driveSyncRemoteFile
+	state      *driveSyncState
+}
+
+func (r *driveSyncRunner) apply(ctx context.Context, a *driveSyncAction) error {
+	switch a.Action {
+	case driveSyncActionUpload, driveSyncActionUpdate:
+		return r.push(ctx, a)
+	case driveSyncActionDownload:
+		return r.pull(ctx, a)
+	case driveSyncActionDeleteLocal:
+		if err := os.Remove(filepath.Join(r.localDir, filepath.FromSlash(a.Path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		delete(r.state.Files, a.Path)
+		return nil
+	case driveSyncActionTrashRemote:
+		_, err := r.svc.Files.Update(a.FileID, &drive.File{Trashed: true}).
+			SupportsAllDrives(true).
+			Fields("id, trashed").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		delete(r.state.Files, a.Path)
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (r *driveSyncRunner) push(ctx context.Context, a *driveSyncAction) error {
+	l := r.local[a.Path]
+	f, err := os.Open(l.AbsPath) //nolint:gosec // path comes from walking the sync dir
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	mimeType := guessMimeType(l.AbsPath)
+	var res *drive.File
+	if a.Action == driveSyncActionUpdate {
+		res, err = r.svc.Files.Update(a.FileID, &drive.File{}).
+			SupportsAllDrives(true).
+			Media(f, gapi.ContentType(mimeType)).
+			Fields("id, md5Checksum, modifiedTime").
+			Context(ctx).
+			Do()
+	} else {
+		parentID, dirErr := r.ensureRemoteDir(ctx, path.Dir(a.Path))
+		if dirErr != nil {
+			return dirErr
+		}
+		res, err = r.svc.Files.Create(&drive.File{Name: path.Base(a.Path), Parents: []string{parentID}}).
+			SupportsAllDrives(true).
+			Media(f, gapi.ContentType(mimeType)).
+			Fields("id, md5Checksum, modifiedTime").
+			Context(ctx).
+			Do()
+	}
+	if err != nil {
+		return err
+	}
+	a.FileID = res.Id
+	r.state.Files[a.Path] = driveSyncEntryFor(l, driveSyncRemoteFile{File: res})
+	return nil
+}
+
+func (r *driveSyncRunner) pull(ctx context.Context, a *driveSyncAction) error {
+	rf := r.remote[a.Path]
+	dest := filepath.Join(r.localDir, filepath.FromSlash(a.Path))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil { //nolint:gosec // user-visible sync tree
+		return err
+	}
+	outPath, _, err := downloadDriveFile(ctx, r.svc, rf.File, dest, "")
+	if err != nil {
+		return err
+	}
+	sum, err := fileMD5(outPath)
+	if err != nil {
+		return err
+	}
+	r.state.Files[a.Path] = driveSyncEntryFor(driveSyncLocalFile{MD5: sum}, rf)
+	return nil
+}
+
+// ensureRemoteDir returns the folder ID for a slash-separated path below the
+// sync root, creating missing folders along the way.
+func (r *driveSyncRunner) ensureRemoteDir(ctx context.Context, rel string) (string, error) {
+	if rel == "." || rel == "" {
+		return r.folderID, nil
+	}
+	if id, ok := r.remoteDirs[rel]; ok {
+		return id, nil
+	}
+	parentID, err := r.ensureRemoteDir(ctx, path.Dir(rel))
+	if err != nil {
+		return "", err
+	}
+	created, err := r.svc.Files.Create(&drive.File{
+		Name:     path.Base(rel),
+		MimeType: driveMimeFolder,
+		Parents:  []string{parentID},
+	}).
+		SupportsAllDrives(true).
+		Fields("id").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", err
+	}
+	r.remoteDirs[rel] = created.Id
+	return created.Id, nil
+}
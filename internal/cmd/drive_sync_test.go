@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // mirrors Drive md5Checksum
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s)) //nolint:gosec // mirrors Drive md5Checksum
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPlanDriveSync(t *testing.T) {
+	local := map[string]driveSyncLocalFile{
+		"same.txt":          {MD5: md5Hex("same")},
+		"new-local.txt":     {MD5: md5Hex("new")},
+		"edited-local.txt":  {MD5: md5Hex("v2")},
+		"both.txt":          {MD5: md5Hex("local")},
+		"gone-remote.txt":   {MD5: md5Hex("x")},
+		"edited-remote.txt": {MD5: md5Hex("r1")},
+	}
+	remote := map[string]driveSyncRemoteFile{
+		"same.txt":          {File: &drive.File{Id: "f1", Md5Checksum: md5Hex("same")}},
+		"new-remote.txt":    {File: &drive.File{Id: "f2", Md5Checksum: md5Hex("r")}},
+		"edited-local.txt":  {File: &drive.File{Id: "f3", Md5Checksum: md5Hex("v1")}},
+		"both.txt":          {File: &drive.File{Id: "f4", Md5Checksum: md5Hex("remote")}},
+		"gone-local.txt":    {File: &drive.File{Id: "f5", Md5Checksum: md5Hex("y")}},
+		"Report.pdf":        {File: &drive.File{Id: "f6", ModifiedTime: "2024-01-02T00:00:00Z"}, Native: true},
+		"edited-remote.txt": {File: &drive.File{Id: "f7", Md5Checksum: md5Hex("r2")}},
+	}
+	prev := map[string]driveSyncStateEntry{
+		"edited-local.txt":  {FileID: "f3", LocalMD5: md5Hex("v1"), RemoteMD5: md5Hex("v1")},
+		"both.txt":          {FileID: "f4", LocalMD5: md5Hex("base"), RemoteMD5: md5Hex("base")},
+		"gone-remote.txt":   {FileID: "f8", LocalMD5: md5Hex("x"), RemoteMD5: md5Hex("x")},
+		"gone-local.txt":    {FileID: "f5", LocalMD5: md5Hex("y"), RemoteMD5: md5Hex("y")},
+		"Report.pdf":        {FileID: "f6", LocalMD5: md5Hex("pdf"), RemoteModified: "2024-01-01T00:00:00Z"},
+		"edited-remote.txt": {FileID: "f7", LocalMD5: md5Hex("r1"), RemoteMD5: md5Hex("r1")},
+	}
+
+	summarize := func(plan []driveSyncAction) map[string]string {
+		out := map[string]string{}
+		for _, a := range plan {
+			out[a.Path] = a.Action
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		conflict string
+		delete   bool
+		want     map[string]string
+	}{
+		{
+			name: "both",
+			mode: driveSyncModeBoth, conflict: driveSyncConflictSkip,
+			want: map[string]string{
+				"new-local.txt":     driveSyncActionUpload,
+				"new-remote.txt":    driveSyncActionDownload,
+				"edited-local.txt":  driveSyncActionUpdate,
+				"both.txt":          driveSyncActionConflict,
+				"gone-remote.txt":   driveSyncActionSkip,
+				"gone-local.txt":    driveSyncActionSkip,
+				"Report.pdf":        driveSyncActionDownload,
+				"edited-remote.txt": driveSyncActionDownload,
+			},
+		},
+		{
+			name: "both with deletes and remote wins",
+			mode: driveSyncModeBoth, conflict: driveSyncConflictRemote, delete: true,
+			want: map[string]string{
+				"new-local.txt":     driveSyncActionUpload,
+				"new-remote.txt":    driveSyncActionDownload,
+				"edited-local.txt":  driveSyncActionUpdate,
+				"both.txt":          driveSyncActionDownload,
+				"gone-remote.txt":   driveSyncActionDeleteLocal,
+				"gone-local.txt":    driveSyncActionTrashRemote,
+				"Report.pdf":        driveSyncActionDownload,
+				"edited-remote.txt": driveSyncActionDownload,
+			},
+		},
+		{
+			name: "push",
+			mode: driveSyncModePush, conflict: driveSyncConflictSkip, delete: true,
+			want: map[string]string{
+				"new-local.txt":     driveSyncActionUpload,
+				"edited-local.txt":  driveSyncActionUpdate,
+				"both.txt":          driveSyncActionUpdate,
+				"gone-remote.txt":   driveSyncActionUpload,
+				"gone-local.txt":    driveSyncActionTrashRemote,
+				"Report.pdf":        driveSyncActionConflict,
+				"edited-remote.txt": driveSyncActionSkip,
+			},
+		},
+		{
+			name: "pull",
+			mode: driveSyncModePull, conflict: driveSyncConflictSkip,
+			want: map[string]string{
+				"new-remote.txt":    driveSyncActionDownload,
+				"edited-local.txt":  driveSyncActionSkip,
+				"both.txt":          driveSyncActionDownload,
+				"gone-remote.txt":   driveSyncActionSkip,
+				"gone-local.txt":    driveSyncActionDownload,
+				"Report.pdf":        driveSyncActionDownload,
+				"edited-remote.txt": driveSyncActionDownload,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, inSync := planDriveSync(local, remote, prev, tc.mode, tc.conflict, tc.delete)
+			got := summarize(plan)
+			if len(got) != len(tc.want) {
+				t.Fatalf("unexpected plan: %v", got)
+			}
+			for p, want := range tc.want {
+				if got[p] != want {
+					t.Fatalf("%s: got %q, want %q (plan=%v)", p, got[p], want, got)
+				}
+			}
+			if len(inSync) != 1 || inSync[0] != "same.txt" {
+				t.Fatalf("unexpected inSync: %v", inSync)
+			}
+		})
+	}
+}
+
+// fakeDriveFolder is a tiny in-memory Drive that supports the calls drive sync makes.
+type fakeDriveFolder struct {
+	mu      sync.Mutex
+	files   map[string]map[string]any
+	content map[string]string
+	nextID  int
+	trashed []string
+}
+
+var fakeDriveParentQuery = regexp.MustCompile(`'([^']+)' in parents`)
+
+func (f *fakeDriveFolder) add(id, parent, name, mimeType, content string) {
+	f.files[id] = map[string]any{
+		"id":           id,
+		"name":         name,
+		"mimeType":     mimeType,
+		"parents":      []string{parent},
+		"modifiedTime": "2024-01-01T00:00:00Z",
+	}
+	if !strings.HasPrefix(mimeType, "application/vnd.google-apps.") {
+		f.files[id]["md5Checksum"] = md5Hex(content)
+	}
+	f.content[id] = content
+}
+
+func (f *fakeDriveFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	p := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+	switch {
+	case p == "/files" && r.Method == http.MethodGet:
+		parent := ""
+		if m := fakeDriveParentQuery.FindStringSubmatch(r.URL.Query().Get("q")); m != nil {
+			parent = m[1]
+		}
+		var out []map[string]any
+		for _, file := range f.files {
+			if parents, _ := file["parents"].([]string); len(parents) > 0 && parents[0] == parent {
+				out = append(out, file)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": out})
+	case p == "/files" && r.Method == http.MethodPost:
+		var meta map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&meta)
+		f.nextID++
+		id := "new" + strconv.Itoa(f.nextID)
+		f.add(id, meta["parents"].([]any)[0].(string), meta["name"].(string), meta["mimeType"].(string), "")
+		_ = json.NewEncoder(w).Encode(f.files[id])
+	case r.URL.Path == "/upload/drive/v3/files" && r.Method == http.MethodPost:
+		meta, body := readMultipartUpload(r)
+		f.nextID++
+		id := "new" + strconv.Itoa(f.nextID)
+		f.add(id, meta["parents"].([]any)[0].(string), meta["name"].(string), "text/plain", body)
+		_ = json.NewEncoder(w).Encode(f.files[id])
+	case strings.HasPrefix(r.URL.Path, "/upload/drive/v3/files/") && r.Method == http.MethodPatch:
+		id := strings.TrimPrefix(r.URL.Path, "/upload/drive/v3/files/")
+		_, body := readMultipartUpload(r)
+		f.content[id] = body
+		f.files[id]["md5Checksum"] = md5Hex(body)
+		_ = json.NewEncoder(w).Encode(f.files[id])
+	case strings.HasSuffix(p, "/export") && r.Method == http.MethodGet:
+		id := strings.TrimSuffix(strings.TrimPrefix(p, "/files/"), "/export")
+		_, _ = io.WriteString(w, f.content[id])
+	case strings.HasPrefix(p, "/files/") && r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+		_, _ = io.WriteString(w, f.content[strings.TrimPrefix(p, "/files/")])
+	case strings.HasPrefix(p, "/files/") && r.Method == http.MethodPatch:
+		id := strings.TrimPrefix(p, "/files/")
+		f.trashed = append(f.trashed, id)
+		delete(f.files, id)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "trashed": true})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func readMultipartUpload(r *http.Request) (map[string]any, string) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, ""
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	var meta map[string]any
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, ""
+	}
+	_ = json.NewDecoder(part).Decode(&meta)
+	part, err = mr.NextPart()
+	if err != nil {
+		return meta, ""
+	}
+	b, _ := io.ReadAll(part)
+	return meta, string(b)
+}
+
+func TestDriveSyncCmd_Both(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	fake := &fakeDriveFolder{files: map[string]map[string]any{}, content: map[string]string{}}
+	fake.add("sub", "root1", "reports", driveMimeFolder, "")
+	fake.add("r1", "sub", "q1.txt", "text/plain", "quarter one")
+	fake.add("d1", "root1", "Notes", driveMimeGoogleDoc, "%PDF notes")
+
+	svc, closeSrv := newDriveTestService(t, fake)
+	t.Cleanup(closeSrv)
+	newDriveService = stubDriveService(svc)
+
+	localDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(localDir, "drafts"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(localDir, "drafts", "plan.txt"), []byte("the plan"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+	flags := &RootFlags{Account: "a@b.com"}
+
+	runSync := func() map[string]any {
+		t.Helper()
+		out := captureStdout(t, func() {
+			cmd := &DriveSyncCmd{LocalDir: localDir, FolderID: "root1", Mode: driveSyncModeBoth, Conflict: driveSyncConflictSkip, State: statePath}
+			if runErr := cmd.Run(ctx, flags); runErr != nil {
+				t.Fatalf("Run: %v", runErr)
+			}
+		})
+		var parsed map[string]any
+		if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+			t.Fatalf("json: %v (%q)", err, out)
+		}
+		return parsed
+	}
+
+	first := runSync()
+	if actions, _ := first["actions"].([]any); len(actions) != 3 {
+		t.Fatalf("expected 3 actions, got %v", first["actions"])
+	}
+	if b, err := os.ReadFile(filepath.Join(localDir, "reports", "q1.txt")); err != nil || string(b) != "quarter one" {
+		t.Fatalf("expected pulled file, got %q err=%v", string(b), err)
+	}
+	if b, err := os.ReadFile(filepath.Join(localDir, "Notes.pdf")); err != nil || string(b) != "%PDF notes" {
+		t.Fatalf("expected exported doc, got %q err=%v", string(b), err)
+	}
+	var pushed bool
+	for id, file := range fake.files {
+		if file["name"] == "plan.txt" {
+			pushed = fake.content[id] == "the plan"
+			parent := file["parents"].([]string)[0]
+			if fake.files[parent]["name"] != "drafts" {
+				t.Fatalf("expected plan.txt under a new drafts folder, got parent %v", fake.files[parent])
+			}
+		}
+	}
+	if !pushed {
+		t.Fatalf("expected plan.txt uploaded")
+	}
+
+	second := runSync()
+	if actions, _ := second["actions"].([]any); len(actions) != 0 {
+		t.Fatalf("expected no actions on second run, got %v", second["actions"])
+	}
+
+	// A local edit is pushed as an in-place update.
+	if err := os.WriteFile(filepath.Join(localDir, "reports", "q1.txt"), []byte("quarter one v2"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	third := runSync()
+	actions, _ := third["actions"].([]any)
+	if len(actions) != 1 || actions[0].(map[string]any)["action"] != driveSyncActionUpdate {
+		t.Fatalf("expected one update, got %v", third["actions"])
+	}
+	if fake.content["r1"] != "quarter one v2" {
+		t.Fatalf("expected remote content updated, got %q", fake.content["r1"])
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/classroom/v1"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/forms/v1"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+	"google.golang.org/api/pubsub/v1"
+	"google.golang.org/api/script/v1"
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+	"google.golang.org/api/tasks/v1"
+)
+
+// fakeAuthPrefix is the bearer token scheme understood by internal/fakegoogle:
+// the token names the account the request acts as.
+const fakeAuthPrefix = "Bearer fake:"
+
+// useAPIEndpoint points the Google API service constructors at endpoint
+// (typically an internal/fakegoogle server) instead of Google. No OAuth
+// tokens are loaded; each request carries a fake bearer token naming the
+// account. The returned func restores the real constructors.
+func useAPIEndpoint(endpoint string) (func(), error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid --endpoint %q (want http(s)://host[:port])", endpoint)
+	}
+	target := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/")}
+
+	origAppScript := newAppScriptService
+	origCalendar := newCalendarService
+	origChat := newChatService
+	origClassroom := newClassroomService
+	origCloudIdentity := newCloudIdentityService
+	origDocs := newDocsService
+	origDrive := newDriveService
+	origForms := newFormsService
+	origGmail := newGmailService
+	origPeopleContacts := newPeopleContactsService
+	origPeopleOther := newPeopleOtherContactsService
+	origPeopleDirectory := newPeopleDirectoryService
+	origPubSub := newPubSubService
+	origSheets := newSheetsService
+	origSlides := newSlidesService
+	origTasks := newTasksService
+
+	newAppScriptService = endpointService(target, script.NewService)
+	newCalendarService = endpointService(target, calendar.NewService)
+	newChatService = endpointService(target, chat.NewService)
+	newClassroomService = endpointService(target, classroom.NewService)
+	newCloudIdentityService = endpointService(target, cloudidentity.NewService)
+	newDocsService = endpointService(target, docs.NewService)
+	newDriveService = endpointService(target, drive.NewService)
+	newFormsService = endpointService(target, forms.NewService)
+	newGmailService = endpointService(target, gmail.NewService)
+	newPeopleContactsService = endpointService(target, people.NewService)
+	newPeopleOtherContactsService = endpointService(target, people.NewService)
+	newPeopleDirectoryService = endpointService(target, people.NewService)
+	// Pub/Sub is not per account (it uses Application Default Credentials), so
+	// its requests carry an empty fake account.
+	newPubSub := endpointService(target, pubsub.NewService)
+	newPubSubService = func(ctx context.Context) (*pubsub.Service, error) { return newPubSub(ctx, "") }
+	newSheetsService = endpointService(target, sheets.NewService)
+	newSlidesService = endpointService(target, slides.NewService)
+	newTasksService = endpointService(target, tasks.NewService)
+
+	return func() {
+		newAppScriptService = origAppScript
+		newCalendarService = origCalendar
+		newChatService = origChat
+		newClassroomService = origClassroom
+		newCloudIdentityService = origCloudIdentity
+		newDocsService = origDocs
+		newDriveService = origDrive
+		newFormsService = origForms
+		newGmailService = origGmail
+		newPeopleContactsService = origPeopleContacts
+		newPeopleOtherContactsService = origPeopleOther
+		newPeopleDirectoryService = origPeopleDirectory
+		newPubSubService = origPubSub
+		newSheetsService = origSheets
+		newSlidesService = origSlides
+		newTasksService = origTasks
+	}, nil
+}
+
+// endpointService wraps a generated NewService. Clients keep their default
+// Google URLs (APIs differ in base path, and uploads use /upload/...); the
+// transport moves each request onto the endpoint host instead.
+func endpointService[S any](endpoint *url.URL, newService func(context.Context, ...option.ClientOption) (S, error)) func(context.Context, string) (S, error) {
+	return func(ctx context.Context, email string) (S, error) {
+		return newService(ctx, option.WithHTTPClient(&http.Client{Transport: &endpointTransport{
+			endpoint: endpoint,
+			account:  email,
+			base:     retryTransport(ctx),
+		}}))
+	}
+}
+
+type endpointTransport struct {
+	endpoint *url.URL
+	account  string
+	base     http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if host := r.URL.Hostname(); host == "googleapis.com" || strings.HasSuffix(host, ".googleapis.com") {
+		r.URL.Scheme = t.endpoint.Scheme
+		r.URL.Host = t.endpoint.Host
+		r.URL.Path = t.endpoint.Path + r.URL.Path
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = t.endpoint.Path + r.URL.RawPath
+		}
+		r.Host = ""
+	}
+	r.Header.Set("Authorization", fakeAuthPrefix+t.account)
+	return t.base.RoundTrip(r)
+}
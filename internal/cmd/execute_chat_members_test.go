@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+func TestNormalizeChatMember(t *testing.T) {
+	cases := map[string]string{
+		"ada@example.com":         "spaces/s1/members/ada@example.com",
+		"users/123":               "spaces/s1/members/123",
+		"spaces/s1/members/users": "spaces/s1/members/users",
+	}
+	for in, want := range cases {
+		if got, err := normalizeChatMember("spaces/s1", in); err != nil || got != want {
+			t.Errorf("normalizeChatMember(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeChatMember("spaces/s1", "spaces/s1"); err == nil {
+		t.Fatalf("expected error for space name")
+	}
+}
+
+func TestExecute_ChatMembersSetRole(t *testing.T) {
+	var mask string
+	var body map[string]any
+	useChatTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || !strings.HasSuffix(r.URL.Path, "/spaces/s1/members/ada@example.com") {
+			http.NotFound(w, r)
+			return
+		}
+		mask = r.URL.Query().Get("updateMask")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "spaces/s1/members/1", "role": body["role"]})
+	}))
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "chat", "members", "set-role", "s1", "ada@example.com", "manager"}); err != nil {
+			t.Fatalf("set-role: %v", err)
+		}
+	})
+	if mask != "role" || body["role"] != "ROLE_MANAGER" || !strings.Contains(out, "role\tROLE_MANAGER") {
+		t.Fatalf("unexpected mask=%q body=%v out=%q", mask, body, out)
+	}
+	if err := Execute([]string{"--account", "a@b.com", "chat", "members", "set-role", "s1", "ada@example.com", "owner"}); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for bad role, got %v", err)
+	}
+}
+
+func TestExecute_ChatSpacesSetup_SyncFromGroup(t *testing.T) {
+	origCI := newCloudIdentityService
+	t.Cleanup(func() { newCloudIdentityService = origCI })
+	ciSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "groups:lookup"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "groups/oncall"})
+		case strings.Contains(r.URL.Path, "groups/oncall/memberships"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"memberships": []any{
+				map[string]any{"preferredMemberKey": map[string]any{"id": "ada@example.com"}, "type": "USER"},
+				map[string]any{"preferredMemberKey": map[string]any{"id": "bob@example.com"}, "type": "USER"},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ciSrv.Close)
+	ciSvc, err := cloudidentity.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(ciSrv.Client()),
+		option.WithEndpoint(ciSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newCloudIdentityService = func(context.Context, string) (*cloudidentity.Service, error) { return ciSvc, nil }
+
+	var mu sync.Mutex
+	var created, deleted []string
+	known := map[string]string{"ada@example.com": "users/1", "me@b.com": "users/7"}
+	useChatTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+		switch {
+		case r.Method == http.MethodGet && strings.Contains(path, "/spaces/s1/members/"):
+			email := path[strings.LastIndex(path, "/")+1:]
+			id, ok := known[email]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 404, "message": "not found"}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "spaces/s1/members/" + strings.TrimPrefix(id, "users/"), "member": map[string]any{"name": id, "type": "HUMAN"}})
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/spaces/s1/members"):
+			var m struct {
+				Member struct {
+					Name string `json:"name"`
+				} `json:"member"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&m)
+			created = append(created, m.Member.Name)
+			known[strings.TrimPrefix(m.Member.Name, "users/")] = "users/2"
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "spaces/s1/members/2", "member": map[string]any{"name": "users/2", "type": "HUMAN"}, "role": "ROLE_MEMBER"})
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/spaces/s1/members"):
+			memberships := []any{
+				map[string]any{"name": "spaces/s1/members/1", "member": map[string]any{"name": "users/1", "type": "HUMAN"}, "role": "ROLE_MEMBER"},
+				map[string]any{"name": "spaces/s1/members/7", "member": map[string]any{"name": "users/7", "type": "HUMAN"}, "role": "ROLE_MANAGER"},
+				map[string]any{"name": "spaces/s1/members/8", "member": map[string]any{"name": "users/8", "type": "HUMAN"}, "role": "ROLE_MANAGER"},
+				map[string]any{"name": "spaces/s1/members/9", "member": map[string]any{"name": "users/9", "type": "HUMAN"}, "role": "ROLE_MEMBER"},
+				map[string]any{"name": "spaces/s1/members/bot", "member": map[string]any{"name": "users/app", "type": "BOT"}, "role": "ROLE_MEMBER"},
+			}
+			if len(created) > 0 {
+				memberships = append(memberships, map[string]any{"name": "spaces/s1/members/2", "member": map[string]any{"name": "users/2", "type": "HUMAN"}, "role": "ROLE_MEMBER"})
+			}
+			kept := memberships[:0]
+			for _, m := range memberships {
+				if name := m.(map[string]any)["name"].(string); !containsStringInSlice(deleted, name) {
+					kept = append(kept, m)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"memberships": kept})
+		case r.Method == http.MethodDelete && strings.Contains(path, "/spaces/s1/members/"):
+			deleted = append(deleted, path[strings.Index(path, "spaces/"):])
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	setup := []string{"--json", "--account", "me@b.com", "chat", "spaces", "setup", "spaces/s1", "--from-group", "oncall@b.com", "--prune"}
+	if err := Execute(setup); err == nil || ExitCode(err) != 2 || !strings.Contains(err.Error(), "remove members not in oncall@b.com from spaces/s1") {
+		t.Fatalf("expected prune to require --force, got %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := Execute(append([]string{"--dry-run"}, setup...)); err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+	})
+	var dry struct {
+		Request struct {
+			Add    []string `json:"add"`
+			Remove []string `json:"remove"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal([]byte(out), &dry); err != nil {
+		t.Fatalf("parse dry run: %v out=%q", err, out)
+	}
+	if strings.Join(dry.Request.Add, ",") != "bob@example.com" || strings.Join(dry.Request.Remove, ",") != "users/9" {
+		t.Fatalf("unexpected dry run plan: %+v", dry.Request)
+	}
+	if len(created) != 0 || len(deleted) != 0 {
+		t.Fatalf("dry run made changes: created=%v deleted=%v", created, deleted)
+	}
+
+	out = captureStdout(t, func() {
+		if err := Execute(append([]string{"--force"}, setup...)); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	})
+	var result chatSpaceSyncResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("parse: %v out=%q", err, out)
+	}
+	if result.Created || result.Unchanged != 1 || strings.Join(result.Added, ",") != "bob@example.com" || strings.Join(result.Removed, ",") != "users/9" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if strings.Join(created, ",") != "users/bob@example.com" || strings.Join(deleted, ",") != "spaces/s1/members/9" {
+		t.Fatalf("unexpected calls created=%v deleted=%v", created, deleted)
+	}
+
+	// Once in sync, --prune has nothing to remove and needs no confirmation.
+	_ = captureStdout(t, func() {
+		if err := Execute(setup); err != nil {
+			t.Fatalf("prune with nothing to remove: %v", err)
+		}
+	})
+	if len(created) != 1 || len(deleted) != 1 {
+		t.Fatalf("in-sync run made changes: created=%v deleted=%v", created, deleted)
+	}
+}
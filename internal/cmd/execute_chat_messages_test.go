@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/option"
+)
+
+func useChatTestServer(t *testing.T, handler http.Handler) {
+	t.Helper()
+	origNew := newChatService
+	t.Cleanup(func() { newChatService = origNew })
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	svc, err := chat.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newChatService = func(context.Context, string) (*chat.Service, error) { return svc, nil }
+}
+
+func TestParseChatCards(t *testing.T) {
+	bare := `{"header":{"title":"Deploy"},"sections":[{"widgets":[{"textParagraph":{"text":"ok"}}]}]}`
+	cards, err := parseChatCards([]byte(bare))
+	if err != nil || len(cards) != 1 || cards[0].CardId != "card-1" || cards[0].Card.Header.Title != "Deploy" {
+		t.Fatalf("bare card: %v %#v", err, cards)
+	}
+
+	cards, err = parseChatCards([]byte(`{"cardsV2":[{"cardId":"status","card":{"header":{"title":"A"}}},{"header":{"title":"B"}}]}`))
+	if err != nil || len(cards) != 2 || cards[0].CardId != "status" || cards[1].CardId != "card-2" || cards[1].Card.Header.Title != "B" {
+		t.Fatalf("wrapped cards: %v %#v", err, cards)
+	}
+
+	if _, err = parseChatCards([]byte(`[]`)); err == nil {
+		t.Fatalf("expected error for empty list")
+	}
+	if _, err = parseChatCards([]byte(`{"card":null}`)); err == nil {
+		t.Fatalf("expected error for missing card")
+	}
+}
+
+func TestExecute_ChatMessagesUpdateDelete(t *testing.T) {
+	var patched map[string]any
+	var patchMask string
+	deleted := ""
+	useChatTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/spaces/s1/messages/m1"):
+			patchMask = r.URL.Query().Get("updateMask")
+			_ = json.NewDecoder(r.Body).Decode(&patched)
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "spaces/s1/messages/m1", "text": patched["text"], "lastUpdateTime": "2025-01-01T00:00:00Z"})
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/spaces/s1/messages/m1"):
+			deleted = r.URL.Query().Get("force")
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "chat", "messages", "update", "spaces/s1/messages/m1",
+			"--text", "done", "--cards-json", `{"header":{"title":"Build"}}`}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	})
+	if patchMask != "text,cards_v2" || patched["text"] != "done" || !strings.Contains(out, `"lastUpdateTime"`) {
+		t.Fatalf("unexpected patch mask=%q body=%v out=%q", patchMask, patched, out)
+	}
+	cardsV2, _ := patched["cardsV2"].([]any)
+	if len(cardsV2) != 1 {
+		t.Fatalf("expected one card, got %v", patched["cardsV2"])
+	}
+
+	if err := Execute([]string{"--account", "a@b.com", "--no-input", "chat", "messages", "delete", "spaces/s1/messages/m1"}); ExitCode(err) != 2 {
+		t.Fatalf("expected refusal without --force, got %v", err)
+	}
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "--force", "chat", "messages", "delete", "spaces/s1/messages/m1", "--with-replies"}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	})
+	if deleted != "true" || !strings.Contains(out, "deleted\ttrue") {
+		t.Fatalf("unexpected delete force=%q out=%q", deleted, out)
+	}
+
+	if err := Execute([]string{"--account", "a@b.com", "chat", "messages", "get", "m1"}); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for bare message id, got %v", err)
+	}
+}
+
+func TestExecute_ChatReactions(t *testing.T) {
+	var created map[string]any
+	var filter string
+	useChatTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/spaces/s1/messages/m1/reactions"):
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "spaces/s1/messages/m1/reactions/r1", "emoji": created["emoji"]})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/spaces/s1/messages/m1/reactions"):
+			filter = r.URL.Query().Get("filter")
+			_ = json.NewEncoder(w).Encode(map[string]any{"reactions": []map[string]any{
+				{"name": "spaces/s1/messages/m1/reactions/r1", "emoji": map[string]any{"unicode": "👍"}, "user": map[string]any{"name": "users/1"}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "chat", "reactions", "add", "spaces/s1/messages/m1", "👍"}); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	})
+	if emoji, _ := created["emoji"].(map[string]any); emoji["unicode"] != "👍" || !strings.Contains(out, "reactions/r1") {
+		t.Fatalf("unexpected create body=%v out=%q", created, out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "chat", "reactions", "list", "spaces/s1/messages/m1", "--emoji", "👍", "--user", "1"}); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	if filter != `emoji.unicode = "👍" AND user.name = "users/1"` {
+		t.Fatalf("unexpected filter %q", filter)
+	}
+	var parsed struct {
+		Reactions []chatReactionItem `json:"reactions"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil || len(parsed.Reactions) != 1 || parsed.Reactions[0].User != "users/1" {
+		t.Fatalf("unexpected list out=%q err=%v", out, err)
+	}
+}
+
+func TestExecute_ChatAttachmentsDownload(t *testing.T) {
+	useChatTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/spaces/s1/messages/m1"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"name": "spaces/s1/messages/m1",
+				"attachment": []map[string]any{
+					{"name": "spaces/s1/messages/m1/attachments/a1", "contentName": "report.txt", "attachmentDataRef": map[string]any{"resourceName": "res-1"}},
+					{"name": "spaces/s1/messages/m1/attachments/a2", "contentName": "../report.txt", "attachmentDataRef": map[string]any{"resourceName": "res-2"}},
+					{"name": "spaces/s1/messages/m1/attachments/a3", "contentName": "Plan", "driveDataRef": map[string]any{"driveFileId": "drive-1"}},
+				},
+			})
+		case strings.Contains(r.URL.Path, "/media/res-"):
+			if r.URL.Query().Get("alt") != "media" {
+				http.Error(w, "missing alt=media", http.StatusBadRequest)
+				return
+			}
+			_, _ = io.WriteString(w, "body of "+strings.TrimPrefix(r.URL.Path[strings.LastIndex(r.URL.Path, "/"):], "/"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	dir := t.TempDir()
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "chat", "attachments", "download", "spaces/s1/messages/m1", "--out-dir", dir}); err != nil {
+				t.Fatalf("download: %v", err)
+			}
+		})
+	})
+	var parsed struct {
+		Downloads []chatAttachmentDownload `json:"downloads"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil || len(parsed.Downloads) != 3 {
+		t.Fatalf("unexpected out=%q err=%v", out, err)
+	}
+	if parsed.Downloads[2].Skipped == "" || parsed.Downloads[2].DriveFileID != "drive-1" {
+		t.Fatalf("expected drive attachment to be skipped: %+v", parsed.Downloads[2])
+	}
+	for name, want := range map[string]string{"report.txt": "body of res-1", "report_2.txt": "body of res-2"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Fatalf("%s: got %q err=%v", name, got, err)
+		}
+	}
+}
@@ -516,6 +516,54 @@ func TestExecute_ClassroomMoreCommands_JSON(t *testing.T) {
 	})
 }
 
+func TestExecute_ClassroomRoster_NDJSONStreamsEachRole(t *testing.T) {
+	origNew := newClassroomService
+	t.Cleanup(func() { newClassroomService = origNew })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/students") && r.URL.Query().Get("pageToken") == "":
+			_ = json.NewEncoder(w).Encode(map[string]any{"students": []map[string]any{{"userId": "s1"}}, "nextPageToken": "p2"})
+		case strings.HasSuffix(r.URL.Path, "/students"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"students": []map[string]any{{"userId": "s2"}}})
+		case strings.HasSuffix(r.URL.Path, "/teachers"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"teachers": []map[string]any{{"userId": "t1", "courseId": "c1"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := classroom.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newClassroomService = func(context.Context, string) (*classroom.Service, error) { return svc, nil }
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--ndjson", "--account", "a@b.com", "classroom", "roster", "c1", "--all"}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	})
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var item classroomRosterItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		got = append(got, item.Role+":"+item.UserID)
+	}
+	if strings.Join(got, ",") != "teacher:t1,student:s1,student:s2" {
+		t.Fatalf("unexpected roster lines: %v\n%s", got, out)
+	}
+}
+
 func TestExecute_ClassroomValidationErrors(t *testing.T) {
 	origNew := newClassroomService
 	t.Cleanup(func() { newClassroomService = origNew })
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/fakegoogle"
+)
+
+func TestExecute_EndpointFakeGoogle_Tasks(t *testing.T) {
+	srv := httptest.NewServer(fakegoogle.New())
+	t.Cleanup(srv.Close)
+
+	run := func(account string, args ...string) map[string]any {
+		t.Helper()
+		out := captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				full := append([]string{"--json", "--endpoint", srv.URL, "--account", account}, args...)
+				if err := Execute(full); err != nil {
+					t.Fatalf("Execute %v: %v", args, err)
+				}
+			})
+		})
+		var parsed map[string]any
+		if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+			t.Fatalf("json parse: %v\nout=%q", err, out)
+		}
+		return parsed
+	}
+
+	created := run("a@example.com", "tasks", "lists", "create", "Chores")
+	listID, _ := created["tasklist"].(map[string]any)["id"].(string)
+	if listID == "" {
+		t.Fatalf("missing tasklist id: %v", created)
+	}
+	run("a@example.com", "tasks", "add", listID, "--title", "Water plants")
+
+	listed := run("a@example.com", "tasks", "list", listID)
+	items, _ := listed["tasks"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["title"] != "Water plants" {
+		t.Fatalf("unexpected tasks: %v", listed)
+	}
+
+	// State is per account.
+	other := run("b@example.com", "tasks", "lists")
+	if lists, _ := other["tasklists"].([]any); len(lists) != 1 {
+		t.Fatalf("expected only the default list for another account, got %v", other)
+	}
+}
+
+func TestExecute_EndpointRetriesTransientErrors(t *testing.T) {
+	fake := fakegoogle.New()
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		mu.Lock()
+		hits[key]++
+		n := hits[key]
+		mu.Unlock()
+		if n == 1 && (strings.HasSuffix(r.URL.Path, "/users/@me/lists") || strings.HasSuffix(r.URL.Path, "/messages/batchModify")) {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"error":{"code":503,"message":"backend"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	run := func(args ...string) {
+		t.Helper()
+		_ = captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				full := append([]string{"--json", "--endpoint", srv.URL, "--retries", "2", "--account", "a@example.com"}, args...)
+				if err := Execute(full); err != nil {
+					t.Fatalf("Execute %v: %v", args, err)
+				}
+			})
+		})
+	}
+
+	run("tasks", "lists")
+	run("gmail", "batch", "modify", "m1", "--add", "STARRED")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["GET /tasks/v1/users/@me/lists"] != 2 || hits["POST /gmail/v1/users/me/messages/batchModify"] != 2 {
+		t.Fatalf("expected each 503 to be retried once, got %v", hits)
+	}
+}
+
+func TestExecute_EndpointInvalid(t *testing.T) {
+	_ = captureStderr(t, func() {
+		err := Execute([]string{"--endpoint", "localhost:1234", "--account", "a@example.com", "tasks", "lists"})
+		if err == nil || ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+	})
+}
+
+func TestUseAPIEndpoint_PubSub(t *testing.T) {
+	var path, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	restore, err := useAPIEndpoint(srv.URL)
+	if err != nil {
+		t.Fatalf("useAPIEndpoint: %v", err)
+	}
+	t.Cleanup(restore)
+
+	ps, err := newPubSubService(context.Background())
+	if err != nil {
+		t.Fatalf("newPubSubService: %v", err)
+	}
+	if _, err := ps.Projects.Subscriptions.Acknowledge("projects/p/subscriptions/s", &pubsub.AcknowledgeRequest{AckIds: []string{"a1"}}).Do(); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if path != "/v1/projects/p/subscriptions/s:acknowledge" || auth != fakeAuthPrefix {
+		t.Fatalf("unexpected request path=%q auth=%q", path, auth)
+	}
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+// fanoutParallelism caps how many per-account runs are in flight at once.
+const fanoutParallelism = 4
+
+// fanoutResult is the captured outcome of one per-account run.
+type fanoutResult struct {
+	Account  string
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// runForAccount runs gog with args (which already select one account) and
+// captures its output. Each account runs in its own process because commands
+// write straight to os.Stdout.
+var runForAccount = func(ctx context.Context, args []string) (stdout, stderr []byte, exitCode int, err error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, 1, err
+	}
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe, args...) //nolint:gosec // re-invokes this binary
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	runErr := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+		return outBuf.Bytes(), errBuf.Bytes(), 0, nil
+	case errors.As(runErr, &exitErr):
+		return outBuf.Bytes(), errBuf.Bytes(), exitErr.ExitCode(), nil
+	default:
+		return outBuf.Bytes(), errBuf.Bytes(), 1, runErr
+	}
+}
+
+// multiAccountCommand is implemented by commands that serve several accounts
+// from one process, so --accounts/--all-accounts reach them instead of
+// running one child per account.
+type multiAccountCommand interface {
+	multiAccount()
+}
+
+func handlesOwnAccounts(kctx *kong.Context) bool {
+	if kctx == nil || kctx.Selected() == nil {
+		return false
+	}
+	target := kctx.Selected().Target
+	if !target.IsValid() || !target.CanAddr() {
+		return false
+	}
+	_, ok := target.Addr().Interface().(multiAccountCommand)
+	return ok
+}
+
+// fanoutAccounts resolves --accounts (emails or aliases) or, with
+// --all-accounts, every account with a stored token for the OAuth client.
+func fanoutAccounts(flags *RootFlags) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	add := func(email string) {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email != "" && !seen[email] {
+			seen[email] = true
+			out = append(out, email)
+		}
+	}
+
+	if flags.AllAccounts {
+		client, err := config.NormalizeClientNameOrDefault(flags.Client)
+		if err != nil {
+			return nil, err
+		}
+		store, err := openSecretsStoreForAccount()
+		if err != nil {
+			return nil, err
+		}
+		toks, err := store.ListTokens()
+		if err != nil {
+			return nil, err
+		}
+		for _, tok := range toks {
+			if tok.Client == client {
+				add(tok.Email)
+			}
+		}
+		sort.Strings(out)
+		if len(out) == 0 {
+			return nil, usage("--all-accounts: no stored tokens (run `gog auth add <email>`)")
+		}
+		return out, nil
+	}
+
+	for _, v := range splitCSV(flags.Accounts) {
+		resolved, ok, err := resolveAccountAlias(v)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			v = resolved
+		}
+		if !strings.Contains(v, "@") {
+			return nil, usagef("--accounts: unknown account or alias %q", v)
+		}
+		add(v)
+	}
+	if len(out) == 0 {
+		return nil, usage("--accounts: no accounts given")
+	}
+	return out, nil
+}
+
+// fanoutChildArgs removes the fan-out flags from args and pins the child to
+// one account. Children never prompt: confirmations need --force.
+func fanoutChildArgs(args []string, account string) []string {
+	out := []string{"--account", account, "--no-input"}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		switch {
+		case a == "--all-accounts", strings.HasPrefix(a, "--all-accounts="), strings.HasPrefix(a, "--accounts="):
+			continue
+		case a == "--accounts":
+			i++
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// runFanout runs the parsed command once per account, concurrently, and
+// merges the results in account order.
+func runFanout(ctx context.Context, args []string, accounts []string) error {
+	results := make([]fanoutResult, len(accounts))
+	sem := make(chan struct{}, fanoutParallelism)
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, account string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			stdout, stderr, code, err := runForAccount(ctx, fanoutChildArgs(args, account))
+			if err != nil {
+				stderr = append(stderr, []byte(err.Error()+"\n")...)
+				if code == 0 {
+					code = 1
+				}
+			}
+			results[i] = fanoutResult{Account: account, Stdout: stdout, Stderr: stderr, ExitCode: code}
+		}(i, account)
+	}
+	wg.Wait()
+
+	u := ui.FromContext(ctx)
+	for _, r := range results {
+		for _, line := range splitLines(r.Stderr) {
+			_, _ = fmt.Fprintf(os.Stderr, "[%s] %s\n", r.Account, line)
+		}
+	}
+
+	switch {
+	case outfmt.IsNDJSON(ctx):
+		if err := writeFanoutNDJSON(results); err != nil {
+			return err
+		}
+	case outfmt.IsJSON(ctx):
+		// Children already applied --select/--results-only.
+		plain := outfmt.WithJSONTransform(ctx, outfmt.JSONTransform{})
+		if err := outfmt.WriteJSON(plain, os.Stdout, mergeFanoutJSON(results)); err != nil {
+			return err
+		}
+	case outfmt.IsPlain(ctx):
+		for _, r := range results {
+			for _, line := range splitLines(r.Stdout) {
+				_, _ = fmt.Fprintf(os.Stdout, "%s\t%s\n", r.Account, line)
+			}
+		}
+	default:
+		for i, r := range results {
+			if i > 0 {
+				u.Out().Println("")
+			}
+			u.Out().Printf("== %s ==", r.Account)
+			_, _ = os.Stdout.Write(r.Stdout)
+		}
+	}
+
+	return fanoutExitError(results)
+}
+
+// fanoutExitError aggregates exit codes: success only if every account
+// succeeded, otherwise the highest child exit code.
+func fanoutExitError(results []fanoutResult) error {
+	code, failed := 0, 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+			code = max(code, r.ExitCode)
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &ExitError{Code: code, Err: fmt.Errorf("%d of %d accounts failed", failed, len(results))}
+}
+
+// mergeFanoutJSON combines per-account JSON documents. Array fields are
+// concatenated with an "account" field added to each item; everything else
+// (page tokens, single objects, errors, exit codes) is reported per account
+// under "accounts".
+func mergeFanoutJSON(results []fanoutResult) any {
+	merged := map[string]any{}
+	var topLevel []any
+	perAccount := make([]map[string]any, 0, len(results))
+
+	for _, r := range results {
+		entry := map[string]any{"account": r.Account, "exitCode": r.ExitCode}
+		if r.ExitCode != 0 {
+			if msg := lastLine(r.Stderr); msg != "" {
+				entry["error"] = msg
+			}
+		}
+		var doc any
+		if len(bytes.TrimSpace(r.Stdout)) > 0 {
+			if err := json.Unmarshal(r.Stdout, &doc); err != nil {
+				entry["output"] = string(r.Stdout)
+				doc = nil
+			}
+		}
+		switch v := doc.(type) {
+		case []any:
+			topLevel = append(topLevel, tagAccount(v, r.Account)...)
+		case map[string]any:
+			for k, val := range v {
+				if arr, ok := val.([]any); ok {
+					existing, _ := merged[k].([]any)
+					merged[k] = append(existing, tagAccount(arr, r.Account)...)
+					continue
+				}
+				entry[k] = val
+			}
+		case nil:
+		default:
+			entry["result"] = v
+		}
+		perAccount = append(perAccount, entry)
+	}
+
+	// Top-level arrays (e.g. --results-only output) merge under "results".
+	if topLevel != nil {
+		merged["results"] = topLevel
+	}
+	merged["accounts"] = perAccount
+	return merged
+}
+
+func tagAccount(items []any, account string) []any {
+	out := make([]any, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			tagged := make(map[string]any, len(obj)+1)
+			for k, v := range obj {
+				tagged[k] = v
+			}
+			tagged["account"] = account
+			out = append(out, tagged)
+			continue
+		}
+		out = append(out, map[string]any{"account": account, "value": item})
+	}
+	return out
+}
+
+// writeFanoutNDJSON re-emits each child's JSON lines with an "account" field.
+// Lines that aren't objects (or aren't JSON) are wrapped as {"value": ...}.
+func writeFanoutNDJSON(results []fanoutResult) error {
+	enc := json.NewEncoder(os.Stdout)
+	for _, r := range results {
+		for _, line := range splitLines(r.Stdout) {
+			var v any
+			if err := json.Unmarshal([]byte(line), &v); err != nil {
+				v = line
+			}
+			if err := enc.Encode(tagAccount([]any{v}, r.Account)[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func splitLines(b []byte) []string {
+	var out []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if line := strings.TrimRight(sc.Text(), "\r"); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func lastLine(b []byte) string {
+	lines := splitLines(b)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jibankumarpanda/gogcli/internal/secrets"
+)
+
+func TestFanoutChildArgs(t *testing.T) {
+	got := fanoutChildArgs([]string{"--json", "--accounts", "a@x.com,b@x.com", "gmail", "search", "is:unread", "--all-accounts", "--", "--accounts"}, "a@x.com")
+	want := []string{"--account", "a@x.com", "--no-input", "--json", "gmail", "search", "is:unread", "--", "--accounts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestMergeFanoutJSON(t *testing.T) {
+	merged := mergeFanoutJSON([]fanoutResult{
+		{Account: "a@x.com", Stdout: []byte(`{"messages":[{"id":"1"}],"nextPageToken":"p2"}`)},
+		{Account: "b@x.com", Stdout: []byte(`{"messages":[{"id":"2"},{"id":"3"}]}`)},
+		{Account: "c@x.com", Stderr: []byte("warning\nauth failed\n"), ExitCode: 4},
+	}).(map[string]any)
+
+	msgs := merged["messages"].([]any)
+	if len(msgs) != 3 || msgs[0].(map[string]any)["account"] != "a@x.com" || msgs[2].(map[string]any)["account"] != "b@x.com" {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	accounts := merged["accounts"].([]map[string]any)
+	if accounts[0]["nextPageToken"] != "p2" || accounts[2]["error"] != "auth failed" || accounts[2]["exitCode"] != 4 {
+		t.Fatalf("unexpected per-account entries: %v", accounts)
+	}
+}
+
+func TestWriteFanoutNDJSON_NonObjectLines(t *testing.T) {
+	out := captureStdout(t, func() {
+		if err := writeFanoutNDJSON([]fanoutResult{
+			{Account: "a@x.com", Stdout: []byte("{\"id\":\"1\"}\nnull\n42\n[1]\nnot json\n")},
+		}); err != nil {
+			t.Fatalf("writeFanoutNDJSON: %v", err)
+		}
+	})
+
+	want := []string{
+		`{"account":"a@x.com","id":"1"}`,
+		`{"account":"a@x.com","value":null}`,
+		`{"account":"a@x.com","value":42}`,
+		`{"account":"a@x.com","value":[1]}`,
+		`{"account":"a@x.com","value":"not json"}`,
+	}
+	if got := strings.Split(strings.TrimSpace(out), "\n"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestExecute_AccountsFanout(t *testing.T) {
+	origRun := runForAccount
+	origStore := openSecretsStoreForAccount
+	t.Cleanup(func() {
+		runForAccount = origRun
+		openSecretsStoreForAccount = origStore
+	})
+	openSecretsStoreForAccount = func() (secrets.Store, error) {
+		return &fakeSecretsStore{tokens: []secrets.Token{
+			{Client: "default", Email: "b@x.com"},
+			{Client: "default", Email: "a@x.com"},
+			{Client: "other", Email: "c@x.com"},
+		}}, nil
+	}
+
+	var (
+		mu    sync.Mutex
+		calls = map[string][]string{}
+	)
+	runForAccount = func(_ context.Context, args []string) ([]byte, []byte, int, error) {
+		account := args[1]
+		mu.Lock()
+		calls[account] = args
+		mu.Unlock()
+		if account == "b@x.com" {
+			return nil, []byte("boom\n"), 8, nil
+		}
+		return []byte(`{"threads":[{"id":"t1"}]}`), nil, 0, nil
+	}
+
+	var execErr error
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			execErr = Execute([]string{"--json", "--all-accounts", "gmail", "search", "is:unread"})
+		})
+	})
+	if ExitCode(execErr) != 8 {
+		t.Fatalf("expected aggregated exit code 8, got %v", execErr)
+	}
+	if len(calls) != 2 || calls["c@x.com"] != nil {
+		t.Fatalf("expected runs for the default client's accounts only, got %v", calls)
+	}
+	if strings.Join(calls["a@x.com"], " ") != "--account a@x.com --no-input --json gmail search is:unread" {
+		t.Fatalf("unexpected child args: %q", calls["a@x.com"])
+	}
+
+	var parsed struct {
+		Threads []struct {
+			ID      string `json:"id"`
+			Account string `json:"account"`
+		} `json:"threads"`
+		Accounts []struct {
+			Account  string `json:"account"`
+			ExitCode int    `json:"exitCode"`
+			Error    string `json:"error"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if len(parsed.Threads) != 1 || parsed.Threads[0].Account != "a@x.com" {
+		t.Fatalf("unexpected threads: %+v", parsed.Threads)
+	}
+	if len(parsed.Accounts) != 2 || parsed.Accounts[1].Account != "b@x.com" || parsed.Accounts[1].Error != "boom" {
+		t.Fatalf("unexpected accounts: %+v", parsed.Accounts)
+	}
+}
+
+func TestExecute_AccountsConflictsWithAccount(t *testing.T) {
+	_ = captureStderr(t, func() {
+		err := Execute([]string{"--account", "a@x.com", "--accounts", "b@x.com", "tasks", "lists"})
+		if ExitCode(err) != 2 {
+			t.Fatalf("expected usage error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// errFileLocked is returned by lockFile when wait is false and another open
+// of the file holds the lock.
+var errFileLocked = errors.New("file is locked")
+
+const fileLockPollInterval = 50 * time.Millisecond
+
+// lockFileContext waits for the lock on path until ctx is cancelled. A
+// blocking lock can't be interrupted, so it polls instead.
+func lockFileContext(ctx context.Context, path string) (*os.File, error) {
+	for {
+		f, err := lockFile(path, false)
+		if !errors.Is(err, errFileLocked) {
+			return f, err
+		}
+		timer := time.NewTimer(fileLockPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
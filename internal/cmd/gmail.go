@@ -26,11 +26,14 @@ type GmailCmd struct {
 	Attachment GmailAttachmentCmd `cmd:"" name:"attachment" group:"Read" help:"Download a single attachment"`
 	URL        GmailURLCmd        `cmd:"" name:"url" group:"Read" help:"Print Gmail web URLs for threads"`
 	History    GmailHistoryCmd    `cmd:"" name:"history" group:"Read" help:"Gmail history"`
+	Export     GmailExportCmd     `cmd:"" name:"export" group:"Read" help:"Back up messages to mbox or Maildir (incremental)"`
+	Import     GmailImportCmd     `cmd:"" name:"import" group:"Write" help:"Import messages from an mbox file or .eml directory"`
 
 	Labels GmailLabelsCmd `cmd:"" name:"labels" aliases:"label" group:"Organize" help:"Label operations"`
 	Batch  GmailBatchCmd  `cmd:"" name:"batch" group:"Organize" help:"Batch operations"`
 
 	Send   GmailSendCmd   `cmd:"" name:"send" group:"Write" help:"Send an email"`
+	Merge  GmailMergeCmd  `cmd:"" name:"merge" aliases:"mail-merge" group:"Write" help:"Send templated messages per row of a CSV file or sheet"`
 	Track  GmailTrackCmd  `cmd:"" name:"track" group:"Write" help:"Email open tracking"`
 	Drafts GmailDraftsCmd `cmd:"" name:"drafts" aliases:"draft" group:"Write" help:"Draft operations"`
 
@@ -98,6 +101,24 @@ func (c *GmailSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Threads, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		idToName, labelsErr := fetchLabelIDToName(svc)
+		if labelsErr != nil {
+			return labelsErr
+		}
+		loc, locErr := resolveOutputLocation(c.Timezone, c.Local)
+		if locErr != nil {
+			return locErr
+		}
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*gmail.Thread) error {
+			items, detailsErr := fetchThreadDetails(ctx, svc, page, idToName, c.Oldest, loc)
+			if detailsErr != nil {
+				return detailsErr
+			}
+			return writeNDJSON(ctx, items)
+		})
+	}
+
 	var threads []*gmail.Thread
 	nextPageToken := ""
 	if c.All {
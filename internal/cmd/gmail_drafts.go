@@ -54,6 +54,12 @@ func (c *GmailDraftsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Drafts, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*gmail.Draft) error {
+			return writeNDJSON(ctx, draftListItems(page))
+		})
+	}
+
 	var drafts []*gmail.Draft
 	nextPageToken := ""
 	if c.All {
@@ -70,23 +76,7 @@ func (c *GmailDraftsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 	}
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			ID        string `json:"id"`
-			MessageID string `json:"messageId,omitempty"`
-			ThreadID  string `json:"threadId,omitempty"`
-		}
-		items := make([]item, 0, len(drafts))
-		for _, d := range drafts {
-			if d == nil {
-				continue
-			}
-			var msgID, threadID string
-			if d.Message != nil {
-				msgID = d.Message.Id
-				threadID = d.Message.ThreadId
-			}
-			items = append(items, item{ID: d.Id, MessageID: msgID, ThreadID: threadID})
-		}
+		items := draftListItems(drafts)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"drafts":        items,
 			"nextPageToken": nextPageToken,
@@ -599,3 +589,25 @@ func (c *GmailDraftsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error
 	}
 	return writeDraftResult(ctx, u, draft, threadID)
 }
+
+type draftListItem struct {
+	ID        string `json:"id"`
+	MessageID string `json:"messageId,omitempty"`
+	ThreadID  string `json:"threadId,omitempty"`
+}
+
+func draftListItems(drafts []*gmail.Draft) []draftListItem {
+	items := make([]draftListItem, 0, len(drafts))
+	for _, d := range drafts {
+		if d == nil {
+			continue
+		}
+		var msgID, threadID string
+		if d.Message != nil {
+			msgID = d.Message.Id
+			threadID = d.Message.ThreadId
+		}
+		items = append(items, draftListItem{ID: d.Id, MessageID: msgID, ThreadID: threadID})
+	}
+	return items
+}
@@ -0,0 +1,750 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	gmailExportFormatMbox    = "mbox"
+	gmailExportFormatMaildir = "maildir"
+
+	gmailExportStateVersion = 1
+	// Progress is flushed to the state file every N messages so an interrupted
+	// export resumes instead of starting over.
+	gmailExportSaveEvery = 100
+	gmailExportListPage  = 500
+)
+
+type GmailExportCmd struct {
+	Query       string `name:"query" aliases:"q" help:"Gmail search query (default: all mail except spam/trash)"`
+	Format      string `name:"format" help:"Archive format: mbox|maildir" default:"mbox" enum:"mbox,maildir"`
+	Out         string `name:"out" help:"Output mbox file or Maildir directory" required:""`
+	Concurrency int    `name:"concurrency" help:"Parallel message fetches" default:"10"`
+	Full        bool   `name:"full" help:"Ignore saved progress and write a fresh archive"`
+	State       string `name:"state" help:"State file path (default: gogcli state dir)"`
+}
+
+// gmailExportState tracks what is already in the archive and the historyId to
+// continue from, so later runs only touch new or relabelled messages. For mbox,
+// MboxSize is the file length covered by Messages; anything appended after the
+// last save is truncated away on resume.
+type gmailExportState struct {
+	Version   int                         `json:"version"`
+	Account   string                      `json:"account"`
+	Query     string                      `json:"query"`
+	Format    string                      `json:"format"`
+	Out       string                      `json:"out"`
+	HistoryID string                      `json:"historyId,omitempty"`
+	UpdatedAt string                      `json:"updatedAt,omitempty"`
+	MboxSize  int64                       `json:"mboxSize,omitempty"`
+	Messages  map[string]gmailExportEntry `json:"messages"`
+}
+
+type gmailExportEntry struct {
+	Labels []string `json:"labels,omitempty"`
+	// Paths are Maildir files relative to the archive root.
+	Paths []string `json:"paths,omitempty"`
+}
+
+func (c *GmailExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	out := strings.TrimSpace(c.Out)
+	if out == "" {
+		return usage("required: --out")
+	}
+	out, err = config.ExpandPath(out)
+	if err != nil {
+		return err
+	}
+	out, err = filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	if format == "" {
+		format = gmailExportFormatMbox
+	}
+	if format != gmailExportFormatMbox && format != gmailExportFormatMaildir {
+		return usagef("invalid --format %q (use mbox|maildir)", c.Format)
+	}
+	if c.Concurrency <= 0 {
+		return usage("--concurrency must be > 0")
+	}
+	query := strings.TrimSpace(c.Query)
+
+	statePath := strings.TrimSpace(c.State)
+	if statePath == "" {
+		statePath, err = gmailExportStatePath(account, out)
+	} else {
+		statePath, err = config.ExpandPath(statePath)
+	}
+	if err != nil {
+		return err
+	}
+	state, err := loadGmailExportState(statePath)
+	if err != nil {
+		return err
+	}
+	if c.Full {
+		state = gmailExportState{}
+	}
+	if len(state.Messages) > 0 && (state.Format != format || state.Query != query) {
+		return usagef("%s was exported with --format %s --query %q; use --full to start over", out, state.Format, state.Query)
+	}
+	state.Version = gmailExportStateVersion
+	state.Account = account
+	state.Query = query
+	state.Format = format
+	state.Out = out
+	if state.Messages == nil {
+		state.Messages = map[string]gmailExportEntry{}
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	// Capture the mailbox position before listing so nothing that changes
+	// mid-export is missed by the next incremental run.
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	idToName, err := fetchLabelIDToName(svc)
+	if err != nil {
+		return err
+	}
+
+	var (
+		newIDs      []string
+		changedIDs  []string
+		incremental bool
+	)
+	if state.HistoryID != "" {
+		changes, histErr := gmailExportHistoryChanges(ctx, svc, state.HistoryID)
+		switch {
+		case histErr == nil:
+			incremental = true
+			var matching map[string]bool
+			if query != "" {
+				ids, listErr := gmailExportListIDs(ctx, svc, query)
+				if listErr != nil {
+					return listErr
+				}
+				matching = make(map[string]bool, len(ids))
+				for _, id := range ids {
+					matching[id] = true
+				}
+			}
+			for _, id := range changes {
+				if _, ok := state.Messages[id]; ok {
+					changedIDs = append(changedIDs, id)
+				} else if matching == nil || matching[id] {
+					newIDs = append(newIDs, id)
+				}
+			}
+		case isStaleHistoryError(histErr):
+			u.Err().Printf("historyId %s expired; rescanning %q", state.HistoryID, query)
+		default:
+			return histErr
+		}
+	}
+	if !incremental {
+		ids, listErr := gmailExportListIDs(ctx, svc, query)
+		if listErr != nil {
+			return listErr
+		}
+		for _, id := range ids {
+			if _, ok := state.Messages[id]; !ok {
+				newIDs = append(newIDs, id)
+			}
+		}
+	}
+
+	if err := dryRunExit(ctx, flags, "gmail.export", map[string]any{
+		"out":         out,
+		"format":      format,
+		"query":       query,
+		"incremental": incremental,
+		"new":         len(newIDs),
+		"changed":     len(changedIDs),
+	}); err != nil {
+		return err
+	}
+
+	archive, err := openGmailArchive(format, out, c.Full, state.MboxSize)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	save := func() error {
+		if mbox, ok := archive.(*gmailMbox); ok {
+			state.MboxSize = mbox.size
+		}
+		state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		return saveGmailExportState(statePath, state)
+	}
+	// Record where this run starts appending before writing anything.
+	if format == gmailExportFormatMbox {
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	exported := 0
+	err = fetchGmailMessages(ctx, svc, newIDs, "raw", c.Concurrency, func(msg *gmail.Message) error {
+		raw, decodeErr := decodeGmailRaw(msg.Raw)
+		if decodeErr != nil {
+			return fmt.Errorf("message %s: %w", msg.Id, decodeErr)
+		}
+		labels := gmailLabelNames(msg.LabelIds, idToName)
+		paths, writeErr := archive.Write(msg, labels, raw)
+		if writeErr != nil {
+			return fmt.Errorf("message %s: %w", msg.Id, writeErr)
+		}
+		state.Messages[msg.Id] = gmailExportEntry{Labels: labels, Paths: paths}
+		exported++
+		if exported%gmailExportSaveEvery == 0 {
+			if saveErr := save(); saveErr != nil {
+				return saveErr
+			}
+			u.Err().Printf("exported %d/%d", exported, len(newIDs))
+		}
+		return nil
+	})
+	if err != nil {
+		_ = save()
+		return err
+	}
+
+	updated, skipped := 0, 0
+	if format == gmailExportFormatMaildir {
+		md := archive.(*gmailMaildir)
+		err = fetchGmailMessages(ctx, svc, changedIDs, "minimal", c.Concurrency, func(msg *gmail.Message) error {
+			labels := gmailLabelNames(msg.LabelIds, idToName)
+			paths, moved, relabelErr := md.Relabel(msg, labels, state.Messages[msg.Id].Paths)
+			if relabelErr != nil {
+				return fmt.Errorf("message %s: %w", msg.Id, relabelErr)
+			}
+			state.Messages[msg.Id] = gmailExportEntry{Labels: labels, Paths: paths}
+			if moved {
+				updated++
+			}
+			return nil
+		})
+		if err != nil {
+			_ = save()
+			return err
+		}
+	} else {
+		// mbox is append-only; label changes on archived messages are not rewritten.
+		skipped = len(changedIDs)
+	}
+
+	state.HistoryID = formatHistoryID(profile.HistoryId)
+	if err := save(); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"out":         out,
+			"format":      format,
+			"incremental": incremental,
+			"exported":    exported,
+			"updated":     updated,
+			"skipped":     skipped,
+			"total":       len(state.Messages),
+			"historyId":   state.HistoryID,
+		})
+	}
+	u.Out().Printf("out\t%s", out)
+	u.Out().Printf("format\t%s", format)
+	u.Out().Printf("exported\t%d", exported)
+	if format == gmailExportFormatMaildir {
+		u.Out().Printf("updated\t%d", updated)
+	} else if skipped > 0 {
+		u.Out().Printf("skipped\t%d (label changes; mbox is append-only)", skipped)
+	}
+	u.Out().Printf("total\t%d", len(state.Messages))
+	u.Out().Printf("historyId\t%s", state.HistoryID)
+	return nil
+}
+
+func gmailExportStatePath(account, out string) (string, error) {
+	dir, err := config.EnsureGmailExportDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(out))
+	name := fmt.Sprintf("%s-%s.json", sanitizeAccountForPath(account), hex.EncodeToString(sum[:8]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadGmailExportState(path string) (gmailExportState, error) {
+	var state gmailExportState
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("read export state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveGmailExportState(path string, state gmailExportState) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func gmailExportListIDs(ctx context.Context, svc *gmail.Service, query string) ([]string, error) {
+	fetch := func(pageToken string) ([]string, string, error) {
+		call := svc.Users.Messages.List("me").
+			MaxResults(gmailExportListPage).
+			Fields("messages(id),nextPageToken").
+			Context(ctx)
+		if query != "" {
+			call = call.Q(query)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		ids := make([]string, 0, len(resp.Messages))
+		for _, m := range resp.Messages {
+			if m != nil && m.Id != "" {
+				ids = append(ids, m.Id)
+			}
+		}
+		return ids, resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+// gmailExportHistoryChanges returns message IDs added or relabelled since
+// startHistoryID. Deletions are ignored: the archive keeps what it has.
+func gmailExportHistoryChanges(ctx context.Context, svc *gmail.Service, startHistoryID string) ([]string, error) {
+	startID, err := parseHistoryID(startHistoryID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	fetch := func(pageToken string) ([]string, string, error) {
+		call := svc.Users.History.List("me").StartHistoryId(startID).MaxResults(gmailExportListPage).Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		var ids []string
+		for _, id := range collectHistoryMessageIDs(resp).FetchIDs {
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return ids, resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+// fetchGmailMessages fetches ids with bounded concurrency and hands each
+// message to handle on the calling goroutine, so handle needs no locking.
+func fetchGmailMessages(ctx context.Context, svc *gmail.Service, ids []string, format string, concurrency int, handle func(*gmail.Message) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		msg *gmail.Message
+		err error
+	}
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				msg, err := svc.Users.Messages.Get("me", id).Format(format).Context(ctx).Do()
+				if err != nil {
+					if isNotFoundAPIError(err) {
+						// Deleted between listing and fetching.
+						continue
+					}
+					err = fmt.Errorf("message %s: %w", id, err)
+				}
+				select {
+				case results <- result{msg: msg, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, id := range ids {
+			select {
+			case jobs <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var firstErr error
+	for r := range results {
+		if firstErr != nil {
+			continue
+		}
+		if r.err == nil {
+			r.err = handle(r.msg)
+		}
+		if r.err != nil {
+			firstErr = r.err
+			cancel()
+		}
+	}
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+func decodeGmailRaw(raw string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(raw, "="))
+}
+
+func gmailLabelNames(labelIDs []string, idToName map[string]string) []string {
+	names := make([]string, 0, len(labelIDs))
+	for _, id := range labelIDs {
+		if n, ok := idToName[id]; ok {
+			names = append(names, n)
+		} else {
+			names = append(names, id)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withGmailExportHeaders prepends Takeout-style X-GM-THRID / X-Gmail-Labels
+// headers and normalizes line endings to LF.
+func withGmailExportHeaders(msg *gmail.Message, labels []string, raw []byte) []byte {
+	var b bytes.Buffer
+	if msg.ThreadId != "" {
+		fmt.Fprintf(&b, "X-GM-THRID: %s\n", msg.ThreadId)
+	}
+	fmt.Fprintf(&b, "X-Gmail-Labels: %s\n", strings.Join(labels, ","))
+	b.Write(bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n")))
+	return b.Bytes()
+}
+
+type gmailArchive interface {
+	// Write stores a message and returns archive-relative paths (Maildir only).
+	Write(msg *gmail.Message, labels []string, raw []byte) ([]string, error)
+	Close() error
+}
+
+// openGmailArchive opens the export target. For mbox, a non-zero size from
+// saved state cuts off messages appended after the last save, so a resumed
+// export does not archive them twice.
+func openGmailArchive(format, out string, truncate bool, size int64) (gmailArchive, error) {
+	if format == gmailExportFormatMaildir {
+		if err := os.MkdirAll(out, 0o700); err != nil {
+			return nil, err
+		}
+		return &gmailMaildir{root: out}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o700); err != nil {
+		return nil, err
+	}
+	mode := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if truncate {
+		mode |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(out, mode, 0o600) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	cur := info.Size()
+	if !truncate && size > 0 {
+		if cur < size {
+			_ = f.Close()
+			return nil, usagef("%s is shorter than the saved export state; use --full to start over", out)
+		}
+		if cur > size {
+			if err := f.Truncate(size); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			cur = size
+		}
+	}
+	return &gmailMbox{f: f, size: cur}, nil
+}
+
+type gmailMbox struct {
+	f *os.File
+	// size counts fully written messages only; a failed write leaves a
+	// partial entry that the next run truncates.
+	size int64
+}
+
+func (m *gmailMbox) Write(msg *gmail.Message, labels []string, raw []byte) ([]string, error) {
+	entry := mboxEntry(msg, labels, raw)
+	if _, err := m.f.Write(entry); err != nil {
+		return nil, err
+	}
+	m.size += int64(len(entry))
+	return nil, nil
+}
+
+func (m *gmailMbox) Close() error {
+	return m.f.Close()
+}
+
+// mboxEntry renders one mboxrd message: a "From " separator, the message with
+// any ">*From " body lines quoted once more, and a trailing blank line.
+func mboxEntry(msg *gmail.Message, labels []string, raw []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From MAILER-DAEMON %s\n", gmailInternalDate(msg).UTC().Format("Mon Jan _2 15:04:05 2006"))
+	lines := bytes.SplitAfter(withGmailExportHeaders(msg, labels, raw), []byte("\n"))
+	for _, line := range lines {
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			b.WriteByte('>')
+		}
+		b.Write(line)
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func gmailInternalDate(msg *gmail.Message) time.Time {
+	if msg == nil || msg.InternalDate <= 0 {
+		return time.Unix(0, 0)
+	}
+	return time.UnixMilli(msg.InternalDate)
+}
+
+// gmailMaildir stores one copy of each message per label folder. Nested
+// labels ("Work/Projects") become nested folders.
+type gmailMaildir struct {
+	root string
+}
+
+func (m *gmailMaildir) Write(msg *gmail.Message, labels []string, raw []byte) ([]string, error) {
+	content := withGmailExportHeaders(msg, labels, raw)
+	paths := gmailMaildirPaths(msg, labels)
+	for _, rel := range paths {
+		if err := m.writeFile(rel, content); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// Relabel moves an archived message to match its current labels, reusing the
+// content already on disk. It reports whether anything changed.
+func (m *gmailMaildir) Relabel(msg *gmail.Message, labels []string, oldPaths []string) ([]string, bool, error) {
+	paths := gmailMaildirPaths(msg, labels)
+	if strings.Join(paths, "\n") == strings.Join(oldPaths, "\n") {
+		return paths, false, nil
+	}
+	var content []byte
+	for _, rel := range oldPaths {
+		b, err := os.ReadFile(filepath.Join(m.root, filepath.FromSlash(rel))) //nolint:gosec // archive-relative path
+		if err == nil {
+			content = b
+			break
+		}
+	}
+	if content == nil {
+		return oldPaths, false, errors.New("archived copy not found; re-run with --full")
+	}
+	content = replaceGmailLabelsHeader(content, labels)
+
+	keep := map[string]bool{}
+	for _, rel := range paths {
+		keep[rel] = true
+		if err := m.writeFile(rel, content); err != nil {
+			return nil, false, err
+		}
+	}
+	for _, rel := range oldPaths {
+		if !keep[rel] {
+			if err := os.Remove(filepath.Join(m.root, filepath.FromSlash(rel))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, false, err
+			}
+		}
+	}
+	return paths, true, nil
+}
+
+func (m *gmailMaildir) Close() error {
+	return nil
+}
+
+func (m *gmailMaildir) writeFile(rel string, content []byte) error {
+	dest := filepath.Join(m.root, filepath.FromSlash(rel))
+	folder := filepath.Dir(filepath.Dir(dest))
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(folder, sub), 0o700); err != nil {
+			return err
+		}
+	}
+	tmp := filepath.Join(folder, "tmp", filepath.Base(dest))
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func replaceGmailLabelsHeader(content []byte, labels []string) []byte {
+	const header = "X-Gmail-Labels: "
+	start := bytes.Index(content, []byte(header))
+	if start < 0 {
+		return content
+	}
+	end := bytes.IndexByte(content[start:], '\n')
+	if end < 0 {
+		return content
+	}
+	var b bytes.Buffer
+	b.Write(content[:start])
+	b.WriteString(header + strings.Join(labels, ","))
+	b.Write(content[start+end:])
+	return b.Bytes()
+}
+
+// gmailMaildirPaths maps a message to "<folder>/cur/<name>" for each folder label.
+func gmailMaildirPaths(msg *gmail.Message, labels []string) []string {
+	name := fmt.Sprintf("%d.%s.gog:2,%s", gmailInternalDate(msg).Unix(), msg.Id, gmailMaildirFlags(msg.LabelIds))
+	folders := gmailMaildirFolders(labels)
+	paths := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		paths = append(paths, folder+"/cur/"+name)
+	}
+	return paths
+}
+
+func gmailMaildirFlags(labelIDs []string) string {
+	draft, flagged, seen := false, false, true
+	for _, id := range labelIDs {
+		switch id {
+		case "DRAFT":
+			draft = true
+		case "STARRED":
+			flagged = true
+		case "UNREAD":
+			seen = false
+		}
+	}
+	// Maildir flags must be in ASCII order.
+	var b strings.Builder
+	if draft {
+		b.WriteByte('D')
+	}
+	if flagged {
+		b.WriteByte('F')
+	}
+	if seen {
+		b.WriteByte('S')
+	}
+	return b.String()
+}
+
+func gmailMaildirFolders(labels []string) []string {
+	var folders []string
+	seen := map[string]bool{}
+	for _, label := range labels {
+		switch {
+		case label == "UNREAD", label == "STARRED", label == "IMPORTANT", strings.HasPrefix(label, "CATEGORY_"):
+			// Flags and tabs, not folders.
+			continue
+		}
+		parts := strings.Split(label, "/")
+		clean := make([]string, 0, len(parts))
+		for _, p := range parts {
+			p = strings.TrimSpace(p)
+			p = strings.NewReplacer("\\", "_", ":", "_").Replace(p)
+			if p == "" || p == "." || p == ".." {
+				p = "_"
+			}
+			clean = append(clean, p)
+		}
+		folder := strings.Join(clean, "/")
+		if !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+	if len(folders) == 0 {
+		folders = append(folders, "Archive")
+	}
+	sort.Strings(folders)
+	return folders
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func TestMboxEntry_QuotesFromLines(t *testing.T) {
+	msg := &gmail.Message{Id: "m1", ThreadId: "t1", InternalDate: 1700000000000}
+	raw := []byte("Subject: hi\r\n\r\nFrom the top\r\n>From quoted\r\nbye")
+
+	got := string(mboxEntry(msg, []string{"INBOX", "Work"}, raw))
+	want := "From MAILER-DAEMON Tue Nov 14 22:13:20 2023\n" +
+		"X-GM-THRID: t1\n" +
+		"X-Gmail-Labels: INBOX,Work\n" +
+		"Subject: hi\n\n" +
+		">From the top\n" +
+		">>From quoted\n" +
+		"bye\n\n"
+	if got != want {
+		t.Fatalf("unexpected mbox entry:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestOpenGmailArchive_MboxResumesAtSavedSize(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "mail.mbox")
+	saved := "From a\n\n"
+	if err := os.WriteFile(out, []byte(saved+"From partial"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	archive, err := openGmailArchive(gmailExportFormatMbox, out, false, int64(len(saved)))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	msg := &gmail.Message{Id: "m1", InternalDate: 1700000000000}
+	if _, err := archive.Write(msg, nil, []byte("Subject: hi\n\nbody\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	size := archive.(*gmailMbox).size
+	if err := archive.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if want := saved + string(mboxEntry(msg, nil, []byte("Subject: hi\n\nbody\n"))); string(b) != want || size != int64(len(want)) {
+		t.Fatalf("unexpected mbox (size %d):\n%q\nwant:\n%q", size, string(b), want)
+	}
+
+	if _, err := openGmailArchive(gmailExportFormatMbox, out, false, size+1); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for a shorter mbox, got %v", err)
+	}
+}
+
+func TestGmailMaildirPaths(t *testing.T) {
+	msg := &gmail.Message{Id: "m1", InternalDate: 1700000000000, LabelIds: []string{"UNREAD", "STARRED", "INBOX", "Label_1"}}
+
+	got := gmailMaildirPaths(msg, []string{"INBOX", "STARRED", "UNREAD", "Work/../Projects"})
+	want := []string{
+		"INBOX/cur/1700000000.m1.gog:2,F",
+		"Work/_/Projects/cur/1700000000.m1.gog:2,F",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected paths: %v", got)
+	}
+
+	if got := gmailMaildirPaths(&gmail.Message{Id: "m2"}, []string{"IMPORTANT"}); len(got) != 1 || got[0] != "Archive/cur/0.m2.gog:2,S" {
+		t.Fatalf("unexpected archive path: %v", got)
+	}
+}
+
+type fakeGmailMailbox struct {
+	mu        sync.Mutex
+	historyID string
+	messages  map[string]*gmail.Message
+	history   []map[string]any
+}
+
+func (f *fakeGmailMailbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	p := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+	switch {
+	case p == "/profile":
+		_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": f.historyID})
+	case p == "/labels":
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+			{"id": "INBOX", "name": "INBOX"},
+			{"id": "UNREAD", "name": "UNREAD"},
+			{"id": "Label_1", "name": "Work"},
+		}})
+	case p == "/messages":
+		var list []map[string]any
+		for id := range f.messages {
+			list = append(list, map[string]any{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"messages": list})
+	case strings.HasPrefix(p, "/messages/"):
+		msg, ok := f.messages[strings.TrimPrefix(p, "/messages/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(msg)
+	case p == "/history":
+		_ = json.NewEncoder(w).Encode(map[string]any{"history": f.history, "historyId": f.historyID})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGmailMailbox) add(id string, labels []string, body string) {
+	raw := "Message-ID: <" + id + "@example.com>\r\nSubject: " + id + "\r\n\r\n" + body + "\r\n"
+	f.messages[id] = &gmail.Message{
+		Id:           id,
+		ThreadId:     "t-" + id,
+		LabelIds:     labels,
+		InternalDate: 1700000000000,
+		Raw:          base64.RawURLEncoding.EncodeToString([]byte(raw)),
+	}
+}
+
+func TestGmailExportCmd_MaildirIncremental(t *testing.T) {
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+
+	fake := &fakeGmailMailbox{historyID: "100", messages: map[string]*gmail.Message{}}
+	fake.add("m1", []string{"INBOX", "UNREAD"}, "first")
+	fake.add("m2", []string{"Label_1"}, "second")
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+
+	out := filepath.Join(t.TempDir(), "archive")
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+	flags := &RootFlags{Account: "a@b.com"}
+
+	run := func() map[string]any {
+		t.Helper()
+		stdout := captureStdout(t, func() {
+			cmd := &GmailExportCmd{Format: gmailExportFormatMaildir, Out: out, Concurrency: 2, State: statePath}
+			if runErr := cmd.Run(ctx, flags); runErr != nil {
+				t.Fatalf("Run: %v", runErr)
+			}
+		})
+		var parsed map[string]any
+		if jsonErr := json.Unmarshal([]byte(stdout), &parsed); jsonErr != nil {
+			t.Fatalf("json: %v (%q)", jsonErr, stdout)
+		}
+		return parsed
+	}
+
+	first := run()
+	if first["exported"] != float64(2) || first["incremental"] != false || first["historyId"] != "100" {
+		t.Fatalf("unexpected first run: %v", first)
+	}
+	inbox := filepath.Join(out, "INBOX", "cur", "1700000000.m1.gog:2,")
+	b, err := os.ReadFile(inbox)
+	if err != nil {
+		t.Fatalf("expected inbox copy: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "X-GM-THRID: t-m1\nX-Gmail-Labels: INBOX,UNREAD\n") {
+		t.Fatalf("unexpected headers: %q", string(b))
+	}
+	if _, err := os.Stat(filepath.Join(out, "Work", "cur", "1700000000.m2.gog:2,S")); err != nil {
+		t.Fatalf("expected Work copy: %v", err)
+	}
+
+	// m1 is read and archived, m3 arrives.
+	fake.mu.Lock()
+	fake.historyID = "120"
+	fake.messages["m1"].LabelIds = []string{"Label_1"}
+	fake.add("m3", []string{"INBOX"}, "third")
+	fake.history = []map[string]any{
+		{"id": "110", "labelsRemoved": []map[string]any{{"message": map[string]any{"id": "m1"}, "labelIds": []string{"INBOX", "UNREAD"}}}},
+		{"id": "111", "messagesAdded": []map[string]any{{"message": map[string]any{"id": "m3"}}}},
+	}
+	fake.mu.Unlock()
+
+	second := run()
+	if second["exported"] != float64(1) || second["updated"] != float64(1) || second["incremental"] != true || second["total"] != float64(3) {
+		t.Fatalf("unexpected second run: %v", second)
+	}
+	if _, err := os.Stat(inbox); !os.IsNotExist(err) {
+		t.Fatalf("expected m1 removed from INBOX, got %v", err)
+	}
+	moved, err := os.ReadFile(filepath.Join(out, "Work", "cur", "1700000000.m1.gog:2,S"))
+	if err != nil {
+		t.Fatalf("expected m1 in Work: %v", err)
+	}
+	if !strings.Contains(string(moved), "X-Gmail-Labels: Work\n") {
+		t.Fatalf("expected relabelled header, got %q", string(moved))
+	}
+	if _, err := os.Stat(filepath.Join(out, "INBOX", "cur", "1700000000.m3.gog:2,S")); err != nil {
+		t.Fatalf("expected m3 in INBOX: %v", err)
+	}
+}
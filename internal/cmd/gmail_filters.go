@@ -19,6 +19,8 @@ type GmailFiltersCmd struct {
 	Get    GmailFiltersGetCmd    `cmd:"" name:"get" aliases:"info,show" help:"Get a specific filter"`
 	Create GmailFiltersCreateCmd `cmd:"" name:"create" aliases:"add,new" help:"Create a new email filter"`
 	Delete GmailFiltersDeleteCmd `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Delete a filter"`
+	Export GmailFiltersExportCmd `cmd:"" name:"export" help:"Export filters as YAML, JSON, or Gmail mailFilters.xml"`
+	Apply  GmailFiltersApplyCmd  `cmd:"" name:"apply" help:"Make the account's filters match a file (shows a plan first)"`
 }
 
 type GmailFiltersListCmd struct{}
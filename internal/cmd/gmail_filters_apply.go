@@ -0,0 +1,651 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	gmailFiltersFormatYAML = "yaml"
+	gmailFiltersFormatJSON = "json"
+	gmailFiltersFormatXML  = "xml"
+)
+
+// gmailFilterSpecFile is the on-disk representation used by export/apply.
+// Labels are referenced by name so the same file works across accounts.
+type gmailFilterSpecFile struct {
+	Filters []gmailFilterSpec `json:"filters" yaml:"filters"`
+}
+
+type gmailFilterSpec struct {
+	Criteria gmailFilterSpecCriteria `json:"criteria" yaml:"criteria"`
+	Action   gmailFilterSpecAction   `json:"action" yaml:"action"`
+}
+
+type gmailFilterSpecCriteria struct {
+	From           string `json:"from,omitempty" yaml:"from,omitempty"`
+	To             string `json:"to,omitempty" yaml:"to,omitempty"`
+	Subject        string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Query          string `json:"query,omitempty" yaml:"query,omitempty"`
+	NegatedQuery   string `json:"negatedQuery,omitempty" yaml:"negatedQuery,omitempty"`
+	HasAttachment  bool   `json:"hasAttachment,omitempty" yaml:"hasAttachment,omitempty"`
+	ExcludeChats   bool   `json:"excludeChats,omitempty" yaml:"excludeChats,omitempty"`
+	Size           int64  `json:"size,omitempty" yaml:"size,omitempty"`
+	SizeComparison string `json:"sizeComparison,omitempty" yaml:"sizeComparison,omitempty"`
+}
+
+type gmailFilterSpecAction struct {
+	AddLabels    []string `json:"addLabels,omitempty" yaml:"addLabels,omitempty"`
+	RemoveLabels []string `json:"removeLabels,omitempty" yaml:"removeLabels,omitempty"`
+	Forward      string   `json:"forward,omitempty" yaml:"forward,omitempty"`
+}
+
+func (s gmailFilterSpec) normalized() gmailFilterSpec {
+	c := s.Criteria
+	c.From = strings.TrimSpace(c.From)
+	c.To = strings.TrimSpace(c.To)
+	c.Subject = strings.TrimSpace(c.Subject)
+	c.Query = strings.TrimSpace(c.Query)
+	c.NegatedQuery = strings.TrimSpace(c.NegatedQuery)
+	c.SizeComparison = strings.ToLower(strings.TrimSpace(c.SizeComparison))
+	if c.Size == 0 {
+		c.SizeComparison = ""
+	}
+	return gmailFilterSpec{
+		Criteria: c,
+		Action: gmailFilterSpecAction{
+			AddLabels:    normalizeFilterLabels(s.Action.AddLabels),
+			RemoveLabels: normalizeFilterLabels(s.Action.RemoveLabels),
+			Forward:      strings.TrimSpace(s.Action.Forward),
+		},
+	}
+}
+
+func normalizeFilterLabels(labels []string) []string {
+	seen := make(map[string]bool, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l == "" || seen[strings.ToLower(l)] {
+			continue
+		}
+		seen[strings.ToLower(l)] = true
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i]) < strings.ToLower(out[j]) })
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (s gmailFilterSpec) validate() error {
+	c := s.Criteria
+	if c.From == "" && c.To == "" && c.Subject == "" && c.Query == "" && c.NegatedQuery == "" && !c.HasAttachment && c.Size == 0 {
+		return errors.New("no criteria")
+	}
+	if c.Size != 0 && c.SizeComparison != "larger" && c.SizeComparison != "smaller" {
+		return fmt.Errorf("sizeComparison must be larger or smaller")
+	}
+	a := s.Action
+	if len(a.AddLabels) == 0 && len(a.RemoveLabels) == 0 && a.Forward == "" {
+		return errors.New("no action")
+	}
+	return nil
+}
+
+// criteriaKey and key identify filters independently of their Gmail IDs.
+// Label names compare case-insensitively, like Gmail itself.
+func (s gmailFilterSpec) criteriaKey() string {
+	b, _ := json.Marshal(s.Criteria)
+	return string(b)
+}
+
+func (s gmailFilterSpec) key() string {
+	a := gmailFilterSpecAction{
+		AddLabels:    lowerAll(s.Action.AddLabels),
+		RemoveLabels: lowerAll(s.Action.RemoveLabels),
+		Forward:      strings.ToLower(s.Action.Forward),
+	}
+	b, _ := json.Marshal(a)
+	return s.criteriaKey() + string(b)
+}
+
+func lowerAll(in []string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = strings.ToLower(s)
+	}
+	return out
+}
+
+func (s gmailFilterSpec) String() string {
+	var parts []string
+	c := s.Criteria
+	add := func(k, v string) {
+		if v != "" {
+			parts = append(parts, k+":"+v)
+		}
+	}
+	add("from", c.From)
+	add("to", c.To)
+	add("subject", c.Subject)
+	add("query", c.Query)
+	add("not", c.NegatedQuery)
+	if c.HasAttachment {
+		parts = append(parts, "has:attachment")
+	}
+	if c.ExcludeChats {
+		parts = append(parts, "-chats")
+	}
+	if c.Size != 0 {
+		parts = append(parts, fmt.Sprintf("size:%s:%d", c.SizeComparison, c.Size))
+	}
+	parts = append(parts, "->")
+	for _, l := range s.Action.AddLabels {
+		parts = append(parts, "+"+l)
+	}
+	for _, l := range s.Action.RemoveLabels {
+		parts = append(parts, "-"+l)
+	}
+	add("forward", s.Action.Forward)
+	return strings.Join(parts, " ")
+}
+
+func gmailFilterToSpec(f *gmail.Filter, idToName map[string]string) gmailFilterSpec {
+	var spec gmailFilterSpec
+	if c := f.Criteria; c != nil {
+		spec.Criteria = gmailFilterSpecCriteria{
+			From:           c.From,
+			To:             c.To,
+			Subject:        c.Subject,
+			Query:          c.Query,
+			NegatedQuery:   c.NegatedQuery,
+			HasAttachment:  c.HasAttachment,
+			ExcludeChats:   c.ExcludeChats,
+			Size:           c.Size,
+			SizeComparison: c.SizeComparison,
+		}
+	}
+	if a := f.Action; a != nil {
+		spec.Action = gmailFilterSpecAction{
+			AddLabels:    labelIDsToNames(a.AddLabelIds, idToName),
+			RemoveLabels: labelIDsToNames(a.RemoveLabelIds, idToName),
+			Forward:      a.Forward,
+		}
+	}
+	return spec.normalized()
+}
+
+func labelIDsToNames(ids []string, idToName map[string]string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := idToName[id]; ok {
+			out = append(out, name)
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+func (s gmailFilterSpec) toGmail(nameToID map[string]string) *gmail.Filter {
+	c := s.Criteria
+	return &gmail.Filter{
+		Criteria: &gmail.FilterCriteria{
+			From:           c.From,
+			To:             c.To,
+			Subject:        c.Subject,
+			Query:          c.Query,
+			NegatedQuery:   c.NegatedQuery,
+			HasAttachment:  c.HasAttachment,
+			ExcludeChats:   c.ExcludeChats,
+			Size:           c.Size,
+			SizeComparison: c.SizeComparison,
+		},
+		Action: &gmail.FilterAction{
+			AddLabelIds:    resolveLabelIDs(s.Action.AddLabels, nameToID),
+			RemoveLabelIds: resolveLabelIDs(s.Action.RemoveLabels, nameToID),
+			Forward:        s.Action.Forward,
+		},
+	}
+}
+
+type gmailFilterCurrent struct {
+	ID   string          `json:"id"`
+	Spec gmailFilterSpec `json:"filter"`
+}
+
+type gmailFilterReplace struct {
+	ID   string          `json:"id"`
+	From gmailFilterSpec `json:"from"`
+	To   gmailFilterSpec `json:"to"`
+}
+
+type gmailFilterPlan struct {
+	CreateLabels []string             `json:"createLabels"`
+	Create       []gmailFilterSpec    `json:"create"`
+	Replace      []gmailFilterReplace `json:"replace"`
+	Delete       []gmailFilterCurrent `json:"delete"`
+	Unmanaged    []gmailFilterCurrent `json:"unmanaged"`
+	Unchanged    int                  `json:"unchanged"`
+}
+
+func (p gmailFilterPlan) empty() bool {
+	return len(p.CreateLabels) == 0 && len(p.Create) == 0 && len(p.Replace) == 0 && len(p.Delete) == 0
+}
+
+// planGmailFilters diffs desired filters against the account's. Filters that
+// match exactly are left alone; a desired filter whose criteria match an
+// existing one with different actions replaces it (the API has no update);
+// anything else is created. Leftover filters are deleted only with prune.
+func planGmailFilters(desired []gmailFilterSpec, actual []gmailFilterCurrent, prune bool) gmailFilterPlan {
+	plan := gmailFilterPlan{
+		CreateLabels: []string{},
+		Create:       []gmailFilterSpec{},
+		Replace:      []gmailFilterReplace{},
+		Delete:       []gmailFilterCurrent{},
+		Unmanaged:    []gmailFilterCurrent{},
+	}
+	used := make([]bool, len(actual))
+	pending := make([]gmailFilterSpec, 0, len(desired))
+	for _, want := range desired {
+		matched := false
+		for i, have := range actual {
+			if !used[i] && have.Spec.key() == want.key() {
+				used[i] = true
+				matched = true
+				break
+			}
+		}
+		if matched {
+			plan.Unchanged++
+			continue
+		}
+		pending = append(pending, want)
+	}
+	for _, want := range pending {
+		replaced := false
+		for i, have := range actual {
+			if !used[i] && have.Spec.criteriaKey() == want.criteriaKey() {
+				used[i] = true
+				replaced = true
+				plan.Replace = append(plan.Replace, gmailFilterReplace{ID: have.ID, From: have.Spec, To: want})
+				break
+			}
+		}
+		if !replaced {
+			plan.Create = append(plan.Create, want)
+		}
+	}
+	for i, have := range actual {
+		if used[i] {
+			continue
+		}
+		if prune {
+			plan.Delete = append(plan.Delete, have)
+		} else {
+			plan.Unmanaged = append(plan.Unmanaged, have)
+		}
+	}
+	return plan
+}
+
+func parseGmailFilterSpecs(data []byte) ([]gmailFilterSpec, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, usage("empty filters file")
+	}
+
+	var filters []gmailFilterSpec
+	if trimmed[0] == '<' {
+		parsed, err := parseGmailFiltersXML(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		filters = parsed
+	} else {
+		// YAML is a superset of JSON, so one decoder handles both.
+		var file gmailFilterSpecFile
+		dec := yaml.NewDecoder(bytes.NewReader(trimmed))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("parse filters: %w", err)
+		}
+		filters = file.Filters
+	}
+
+	out := make([]gmailFilterSpec, 0, len(filters))
+	seen := make(map[string]int, len(filters))
+	for i, f := range filters {
+		f = f.normalized()
+		if err := f.validate(); err != nil {
+			return nil, usagef("filters[%d]: %v", i, err)
+		}
+		if prev, ok := seen[f.key()]; ok {
+			return nil, usagef("filters[%d]: duplicate of filters[%d]", i, prev)
+		}
+		seen[f.key()] = i
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+type gmailFilterLabels struct {
+	nameToID map[string]string
+	idToName map[string]string
+}
+
+func fetchGmailFilterLabels(ctx context.Context, svc *gmail.Service) (*gmailFilterLabels, error) {
+	resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return &gmailFilterLabels{
+		nameToID: labelNameToID(resp.Labels),
+		idToName: labelIDToName(resp.Labels),
+	}, nil
+}
+
+func (l *gmailFilterLabels) add(label *gmail.Label) {
+	added := []*gmail.Label{label}
+	for k, v := range labelNameToID(added) {
+		l.nameToID[k] = v
+	}
+	for k, v := range labelIDToName(added) {
+		l.idToName[k] = v
+	}
+}
+
+// canonical rewrites label references (names in any case, or IDs) to the
+// account's label names; unknown labels are kept and reported as missing.
+func (l *gmailFilterLabels) canonical(spec gmailFilterSpec, missing map[string]string) gmailFilterSpec {
+	fix := func(in []string) []string {
+		out := make([]string, 0, len(in))
+		for _, name := range in {
+			if id, ok := l.nameToID[strings.ToLower(name)]; ok {
+				out = append(out, l.idToName[id])
+				continue
+			}
+			if _, ok := missing[strings.ToLower(name)]; !ok {
+				missing[strings.ToLower(name)] = name
+			}
+			out = append(out, name)
+		}
+		return out
+	}
+	spec.Action.AddLabels = fix(spec.Action.AddLabels)
+	spec.Action.RemoveLabels = fix(spec.Action.RemoveLabels)
+	return spec.normalized()
+}
+
+func listGmailFilters(ctx context.Context, svc *gmail.Service, labels *gmailFilterLabels) ([]gmailFilterCurrent, error) {
+	resp, err := svc.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]gmailFilterCurrent, 0, len(resp.Filter))
+	for _, f := range resp.Filter {
+		out = append(out, gmailFilterCurrent{ID: f.Id, Spec: gmailFilterToSpec(f, labels.idToName)})
+	}
+	return out, nil
+}
+
+type GmailFiltersExportCmd struct {
+	Format string `name:"format" help:"Output format: yaml|json|xml (Gmail mailFilters.xml); default yaml, or json with --json"`
+	Out    string `name:"out" short:"o" help:"Write to this file instead of stdout"`
+}
+
+func (c *GmailFiltersExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	if format == "" {
+		format = gmailFiltersFormatYAML
+		if outfmt.IsJSON(ctx) {
+			format = gmailFiltersFormatJSON
+		}
+	}
+	switch format {
+	case gmailFiltersFormatYAML, gmailFiltersFormatJSON, gmailFiltersFormatXML:
+	default:
+		return usagef("invalid --format %q (expected yaml, json, or xml)", c.Format)
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	labels, err := fetchGmailFilterLabels(ctx, svc)
+	if err != nil {
+		return err
+	}
+	current, err := listGmailFilters(ctx, svc, labels)
+	if err != nil {
+		return err
+	}
+	file := gmailFilterSpecFile{Filters: make([]gmailFilterSpec, 0, len(current))}
+	for _, f := range current {
+		file.Filters = append(file.Filters, f.Spec)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case gmailFiltersFormatJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(file)
+	case gmailFiltersFormatXML:
+		err = writeGmailFiltersXML(&buf, account, file.Filters)
+	default:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err = enc.Encode(file)
+		if err == nil {
+			err = enc.Close()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(c.Out) == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	outPath, err := config.ExpandPath(c.Out)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(outPath); dir != "" {
+		if mkErr := os.MkdirAll(dir, 0o755); mkErr != nil {
+			return mkErr
+		}
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"path":    outPath,
+			"format":  format,
+			"filters": len(file.Filters),
+		})
+	}
+	u.Out().Printf("Exported %d filters to %s", len(file.Filters), outPath)
+	return nil
+}
+
+type GmailFiltersApplyCmd struct {
+	File  string `arg:"" name:"file" help:"Filters file (YAML, JSON, or Gmail mailFilters.xml), or '-' for stdin"`
+	Prune bool   `name:"prune" help:"Delete filters that are not in the file"`
+}
+
+func (c *GmailFiltersApplyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	var data []byte
+	var err error
+	if c.File == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		var path string
+		path, err = config.ExpandPath(c.File)
+		if err != nil {
+			return err
+		}
+		data, err = os.ReadFile(path) //nolint:gosec // user-provided path
+	}
+	if err != nil {
+		return err
+	}
+	desired, err := parseGmailFilterSpecs(data)
+	if err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	labels, err := fetchGmailFilterLabels(ctx, svc)
+	if err != nil {
+		return err
+	}
+	current, err := listGmailFilters(ctx, svc, labels)
+	if err != nil {
+		return err
+	}
+
+	missing := map[string]string{}
+	for i := range desired {
+		desired[i] = labels.canonical(desired[i], missing)
+	}
+	plan := planGmailFilters(desired, current, c.Prune)
+	for _, name := range missing {
+		plan.CreateLabels = append(plan.CreateLabels, name)
+	}
+	sort.Strings(plan.CreateLabels)
+
+	if !outfmt.IsJSON(ctx) {
+		printGmailFilterPlan(u, plan, c.Prune)
+	}
+	if err := dryRunExit(ctx, flags, "gmail.filters.apply", map[string]any{"plan": plan}); err != nil {
+		return err
+	}
+	if plan.empty() {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"plan": plan, "applied": false})
+		}
+		u.Out().Println("No changes")
+		return nil
+	}
+	if n := len(plan.Replace) + len(plan.Delete); n > 0 {
+		if err := confirmDestructive(ctx, flags, fmt.Sprintf("replace or delete %d gmail filters", n)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range plan.CreateLabels {
+		label, createErr := createLabel(ctx, svc, name)
+		if createErr != nil && !isDuplicateLabelError(createErr) {
+			return fmt.Errorf("create label %q: %w", name, createErr)
+		}
+		if createErr != nil {
+			// Created concurrently; pick up its ID.
+			if labels, err = fetchGmailFilterLabels(ctx, svc); err != nil {
+				return err
+			}
+			continue
+		}
+		labels.add(label)
+	}
+
+	created, deleted := 0, 0
+	for _, want := range plan.Create {
+		if _, err := svc.Users.Settings.Filters.Create("me", want.toGmail(labels.nameToID)).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("create filter (%s): %w", want, err)
+		}
+		created++
+	}
+	// Create the replacement before deleting the old filter so mail is never
+	// left unfiltered in between.
+	for _, r := range plan.Replace {
+		if _, err := svc.Users.Settings.Filters.Create("me", r.To.toGmail(labels.nameToID)).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("create filter (%s): %w", r.To, err)
+		}
+		created++
+		if err := svc.Users.Settings.Filters.Delete("me", r.ID).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("delete filter %s: %w", r.ID, err)
+		}
+		deleted++
+	}
+	for _, d := range plan.Delete {
+		if err := svc.Users.Settings.Filters.Delete("me", d.ID).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("delete filter %s: %w", d.ID, err)
+		}
+		deleted++
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"plan":          plan,
+			"applied":       true,
+			"labelsCreated": len(plan.CreateLabels),
+			"created":       created,
+			"deleted":       deleted,
+		})
+	}
+	u.Out().Printf("Applied: %d labels created, %d filters created, %d deleted", len(plan.CreateLabels), created, deleted)
+	return nil
+}
+
+func printGmailFilterPlan(u *ui.UI, plan gmailFilterPlan, prune bool) {
+	if u == nil {
+		return
+	}
+	for _, name := range plan.CreateLabels {
+		u.Out().Printf("+ label   %s", name)
+	}
+	for _, f := range plan.Create {
+		u.Out().Printf("+ create  %s", f)
+	}
+	for _, r := range plan.Replace {
+		u.Out().Printf("~ replace %s  %s", r.ID, r.From)
+		u.Out().Printf("       => %s", r.To)
+	}
+	for _, d := range plan.Delete {
+		u.Out().Printf("- delete  %s  %s", d.ID, d.Spec)
+	}
+	for _, d := range plan.Unmanaged {
+		u.Out().Printf("  keep    %s  %s", d.ID, d.Spec)
+	}
+	summary := fmt.Sprintf("Plan: %d to create, %d to replace, %d to delete, %d unchanged",
+		len(plan.Create), len(plan.Replace), len(plan.Delete), plan.Unchanged)
+	if !prune && len(plan.Unmanaged) > 0 {
+		summary += fmt.Sprintf(" (%d unmanaged kept; use --prune to delete)", len(plan.Unmanaged))
+	}
+	u.Err().Println(summary)
+}
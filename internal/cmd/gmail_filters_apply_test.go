@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func TestPlanGmailFilters(t *testing.T) {
+	spec := func(from string, add ...string) gmailFilterSpec {
+		return gmailFilterSpec{
+			Criteria: gmailFilterSpecCriteria{From: from},
+			Action:   gmailFilterSpecAction{AddLabels: add},
+		}.normalized()
+	}
+	actual := []gmailFilterCurrent{
+		{ID: "f1", Spec: spec("a@example.com", "Work")},
+		{ID: "f2", Spec: spec("b@example.com", "Old")},
+		{ID: "f3", Spec: spec("c@example.com", "Misc")},
+	}
+	desired := []gmailFilterSpec{
+		spec("a@example.com", "work"),
+		spec("b@example.com", "New"),
+		spec("d@example.com", "Receipts"),
+	}
+
+	plan := planGmailFilters(desired, actual, false)
+	if plan.Unchanged != 1 {
+		t.Fatalf("expected case-insensitive label match, got %+v", plan)
+	}
+	if len(plan.Replace) != 1 || plan.Replace[0].ID != "f2" || plan.Replace[0].To.Action.AddLabels[0] != "New" {
+		t.Fatalf("unexpected replace: %+v", plan.Replace)
+	}
+	if len(plan.Create) != 1 || plan.Create[0].Criteria.From != "d@example.com" {
+		t.Fatalf("unexpected create: %+v", plan.Create)
+	}
+	if len(plan.Delete) != 0 || len(plan.Unmanaged) != 1 || plan.Unmanaged[0].ID != "f3" {
+		t.Fatalf("expected f3 kept as unmanaged: %+v", plan)
+	}
+
+	pruned := planGmailFilters(desired, actual, true)
+	if len(pruned.Delete) != 1 || pruned.Delete[0].ID != "f3" || len(pruned.Unmanaged) != 0 {
+		t.Fatalf("expected f3 deleted with prune: %+v", pruned)
+	}
+}
+
+func TestParseGmailFilterSpecs_Validation(t *testing.T) {
+	if _, err := parseGmailFilterSpecs([]byte("filters:\n  - action:\n      addLabels: [X]\n")); err == nil {
+		t.Fatalf("expected missing criteria error")
+	}
+	if _, err := parseGmailFilterSpecs([]byte("filters:\n  - criteria:\n      from: a\n      form: b\n    action:\n      forward: x@example.com\n")); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+	dup := `{"filters":[{"criteria":{"from":"a"},"action":{"addLabels":["X"]}},{"criteria":{"from":" a "},"action":{"addLabels":["x"]}}]}`
+	if _, err := parseGmailFilterSpecs([]byte(dup)); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+}
+
+func TestGmailFiltersXML_RoundTrip(t *testing.T) {
+	filters := []gmailFilterSpec{
+		{
+			Criteria: gmailFilterSpecCriteria{From: "news@example.com", Query: "unsubscribe", Size: 2048, SizeComparison: "larger"},
+			Action:   gmailFilterSpecAction{AddLabels: []string{"CATEGORY_PROMOTIONS", "News", "STARRED"}, RemoveLabels: []string{"INBOX", "UNREAD"}},
+		},
+		{
+			Criteria: gmailFilterSpecCriteria{To: "me+bills@example.com", HasAttachment: true},
+			Action:   gmailFilterSpecAction{Forward: "accounts@example.com", AddLabels: []string{"IMPORTANT"}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeGmailFiltersXML(&buf, "a@b.com", filters); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<apps:property name="shouldArchive" value="true"></apps:property>`) {
+		t.Fatalf("expected apps:property output, got:\n%s", buf.String())
+	}
+
+	got, err := parseGmailFilterSpecs(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(got) != len(filters) {
+		t.Fatalf("expected %d filters, got %d", len(filters), len(got))
+	}
+	for i := range filters {
+		if got[i].key() != filters[i].normalized().key() {
+			t.Fatalf("filter %d mismatch:\n%s\nwant:\n%s", i, got[i], filters[i].normalized())
+		}
+	}
+
+	bad := []gmailFilterSpec{{Criteria: gmailFilterSpecCriteria{From: "x"}, Action: gmailFilterSpecAction{RemoveLabels: []string{"Work"}}}}
+	if err := writeGmailFiltersXML(io.Discard, "a@b.com", bad); err == nil {
+		t.Fatalf("expected error for unsupported remove label")
+	}
+}
+
+func TestGmailFiltersApplyCmd_Prune(t *testing.T) {
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+
+	var (
+		mu       sync.Mutex
+		labels   = []map[string]any{{"id": "INBOX", "name": "INBOX"}, {"id": "Label_1", "name": "Work"}}
+		created  []*gmail.Filter
+		deleted  []string
+		newLabel []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		p := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		switch {
+		case p == "/labels" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": labels})
+		case p == "/labels" && r.Method == http.MethodPost:
+			var l map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&l)
+			l["id"] = "Label_" + l["name"].(string)
+			newLabel = append(newLabel, l["name"].(string))
+			labels = append(labels, l)
+			_ = json.NewEncoder(w).Encode(l)
+		case p == "/settings/filters" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"filter": []map[string]any{
+				{"id": "keep", "criteria": map[string]any{"from": "a@example.com"}, "action": map[string]any{"addLabelIds": []string{"Label_1"}, "removeLabelIds": []string{"INBOX"}}},
+				{"id": "stale", "criteria": map[string]any{"subject": "old"}, "action": map[string]any{"addLabelIds": []string{"Label_1"}}},
+			}})
+		case p == "/settings/filters" && r.Method == http.MethodPost:
+			var f gmail.Filter
+			_ = json.NewDecoder(r.Body).Decode(&f)
+			created = append(created, &f)
+			f.Id = "new"
+			_ = json.NewEncoder(w).Encode(f)
+		case strings.HasPrefix(p, "/settings/filters/") && r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(p, "/settings/filters/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+
+	path := filepath.Join(t.TempDir(), "filters.yaml")
+	file := `filters:
+  - criteria:
+      from: a@example.com
+    action:
+      addLabels: [work]
+      removeLabels: [inbox]
+  - criteria:
+      query: "has:invoice"
+    action:
+      addLabels: [Receipts/2026]
+`
+	if writeErr := os.WriteFile(path, []byte(file), 0o600); writeErr != nil {
+		t.Fatalf("write: %v", writeErr)
+	}
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+
+	out := captureStdout(t, func() {
+		cmd := &GmailFiltersApplyCmd{File: path, Prune: true}
+		if runErr := cmd.Run(ctx, &RootFlags{Account: "a@b.com", Force: true}); runErr != nil {
+			t.Fatalf("Run: %v", runErr)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	if parsed["applied"] != true || parsed["created"] != float64(1) || parsed["deleted"] != float64(1) {
+		t.Fatalf("unexpected result: %v", parsed)
+	}
+	if len(newLabel) != 1 || newLabel[0] != "Receipts/2026" {
+		t.Fatalf("expected Receipts/2026 label created, got %v", newLabel)
+	}
+	if len(created) != 1 || created[0].Criteria.Query != "has:invoice" || strings.Join(created[0].Action.AddLabelIds, ",") != "Label_Receipts/2026" {
+		t.Fatalf("unexpected created filter: %+v", created)
+	}
+	if strings.Join(deleted, ",") != "stale" {
+		t.Fatalf("expected only stale deleted, got %v", deleted)
+	}
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Gmail's settings UI imports and exports filters as an Atom feed
+// (mailFilters.xml) with one apps:property per criterion or action.
+
+type gmailFiltersAtomProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gmailFiltersAtomIn struct {
+	Entries []struct {
+		Properties []gmailFiltersAtomProperty `xml:"property"`
+	} `xml:"entry"`
+}
+
+type gmailFiltersAtomOut struct {
+	XMLName   xml.Name `xml:"feed"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	XmlnsApps string   `xml:"xmlns:apps,attr"`
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Updated   string   `xml:"updated"`
+	Author    struct {
+		Email string `xml:"email"`
+	} `xml:"author"`
+	Entries []gmailFiltersAtomEntryOut `xml:"entry"`
+}
+
+type gmailFiltersAtomEntryOut struct {
+	Category struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Title      string                     `xml:"title"`
+	ID         string                     `xml:"id"`
+	Updated    string                     `xml:"updated"`
+	Content    string                     `xml:"content"`
+	Properties []gmailFiltersAtomProperty `xml:"apps:property"`
+}
+
+var gmailSmartLabels = map[string]string{
+	"^smartlabel_personal":     "CATEGORY_PERSONAL",
+	"^smartlabel_social":       "CATEGORY_SOCIAL",
+	"^smartlabel_promo":        "CATEGORY_PROMOTIONS",
+	"^smartlabel_notification": "CATEGORY_UPDATES",
+	"^smartlabel_group":        "CATEGORY_FORUMS",
+}
+
+var gmailSizeUnits = map[string]int64{
+	"s_sb":  1,
+	"s_skb": 1 << 10,
+	"s_smb": 1 << 20,
+}
+
+func parseGmailFiltersXML(data []byte) ([]gmailFilterSpec, error) {
+	var feed gmailFiltersAtomIn
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("parse mailFilters.xml: %w", err)
+	}
+
+	out := make([]gmailFilterSpec, 0, len(feed.Entries))
+	for i, entry := range feed.Entries {
+		var spec gmailFilterSpec
+		unit := int64(1)
+		for _, p := range entry.Properties {
+			v := p.Value
+			c := &spec.Criteria
+			a := &spec.Action
+			switch p.Name {
+			case "from":
+				c.From = v
+			case "to":
+				c.To = v
+			case "subject":
+				c.Subject = v
+			case "hasTheWord":
+				c.Query = v
+			case "doesNotHaveTheWord":
+				c.NegatedQuery = v
+			case "hasAttachment":
+				c.HasAttachment = v == "true"
+			case "excludeChats":
+				c.ExcludeChats = v == "true"
+			case "size":
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("filter %d: invalid size %q", i, v)
+				}
+				c.Size = n
+			case "sizeOperator":
+				switch v {
+				case "s_sl":
+					c.SizeComparison = "larger"
+				case "s_ss":
+					c.SizeComparison = "smaller"
+				}
+			case "sizeUnit":
+				if m, ok := gmailSizeUnits[v]; ok {
+					unit = m
+				}
+			case "label":
+				a.AddLabels = append(a.AddLabels, v)
+			case "smartLabelToApply":
+				if id, ok := gmailSmartLabels[v]; ok {
+					a.AddLabels = append(a.AddLabels, id)
+				}
+			case "shouldArchive":
+				if v == "true" {
+					a.RemoveLabels = append(a.RemoveLabels, "INBOX")
+				}
+			case "shouldMarkAsRead":
+				if v == "true" {
+					a.RemoveLabels = append(a.RemoveLabels, "UNREAD")
+				}
+			case "shouldNeverSpam":
+				if v == "true" {
+					a.RemoveLabels = append(a.RemoveLabels, "SPAM")
+				}
+			case "shouldNeverMarkAsImportant":
+				if v == "true" {
+					a.RemoveLabels = append(a.RemoveLabels, "IMPORTANT")
+				}
+			case "shouldStar":
+				if v == "true" {
+					a.AddLabels = append(a.AddLabels, "STARRED")
+				}
+			case "shouldTrash":
+				if v == "true" {
+					a.AddLabels = append(a.AddLabels, "TRASH")
+				}
+			case "shouldAlwaysMarkAsImportant":
+				if v == "true" {
+					a.AddLabels = append(a.AddLabels, "IMPORTANT")
+				}
+			case "forwardTo":
+				a.Forward = v
+			}
+		}
+		spec.Criteria.Size *= unit
+		out = append(out, spec)
+	}
+	return out, nil
+}
+
+func writeGmailFiltersXML(w io.Writer, account string, filters []gmailFilterSpec) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	feed := gmailFiltersAtomOut{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsApps: "http://schemas.google.com/apps/2006",
+		Title:     "Mail Filters",
+		ID:        "tag:mail.google.com,2008:filters:" + strconv.Itoa(len(filters)),
+		Updated:   now,
+	}
+	feed.Author.Email = account
+
+	smartByLabel := make(map[string]string, len(gmailSmartLabels))
+	for k, v := range gmailSmartLabels {
+		smartByLabel[v] = k
+	}
+
+	for i, f := range filters {
+		entry := gmailFiltersAtomEntryOut{
+			Title:   "Mail Filter",
+			ID:      fmt.Sprintf("tag:mail.google.com,2008:filter:%d", i+1),
+			Updated: now,
+		}
+		entry.Category.Term = "filter"
+		prop := func(name, value string) {
+			if value != "" {
+				entry.Properties = append(entry.Properties, gmailFiltersAtomProperty{Name: name, Value: value})
+			}
+		}
+		c := f.Criteria
+		prop("from", c.From)
+		prop("to", c.To)
+		prop("subject", c.Subject)
+		prop("hasTheWord", c.Query)
+		prop("doesNotHaveTheWord", c.NegatedQuery)
+		if c.HasAttachment {
+			prop("hasAttachment", "true")
+		}
+		if c.ExcludeChats {
+			prop("excludeChats", "true")
+		}
+		if c.Size != 0 {
+			prop("size", strconv.FormatInt(c.Size, 10))
+			if c.SizeComparison == "smaller" {
+				prop("sizeOperator", "s_ss")
+			} else {
+				prop("sizeOperator", "s_sl")
+			}
+			prop("sizeUnit", "s_sb")
+		}
+		for _, l := range f.Action.AddLabels {
+			switch strings.ToUpper(l) {
+			case "STARRED":
+				prop("shouldStar", "true")
+			case "TRASH":
+				prop("shouldTrash", "true")
+			case "IMPORTANT":
+				prop("shouldAlwaysMarkAsImportant", "true")
+			default:
+				if smart, ok := smartByLabel[strings.ToUpper(l)]; ok {
+					prop("smartLabelToApply", smart)
+					continue
+				}
+				prop("label", l)
+			}
+		}
+		for _, l := range f.Action.RemoveLabels {
+			switch strings.ToUpper(l) {
+			case "INBOX":
+				prop("shouldArchive", "true")
+			case "UNREAD":
+				prop("shouldMarkAsRead", "true")
+			case "SPAM":
+				prop("shouldNeverSpam", "true")
+			case "IMPORTANT":
+				prop("shouldNeverMarkAsImportant", "true")
+			default:
+				return fmt.Errorf("filter %d (%s): removing label %q cannot be expressed in mailFilters.xml", i, f, l)
+			}
+		}
+		prop("forwardTo", f.Action.Forward)
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -51,6 +51,15 @@ func (c *GmailHistoryCmd) Run(ctx context.Context, flags *RootFlags) error {
 		historyIDs := collectHistoryMessageIDs(resp)
 		return historyIDs.FetchIDs, resp.NextPageToken, nil
 	}
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []string) error {
+			items := make([]map[string]string, 0, len(page))
+			for _, id := range page {
+				items = append(items, map[string]string{"id": id})
+			}
+			return writeNDJSON(ctx, items)
+		})
+	}
 	var ids []string
 	nextPageToken := ""
 	if c.All {
@@ -0,0 +1,549 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	gmailImportModeImport = "import"
+	gmailImportModeInsert = "insert"
+
+	gmailImportStateVersion = 1
+	gmailImportSaveEvery    = 50
+)
+
+type GmailImportCmd struct {
+	Source             string   `arg:"" name:"source" help:"mbox file or directory of .eml files"`
+	Label              []string `name:"label" help:"Label to add to every message, or FROM=TO to rename an X-Gmail-Labels label (repeatable)"`
+	IgnoreSourceLabels bool     `name:"ignore-source-labels" help:"Do not apply labels from X-Gmail-Labels headers"`
+	Mode               string   `name:"mode" help:"API method: import (spam classification, dedupe) or insert (as-is)" default:"import" enum:"import,insert"`
+	NeverMarkSpam      bool     `name:"never-mark-spam" help:"Never send imported messages to spam (import mode)"`
+	ProcessForCalendar bool     `name:"process-for-calendar" help:"Create calendar events from invitations (import mode)"`
+	InternalDateSource string   `name:"internal-date-source" help:"Message date: dateHeader|receivedTime" default:"dateHeader" enum:"dateHeader,receivedTime"`
+	Concurrency        int      `name:"concurrency" help:"Parallel uploads" default:"5"`
+	Restart            bool     `name:"restart" help:"Ignore saved progress and import everything again"`
+	State              string   `name:"state" help:"Progress file path (default: gogcli state dir)"`
+}
+
+// gmailImportState maps a content hash of each source message to the Gmail
+// message ID it became, so re-running skips what is already in the mailbox.
+type gmailImportState struct {
+	Version   int               `json:"version"`
+	Account   string            `json:"account"`
+	Source    string            `json:"source"`
+	UpdatedAt string            `json:"updatedAt,omitempty"`
+	Done      map[string]string `json:"done"`
+}
+
+type gmailImportJob struct {
+	key      string
+	name     string
+	raw      []byte
+	labelIDs []string
+}
+
+type gmailImportResult struct {
+	job gmailImportJob
+	id  string
+	err error
+}
+
+func (c *GmailImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	source := strings.TrimSpace(c.Source)
+	if source == "" {
+		return usage("empty source")
+	}
+	source, err = config.ExpandPath(source)
+	if err != nil {
+		return err
+	}
+	source, err = filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if c.Concurrency <= 0 {
+		return usage("--concurrency must be > 0")
+	}
+	mode := strings.ToLower(strings.TrimSpace(c.Mode))
+	if mode == "" {
+		mode = gmailImportModeImport
+	}
+	if mode == gmailImportModeInsert && (c.NeverMarkSpam || c.ProcessForCalendar) {
+		return usage("--never-mark-spam/--process-for-calendar require --mode import")
+	}
+	extraLabels, labelMap, err := parseGmailImportLabels(c.Label)
+	if err != nil {
+		return err
+	}
+
+	statePath := strings.TrimSpace(c.State)
+	if statePath == "" {
+		statePath, err = gmailImportStatePath(account, source)
+	} else {
+		statePath, err = config.ExpandPath(statePath)
+	}
+	if err != nil {
+		return err
+	}
+	state, err := loadGmailImportState(statePath)
+	if err != nil {
+		return err
+	}
+	if c.Restart || state.Done == nil {
+		state.Done = map[string]string{}
+	}
+	state.Version = gmailImportStateVersion
+	state.Account = account
+	state.Source = source
+
+	walk := func(fn func(name string, raw []byte) error) error {
+		if info.IsDir() {
+			return walkEMLDir(source, fn)
+		}
+		return walkMboxFile(source, fn)
+	}
+
+	if flags != nil && flags.DryRun {
+		total, pending := 0, 0
+		if err := walk(func(_ string, raw []byte) error {
+			total++
+			if _, ok := state.Done[gmailImportKey(raw)]; !ok {
+				pending++
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		return dryRunExit(ctx, flags, "gmail.import", map[string]any{
+			"source":  source,
+			"mode":    mode,
+			"total":   total,
+			"pending": pending,
+			"labels":  c.Label,
+		})
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	labels, err := newGmailImportLabels(ctx, svc, labelMap)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The walker only reads this snapshot; state.Done is updated below.
+	done := make(map[string]bool, len(state.Done))
+	for key := range state.Done {
+		done[key] = true
+	}
+
+	jobs := make(chan gmailImportJob)
+	results := make(chan gmailImportResult)
+	skipped := 0
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		walkErr = walk(func(name string, raw []byte) error {
+			key := gmailImportKey(raw)
+			if done[key] {
+				skipped++
+				return nil
+			}
+			var names []string
+			if !c.IgnoreSourceLabels {
+				names = gmailSourceLabels(raw)
+			}
+			ids, labelErr := labels.resolve(append(names, extraLabels...))
+			if labelErr != nil {
+				return labelErr
+			}
+			select {
+			case jobs <- gmailImportJob{key: key, name: name, raw: raw, labelIDs: ids}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The loop below drains results until close, so every finished
+			// upload reaches state.Done even after cancellation.
+			for job := range jobs {
+				id, uploadErr := c.upload(ctx, svc, mode, job)
+				results <- gmailImportResult{job: job, id: id, err: uploadErr}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	save := func() error {
+		state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		return saveGmailImportState(statePath, state)
+	}
+
+	imported, failed := 0, 0
+	var saveErr error
+	for r := range results {
+		if r.err != nil {
+			// Uploads cut short by cancellation are retried on the next run.
+			if ctx.Err() == nil {
+				failed++
+				u.Err().Printf("%s: %v", r.job.name, r.err)
+			}
+			continue
+		}
+		state.Done[r.job.key] = r.id
+		imported++
+		if saveErr == nil && imported%gmailImportSaveEvery == 0 {
+			if saveErr = save(); saveErr != nil {
+				// Stop the walker and workers, but keep recording uploads
+				// that already succeeded so the final save has them.
+				cancel()
+				continue
+			}
+			u.Err().Printf("imported %d", imported)
+		}
+	}
+	if err := save(); err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	if walkErr != nil && !errors.Is(walkErr, context.Canceled) {
+		return walkErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"source":   source,
+			"mode":     mode,
+			"imported": imported,
+			"skipped":  skipped,
+			"failed":   failed,
+		}); err != nil {
+			return err
+		}
+	} else {
+		u.Out().Printf("source\t%s", source)
+		u.Out().Printf("imported\t%d", imported)
+		u.Out().Printf("skipped\t%d", skipped)
+		u.Out().Printf("failed\t%d", failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("gmail import: %d messages failed (re-run to retry)", failed)
+	}
+	return nil
+}
+
+func (c *GmailImportCmd) upload(ctx context.Context, svc *gmail.Service, mode string, job gmailImportJob) (string, error) {
+	meta := &gmail.Message{LabelIds: job.labelIDs}
+	media := gapi.ContentType("message/rfc822")
+	var (
+		msg *gmail.Message
+		err error
+	)
+	if mode == gmailImportModeInsert {
+		msg, err = svc.Users.Messages.Insert("me", meta).
+			InternalDateSource(c.InternalDateSource).
+			Media(bytes.NewReader(job.raw), media).
+			Context(ctx).
+			Do()
+	} else {
+		msg, err = svc.Users.Messages.Import("me", meta).
+			InternalDateSource(c.InternalDateSource).
+			NeverMarkSpam(c.NeverMarkSpam).
+			ProcessForCalendar(c.ProcessForCalendar).
+			Media(bytes.NewReader(job.raw), media).
+			Context(ctx).
+			Do()
+	}
+	if err != nil {
+		return "", err
+	}
+	return msg.Id, nil
+}
+
+func parseGmailImportLabels(values []string) ([]string, map[string]string, error) {
+	var extra []string
+	mapping := map[string]string{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if from, to, ok := strings.Cut(v, "="); ok {
+			from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+			if from == "" {
+				return nil, nil, usagef("invalid --label %q (use NAME or FROM=TO)", v)
+			}
+			mapping[strings.ToLower(from)] = to
+			continue
+		}
+		extra = append(extra, v)
+	}
+	return extra, mapping, nil
+}
+
+func gmailImportKey(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
+}
+
+// gmailSourceLabels reads the X-Gmail-Labels header written by Takeout and
+// `gog gmail export`.
+func gmailSourceLabels(raw []byte) []string {
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(raw)))
+	header, _ := tp.ReadMIMEHeader()
+	value := header.Get("X-Gmail-Labels")
+	if value == "" {
+		return nil
+	}
+	var out []string
+	for _, l := range strings.Split(value, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// gmailImportLabels resolves label names to IDs, creating user labels that do
+// not exist yet. It is only used from the walking goroutine.
+type gmailImportLabels struct {
+	ctx      context.Context
+	svc      *gmail.Service
+	nameToID map[string]string
+	mapping  map[string]string
+}
+
+func newGmailImportLabels(ctx context.Context, svc *gmail.Service, mapping map[string]string) (*gmailImportLabels, error) {
+	nameToID, err := fetchLabelNameToID(svc)
+	if err != nil {
+		return nil, err
+	}
+	return &gmailImportLabels{ctx: ctx, svc: svc, nameToID: nameToID, mapping: mapping}, nil
+}
+
+func (l *gmailImportLabels) resolve(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if to, ok := l.mapping[strings.ToLower(name)]; ok {
+			name = to
+		}
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		switch key {
+		case "opened", "archived":
+			// Takeout pseudo-labels: read state and "not in inbox" are implied.
+			continue
+		}
+		id, ok := l.nameToID[key]
+		if !ok {
+			// Takeout spells system labels as "Category Promotions" etc.
+			id, ok = l.nameToID[strings.ReplaceAll(key, " ", "_")]
+		}
+		if !ok {
+			created, err := createLabel(l.ctx, l.svc, name)
+			if err != nil {
+				if !isDuplicateLabelError(err) {
+					return nil, mapLabelCreateError(err, name)
+				}
+				refreshed, refreshErr := fetchLabelNameToID(l.svc)
+				if refreshErr != nil {
+					return nil, refreshErr
+				}
+				l.nameToID = refreshed
+				if id, ok = refreshed[key]; !ok {
+					return nil, mapLabelCreateError(err, name)
+				}
+			} else {
+				id = created.Id
+			}
+			l.nameToID[key] = id
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func gmailImportStatePath(account, source string) (string, error) {
+	dir, err := config.EnsureGmailImportDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(source))
+	name := fmt.Sprintf("%s-%s.json", sanitizeAccountForPath(account), hex.EncodeToString(sum[:8]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadGmailImportState(path string) (gmailImportState, error) {
+	var state gmailImportState
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("read import state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveGmailImportState(path string, state gmailImportState) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func walkEMLDir(dir string, fn func(name string, raw []byte) error) error {
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && strings.EqualFold(filepath.Ext(p), ".eml") {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		raw, err := os.ReadFile(p) //nolint:gosec // walking a user-provided dir
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if err := fn(rel, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkMboxFile(path string, fn func(name string, raw []byte) error) error {
+	f, err := os.Open(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readMbox(f, func(n int, raw []byte) error {
+		return fn(fmt.Sprintf("%s#%d", filepath.Base(path), n), raw)
+	})
+}
+
+// readMbox splits an mbox stream on "From " separator lines and undoes
+// mboxrd quoting (">From " -> "From "). n is the 1-based message number.
+func readMbox(r io.Reader, fn func(n int, raw []byte) error) error {
+	br := bufio.NewReader(r)
+	var cur bytes.Buffer
+	inMsg := false
+	afterBlank := true
+	n := 0
+
+	flush := func() error {
+		if !inMsg {
+			return nil
+		}
+		n++
+		msg := bytes.TrimSuffix(cur.Bytes(), []byte("\n"))
+		out := make([]byte, len(msg))
+		copy(out, msg)
+		cur.Reset()
+		return fn(n, out)
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case afterBlank && bytes.HasPrefix(line, []byte("From ")):
+				if flushErr := flush(); flushErr != nil {
+					return flushErr
+				}
+				inMsg = true
+			case inMsg:
+				if line[0] == '>' && bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+					line = line[1:]
+				}
+				cur.Write(line)
+			}
+			afterBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return flush()
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func TestReadMbox_RoundTripsExport(t *testing.T) {
+	var mbox bytes.Buffer
+	mbox.Write(mboxEntry(&gmail.Message{Id: "a"}, []string{"INBOX"}, []byte("Subject: a\r\n\r\nFrom here\r\nbody a")))
+	mbox.Write(mboxEntry(&gmail.Message{Id: "b"}, nil, []byte("Subject: b\n\n>From quoted\n")))
+
+	var got []string
+	err := readMbox(&mbox, func(n int, raw []byte) error {
+		got = append(got, string(raw))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readMbox: %v", err)
+	}
+	want := []string{
+		"X-Gmail-Labels: INBOX\nSubject: a\n\nFrom here\nbody a\n",
+		"X-Gmail-Labels: \nSubject: b\n\n>From quoted\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d messages, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("message %d:\n%q\nwant:\n%q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGmailSourceLabels(t *testing.T) {
+	got := gmailSourceLabels([]byte("X-GM-THRID: 1\r\nX-Gmail-Labels: Inbox, Work/Projects,Opened\r\nSubject: x\r\n\r\nbody"))
+	if strings.Join(got, "|") != "Inbox|Work/Projects|Opened" {
+		t.Fatalf("unexpected labels: %q", got)
+	}
+	if got := gmailSourceLabels([]byte("Subject: x\n\nbody")); len(got) != 0 {
+		t.Fatalf("expected no labels, got %q", got)
+	}
+}
+
+func TestGmailImportCmd_ResumesFromState(t *testing.T) {
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+
+	var (
+		mu       sync.Mutex
+		imported []map[string]any
+		created  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/gmail/v1/users/me/labels" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+				{"id": "INBOX", "name": "INBOX"},
+				{"id": "CATEGORY_PROMOTIONS", "name": "CATEGORY_PROMOTIONS"},
+				{"id": "Label_1", "name": "Legacy"},
+			}})
+		case r.URL.Path == "/gmail/v1/users/me/labels" && r.Method == http.MethodPost:
+			var label map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&label)
+			name, _ := label["name"].(string)
+			created = append(created, name)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_new_" + name, "name": name})
+		case r.URL.Path == "/upload/gmail/v1/users/me/messages/import" && r.Method == http.MethodPost:
+			if got := r.URL.Query().Get("neverMarkSpam"); got != "true" {
+				t.Errorf("expected neverMarkSpam=true, got %q", got)
+			}
+			if got := r.URL.Query().Get("internalDateSource"); got != "receivedTime" {
+				t.Errorf("expected internalDateSource=receivedTime, got %q", got)
+			}
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			var meta map[string]any
+			part, _ := mr.NextPart()
+			_ = json.NewDecoder(part).Decode(&meta)
+			part, _ = mr.NextPart()
+			body, _ := io.ReadAll(part)
+			meta["raw"] = string(body)
+			imported = append(imported, meta)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "g" + string(rune('0'+len(imported)))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "legacy.mbox")
+	mbox := "From a@example.com Mon Jan  1 00:00:00 2024\n" +
+		"X-Gmail-Labels: Inbox,Category Promotions,Opened,Old\n" +
+		"Subject: one\n\nbody one\n\n" +
+		"From b@example.com Mon Jan  1 00:00:00 2024\n" +
+		"Subject: two\n\nbody two\n"
+	if writeErr := os.WriteFile(src, []byte(mbox), 0o600); writeErr != nil {
+		t.Fatalf("write: %v", writeErr)
+	}
+	statePath := filepath.Join(dir, "state.json")
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+	flags := &RootFlags{Account: "a@b.com"}
+
+	run := func() map[string]any {
+		t.Helper()
+		out := captureStdout(t, func() {
+			cmd := &GmailImportCmd{
+				Source:             src,
+				Label:              []string{"Migrated", "Old=Legacy"},
+				Mode:               gmailImportModeImport,
+				NeverMarkSpam:      true,
+				InternalDateSource: "receivedTime",
+				Concurrency:        2,
+				State:              statePath,
+			}
+			if runErr := cmd.Run(ctx, flags); runErr != nil {
+				t.Fatalf("Run: %v", runErr)
+			}
+		})
+		var parsed map[string]any
+		if jsonErr := json.Unmarshal([]byte(out), &parsed); jsonErr != nil {
+			t.Fatalf("json: %v (%q)", jsonErr, out)
+		}
+		return parsed
+	}
+
+	first := run()
+	if first["imported"] != float64(2) || first["skipped"] != float64(0) {
+		t.Fatalf("unexpected first run: %v", first)
+	}
+	if len(created) != 1 || created[0] != "Migrated" {
+		t.Fatalf("expected only Migrated label created, got %v", created)
+	}
+	for _, msg := range imported {
+		raw, _ := msg["raw"].(string)
+		labels, _ := msg["labelIds"].([]any)
+		var ids []string
+		for _, l := range labels {
+			ids = append(ids, l.(string))
+		}
+		switch {
+		case strings.Contains(raw, "Subject: one"):
+			if strings.Join(ids, ",") != "INBOX,CATEGORY_PROMOTIONS,Label_1,Label_new_Migrated" {
+				t.Fatalf("unexpected labels for one: %v", ids)
+			}
+		case strings.Contains(raw, "Subject: two"):
+			if strings.Join(ids, ",") != "Label_new_Migrated" {
+				t.Fatalf("unexpected labels for two: %v", ids)
+			}
+		default:
+			t.Fatalf("unexpected message: %q", raw)
+		}
+	}
+
+	second := run()
+	if second["imported"] != float64(0) || second["skipped"] != float64(2) {
+		t.Fatalf("unexpected second run: %v", second)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("expected no new uploads, got %d", len(imported))
+	}
+}
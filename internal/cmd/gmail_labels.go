@@ -214,9 +214,14 @@ func fetchLabelNameToID(svc *gmail.Service) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	m := make(map[string]string, len(resp.Labels))
-	for _, l := range resp.Labels {
-		if l.Id == "" {
+	return labelNameToID(resp.Labels), nil
+}
+
+// labelNameToID maps lowercased label IDs and names to label IDs.
+func labelNameToID(labels []*gmail.Label) map[string]string {
+	m := make(map[string]string, len(labels)*2)
+	for _, l := range labels {
+		if l == nil || l.Id == "" {
 			continue
 		}
 		m[strings.ToLower(l.Id)] = l.Id
@@ -224,7 +229,7 @@ func fetchLabelNameToID(svc *gmail.Service) (map[string]string, error) {
 			m[strings.ToLower(l.Name)] = l.Id
 		}
 	}
-	return m, nil
+	return m
 }
 
 func fetchLabelNameOnlyToID(svc *gmail.Service) (map[string]string, error) {
@@ -309,9 +314,15 @@ func fetchLabelIDToName(svc *gmail.Service) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	m := make(map[string]string, len(resp.Labels))
-	for _, l := range resp.Labels {
-		if l.Id == "" {
+	return labelIDToName(resp.Labels), nil
+}
+
+// labelIDToName maps label IDs to names, falling back to the ID for
+// labels without one.
+func labelIDToName(labels []*gmail.Label) map[string]string {
+	m := make(map[string]string, len(labels))
+	for _, l := range labels {
+		if l == nil || l.Id == "" {
 			continue
 		}
 		if l.Name != "" {
@@ -320,5 +331,5 @@ func fetchLabelIDToName(svc *gmail.Service) (map[string]string, error) {
 			m[l.Id] = l.Id
 		}
 	}
-	return m, nil
+	return m
 }
@@ -0,0 +1,736 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/tracking"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	gmailMergeStateVersion = 1
+
+	gmailMergeStatusSent    = "sent"
+	gmailMergeStatusSkipped = "skipped"
+	gmailMergeStatusFailed  = "failed"
+)
+
+type GmailMergeCmd struct {
+	Template  string   `name:"template" required:"" help:"Template file (.md, .txt or .html); optional front matter sets subject/cc/bcc/reply-to"`
+	Data      string   `name:"data" required:"" help:"Recipients: a .csv/.tsv file or <spreadsheetId>!<range> (first row is the header)"`
+	Subject   string   `name:"subject" help:"Subject template (overrides front matter)"`
+	ToColumn  string   `name:"to-column" help:"Column holding the recipient address" default:"email"`
+	Attach    []string `name:"attach" help:"Attachment for every message (repeatable)"`
+	AttachCol string   `name:"attach-column" help:"Column with per-row attachment paths (comma or semicolon separated)"`
+	From      string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Rate      float64  `name:"rate" help:"Max messages per minute (0 = no throttling)" default:"20"`
+	Limit     int      `name:"limit" help:"Send at most N messages this run (0 = all)"`
+	Track     bool     `name:"track" help:"Enable per-recipient open tracking (HTML bodies; requires tracking setup)"`
+	Results   string   `name:"results" help:"Write per-row results to a .csv file or <spreadsheetId>!<sheet>"`
+	Restart   bool     `name:"restart" help:"Ignore saved progress and send to every row again"`
+	State     string   `name:"state" help:"Progress file path (default: gogcli state dir)"`
+}
+
+// gmailMergeState records sent rows by data row number (1-based, header
+// excluded) so a re-run resumes after the last successful row.
+type gmailMergeState struct {
+	Version   int                      `json:"version"`
+	Account   string                   `json:"account"`
+	Template  string                   `json:"template"`
+	Data      string                   `json:"data"`
+	UpdatedAt string                   `json:"updatedAt,omitempty"`
+	Sent      map[int]gmailMergeResult `json:"sent"`
+}
+
+type gmailMergeResult struct {
+	Row        int    `json:"-"`
+	To         string `json:"to"`
+	Status     string `json:"-"`
+	MessageID  string `json:"messageId"`
+	ThreadID   string `json:"threadId"`
+	TrackingID string `json:"trackingId,omitempty"`
+	SentAt     string `json:"sentAt"`
+	Error      string `json:"-"`
+}
+
+// gmailMergeMessage is one rendered row.
+type gmailMergeMessage struct {
+	Row         int
+	To          string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Body        string
+	BodyHTML    string
+	Attachments []string
+	Err         error
+}
+
+type gmailMergeTemplate struct {
+	subject  *template.Template
+	cc       *template.Template
+	bcc      *template.Template
+	replyTo  *template.Template
+	text     *template.Template
+	html     *htmltemplate.Template
+	markdown bool
+}
+
+func (c *GmailMergeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Rate < 0 {
+		return usage("--rate must be >= 0")
+	}
+	if c.Limit < 0 {
+		return usage("--limit must be >= 0")
+	}
+	toColumn := strings.TrimSpace(c.ToColumn)
+	if toColumn == "" {
+		return usage("empty --to-column")
+	}
+
+	templatePath, err := config.ExpandPath(strings.TrimSpace(c.Template))
+	if err != nil {
+		return err
+	}
+	if templatePath, err = filepath.Abs(templatePath); err != nil {
+		return err
+	}
+	tmpl, err := loadGmailMergeTemplate(templatePath, c.Subject)
+	if err != nil {
+		return err
+	}
+	if c.Track && tmpl.text != nil && !tmpl.markdown {
+		return usage("--track requires an HTML body (.html or .md template)")
+	}
+
+	staticAttach := make([]string, 0, len(c.Attach))
+	for _, p := range c.Attach {
+		expanded, expandErr := config.ExpandPath(p)
+		if expandErr != nil {
+			return expandErr
+		}
+		staticAttach = append(staticAttach, expanded)
+	}
+
+	dataSpec := strings.TrimSpace(c.Data)
+	values, err := loadGmailMergeData(ctx, account, dataSpec)
+	if err != nil {
+		return err
+	}
+	if len(values) < 2 {
+		return usage("--data has no rows below the header")
+	}
+	records := sheetValuesRecords(values)
+	if _, ok := records[0][toColumn]; !ok {
+		return usagef("--to-column %q not found in the data header", toColumn)
+	}
+	if c.AttachCol != "" {
+		if _, ok := records[0][c.AttachCol]; !ok {
+			return usagef("--attach-column %q not found in the data header", c.AttachCol)
+		}
+	}
+
+	messages := make([]gmailMergeMessage, len(records))
+	for i, rec := range records {
+		messages[i] = tmpl.render(i+1, rec, toColumn, c.AttachCol, staticAttach)
+	}
+
+	statePath := strings.TrimSpace(c.State)
+	if statePath == "" {
+		statePath, err = gmailMergeStatePath(account, templatePath, dataSpec)
+	} else {
+		statePath, err = config.ExpandPath(statePath)
+	}
+	if err != nil {
+		return err
+	}
+	state, err := loadGmailMergeState(statePath)
+	if err != nil {
+		return err
+	}
+	if c.Restart || state.Sent == nil {
+		state.Sent = map[int]gmailMergeResult{}
+	}
+	state.Version = gmailMergeStateVersion
+	state.Account = account
+	state.Template = templatePath
+	state.Data = dataSpec
+
+	// A recorded row must still address the same recipient; otherwise the
+	// data was edited (rows inserted/removed) and resuming would misfire.
+	for _, m := range messages {
+		if prev, ok := state.Sent[m.Row]; ok && m.Err == nil && !strings.EqualFold(prev.To, m.To) {
+			return usagef("data changed since the last run (row %d was %s, now %s); use --restart or a new --state", m.Row, prev.To, m.To)
+		}
+	}
+
+	pending := 0
+	var preview *gmailMergeMessage
+	for i, m := range messages {
+		if _, ok := state.Sent[m.Row]; ok || m.Err != nil {
+			continue
+		}
+		pending++
+		if preview == nil {
+			preview = &messages[i]
+		}
+	}
+
+	if flags != nil && flags.DryRun {
+		renderErrors := []map[string]any{}
+		for _, m := range messages {
+			if m.Err != nil {
+				renderErrors = append(renderErrors, map[string]any{"row": m.Row, "error": m.Err.Error()})
+			}
+		}
+		req := map[string]any{
+			"template":      templatePath,
+			"data":          dataSpec,
+			"rows":          len(messages),
+			"already_sent":  len(state.Sent),
+			"pending":       pending,
+			"render_errors": renderErrors,
+			"from":          strings.TrimSpace(c.From),
+			"track":         c.Track,
+		}
+		if preview != nil {
+			req["first"] = map[string]any{
+				"row":         preview.Row,
+				"to":          preview.To,
+				"cc":          preview.Cc,
+				"bcc":         preview.Bcc,
+				"subject":     preview.Subject,
+				"body":        preview.Body,
+				"body_html":   preview.BodyHTML,
+				"attachments": preview.Attachments,
+			}
+		}
+		return dryRunExit(ctx, flags, "gmail.merge", req)
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	fromAddr, _, err := resolveSendFrom(ctx, svc, account, c.From)
+	if err != nil {
+		return err
+	}
+	var trackingCfg *tracking.Config
+	if c.Track {
+		trackingCfg, err = tracking.LoadConfig(account)
+		if err != nil {
+			return fmt.Errorf("load tracking config: %w", err)
+		}
+		if !trackingCfg.IsConfigured() {
+			return fmt.Errorf("tracking not configured; run 'gog gmail track setup' first")
+		}
+	}
+
+	save := func() error {
+		state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		return saveGmailMergeState(statePath, state)
+	}
+
+	var interval time.Duration
+	if c.Rate > 0 {
+		interval = time.Duration(float64(time.Minute) / c.Rate)
+	}
+	results := make([]gmailMergeResult, 0, len(messages))
+	sent, skipped, failed := 0, 0, 0
+	var lastSend time.Time
+	var runErr error
+	for _, m := range messages {
+		if prev, ok := state.Sent[m.Row]; ok {
+			prev.Row, prev.Status = m.Row, gmailMergeStatusSkipped
+			results = append(results, prev)
+			skipped++
+			continue
+		}
+		res := gmailMergeResult{Row: m.Row, To: m.To}
+		if m.Err != nil {
+			res.Status, res.Error = gmailMergeStatusFailed, m.Err.Error()
+			results = append(results, res)
+			failed++
+			u.Err().Printf("row %d: %v", m.Row, m.Err)
+			continue
+		}
+		if runErr != nil || (c.Limit > 0 && sent >= c.Limit) {
+			// Not attempted this run; a re-run picks it up.
+			continue
+		}
+		if interval > 0 && !lastSend.IsZero() {
+			if waitErr := sleepContext(ctx, time.Until(lastSend.Add(interval))); waitErr != nil {
+				runErr = waitErr
+				continue
+			}
+		}
+		lastSend = time.Now()
+
+		out, sendErr := c.send(ctx, svc, fromAddr, trackingCfg, m)
+		if sendErr != nil {
+			res.Status, res.Error = gmailMergeStatusFailed, sendErr.Error()
+			results = append(results, res)
+			failed++
+			u.Err().Printf("row %d (%s): %v", m.Row, m.To, sendErr)
+			continue
+		}
+		res.Status = gmailMergeStatusSent
+		res.MessageID, res.ThreadID, res.TrackingID = out.MessageID, out.ThreadID, out.TrackingID
+		res.SentAt = time.Now().UTC().Format(time.RFC3339)
+		results = append(results, res)
+		state.Sent[m.Row] = res
+		sent++
+		if saveErr := save(); saveErr != nil {
+			return saveErr
+		}
+	}
+	if err := save(); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(c.Results) != "" {
+		if err := writeGmailMergeResults(ctx, account, strings.TrimSpace(c.Results), results); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	remaining := len(messages) - sent - skipped - failed
+	if outfmt.IsJSON(ctx) {
+		items := make([]map[string]any, 0, len(results))
+		for _, r := range results {
+			items = append(items, gmailMergeResultMap(r))
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"from":      fromAddr,
+			"sent":      sent,
+			"skipped":   skipped,
+			"failed":    failed,
+			"remaining": remaining,
+			"results":   items,
+		}); err != nil {
+			return err
+		}
+	} else {
+		u.Out().Printf("from\t%s", fromAddr)
+		u.Out().Printf("sent\t%d", sent)
+		u.Out().Printf("skipped\t%d", skipped)
+		u.Out().Printf("failed\t%d", failed)
+		u.Out().Printf("remaining\t%d", remaining)
+	}
+	if failed > 0 {
+		return fmt.Errorf("gmail merge: %d rows failed (fix and re-run to retry)", failed)
+	}
+	return nil
+}
+
+func (c *GmailMergeCmd) send(ctx context.Context, svc *gmail.Service, fromAddr string, trackingCfg *tracking.Config, m gmailMergeMessage) (sendResult, error) {
+	atts := make([]mailAttachment, 0, len(m.Attachments))
+	for _, p := range m.Attachments {
+		atts = append(atts, mailAttachment{Path: p})
+	}
+	results, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+		FromAddr:    fromAddr,
+		ReplyTo:     m.ReplyTo,
+		Subject:     m.Subject,
+		Body:        m.Body,
+		BodyHTML:    m.BodyHTML,
+		Attachments: atts,
+		Track:       c.Track,
+		TrackingCfg: trackingCfg,
+	}, []sendBatch{{To: []string{m.To}, Cc: m.Cc, Bcc: m.Bcc, TrackingRecipient: m.To}})
+	if err != nil {
+		return sendResult{}, err
+	}
+	return results[0], nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loadGmailMergeTemplate reads a template file. An optional front matter
+// block ("---" lines around "key: value" pairs) sets subject, cc, bcc and
+// reply-to; the rest is the body. .html bodies are HTML templates (row
+// values are escaped), .md bodies are sent as text plus rendered HTML, and
+// anything else is plain text.
+func loadGmailMergeTemplate(path, subjectOverride string) (*gmailMergeTemplate, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	meta, body, err := splitFrontMatter(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s := strings.TrimSpace(subjectOverride); s != "" {
+		meta["subject"] = s
+	}
+	if strings.TrimSpace(meta["subject"]) == "" {
+		return nil, usage("required: --subject or a subject: line in the template front matter")
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, usagef("template %s has an empty body", path)
+	}
+
+	parse := func(name, text string) (*template.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		t, parseErr := template.New(name).Option("missingkey=error").Parse(text)
+		if parseErr != nil {
+			return nil, fmt.Errorf("parse %s template: %w", name, parseErr)
+		}
+		return t, nil
+	}
+	out := &gmailMergeTemplate{}
+	if out.subject, err = parse("subject", meta["subject"]); err != nil {
+		return nil, err
+	}
+	if out.cc, err = parse("cc", meta["cc"]); err != nil {
+		return nil, err
+	}
+	if out.bcc, err = parse("bcc", meta["bcc"]); err != nil {
+		return nil, err
+	}
+	if out.replyTo, err = parse("reply-to", meta["reply-to"]); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		out.html, err = htmltemplate.New("body").Option("missingkey=error").Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("parse body template: %w", err)
+		}
+	case ".md", ".markdown":
+		out.markdown = true
+		fallthrough
+	default:
+		if out.text, err = parse("body", body); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func splitFrontMatter(text string) (map[string]string, string, error) {
+	meta := map[string]string{}
+	if !strings.HasPrefix(text, "---\n") {
+		return meta, text, nil
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n---") {
+			return nil, "", errors.New("unterminated front matter (missing closing ---)")
+		}
+		end = len(rest) - len("\n---")
+	}
+	for _, line := range strings.Split(rest[:end], "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid front matter line %q (want key: value)", line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch key {
+		case "subject", "cc", "bcc", "reply-to":
+		default:
+			return nil, "", fmt.Errorf("unknown front matter key %q (want subject, cc, bcc or reply-to)", key)
+		}
+		meta[key] = strings.TrimSpace(value)
+	}
+	body := ""
+	if end+len("\n---\n") <= len(rest) {
+		body = rest[end+len("\n---\n"):]
+	}
+	return meta, body, nil
+}
+
+func (t *gmailMergeTemplate) render(row int, rec map[string]any, toColumn, attachColumn string, staticAttach []string) gmailMergeMessage {
+	m := gmailMergeMessage{Row: row, To: strings.TrimSpace(fmt.Sprint(rec[toColumn]))}
+	fail := func(err error) gmailMergeMessage {
+		m.Err = err
+		return m
+	}
+	if m.To == "" {
+		return fail(fmt.Errorf("empty %s", toColumn))
+	}
+	exec := func(tmpl *template.Template) (string, error) {
+		if tmpl == nil {
+			return "", nil
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, rec); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	var err error
+	if m.Subject, err = exec(t.subject); err != nil {
+		return fail(err)
+	}
+	m.Subject = strings.TrimSpace(m.Subject)
+	if m.Subject == "" {
+		return fail(errors.New("empty subject"))
+	}
+	cc, err := exec(t.cc)
+	if err != nil {
+		return fail(err)
+	}
+	bcc, err := exec(t.bcc)
+	if err != nil {
+		return fail(err)
+	}
+	m.Cc, m.Bcc = splitCSV(cc), splitCSV(bcc)
+	if m.ReplyTo, err = exec(t.replyTo); err != nil {
+		return fail(err)
+	}
+	m.ReplyTo = strings.TrimSpace(m.ReplyTo)
+
+	if t.html != nil {
+		var buf bytes.Buffer
+		if err = t.html.Execute(&buf, rec); err != nil {
+			return fail(err)
+		}
+		m.BodyHTML = buf.String()
+	} else {
+		if m.Body, err = exec(t.text); err != nil {
+			return fail(err)
+		}
+		if t.markdown {
+			m.BodyHTML = markdownToHTML(m.Body)
+		}
+	}
+
+	m.Attachments = append(m.Attachments, staticAttach...)
+	if attachColumn != "" {
+		raw := strings.ReplaceAll(fmt.Sprint(rec[attachColumn]), ";", ",")
+		for _, p := range splitCSV(raw) {
+			expanded, expandErr := config.ExpandPath(p)
+			if expandErr != nil {
+				return fail(expandErr)
+			}
+			m.Attachments = append(m.Attachments, expanded)
+		}
+	}
+	for _, p := range m.Attachments {
+		if _, statErr := os.Stat(p); statErr != nil {
+			return fail(fmt.Errorf("attachment: %w", statErr))
+		}
+	}
+	return m
+}
+
+// isSheetsRangeSpec reports whether spec looks like "<spreadsheetId>!<range>"
+// rather than a file path.
+func isSheetsRangeSpec(spec string) bool {
+	id, _, ok := strings.Cut(spec, "!")
+	return ok && id != "" && !strings.ContainsAny(id, `./\`)
+}
+
+// loadGmailMergeData returns the header row followed by data rows.
+func loadGmailMergeData(ctx context.Context, account, spec string) ([][]any, error) {
+	if spec == "" {
+		return nil, usage("empty --data")
+	}
+	if isSheetsRangeSpec(spec) {
+		id, rng, _ := strings.Cut(spec, "!")
+		svc, err := newSheetsService(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := svc.Spreadsheets.Values.Get(normalizeGoogleID(id), cleanRange(rng)).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("read --data %s: %w", spec, err)
+		}
+		return resp.Values, nil
+	}
+
+	path, err := config.ExpandPath(spec)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	tsv := strings.EqualFold(filepath.Ext(path), ".tsv")
+	table, err := parseImportDelimited(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), tsv, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return table.Rows, nil
+}
+
+func gmailMergeResultMap(r gmailMergeResult) map[string]any {
+	item := map[string]any{"row": r.Row, "to": r.To, "status": r.Status}
+	if r.MessageID != "" {
+		item["messageId"] = r.MessageID
+		item["threadId"] = r.ThreadID
+	}
+	if r.TrackingID != "" {
+		item["trackingId"] = r.TrackingID
+	}
+	if r.SentAt != "" {
+		item["sentAt"] = r.SentAt
+	}
+	if r.Error != "" {
+		item["error"] = r.Error
+	}
+	return item
+}
+
+var gmailMergeResultHeader = []string{"row", "to", "status", "messageId", "threadId", "trackingId", "sentAt", "error"}
+
+func (r gmailMergeResult) cells() []string {
+	return []string{strconv.Itoa(r.Row), r.To, r.Status, r.MessageID, r.ThreadID, r.TrackingID, r.SentAt, r.Error}
+}
+
+// writeGmailMergeResults writes results to a CSV file or replaces the
+// contents of a sheet (created when missing) given as <spreadsheetId>!<sheet>.
+func writeGmailMergeResults(ctx context.Context, account, spec string, results []gmailMergeResult) error {
+	if !isSheetsRangeSpec(spec) {
+		path, err := config.ExpandPath(spec)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write(gmailMergeResultHeader)
+		for _, r := range results {
+			_ = w.Write(r.cells())
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		return os.WriteFile(path, buf.Bytes(), 0o600)
+	}
+
+	id, sheetPart, _ := strings.Cut(spec, "!")
+	id = normalizeGoogleID(id)
+	sheetTitle, _, err := splitA1Sheet(cleanRange(sheetPart) + "!A1")
+	if err != nil {
+		return err
+	}
+	svc, err := newSheetsService(ctx, account)
+	if err != nil {
+		return err
+	}
+	ids, err := fetchSheetIDMap(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := ids[sheetTitle]; !ok {
+		if _, err = svc.Spreadsheets.BatchUpdate(id, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: sheetTitle}}}},
+		}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("add results sheet %q: %w", sheetTitle, err)
+		}
+	}
+	prefix := formatSheetPrefix(sheetTitle)
+	if _, err = svc.Spreadsheets.Values.Clear(id, strings.TrimSuffix(prefix, "!"), &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("clear results sheet: %w", err)
+	}
+	values := make([][]any, 0, len(results)+1)
+	values = append(values, stringsToCells(gmailMergeResultHeader))
+	for _, r := range results {
+		values = append(values, stringsToCells(r.cells()))
+	}
+	if _, err = svc.Spreadsheets.Values.Update(id, prefix+"A1", &sheets.ValueRange{Values: values}).
+		ValueInputOption("RAW").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("write results sheet: %w", err)
+	}
+	return nil
+}
+
+func stringsToCells(in []string) []any {
+	out := make([]any, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
+func gmailMergeStatePath(account, template, data string) (string, error) {
+	dir, err := config.EnsureGmailMergeDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(template + "\x00" + data))
+	name := fmt.Sprintf("%s-%s.json", sanitizeAccountForPath(account), hex.EncodeToString(sum[:8]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadGmailMergeState(path string) (gmailMergeState, error) {
+	var state gmailMergeState
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("read merge state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveGmailMergeState(path string, state gmailMergeState) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
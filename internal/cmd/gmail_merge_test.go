@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jibankumarpanda/gogcli/internal/fakegoogle"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	meta, body, err := splitFrontMatter("---\nsubject: Hi {{.name}}\ncc: {{.manager}}\n---\nHello\n")
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if meta["subject"] != "Hi {{.name}}" || meta["cc"] != "{{.manager}}" || body != "Hello\n" {
+		t.Fatalf("unexpected: %v %q", meta, body)
+	}
+	if _, _, err = splitFrontMatter("---\nfrom: x\n---\nbody"); err == nil {
+		t.Fatalf("expected unknown key error")
+	}
+	if meta, body, _ = splitFrontMatter("# Title\n"); len(meta) != 0 || body != "# Title\n" {
+		t.Fatalf("expected no front matter, got %v %q", meta, body)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	got := markdownToHTML("# Hi <you>\n\nSee **this** and [docs](https://x.test/?a=1&b=2).\n\n- one\n- two\n")
+	want := "<h1>Hi &lt;you&gt;</h1>\n" +
+		"<p>See <strong>this</strong> and <a href=\"https://x.test/?a=1&amp;b=2\">docs</a>.</p>\n" +
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGmailMerge_FakeGoogle(t *testing.T) {
+	srv := httptest.NewServer(fakegoogle.New())
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "invite.md")
+	dataPath := filepath.Join(dir, "people.csv")
+	statePath := filepath.Join(dir, "state.json")
+	resultsPath := filepath.Join(dir, "results.csv")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(tmplPath, "---\nsubject: Welcome, {{.name}}\n---\nHi **{{.name}}**, your code is {{.code}}.\n")
+	write(dataPath, "email,name,code\nada@example.com,Ada,A1\n,Nobody,X\nbob@example.com,Bob,B2\n")
+
+	merge := func() (map[string]any, error) {
+		var execErr error
+		out := captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				execErr = Execute([]string{
+					"--json", "--endpoint", srv.URL, "--account", "me@example.com",
+					"gmail", "merge", "--template", tmplPath, "--data", dataPath,
+					"--rate", "0", "--state", statePath, "--results", resultsPath,
+				})
+			})
+		})
+		var parsed map[string]any
+		if execErr == nil || ExitCode(execErr) != 2 {
+			if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+				t.Fatalf("json parse: %v\nout=%q", err, out)
+			}
+		}
+		return parsed, execErr
+	}
+
+	first, err := merge()
+	if err == nil || !strings.Contains(err.Error(), "1 rows failed") {
+		t.Fatalf("expected a failed row, got %v", err)
+	}
+	if first["sent"] != 2.0 || first["failed"] != 1.0 {
+		t.Fatalf("unexpected first run: %v", first)
+	}
+	results := first["results"].([]any)
+	adaID, _ := results[0].(map[string]any)["messageId"].(string)
+	if adaID == "" {
+		t.Fatalf("missing messageId: %v", results[0])
+	}
+
+	raw := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if execErr := Execute([]string{"--json", "--endpoint", srv.URL, "--account", "me@example.com", "gmail", "get", adaID, "--format", "raw"}); execErr != nil {
+				t.Fatalf("gmail get: %v", execErr)
+			}
+		})
+	})
+	var got struct {
+		Message struct {
+			Raw string `json:"raw"`
+		} `json:"message"`
+	}
+	if err = json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatalf("parse get: %v", err)
+	}
+	decoded, err := decodeBase64URLBytes(got.Message.Raw)
+	if err != nil {
+		t.Fatalf("decode raw: %v", err)
+	}
+	for _, want := range []string{"Subject: Welcome, Ada", "To: ada@example.com", "<strong>Ada</strong>", "your code is A1"} {
+		if !strings.Contains(string(decoded), want) {
+			t.Fatalf("message missing %q:\n%s", want, decoded)
+		}
+	}
+
+	// Fix the bad row and resume: only it is sent.
+	write(dataPath, "email,name,code\nada@example.com,Ada,A1\ncy@example.com,Cy,C3\nbob@example.com,Bob,B2\n")
+	second, err := merge()
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if second["sent"] != 1.0 || second["skipped"] != 2.0 {
+		t.Fatalf("unexpected second run: %v", second)
+	}
+	csvOut, _ := os.ReadFile(resultsPath)
+	if !strings.HasPrefix(string(csvOut), "row,to,status,messageId,threadId,trackingId,sentAt,error\n1,ada@example.com,skipped,"+adaID) ||
+		!strings.Contains(string(csvOut), "\n2,cy@example.com,sent,") {
+		t.Fatalf("unexpected results csv:\n%s", csvOut)
+	}
+
+	// Reordered data no longer lines up with recorded rows.
+	write(dataPath, "email,name,code\nbob@example.com,Bob,B2\nada@example.com,Ada,A1\n")
+	if _, err = merge(); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for changed data, got %v", err)
+	}
+}
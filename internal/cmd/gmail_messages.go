@@ -61,6 +61,24 @@ func (c *GmailMessagesSearchCmd) Run(ctx context.Context, flags *RootFlags) erro
 		return resp.Messages, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		idToName, labelsErr := fetchLabelIDToName(svc)
+		if labelsErr != nil {
+			return labelsErr
+		}
+		loc, locErr := resolveOutputLocation(c.Timezone, c.Local)
+		if locErr != nil {
+			return locErr
+		}
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*gmail.Message) error {
+			items, detailsErr := fetchMessageDetails(ctx, svc, page, idToName, loc, c.IncludeBody)
+			if detailsErr != nil {
+				return detailsErr
+			}
+			return writeNDJSON(ctx, items)
+		})
+	}
+
 	var messages []*gmail.Message
 	nextPageToken := ""
 	if c.All {
@@ -142,48 +142,9 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	sendAsList, sendAsListErr := listSendAs(ctx, svc)
-
-	// Determine the From address
-	fromAddr := account
-	sendingEmail := account // The email we're sending from (without display name)
-	if fromEmail := strings.TrimSpace(c.From); fromEmail != "" {
-		// Validate that this is a configured and verified send-as alias.
-		var sa *gmail.SendAs
-		if sendAsListErr == nil {
-			sa = findSendAsByEmail(sendAsList, fromEmail)
-			if sa == nil {
-				return fmt.Errorf("invalid --from address %q: not found in send-as settings", fromEmail)
-			}
-		} else {
-			// Fallback: preserve legacy behavior if we cannot list settings.
-			var getErr error
-			sa, getErr = svc.Users.Settings.SendAs.Get("me", fromEmail).Context(ctx).Do()
-			if getErr != nil {
-				return fmt.Errorf("invalid --from address %q: %w", fromEmail, getErr)
-			}
-		}
-
-		if sa.VerificationStatus != gmailVerificationAccepted {
-			return fmt.Errorf("--from address %q is not verified (status: %s)", fromEmail, sa.VerificationStatus)
-		}
-
-		sendingEmail = fromEmail
-		fromAddr = fromEmail
-
-		if displayName := strings.TrimSpace(sa.DisplayName); displayName != "" {
-			fromAddr = displayName + " <" + fromEmail + ">"
-		}
-	} else {
-		// No --from specified: best-effort look up the primary account's display name.
-		displayName := ""
-		if sendAsListErr == nil {
-			displayName = primaryDisplayNameFromSendAsList(sendAsList, account)
-		}
-		if displayName != "" {
-			fromAddr = displayName + " <" + account + ">"
-		}
-		// If lookup fails, we just use the plain email address (no error)
+	fromAddr, sendingEmail, err := resolveSendFrom(ctx, svc, account, c.From)
+	if err != nil {
+		return err
 	}
 
 	// Fetch reply info (includes recipient headers for reply-all, and body for quoting)
@@ -269,6 +230,57 @@ func (c *GmailSendCmd) resolveTrackingConfig(account string, toRecipients, ccRec
 	return trackingCfg, nil
 }
 
+// resolveSendFrom returns the From header value and the bare sending address.
+// A non-empty from must be a verified send-as alias; otherwise the account is
+// used, with its display name when one can be looked up.
+func resolveSendFrom(ctx context.Context, svc *gmail.Service, account, from string) (string, string, error) {
+	sendAsList, sendAsListErr := listSendAs(ctx, svc)
+
+	// Determine the From address
+	fromAddr := account
+	sendingEmail := account // The email we're sending from (without display name)
+	if fromEmail := strings.TrimSpace(from); fromEmail != "" {
+		// Validate that this is a configured and verified send-as alias.
+		var sa *gmail.SendAs
+		if sendAsListErr == nil {
+			sa = findSendAsByEmail(sendAsList, fromEmail)
+			if sa == nil {
+				return "", "", fmt.Errorf("invalid --from address %q: not found in send-as settings", fromEmail)
+			}
+		} else {
+			// Fallback: preserve legacy behavior if we cannot list settings.
+			var getErr error
+			sa, getErr = svc.Users.Settings.SendAs.Get("me", fromEmail).Context(ctx).Do()
+			if getErr != nil {
+				return "", "", fmt.Errorf("invalid --from address %q: %w", fromEmail, getErr)
+			}
+		}
+
+		if sa.VerificationStatus != gmailVerificationAccepted {
+			return "", "", fmt.Errorf("--from address %q is not verified (status: %s)", fromEmail, sa.VerificationStatus)
+		}
+
+		sendingEmail = fromEmail
+		fromAddr = fromEmail
+
+		if displayName := strings.TrimSpace(sa.DisplayName); displayName != "" {
+			fromAddr = displayName + " <" + fromEmail + ">"
+		}
+	} else {
+		// No --from specified: best-effort look up the primary account's display name.
+		displayName := ""
+		if sendAsListErr == nil {
+			displayName = primaryDisplayNameFromSendAsList(sendAsList, account)
+		}
+		if displayName != "" {
+			fromAddr = displayName + " <" + account + ">"
+		}
+		// If lookup fails, we just use the plain email address (no error)
+	}
+
+	return fromAddr, sendingEmail, nil
+}
+
 func listSendAs(ctx context.Context, svc *gmail.Service) ([]*gmail.SendAs, error) {
 	if svc == nil {
 		return nil, nil
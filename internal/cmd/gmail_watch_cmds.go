@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -25,18 +26,20 @@ var (
 )
 
 type GmailWatchCmd struct {
-	Start  GmailWatchStartCmd  `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
-	Status GmailWatchStatusCmd `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
-	Renew  GmailWatchRenewCmd  `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
-	Stop   GmailWatchStopCmd   `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
-	Serve  GmailWatchServeCmd  `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Start      GmailWatchStartCmd      `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
+	Status     GmailWatchStatusCmd     `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
+	Renew      GmailWatchRenewCmd      `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
+	Stop       GmailWatchStopCmd       `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
+	Serve      GmailWatchServeCmd      `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Run        GmailWatchRunCmd        `cmd:"" name:"run" help:"Receive changes without a public endpoint (Pub/Sub pull or history polling)"`
+	Deliveries GmailWatchDeliveriesCmd `cmd:"" name:"deliveries" help:"Inspect and replay queued or dead-lettered hook deliveries"`
 }
 
 type GmailWatchStartCmd struct {
 	Topic       string   `name:"topic" help:"Pub/Sub topic (projects/.../topics/...)"`
 	Labels      []string `name:"label" help:"Label IDs or names (repeatable, comma-separated)"`
 	TTL         string   `name:"ttl" help:"Renew after duration (seconds or Go duration)"`
-	HookURL     string   `name:"hook-url" help:"Webhook URL to forward messages"`
+	HookURL     string   `name:"hook-url" aliases:"sink" help:"Webhook URL to forward messages, or a sink: exec:<command>, file:<path>, unix:<socket>, stdout"`
 	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
 	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
@@ -169,6 +172,8 @@ func (c *GmailWatchRenewCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if ttl == 0 {
 		updated.RenewAfterMs = state.RenewAfterMs
 	}
+	updated.LastRenewStatus = gmailWatchRenewStatusOK
+	updated.LastRenewAtMs = updated.UpdatedAtMs
 
 	if err := store.Update(func(s *gmailWatchState) error {
 		*s = updated
@@ -213,16 +218,24 @@ func (c *GmailWatchStopCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type GmailWatchServeCmd struct {
-	Bind          string   `name:"bind" help:"Bind address" default:"127.0.0.1"`
-	Port          int      `name:"port" help:"Listen port" default:"8788"`
-	Path          string   `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	Bind         string              `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port         int                 `name:"port" help:"Listen port" default:"8788"`
+	Path         string              `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	VerifyOIDC   bool                `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
+	OIDCEmail    string              `name:"oidc-email" help:"Expected service account email"`
+	OIDCAudience string              `name:"oidc-audience" help:"Expected OIDC audience"`
+	SharedToken  string              `name:"token" help:"Shared token for x-gog-token or ?token="`
+	All          bool                `name:"all" help:"Serve every account with a stored token (same as --all-accounts); pushes are routed by emailAddress"`
+	AutoRenew    bool                `name:"auto-renew" help:"Renew the Gmail watch in the background when renew_after passes, using the stored topic and labels"`
+	Hook         GmailWatchHookFlags `embed:""`
+}
+
+// GmailWatchHookFlags are the delivery settings shared by every way of
+// receiving Gmail changes (push, pull and poll).
+type GmailWatchHookFlags struct {
 	Timezone      string   `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
 	Local         bool     `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
-	VerifyOIDC    bool     `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
-	OIDCEmail     string   `name:"oidc-email" help:"Expected service account email"`
-	OIDCAudience  string   `name:"oidc-audience" help:"Expected OIDC audience"`
-	SharedToken   string   `name:"token" help:"Shared token for x-gog-token or ?token="`
-	HookURL       string   `name:"hook-url" help:"Webhook URL to forward messages"`
+	HookURL       string   `name:"hook-url" aliases:"sink" help:"Webhook URL to forward messages, or a sink: exec:<command>, file:<path>, unix:<socket>, stdout"`
 	HookToken     string   `name:"hook-token" help:"Webhook bearer token"`
 	IncludeBody   bool     `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes      int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
@@ -233,7 +246,7 @@ type GmailWatchServeCmd struct {
 
 func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
-	account, err := requireAccount(flags)
+	accounts, err := c.accounts(flags)
 	if err != nil {
 		return err
 	}
@@ -253,26 +266,116 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		return usage("--oidc-audience requires --verify-oidc")
 	}
 
-	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	var validator *idtoken.Validator
+	if c.VerifyOIDC {
+		validator, err = newOIDCValidator(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	servers := make([]*gmailWatchServer, 0, len(accounts))
+	for _, account := range accounts {
+		server, err := c.Hook.newServer(ctx, kctx, flags, account, loadGmailWatchStore)
+		if err != nil {
+			if len(accounts) > 1 {
+				return fmt.Errorf("%s: %w", account, err)
+			}
+			return err
+		}
+		if len(accounts) > 1 {
+			prefix := account + ": "
+			logf, warnf := server.logf, server.warnf
+			server.logf = func(format string, args ...any) { logf(prefix+format, args...) }
+			server.warnf = func(format string, args ...any) { warnf(prefix+format, args...) }
+		}
+		if c.AutoRenew && strings.TrimSpace(server.store.Get().Topic) == "" {
+			return usagef("--auto-renew: stored watch for %s has no topic (run gmail watch start)", account)
+		}
+		server.validator = validator
+		server.cfg.Bind = c.Bind
+		server.cfg.Port = c.Port
+		server.cfg.Path = c.Path
+		server.cfg.VerifyOIDC = c.VerifyOIDC
+		server.cfg.OIDCEmail = c.OIDCEmail
+		server.cfg.OIDCAudience = c.OIDCAudience
+		server.cfg.SharedToken = c.SharedToken
+		servers = append(servers, server)
+	}
+
+	var handler http.Handler = servers[0]
+	if len(servers) > 1 {
+		handler = newGmailWatchRouter(servers)
+	}
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	if len(servers) > 1 {
+		u.Err().Printf("watch: listening on %s%s for %d accounts", addr, c.Path, len(servers))
+	} else {
+		u.Err().Printf("watch: listening on %s%s", addr, c.Path)
+	}
+
+	retryCtx, stopRetry := context.WithCancel(ctx)
+	defer stopRetry()
+	for _, server := range servers {
+		go server.retryDeliveries(retryCtx)
+		if c.AutoRenew {
+			go server.autoRenew(retryCtx)
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
+
+// multiAccount lets --accounts/--all-accounts select the mailboxes this one
+// process serves instead of starting a server per account.
+func (c *GmailWatchServeCmd) multiAccount() {}
+
+func (c *GmailWatchServeCmd) accounts(flags *RootFlags) ([]string, error) {
+	if strings.TrimSpace(flags.Accounts) == "" && !flags.AllAccounts && !c.All {
+		account, err := requireAccount(flags)
+		if err != nil {
+			return nil, err
+		}
+		return []string{account}, nil
+	}
+	if c.All && strings.TrimSpace(flags.Account) != "" {
+		return nil, usage("--all cannot be combined with --account")
+	}
+	resolved := *flags
+	resolved.AllAccounts = flags.AllAccounts || c.All
+	return fanoutAccounts(&resolved)
+}
+
+// newServer resolves the hook (falling back to the one stored with the watch)
+// and builds the handler that turns Gmail history into hook payloads.
+func (f GmailWatchHookFlags) newServer(ctx context.Context, kctx *kong.Context, flags *RootFlags, account string, load func(string) (*gmailWatchStore, error)) (*gmailWatchServer, error) {
+	u := ui.FromContext(ctx)
+	loc, err := resolveOutputLocation(f.Timezone, f.Local)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	historyTypes, err := parseHistoryTypes(c.HistoryTypes)
+	historyTypes, err := parseHistoryTypes(f.HistoryTypes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	store, err := loadGmailWatchStore(account)
+	store, err := load(account)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	state := store.Get()
 
-	hookURL := c.HookURL
-	hookToken := c.HookToken
-	includeBody := c.IncludeBody
-	maxBytes := c.MaxBytes
+	hookURL := f.HookURL
+	hookToken := f.HookToken
+	includeBody := f.IncludeBody
+	maxBytes := f.MaxBytes
 
 	if hookURL == "" && state.Hook != nil {
 		hookURL = state.Hook.URL
@@ -293,36 +396,21 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		if errors.Is(err, errNoHookConfigured) {
 			hook = nil
 		} else {
-			return err
+			return nil, err
 		}
 	}
-	if c.SaveHook && hook != nil {
+	if f.SaveHook && hook != nil {
 		if updateErr := store.Update(func(s *gmailWatchState) error {
 			s.Hook = hook
 			s.UpdatedAtMs = time.Now().UnixMilli()
 			return nil
 		}); updateErr != nil {
-			return updateErr
-		}
-	}
-
-	validator := (*idtoken.Validator)(nil)
-	if c.VerifyOIDC {
-		validator, err = newOIDCValidator(ctx)
-		if err != nil {
-			return err
+			return nil, updateErr
 		}
 	}
 
 	cfg := gmailWatchServeConfig{
 		Account:       account,
-		Bind:          c.Bind,
-		Port:          c.Port,
-		Path:          c.Path,
-		VerifyOIDC:    c.VerifyOIDC,
-		OIDCEmail:     c.OIDCEmail,
-		OIDCAudience:  c.OIDCAudience,
-		SharedToken:   c.SharedToken,
 		HookTimeout:   defaultHookRequestTimeoutSec * time.Second,
 		HistoryMax:    defaultHistoryMaxResults,
 		ResyncMax:     defaultHistoryResyncMax,
@@ -331,7 +419,7 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		IncludeBody:   includeBody,
 		MaxBodyBytes:  maxBytes,
 		DateLocation:  loc,
-		ExcludeLabels: splitCommaList(c.ExcludeLabels),
+		ExcludeLabels: splitCommaList(f.ExcludeLabels),
 		VerboseOutput: flags.Verbose,
 	}
 	if hook != nil {
@@ -345,27 +433,25 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		cfg.MaxBodyBytes = defaultHookMaxBytes
 	}
 
-	hookClient := &http.Client{Timeout: cfg.HookTimeout}
 	server := &gmailWatchServer{
 		cfg:             cfg,
 		store:           store,
-		validator:       validator,
 		newService:      newGmailService,
-		hookClient:      hookClient,
+		hookClient:      &http.Client{Timeout: cfg.HookTimeout},
 		excludeLabelIDs: stringSet(cfg.ExcludeLabels),
 		logf:            u.Err().Printf,
 		warnf:           u.Err().Printf,
 	}
-
-	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
-	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
-
-	httpServer := &http.Server{
-		Addr:              addr,
-		Handler:           server,
-		ReadHeaderTimeout: 5 * time.Second,
+	if hook != nil {
+		server.queue, err = newGmailWatchDeliveryQueue(account)
+		if err != nil {
+			return nil, err
+		}
+		server.queue.onDead = func(d gmailWatchDelivery) {
+			server.warnf("watch: delivery %s moved to dead letter after %d attempt(s): %s", d.ID, d.Attempts, d.LastError)
+		}
 	}
-	return listenAndServe(httpServer)
+	return server, nil
 }
 
 func writeWatchState(ctx context.Context, state gmailWatchState) error {
@@ -415,6 +501,18 @@ func writeWatchState(ctx context.Context, state gmailWatchState) error {
 	if state.LastPushMessageID != "" {
 		u.Out().Printf("last_push_message_id\t%s", state.LastPushMessageID)
 	}
+	if state.LastRenewStatus != "" {
+		u.Out().Printf("last_renew_status\t%s", state.LastRenewStatus)
+	}
+	if state.LastRenewAtMs > 0 {
+		u.Out().Printf("last_renew_at\t%s", formatUnixMillis(state.LastRenewAtMs))
+	}
+	if state.LastRenewError != "" {
+		u.Out().Printf("last_renew_error\t%s", state.LastRenewError)
+	}
+	if state.RenewFailures > 0 {
+		u.Out().Printf("renew_failures\t%d", state.RenewFailures)
+	}
 	return nil
 }
 
@@ -461,6 +559,9 @@ func hookFromFlags(url, token string, includeBody bool, maxBytes int, maxBytesCh
 		}
 		return nil, errNoHookConfigured
 	}
+	if err := validateGmailWatchSink(url, token); err != nil {
+		return nil, err
+	}
 	if maxBytes <= 0 {
 		if includeBody {
 			maxBytes = defaultHookMaxBytes
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type GmailWatchDeliveriesCmd struct {
+	List  GmailWatchDeliveriesListCmd  `cmd:"" name:"list" aliases:"ls" help:"List pending and dead-lettered hook deliveries"`
+	Retry GmailWatchDeliveriesRetryCmd `cmd:"" name:"retry" aliases:"replay" help:"Move dead-lettered deliveries back onto the queue and send them"`
+	Purge GmailWatchDeliveriesPurgeCmd `cmd:"" name:"purge" aliases:"rm,delete" help:"Delete dead-lettered (or pending) deliveries"`
+}
+
+type GmailWatchDeliveriesListCmd struct {
+	Pending bool `name:"pending" help:"Only show deliveries waiting to be sent"`
+	Dead    bool `name:"dead" help:"Only show dead-lettered deliveries"`
+}
+
+func (c *GmailWatchDeliveriesListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Pending && c.Dead {
+		return usage("--pending and --dead are mutually exclusive")
+	}
+	queue, err := newGmailWatchDeliveryQueue(account)
+	if err != nil {
+		return err
+	}
+
+	var pending, dead []gmailWatchDelivery
+	if !c.Dead {
+		if pending, err = queue.Pending(); err != nil {
+			return err
+		}
+	}
+	if !c.Pending {
+		if dead, err = queue.Dead(); err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{"account": account}
+		if !c.Dead {
+			out["pending"] = nonNilDeliveries(pending)
+		}
+		if !c.Pending {
+			out["dead"] = nonNilDeliveries(dead)
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
+	}
+	if len(pending) == 0 && len(dead) == 0 {
+		u.Err().Println("No deliveries")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tSTATE\tHISTORY\tMESSAGES\tATTEMPTS\tCREATED\tNEXT\tLAST_ERROR")
+	for _, d := range pending {
+		fmt.Fprintf(w, "%s\tpending\t%s\t%d\t%d\t%s\t%s\t%s\n", d.ID, deliveryHistoryID(d), deliveryMessageCount(d),
+			d.Attempts, formatUnixMillis(d.CreatedAtMs), formatUnixMillis(d.NextAttemptMs), d.LastError)
+	}
+	for _, d := range dead {
+		fmt.Fprintf(w, "%s\tdead\t%s\t%d\t%d\t%s\t%s\t%s\n", d.ID, deliveryHistoryID(d), deliveryMessageCount(d),
+			d.Attempts, formatUnixMillis(d.CreatedAtMs), "", d.LastError)
+	}
+	return nil
+}
+
+type GmailWatchDeliveriesRetryCmd struct {
+	IDs       []string `arg:"" name:"deliveryId" optional:"" help:"Dead-lettered delivery IDs (see deliveries list)"`
+	All       bool     `name:"all" help:"Retry every dead-lettered delivery"`
+	HookURL   string   `name:"hook-url" aliases:"sink" help:"Webhook URL or sink to send to (default: the hook stored with the watch)"`
+	HookToken string   `name:"hook-token" help:"Webhook bearer token"`
+	QueueOnly bool     `name:"queue-only" help:"Only requeue; leave sending to a running watch serve/run (implied while one is delivering)"`
+}
+
+func (c *GmailWatchDeliveriesRetryCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	ids := trimmedDeliveryIDs(c.IDs)
+	if len(ids) == 0 && !c.All {
+		return usage("specify delivery IDs or --all")
+	}
+	if len(ids) > 0 && c.All {
+		return usage("delivery IDs and --all are mutually exclusive")
+	}
+	if err := dryRunExit(ctx, flags, "gmail.watch.deliveries.retry", map[string]any{"account": account, "ids": ids, "all": c.All}); err != nil {
+		return err
+	}
+
+	queue, err := newGmailWatchDeliveryQueue(account)
+	if err != nil {
+		return err
+	}
+	requeued, err := queue.Requeue(ids)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 && len(requeued) != len(ids) {
+		return fmt.Errorf("dead-lettered deliveries not found: %s", strings.Join(missingDeliveryIDs(ids, requeued), ", "))
+	}
+
+	delivered := 0
+	var sendErr error
+	sent, busy := false, false
+	if !c.QueueOnly && len(requeued) > 0 {
+		server, err := newGmailWatchRetryServer(account, c.HookURL, c.HookToken)
+		if err != nil {
+			return err
+		}
+		if server != nil {
+			server.queue = queue
+			queue.onDead = func(d gmailWatchDelivery) {
+				u.Err().Printf("delivery %s moved to dead letter: %s", d.ID, d.LastError)
+			}
+			// Leave sending to a serve/run process that is already
+			// delivering, so payloads stay in order and go out once.
+			delivered, sent, sendErr = queue.TryDrain(ctx, server.sendHook)
+			busy = !sent
+		}
+	}
+	pending, err := queue.Pending()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{
+			"requeued":  requeued,
+			"delivered": delivered,
+			"pending":   len(pending),
+		}
+		if sendErr != nil {
+			out["error"] = sendErr.Error()
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
+	}
+	u.Out().Printf("requeued\t%d", len(requeued))
+	if sent {
+		u.Out().Printf("delivered\t%d", delivered)
+	}
+	u.Out().Printf("pending\t%d", len(pending))
+	if sendErr != nil {
+		u.Err().Printf("hook failed: %v (left queued)", sendErr)
+	} else if busy {
+		u.Err().Println("Another gmail watch process is delivering; it will send the requeued deliveries")
+	} else if !sent && len(pending) > 0 {
+		u.Err().Println("Pending deliveries are sent by the next gmail watch serve/run")
+	}
+	return nil
+}
+
+// newGmailWatchRetryServer builds a sender for the hook named by the flags or
+// stored with the watch; nil when neither exists.
+func newGmailWatchRetryServer(account, hookURL, hookToken string) (*gmailWatchServer, error) {
+	store, err := loadOrCreateGmailWatchStore(account)
+	if err != nil {
+		return nil, err
+	}
+	hookURL = strings.TrimSpace(hookURL)
+	if hookURL != "" {
+		if err := validateGmailWatchSink(hookURL, hookToken); err != nil {
+			return nil, err
+		}
+	} else {
+		state := store.Get()
+		if state.Hook == nil || state.Hook.URL == "" {
+			return nil, nil
+		}
+		hookURL = state.Hook.URL
+		if hookToken == "" {
+			hookToken = state.Hook.Token
+		}
+	}
+	return &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Account:   account,
+			HookURL:   hookURL,
+			HookToken: hookToken,
+		},
+		store:      store,
+		hookClient: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}, nil
+}
+
+type GmailWatchDeliveriesPurgeCmd struct {
+	IDs     []string `arg:"" name:"deliveryId" optional:"" help:"Delivery IDs to delete (default: all dead-lettered)"`
+	Pending bool     `name:"pending" help:"Also delete matching deliveries from the pending queue"`
+}
+
+func (c *GmailWatchDeliveriesPurgeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	ids := trimmedDeliveryIDs(c.IDs)
+
+	action := fmt.Sprintf("delete all dead-lettered gmail watch deliveries for %s", account)
+	switch {
+	case len(ids) > 0:
+		action = fmt.Sprintf("delete %d gmail watch deliveries for %s", len(ids), account)
+	case c.Pending:
+		action = fmt.Sprintf("delete all pending and dead-lettered gmail watch deliveries for %s", account)
+	}
+	if confirmErr := confirmDestructive(ctx, flags, action); confirmErr != nil {
+		return confirmErr
+	}
+
+	queue, err := newGmailWatchDeliveryQueue(account)
+	if err != nil {
+		return err
+	}
+	purged, err := queue.Purge(ids, c.Pending)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 && len(purged) != len(ids) {
+		return fmt.Errorf("deliveries not found: %s", strings.Join(missingDeliveryIDs(ids, purged), ", "))
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"purged": nonNilStrings(purged)})
+	}
+	u.Out().Printf("purged\t%d", len(purged))
+	return nil
+}
+
+func trimmedDeliveryIDs(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, part := range strings.Split(id, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func missingDeliveryIDs(want, got []string) []string {
+	found := stringSet(got)
+	var missing []string
+	for _, id := range want {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func deliveryHistoryID(d gmailWatchDelivery) string {
+	if d.Payload == nil {
+		return ""
+	}
+	return d.Payload.HistoryID
+}
+
+func deliveryMessageCount(d gmailWatchDelivery) int {
+	if d.Payload == nil {
+		return 0
+	}
+	return len(d.Payload.Messages)
+}
+
+func nonNilDeliveries(list []gmailWatchDelivery) []gmailWatchDelivery {
+	if list == nil {
+		return []gmailWatchDelivery{}
+	}
+	return list
+}
+
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+)
+
+const (
+	gmailDeliveryMaxAttempts = 15
+	gmailDeliveryBaseBackoff = 5 * time.Second
+	gmailDeliveryMaxBackoff  = 10 * time.Minute
+	gmailDeliveryIdleCheck   = 30 * time.Second
+)
+
+// gmailWatchDelivery is one hook payload waiting in (or dead-lettered from)
+// an account's delivery queue.
+type gmailWatchDelivery struct {
+	ID            string            `json:"id"`
+	CreatedAtMs   int64             `json:"createdAtMs"`
+	Attempts      int               `json:"attempts,omitempty"`
+	NextAttemptMs int64             `json:"nextAttemptMs,omitempty"`
+	LastError     string            `json:"lastError,omitempty"`
+	DeadAtMs      int64             `json:"deadAtMs,omitempty"`
+	Payload       *gmailHookPayload `json:"payload"`
+}
+
+// gmailHookStatusError is a non-2xx hook response.
+type gmailHookStatusError struct {
+	StatusCode int
+}
+
+func (e *gmailHookStatusError) Error() string {
+	return fmt.Sprintf("hook status %d", e.StatusCode)
+}
+
+// isPoisonedDelivery reports whether retrying can't help: the hook rejected
+// the payload itself rather than being unavailable.
+func isPoisonedDelivery(err error) bool {
+	var statusErr *gmailHookStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// gmailWatchDeliveryQueue is an on-disk FIFO of hook payloads per account.
+// Payloads are delivered strictly in order: a failing head blocks the queue
+// until it succeeds or is moved to the dead-letter file. Several processes
+// (watch serve/run and the deliveries commands) may share a queue, so the
+// files are guarded by OS file locks as well as mutexes.
+type gmailWatchDeliveryQueue struct {
+	pendingPath   string
+	deadPath      string
+	lockPath      string // held around every read-modify-write of the files
+	drainLockPath string // held while delivering, so one process sends at a time
+	maxAttempts   int
+	now           func() time.Time
+	onDead        func(gmailWatchDelivery)
+
+	mu      sync.Mutex // guards the files
+	drainMu sync.Mutex // serializes deliveries so ordering holds
+}
+
+func gmailWatchDeliveryDir() (string, error) {
+	dir, err := config.EnsureGmailWatchDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "deliveries")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail watch deliveries dir: %w", err)
+	}
+	return dir, nil
+}
+
+func newGmailWatchDeliveryQueue(account string) (*gmailWatchDeliveryQueue, error) {
+	dir, err := gmailWatchDeliveryDir()
+	if err != nil {
+		return nil, err
+	}
+	name := sanitizeAccountForPath(account)
+	return &gmailWatchDeliveryQueue{
+		pendingPath:   filepath.Join(dir, name+".jsonl"),
+		deadPath:      filepath.Join(dir, name+".dead.jsonl"),
+		lockPath:      filepath.Join(dir, name+".lock"),
+		drainLockPath: filepath.Join(dir, name+".drain.lock"),
+		maxAttempts:   gmailDeliveryMaxAttempts,
+		now:           time.Now,
+	}, nil
+}
+
+// locked runs fn while holding the queue's file lock.
+func (q *gmailWatchDeliveryQueue) locked(fn func() error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lock, err := lockFile(q.lockPath, true)
+	if err != nil {
+		return fmt.Errorf("lock delivery queue: %w", err)
+	}
+	defer lock.Close()
+	return fn()
+}
+
+// gmailDeliveryBackoff doubles from the base delay after each failed attempt.
+func gmailDeliveryBackoff(attempts int) time.Duration {
+	d := gmailDeliveryBaseBackoff
+	for i := 1; i < attempts && d < gmailDeliveryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > gmailDeliveryMaxBackoff {
+		d = gmailDeliveryMaxBackoff
+	}
+	return d
+}
+
+func (q *gmailWatchDeliveryQueue) Enqueue(payload *gmailHookPayload) (gmailWatchDelivery, error) {
+	var d gmailWatchDelivery
+	err := q.locked(func() error {
+		pending, err := readGmailDeliveries(q.pendingPath)
+		if err != nil {
+			return err
+		}
+		now := q.now()
+		id := strconv.FormatInt(now.UnixNano(), 10)
+		if n := len(pending); n > 0 && pending[n-1].ID >= id {
+			last, _ := strconv.ParseInt(pending[n-1].ID, 10, 64)
+			id = strconv.FormatInt(last+1, 10)
+		}
+		d = gmailWatchDelivery{ID: id, CreatedAtMs: now.UnixMilli(), Payload: payload}
+		return writeGmailDeliveries(q.pendingPath, append(pending, d))
+	})
+	if err != nil {
+		return gmailWatchDelivery{}, err
+	}
+	return d, nil
+}
+
+func (q *gmailWatchDeliveryQueue) Pending() ([]gmailWatchDelivery, error) {
+	var pending []gmailWatchDelivery
+	err := q.locked(func() (err error) {
+		pending, err = readGmailDeliveries(q.pendingPath)
+		return err
+	})
+	return pending, err
+}
+
+func (q *gmailWatchDeliveryQueue) Dead() ([]gmailWatchDelivery, error) {
+	var dead []gmailWatchDelivery
+	err := q.locked(func() (err error) {
+		dead, err = readGmailDeliveries(q.deadPath)
+		return err
+	})
+	return dead, err
+}
+
+// NextAttemptIn returns how long until the head of the queue is due, and
+// false when the queue is empty.
+func (q *gmailWatchDeliveryQueue) NextAttemptIn() (time.Duration, bool, error) {
+	pending, err := q.Pending()
+	if err != nil || len(pending) == 0 {
+		return 0, false, err
+	}
+	wait := time.UnixMilli(pending[0].NextAttemptMs).Sub(q.now())
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true, nil
+}
+
+// Drain sends payloads in order until the queue is empty, the head is still
+// backing off, or the head fails. A head that fails permanently, or too
+// often, moves to the dead-letter file and draining continues. It returns
+// the number delivered and the error that blocked the queue, if any.
+func (q *gmailWatchDeliveryQueue) Drain(ctx context.Context, send func(context.Context, *gmailHookPayload) error) (int, error) {
+	q.drainMu.Lock()
+	defer q.drainMu.Unlock()
+	lock, err := lockFileContext(ctx, q.drainLockPath)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Close()
+	return q.drainLocked(ctx, send)
+}
+
+// TryDrain drains unless another drain, in this process or another, is
+// running; that one re-reads the queue after each delivery and so picks up
+// anything just enqueued. It reports whether it drained.
+func (q *gmailWatchDeliveryQueue) TryDrain(ctx context.Context, send func(context.Context, *gmailHookPayload) error) (int, bool, error) {
+	if !q.drainMu.TryLock() {
+		return 0, false, nil
+	}
+	defer q.drainMu.Unlock()
+	lock, err := lockFile(q.drainLockPath, false)
+	if errors.Is(err, errFileLocked) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer lock.Close()
+	delivered, err := q.drainLocked(ctx, send)
+	return delivered, true, err
+}
+
+func (q *gmailWatchDeliveryQueue) drainLocked(ctx context.Context, send func(context.Context, *gmailHookPayload) error) (int, error) {
+	delivered := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return delivered, err
+		}
+		pending, err := q.Pending()
+		if err != nil {
+			return delivered, err
+		}
+		if len(pending) == 0 {
+			return delivered, nil
+		}
+		head := pending[0]
+		if head.NextAttemptMs > q.now().UnixMilli() {
+			return delivered, nil
+		}
+
+		sendErr := send(ctx, head.Payload)
+		if sendErr == nil {
+			if err := q.remove(head.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		head.Attempts++
+		head.LastError = sendErr.Error()
+		if isPoisonedDelivery(sendErr) || head.Attempts >= q.maxAttempts {
+			head.DeadAtMs = q.now().UnixMilli()
+			if err := q.moveToDead(head); err != nil {
+				return delivered, err
+			}
+			if q.onDead != nil {
+				q.onDead(head)
+			}
+			continue
+		}
+		head.NextAttemptMs = q.now().Add(gmailDeliveryBackoff(head.Attempts)).UnixMilli()
+		if err := q.update(head); err != nil {
+			return delivered, err
+		}
+		return delivered, sendErr
+	}
+}
+
+func (q *gmailWatchDeliveryQueue) remove(id string) error {
+	return q.locked(func() error {
+		pending, err := readGmailDeliveries(q.pendingPath)
+		if err != nil {
+			return err
+		}
+		_, kept := splitGmailDeliveries(pending, []string{id})
+		return writeGmailDeliveries(q.pendingPath, kept)
+	})
+}
+
+func (q *gmailWatchDeliveryQueue) update(delivery gmailWatchDelivery) error {
+	return q.locked(func() error {
+		pending, err := readGmailDeliveries(q.pendingPath)
+		if err != nil {
+			return err
+		}
+		for i := range pending {
+			if pending[i].ID == delivery.ID {
+				pending[i] = delivery
+			}
+		}
+		return writeGmailDeliveries(q.pendingPath, pending)
+	})
+}
+
+func (q *gmailWatchDeliveryQueue) moveToDead(delivery gmailWatchDelivery) error {
+	return q.locked(func() error {
+		dead, err := readGmailDeliveries(q.deadPath)
+		if err != nil {
+			return err
+		}
+		if err := writeGmailDeliveries(q.deadPath, append(dead, delivery)); err != nil {
+			return err
+		}
+		pending, err := readGmailDeliveries(q.pendingPath)
+		if err != nil {
+			return err
+		}
+		_, kept := splitGmailDeliveries(pending, []string{delivery.ID})
+		return writeGmailDeliveries(q.pendingPath, kept)
+	})
+}
+
+// Requeue moves dead-lettered deliveries (all when ids is empty) to the end
+// of the pending queue with their attempts reset. It returns the moved IDs.
+func (q *gmailWatchDeliveryQueue) Requeue(ids []string) ([]string, error) {
+	var movedIDs []string
+	err := q.locked(func() error {
+		dead, err := readGmailDeliveries(q.deadPath)
+		if err != nil {
+			return err
+		}
+		pending, err := readGmailDeliveries(q.pendingPath)
+		if err != nil {
+			return err
+		}
+		moved, kept := splitGmailDeliveries(dead, ids)
+		movedIDs = make([]string, 0, len(moved))
+		for _, d := range moved {
+			d.Attempts = 0
+			d.NextAttemptMs = 0
+			d.DeadAtMs = 0
+			pending = append(pending, d)
+			movedIDs = append(movedIDs, d.ID)
+		}
+		if err := writeGmailDeliveries(q.pendingPath, pending); err != nil {
+			return err
+		}
+		return writeGmailDeliveries(q.deadPath, kept)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movedIDs, nil
+}
+
+// Purge deletes deliveries (all when ids is empty) from the dead-letter file,
+// and from the pending queue too when includePending is set.
+func (q *gmailWatchDeliveryQueue) Purge(ids []string, includePending bool) ([]string, error) {
+	paths := []string{q.deadPath}
+	if includePending {
+		paths = append(paths, q.pendingPath)
+	}
+	var purged []string
+	err := q.locked(func() error {
+		for _, path := range paths {
+			list, err := readGmailDeliveries(path)
+			if err != nil {
+				return err
+			}
+			removed, kept := splitGmailDeliveries(list, ids)
+			if len(removed) == 0 {
+				continue
+			}
+			for _, d := range removed {
+				purged = append(purged, d.ID)
+			}
+			if err := writeGmailDeliveries(path, kept); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// splitGmailDeliveries separates deliveries whose ID is in ids (or all of
+// them when ids is empty) from the rest, keeping order.
+func splitGmailDeliveries(list []gmailWatchDelivery, ids []string) (matched, rest []gmailWatchDelivery) {
+	want := stringSet(ids)
+	for _, d := range list {
+		if _, ok := want[d.ID]; ok || len(ids) == 0 {
+			matched = append(matched, d)
+			continue
+		}
+		rest = append(rest, d)
+	}
+	return matched, rest
+}
+
+func readGmailDeliveries(path string) ([]gmailWatchDelivery, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is under the config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []gmailWatchDelivery
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var d gmailWatchDelivery
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		out = append(out, d)
+	}
+	return out, scanner.Err()
+}
+
+func writeGmailDeliveries(path string, list []gmailWatchDelivery) error {
+	if len(list) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, d := range list {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// errGmailDeliveryQueued marks a failed send whose payload is safely queued.
+var errGmailDeliveryQueued = errors.New("queued for retry")
+
+// deliver sends a hook payload through the account's delivery queue, so a
+// failed send is retried later instead of dropped. Without a queue it posts
+// once.
+func (s *gmailWatchServer) deliver(ctx context.Context, payload *gmailHookPayload) error {
+	if s.queue == nil {
+		return s.sendHook(ctx, payload)
+	}
+	if _, err := s.queue.Enqueue(payload); err != nil {
+		s.warnf("watch: queue delivery: %v", err)
+		return s.sendHook(ctx, payload)
+	}
+	if _, _, err := s.queue.TryDrain(ctx, s.sendHook); err != nil {
+		return fmt.Errorf("%w (%w)", err, errGmailDeliveryQueued)
+	}
+	return nil
+}
+
+// retryDeliveries drains the delivery queue whenever its head comes due,
+// until ctx is cancelled.
+func (s *gmailWatchServer) retryDeliveries(ctx context.Context) {
+	if s.queue == nil {
+		return
+	}
+	for {
+		if _, err := s.queue.Drain(ctx, s.sendHook); err != nil && ctx.Err() == nil {
+			s.warnf("watch: hook retry failed: %v", err)
+		}
+		wait := gmailDeliveryIdleCheck
+		if next, ok, err := s.queue.NextAttemptIn(); err == nil && ok && next < wait {
+			wait = next
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
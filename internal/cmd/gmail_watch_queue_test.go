@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+)
+
+func newGmailWatchDeliveryQueueForTest(t *testing.T, now *time.Time) *gmailWatchDeliveryQueue {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	q, err := newGmailWatchDeliveryQueue("a@b.com")
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	q.now = func() time.Time { return *now }
+	return q
+}
+
+func TestGmailWatchDeliveryQueue_RetriesInOrder(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	q := newGmailWatchDeliveryQueueForTest(t, &now)
+	for _, id := range []string{"1", "2"} {
+		if _, err := q.Enqueue(&gmailHookPayload{HistoryID: id}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	var sent []string
+	fail := true
+	send := func(_ context.Context, p *gmailHookPayload) error {
+		if fail {
+			return &gmailHookStatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		sent = append(sent, p.HistoryID)
+		return nil
+	}
+
+	if n, err := q.Drain(context.Background(), send); n != 0 || err == nil || err.Error() != "hook status 503" {
+		t.Fatalf("expected blocked drain, got %d %v", n, err)
+	}
+	pending, _ := q.Pending()
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].NextAttemptMs != now.Add(gmailDeliveryBaseBackoff).UnixMilli() {
+		t.Fatalf("unexpected pending: %+v", pending)
+	}
+
+	// Not due yet: nothing is sent, and the later payload doesn't jump ahead.
+	fail = false
+	if n, err := q.Drain(context.Background(), send); n != 0 || err != nil || len(sent) != 0 {
+		t.Fatalf("expected no sends before backoff, got %d %v %v", n, err, sent)
+	}
+	if wait, ok, _ := q.NextAttemptIn(); !ok || wait != gmailDeliveryBaseBackoff {
+		t.Fatalf("next attempt in %v %v", wait, ok)
+	}
+
+	now = now.Add(gmailDeliveryBaseBackoff)
+	if n, err := q.Drain(context.Background(), send); n != 2 || err != nil {
+		t.Fatalf("drain: %d %v", n, err)
+	}
+	if strings.Join(sent, ",") != "1,2" {
+		t.Fatalf("sent out of order: %v", sent)
+	}
+	if pending, _ := q.Pending(); len(pending) != 0 {
+		t.Fatalf("expected empty queue, got %+v", pending)
+	}
+}
+
+func TestGmailWatchDeliveryQueue_DeadLetters(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	q := newGmailWatchDeliveryQueueForTest(t, &now)
+	q.maxAttempts = 2
+	for _, id := range []string{"poison", "flaky", "ok"} {
+		if _, err := q.Enqueue(&gmailHookPayload{HistoryID: id}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	var dead []string
+	q.onDead = func(d gmailWatchDelivery) { dead = append(dead, d.Payload.HistoryID) }
+	send := func(_ context.Context, p *gmailHookPayload) error {
+		switch p.HistoryID {
+		case "poison":
+			return &gmailHookStatusError{StatusCode: http.StatusUnprocessableEntity}
+		case "flaky":
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	// The 422 is dead-lettered straight away; the network error is retried.
+	if _, err := q.Drain(context.Background(), send); err == nil {
+		t.Fatalf("expected flaky failure")
+	}
+	now = now.Add(time.Hour)
+	if n, err := q.Drain(context.Background(), send); n != 1 || err != nil {
+		t.Fatalf("drain: %d %v", n, err)
+	}
+	if strings.Join(dead, ",") != "poison,flaky" {
+		t.Fatalf("dead = %v", dead)
+	}
+	letters, _ := q.Dead()
+	if len(letters) != 2 || letters[1].Attempts != 2 || letters[1].LastError != "connection refused" || letters[1].DeadAtMs == 0 {
+		t.Fatalf("unexpected dead letters: %+v", letters)
+	}
+
+	moved, err := q.Requeue([]string{letters[1].ID})
+	if err != nil || len(moved) != 1 {
+		t.Fatalf("requeue: %v %v", moved, err)
+	}
+	pending, _ := q.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 0 || pending[0].DeadAtMs != 0 {
+		t.Fatalf("unexpected requeued: %+v", pending)
+	}
+
+	purged, err := q.Purge(nil, false)
+	if err != nil || len(purged) != 1 || purged[0] != letters[0].ID {
+		t.Fatalf("purge: %v %v", purged, err)
+	}
+	if pending, _ := q.Pending(); len(pending) != 1 {
+		t.Fatalf("purge without --pending touched the queue: %+v", pending)
+	}
+}
+
+func TestGmailWatchDeliveryQueue_SharedAcrossInstances(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// Two queues on one directory stand in for serve and a deliveries
+	// command: each opens the lock files separately, as another process would.
+	q1, err := newGmailWatchDeliveryQueue("a@b.com")
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	q2, err := newGmailWatchDeliveryQueue("a@b.com")
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+
+	const each = 20
+	var wg sync.WaitGroup
+	for i, q := range []*gmailWatchDeliveryQueue{q1, q2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				if _, err := q.Enqueue(&gmailHookPayload{HistoryID: fmt.Sprintf("%d-%d", i, j)}); err != nil {
+					t.Errorf("enqueue: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	pending, err := q1.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 2*each {
+		t.Fatalf("lost enqueues: %d of %d", len(pending), 2*each)
+	}
+
+	// While q1 is delivering, q2 can't start a second drain but can still
+	// add to the queue; q1 sends everything exactly once, in order.
+	started, release := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	var sent []string
+	send := func(_ context.Context, p *gmailHookPayload) error {
+		mu.Lock()
+		sent = append(sent, p.HistoryID)
+		first := len(sent) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+	done := make(chan int)
+	go func() {
+		n, drainErr := q1.Drain(context.Background(), send)
+		if drainErr != nil {
+			t.Errorf("drain: %v", drainErr)
+		}
+		done <- n
+	}()
+	<-started
+	if n, ok, err := q2.TryDrain(context.Background(), send); n != 0 || ok || err != nil {
+		t.Fatalf("expected busy TryDrain, got %d %v %v", n, ok, err)
+	}
+	if _, err := q2.Enqueue(&gmailHookPayload{HistoryID: "late"}); err != nil {
+		t.Fatalf("enqueue while draining: %v", err)
+	}
+	close(release)
+	if n := <-done; n != 2*each+1 {
+		t.Fatalf("delivered %d, want %d", n, 2*each+1)
+	}
+	want := make([]string, 0, 2*each+1)
+	for _, d := range pending {
+		want = append(want, d.Payload.HistoryID)
+	}
+	want = append(want, "late")
+	if strings.Join(sent, ",") != strings.Join(want, ",") {
+		t.Fatalf("sent %v, want %v", sent, want)
+	}
+}
+
+func TestGmailWatchDeliveriesRetry_RequeueOnlyWhileAnotherProcessDelivers(t *testing.T) {
+	now := time.Now()
+	q := newGmailWatchDeliveryQueueForTest(t, &now)
+	if _, err := q.Enqueue(&gmailHookPayload{HistoryID: "200"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Drain(context.Background(), func(context.Context, *gmailHookPayload) error {
+		return &gmailHookStatusError{StatusCode: http.StatusBadRequest}
+	}); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	var received atomic.Int32
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		received.Add(1)
+	}))
+	defer hookSrv.Close()
+
+	lock, err := lockFile(q.drainLockPath, true)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	defer lock.Close()
+	out := captureStdout(t, func() {
+		ctx := outfmt.WithMode(gmailWatchRunContext(t), outfmt.Mode{JSON: true})
+		if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, []string{"--all", "--hook-url", hookSrv.URL}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("retry: %v", err)
+		}
+	})
+	if received.Load() != 0 || !strings.Contains(out, `"delivered":0`) || !strings.Contains(out, `"pending":1`) {
+		t.Fatalf("expected requeue only, hook saw %d, out=%q", received.Load(), out)
+	}
+}
+
+func TestGmailDeliveryBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 4: 40 * time.Second, 30: gmailDeliveryMaxBackoff} {
+		if got := gmailDeliveryBackoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestGmailWatchDeliveries_QueueRetryPurge(t *testing.T) {
+	_, _ = newGmailWatchRunTest(t)
+	seedGmailWatchHistory(t, "100")
+
+	var status atomic.Int32
+	status.Store(http.StatusBadRequest)
+	var received atomic.Int32
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer hookSrv.Close()
+
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	if err := runKong(t, &GmailWatchRunCmd{}, []string{"--once", "--hook-url", hookSrv.URL}, gmailWatchRunContext(t), flags); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	listDead := func() []gmailWatchDelivery {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := runKong(t, &GmailWatchDeliveriesListCmd{}, []string{"--dead"}, outfmt.WithMode(gmailWatchRunContext(t), outfmt.Mode{JSON: true}), flags); err != nil {
+				t.Fatalf("list: %v", err)
+			}
+		})
+		var parsed struct {
+			Dead    []gmailWatchDelivery `json:"dead"`
+			Pending []gmailWatchDelivery `json:"pending"`
+		}
+		if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+			t.Fatalf("list json: %v (%q)", err, out)
+		}
+		if parsed.Pending != nil {
+			t.Fatalf("--dead should omit pending: %q", out)
+		}
+		return parsed.Dead
+	}
+	dead := listDead()
+	if len(dead) != 1 || dead[0].Payload == nil || dead[0].Payload.HistoryID != "200" || dead[0].LastError != "hook status 400" {
+		t.Fatalf("unexpected dead letters: %+v", dead)
+	}
+
+	if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, nil, gmailWatchRunContext(t), flags); err == nil || ExitCode(err) != 2 {
+		t.Fatalf("expected usage error without ids, got %v", err)
+	}
+	if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, []string{"nope"}, gmailWatchRunContext(t), flags); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	status.Store(http.StatusOK)
+	if err := runKong(t, &GmailWatchDeliveriesRetryCmd{}, []string{dead[0].ID, "--hook-url", hookSrv.URL}, gmailWatchRunContext(t), flags); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if received.Load() != 2 {
+		t.Fatalf("expected redelivery, hook saw %d requests", received.Load())
+	}
+	if dead := listDead(); len(dead) != 0 {
+		t.Fatalf("expected no dead letters, got %+v", dead)
+	}
+
+	queue, err := newGmailWatchDeliveryQueue("a@b.com")
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if _, err := queue.Enqueue(&gmailHookPayload{HistoryID: "300"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := runKong(t, &GmailWatchDeliveriesPurgeCmd{}, []string{"--pending"}, gmailWatchRunContext(t), flags); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 0 {
+		t.Fatalf("expected purged queue, got %+v", pending)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	gmailWatchRenewStatusOK    = "ok"
+	gmailWatchRenewStatusError = "error"
+
+	// Gmail watches last 7 days; renewing daily leaves days of slack if a
+	// renewal keeps failing.
+	gmailWatchRenewInterval    = 24 * time.Hour
+	gmailWatchRenewExpiryLead  = time.Hour
+	gmailWatchRenewBaseBackoff = time.Minute
+	gmailWatchRenewMaxBackoff  = time.Hour
+	gmailWatchRenewRecheck     = time.Hour
+)
+
+// gmailWatchRenewDueAt is when the stored watch should next be renewed:
+// RenewAfterMs when set, otherwise a day before it expires.
+func gmailWatchRenewDueAt(state gmailWatchState) time.Time {
+	if state.RenewAfterMs > 0 {
+		return time.UnixMilli(state.RenewAfterMs)
+	}
+	if state.ExpirationMs > 0 {
+		return time.UnixMilli(state.ExpirationMs).Add(-gmailWatchRenewInterval)
+	}
+	return time.Time{}
+}
+
+// gmailWatchNextRenewAfter schedules the next renewal a day out, but always
+// before the new expiration.
+func gmailWatchNextRenewAfter(now time.Time, expirationMs int64) int64 {
+	next := now.Add(gmailWatchRenewInterval)
+	if expirationMs > 0 {
+		if latest := time.UnixMilli(expirationMs).Add(-gmailWatchRenewExpiryLead); latest.Before(next) {
+			next = latest
+		}
+	}
+	return next.UnixMilli()
+}
+
+func gmailWatchRenewBackoff(failures int) time.Duration {
+	d := gmailWatchRenewBaseBackoff
+	for i := 1; i < failures && d < gmailWatchRenewMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > gmailWatchRenewMaxBackoff {
+		d = gmailWatchRenewMaxBackoff
+	}
+	return d
+}
+
+// renewWatch re-calls users.watch with the stored topic and labels. Unlike
+// `watch renew`, it keeps the stored historyId: the server is still working
+// through history from there.
+func (s *gmailWatchServer) renewWatch(ctx context.Context) error {
+	state := s.store.Get()
+	topic := strings.TrimSpace(state.Topic)
+	if topic == "" {
+		return s.recordRenewFailure(errors.New("stored watch state missing topic"))
+	}
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		return s.recordRenewFailure(err)
+	}
+	resp, err := requestGmailWatch(ctx, svc, topic, state.Labels)
+	if err != nil {
+		return s.recordRenewFailure(err)
+	}
+
+	now := time.Now()
+	return s.store.Update(func(st *gmailWatchState) error {
+		if st.HistoryID == "" {
+			st.HistoryID = formatHistoryID(resp.HistoryId)
+		}
+		st.ExpirationMs = resp.Expiration
+		st.ProviderExpirationMs = resp.Expiration
+		st.RenewAfterMs = gmailWatchNextRenewAfter(now, resp.Expiration)
+		st.UpdatedAtMs = now.UnixMilli()
+		st.LastRenewStatus = gmailWatchRenewStatusOK
+		st.LastRenewAtMs = now.UnixMilli()
+		st.LastRenewError = ""
+		st.RenewFailures = 0
+		return nil
+	})
+}
+
+func (s *gmailWatchServer) recordRenewFailure(err error) error {
+	_ = s.store.Update(func(st *gmailWatchState) error {
+		st.LastRenewStatus = gmailWatchRenewStatusError
+		st.LastRenewAtMs = time.Now().UnixMilli()
+		st.LastRenewError = err.Error()
+		st.RenewFailures++
+		return nil
+	})
+	return err
+}
+
+// autoRenew keeps the Gmail watch alive until ctx is cancelled, renewing when
+// it comes due and backing off after failures.
+func (s *gmailWatchServer) autoRenew(ctx context.Context) {
+	var retryAt time.Time
+	for {
+		now := time.Now()
+		due := gmailWatchRenewDueAt(s.store.Get())
+		if retryAt.After(due) {
+			due = retryAt
+		}
+		if !due.After(now) {
+			if err := s.renewWatch(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				state := s.store.Get()
+				backoff := gmailWatchRenewBackoff(state.RenewFailures)
+				retryAt = time.Now().Add(backoff)
+				if state.ExpirationMs > 0 && time.Now().UnixMilli() >= state.ExpirationMs {
+					s.warnf("watch: renew failed and the watch has expired (retrying in %s): %v", backoff, err)
+				} else {
+					s.warnf("watch: renew failed (retrying in %s): %v", backoff, err)
+				}
+			} else {
+				retryAt = time.Time{}
+				s.logf("watch: renewed, expires %s", formatUnixMillis(s.store.Get().ExpirationMs))
+			}
+			continue
+		}
+
+		// Re-check periodically rather than sleeping until a far-off due time.
+		wait := due.Sub(now)
+		if wait > gmailWatchRenewRecheck {
+			wait = gmailWatchRenewRecheck
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func newGmailWatchRenewTest(t *testing.T, failures int32) (*gmailWatchServer, *atomic.Int32) {
+	t.Helper()
+	s := newGmailWatchSinkServer(t, "")
+	if err := s.store.Update(func(st *gmailWatchState) error {
+		st.Account = "a@b.com"
+		st.Topic = "projects/p/topics/gmail"
+		st.Labels = []string{"INBOX"}
+		st.HistoryID = "100"
+		st.RenewAfterMs = 1
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	var calls atomic.Int32
+	expiration := time.Now().Add(7 * 24 * time.Hour).UnixMilli()
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/users/me/watch") {
+			http.NotFound(w, r)
+			return
+		}
+		var req gmail.WatchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.TopicName != "projects/p/topics/gmail" || strings.Join(req.LabelIds, ",") != "INBOX" {
+			t.Errorf("unexpected watch request: %+v", req)
+		}
+		if calls.Add(1) <= failures {
+			http.Error(w, `{"error":{"code":503,"message":"backend"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"historyId": "900", "expiration": strconv.FormatInt(expiration, 10)})
+	})
+	t.Cleanup(cleanup)
+	s.newService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+	return s, &calls
+}
+
+func TestGmailWatchRenewWatch_KeepsHistoryAndRecordsStatus(t *testing.T) {
+	s, _ := newGmailWatchRenewTest(t, 1)
+
+	if err := s.renewWatch(context.Background()); err == nil {
+		t.Fatalf("expected first renewal to fail")
+	}
+	if st := s.store.Get(); st.LastRenewStatus != "error" || st.RenewFailures != 1 || st.LastRenewError == "" || st.RenewAfterMs != 1 {
+		t.Fatalf("unexpected state after failure: %+v", st)
+	}
+
+	if err := s.renewWatch(context.Background()); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	st := s.store.Get()
+	if st.HistoryID != "100" {
+		t.Fatalf("renewal must not move historyId, got %q", st.HistoryID)
+	}
+	if st.LastRenewStatus != "ok" || st.RenewFailures != 0 || st.LastRenewError != "" || st.ExpirationMs == 0 {
+		t.Fatalf("unexpected state after renewal: %+v", st)
+	}
+	if next := time.UnixMilli(st.RenewAfterMs); next.Before(time.Now().Add(23*time.Hour)) || next.After(time.Now().Add(25*time.Hour)) {
+		t.Fatalf("unexpected renewAfter %s", next)
+	}
+}
+
+func TestGmailWatchRenewWatch_MissingTopicRecordsFailure(t *testing.T) {
+	s, calls := newGmailWatchRenewTest(t, 0)
+	if err := s.store.Update(func(st *gmailWatchState) error {
+		st.Topic = ""
+		return nil
+	}); err != nil {
+		t.Fatalf("clear topic: %v", err)
+	}
+
+	if err := s.renewWatch(context.Background()); err == nil {
+		t.Fatalf("expected renewal without a topic to fail")
+	}
+	st := s.store.Get()
+	if st.LastRenewStatus != "error" || st.RenewFailures != 1 || !strings.Contains(st.LastRenewError, "missing topic") {
+		t.Fatalf("failure not recorded: %+v", st)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no watch call, got %d", calls.Load())
+	}
+}
+
+func TestGmailWatchAutoRenew_RenewsWhenDue(t *testing.T) {
+	s, calls := newGmailWatchRenewTest(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.autoRenew(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.store.Get().LastRenewStatus != "ok" {
+		if time.Now().After(deadline) {
+			t.Fatalf("watch not renewed: %+v", s.store.Get())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if calls.Load() != 1 {
+		t.Fatalf("expected one renewal before the next due time, got %d", calls.Load())
+	}
+}
+
+func TestGmailWatchRenewSchedule(t *testing.T) {
+	now := time.UnixMilli(1_000_000_000)
+	if got := gmailWatchNextRenewAfter(now, now.Add(2*time.Hour).UnixMilli()); got != now.Add(time.Hour).UnixMilli() {
+		t.Fatalf("renewal should land before a near expiration, got %d", got)
+	}
+	if got := gmailWatchRenewDueAt(gmailWatchState{ExpirationMs: now.Add(7 * 24 * time.Hour).UnixMilli()}); !got.Equal(now.Add(6 * 24 * time.Hour)) {
+		t.Fatalf("due without renewAfter = %s", got)
+	}
+	for failures, want := range map[int]time.Duration{1: time.Minute, 3: 4 * time.Minute, 20: time.Hour} {
+		if got := gmailWatchRenewBackoff(failures); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestGmailWatchServe_AutoRenewRequiresTopic(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+	listenAndServe = func(*http.Server) error { return nil }
+	seedGmailWatchHistory(t, "100")
+	err := runKong(t, &GmailWatchServeCmd{}, []string{"--auto-renew"}, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"})
+	if err == nil || ExitCode(err) != 2 || !strings.Contains(err.Error(), "no topic") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	gmailWatchHealthPath = "/healthz"
+	gmailWatchStatusPath = "/status"
+)
+
+// gmailWatchRouter serves one push endpoint for several accounts sharing a
+// Pub/Sub topic, routing each push by its emailAddress. Auth settings are the
+// same for every account, so the first server authorizes requests.
+type gmailWatchRouter struct {
+	servers  []*gmailWatchServer
+	accounts map[string]*gmailWatchServer
+}
+
+func newGmailWatchRouter(servers []*gmailWatchServer) *gmailWatchRouter {
+	accounts := make(map[string]*gmailWatchServer, len(servers))
+	for _, s := range servers {
+		accounts[strings.ToLower(s.cfg.Account)] = s
+	}
+	return &gmailWatchRouter{servers: servers, accounts: accounts}
+}
+
+func (rt *gmailWatchRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	first := rt.servers[0]
+	if !pathMatches(first.cfg.Path, r.URL.Path) {
+		if !serveGmailWatchStatus(w, r, rt.servers) {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	payload, ok := first.readPush(w, r)
+	if !ok {
+		return
+	}
+	s := rt.accounts[strings.ToLower(strings.TrimSpace(payload.EmailAddress))]
+	if s == nil {
+		if payload.EmailAddress == "" {
+			first.warnf("watch: ignoring push without emailAddress")
+		} else {
+			first.warnf("watch: ignoring push for %s", payload.EmailAddress)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	s.respondPush(w, r, payload)
+}
+
+type gmailWatchAccountStatus struct {
+	Account                string `json:"account"`
+	HistoryID              string `json:"historyId,omitempty"`
+	ExpirationMs           int64  `json:"expirationMs,omitempty"`
+	RenewAfterMs           int64  `json:"renewAfterMs,omitempty"`
+	RenewDue               bool   `json:"renewDue"`
+	LastRenewStatus        string `json:"lastRenewStatus,omitempty"`
+	LastRenewAtMs          int64  `json:"lastRenewAtMs,omitempty"`
+	LastRenewError         string `json:"lastRenewError,omitempty"`
+	LastDeliveryStatus     string `json:"lastDeliveryStatus,omitempty"`
+	LastDeliveryAtMs       int64  `json:"lastDeliveryAtMs,omitempty"`
+	LastDeliveryStatusNote string `json:"lastDeliveryStatusNote,omitempty"`
+	PendingDeliveries      int    `json:"pendingDeliveries"`
+	DeadDeliveries         int    `json:"deadDeliveries"`
+}
+
+func (s *gmailWatchServer) status(now time.Time) gmailWatchAccountStatus {
+	state := s.store.Get()
+	out := gmailWatchAccountStatus{
+		Account:                s.cfg.Account,
+		HistoryID:              state.HistoryID,
+		ExpirationMs:           state.ExpirationMs,
+		RenewAfterMs:           state.RenewAfterMs,
+		RenewDue:               state.RenewAfterMs > 0 && now.UnixMilli() >= state.RenewAfterMs,
+		LastRenewStatus:        state.LastRenewStatus,
+		LastRenewAtMs:          state.LastRenewAtMs,
+		LastRenewError:         state.LastRenewError,
+		LastDeliveryStatus:     state.LastDeliveryStatus,
+		LastDeliveryAtMs:       state.LastDeliveryAtMs,
+		LastDeliveryStatusNote: state.LastDeliveryStatusNote,
+	}
+	if s.queue != nil {
+		if pending, err := s.queue.Pending(); err == nil {
+			out.PendingDeliveries = len(pending)
+		}
+		if dead, err := s.queue.Dead(); err == nil {
+			out.DeadDeliveries = len(dead)
+		}
+	}
+	return out
+}
+
+// serveGmailWatchStatus answers GET /healthz (unauthenticated liveness) and
+// GET /status (per-account summary, same auth as pushes). It reports whether
+// the request was one of those.
+func serveGmailWatchStatus(w http.ResponseWriter, r *http.Request, servers []*gmailWatchServer) bool {
+	if r.URL.Path != gmailWatchHealthPath && r.URL.Path != gmailWatchStatusPath {
+		return false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return true
+	}
+	var body any
+	if r.URL.Path == gmailWatchHealthPath {
+		body = map[string]any{"ok": true, "accounts": len(servers)}
+	} else {
+		if len(servers) == 0 || !servers[0].authorize(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return true
+		}
+		now := time.Now()
+		accounts := make([]gmailWatchAccountStatus, 0, len(servers))
+		for _, s := range servers {
+			accounts = append(accounts, s.status(now))
+		}
+		body = map[string]any{"accounts": accounts}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+	return true
+}
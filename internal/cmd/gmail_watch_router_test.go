@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func gmailWatchPushRequest(t *testing.T, email, historyID, messageID string) *http.Request {
+	t.Helper()
+	data := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(`{"emailAddress":%q,"historyId":%q}`, email, historyID)))
+	body, _ := json.Marshal(map[string]any{"message": map[string]any{"data": data, "messageId": messageID}})
+	return httptest.NewRequest(http.MethodPost, "/gmail-pubsub", bytes.NewReader(body))
+}
+
+func TestGmailWatchServe_MultiAccountRoutesByEmail(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var mu sync.Mutex
+	var hooks []gmailHookPayload
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p gmailHookPayload
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		hooks = append(hooks, p)
+		mu.Unlock()
+	}))
+	defer hookSrv.Close()
+
+	for _, account := range []string{"a@b.com", "c@d.com"} {
+		store, err := newGmailWatchStore(account)
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+		if err := store.Update(func(s *gmailWatchState) error {
+			s.Account = account
+			s.HistoryID = "100"
+			s.RenewAfterMs = 1
+			s.Hook = &gmailWatchHook{URL: hookSrv.URL}
+			return nil
+		}); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	gsvc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/users/me/history"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "200",
+				"history":   []map[string]any{{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}}}},
+			})
+		case strings.Contains(r.URL.Path, "/users/me/messages/m1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "threadId": "t1"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+	var svcAccounts []string
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+	newGmailService = func(_ context.Context, account string) (*gmail.Service, error) {
+		mu.Lock()
+		svcAccounts = append(svcAccounts, account)
+		mu.Unlock()
+		return gsvc, nil
+	}
+
+	var handler http.Handler
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+	listenAndServe = func(srv *http.Server) error {
+		handler = srv.Handler
+		return nil
+	}
+
+	if err := Execute([]string{"--accounts", "a@b.com,c@d.com", "gmail", "watch", "serve", "--port", "9999"}); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if _, ok := handler.(*gmailWatchRouter); !ok {
+		t.Fatalf("expected router, got %T", handler)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, gmailWatchPushRequest(t, "C@d.com", "200", "p1"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("push status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, gmailWatchPushRequest(t, "x@y.com", "200", "p2"))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unknown account status %d", rec.Code)
+	}
+	if len(hooks) != 1 || hooks[0].Account != "c@d.com" || strings.Join(svcAccounts, ",") != "c@d.com" {
+		t.Fatalf("unexpected routing: hooks=%+v services=%v", hooks, svcAccounts)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"accounts":2`) {
+		t.Fatalf("healthz: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		Accounts []gmailWatchAccountStatus `json:"accounts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("status json: %v (%s)", err, rec.Body.String())
+	}
+	if len(status.Accounts) != 2 || status.Accounts[0].Account != "a@b.com" || status.Accounts[0].LastDeliveryStatus != "" {
+		t.Fatalf("unexpected status: %+v", status.Accounts)
+	}
+	if c := status.Accounts[1]; c.LastDeliveryStatus != "ok" || c.HistoryID != "200" || !c.RenewDue || c.RenewAfterMs != 1 {
+		t.Fatalf("unexpected status for c@d.com: %+v", c)
+	}
+}
+
+func TestGmailWatchServer_StatusEndpointsRequireAuth(t *testing.T) {
+	s := newGmailWatchSinkServer(t, "")
+	s.cfg.Path = "/gmail-pubsub"
+	s.cfg.SharedToken = "secret"
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz should not need auth, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token: %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status?token=secret", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"account":"a@b.com"`) {
+		t.Fatalf("status with token: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("other path: %d", rec.Code)
+	}
+}
+
+func TestGmailWatchServe_AllRejectsAccount(t *testing.T) {
+	err := runKong(t, &GmailWatchServeCmd{}, []string{"--all"}, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"})
+	if err == nil || ExitCode(err) != 2 {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
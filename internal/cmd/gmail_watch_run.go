@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const (
+	gmailWatchModePull = "pull"
+	gmailWatchModePoll = "poll"
+
+	defaultPullMaxMessages = 10
+	defaultPullRetryDelay  = 5 * time.Second
+)
+
+// newPubSubService uses Application Default Credentials: pulling reads a
+// subscription in a GCP project, which the per-account Gmail token can't.
+var newPubSubService = func(ctx context.Context) (*pubsub.Service, error) {
+	ts, err := google.DefaultTokenSource(ctx, pubsub.PubsubScope)
+	if err != nil {
+		return nil, err
+	}
+	return pubsub.NewService(ctx, option.WithHTTPClient(&http.Client{
+		Transport: &oauth2.Transport{Source: ts, Base: retryTransport(ctx)},
+	}))
+}
+
+type GmailWatchRunCmd struct {
+	Mode         string              `name:"mode" help:"pull: read a Pub/Sub pull subscription; poll: call history.list on an interval" enum:"pull,poll" default:"poll"`
+	Subscription string              `name:"subscription" help:"Pub/Sub subscription for --mode pull (projects/<project>/subscriptions/<name>)"`
+	Interval     string              `name:"interval" help:"Poll interval for --mode poll (seconds or Go duration)" default:"30s"`
+	Once         bool                `name:"once" help:"Process pending changes once and exit"`
+	Hook         GmailWatchHookFlags `embed:""`
+}
+
+func (c *GmailWatchRunCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	subscription := strings.TrimSpace(c.Subscription)
+	interval, err := parseDurationSeconds(c.Interval)
+	if err != nil {
+		return usagef("invalid --interval: %v", err)
+	}
+	load := loadGmailWatchStore
+	switch c.Mode {
+	case gmailWatchModePull:
+		if !strings.HasPrefix(subscription, "projects/") || !strings.Contains(subscription, "/subscriptions/") {
+			return usage("--mode pull requires --subscription projects/<project>/subscriptions/<name>")
+		}
+	case gmailWatchModePoll:
+		if subscription != "" {
+			return usage("--subscription requires --mode pull")
+		}
+		if interval <= 0 && !c.Once {
+			return usage("--interval must be > 0")
+		}
+		// Polling needs no Pub/Sub topic, so it can start without watch start;
+		// the first poll seeds the stored historyId.
+		load = loadOrCreateGmailWatchStore
+	default:
+		return usagef("invalid --mode %q (expected pull|poll)", c.Mode)
+	}
+
+	server, err := c.Hook.newServer(ctx, kctx, flags, account, load)
+	if err != nil {
+		return err
+	}
+	emit := func(payload *gmailHookPayload) {
+		_ = json.NewEncoder(os.Stdout).Encode(payload)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if c.Once {
+		// Flush anything left queued by an earlier run before adding to it.
+		if server.queue != nil {
+			if _, err := server.queue.Drain(ctx, server.sendHook); err != nil {
+				server.warnf("watch: hook retry failed: %v", err)
+			}
+		}
+	} else {
+		go server.retryDeliveries(ctx)
+	}
+
+	if c.Mode == gmailWatchModePoll {
+		u.Err().Printf("watch: polling history every %s", interval)
+		return runGmailWatchLoop(ctx, c.Once, interval, interval, server.warnf, func(ctx context.Context) error {
+			return server.poll(ctx, emit)
+		})
+	}
+
+	ps, err := newPubSubService(ctx)
+	if err != nil {
+		return fmt.Errorf("pubsub: %w", err)
+	}
+	u.Err().Printf("watch: pulling from %s", subscription)
+	return runGmailWatchLoop(ctx, c.Once, 0, defaultPullRetryDelay, server.warnf, func(ctx context.Context) error {
+		return server.pull(ctx, ps, subscription, emit)
+	})
+}
+
+func loadOrCreateGmailWatchStore(account string) (*gmailWatchStore, error) {
+	store, err := loadGmailWatchStore(account)
+	if errors.Is(err, errGmailWatchStateNotFound) {
+		store, err = newGmailWatchStore(account)
+		if err != nil {
+			return nil, err
+		}
+		store.state.Account = account
+		return store, nil
+	}
+	return store, err
+}
+
+// runGmailWatchLoop calls tick until ctx is cancelled, waiting idle after a
+// successful tick and retry after a failed one. With once, the first tick's
+// error is returned instead of retried.
+func runGmailWatchLoop(ctx context.Context, once bool, idle, retry time.Duration, warnf func(string, ...any), tick func(context.Context) error) error {
+	for {
+		wait := idle
+		if err := tick(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if once {
+				return err
+			}
+			warnf("watch: %v", err)
+			wait = retry
+		}
+		if once {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatch runs a change notification through the same path as a push:
+// history is read from the stored historyId and the result goes to the hook,
+// or to emit when no hook is configured. It fails when the hook payload was
+// neither delivered nor queued.
+func (s *gmailWatchServer) dispatch(ctx context.Context, payload gmailPushPayload, emit func(*gmailHookPayload)) error {
+	prev := s.store.Get()
+	result, err := s.handlePush(ctx, payload)
+	if err != nil {
+		if errors.Is(err, errNoNewMessages) {
+			return nil
+		}
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if s.cfg.HookURL == "" {
+		if s.cfg.AllowNoHook && emit != nil {
+			emit(result)
+		}
+		return nil
+	}
+	return s.deliverOrRewind(ctx, result, prev)
+}
+
+// deliverOrRewind sends result to the hook. A failed send that was queued
+// counts as handled. Otherwise the history position is rewound to prev and an
+// error returned, so a redelivered notification (or the next poll) reads the
+// same changes again.
+func (s *gmailWatchServer) deliverOrRewind(ctx context.Context, result *gmailHookPayload, prev gmailWatchState) error {
+	err := s.deliver(ctx, result)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, errGmailDeliveryQueued) {
+		s.warnf("watch: hook failed: %v", err)
+		return nil
+	}
+	if rewindErr := s.store.Update(func(state *gmailWatchState) error {
+		state.HistoryID = prev.HistoryID
+		state.LastPushMessageID = prev.LastPushMessageID
+		return nil
+	}); rewindErr != nil {
+		return fmt.Errorf("hook failed: %w (rewind history: %v)", err, rewindErr)
+	}
+	return fmt.Errorf("hook failed: %w", err)
+}
+
+// poll treats the mailbox's current historyId as if it had arrived in a push,
+// so unchanged mailboxes cost one profile read and no history.list.
+func (s *gmailWatchServer) poll(ctx context.Context, emit func(*gmailHookPayload)) error {
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		return err
+	}
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+	historyID := formatHistoryID(profile.HistoryId)
+	if s.store.Get().HistoryID == "" {
+		return s.store.Update(func(state *gmailWatchState) error {
+			state.HistoryID = historyID
+			state.UpdatedAtMs = time.Now().UnixMilli()
+			return nil
+		})
+	}
+	if stale, err := isStaleHistoryID(s.store.Get().HistoryID, historyID); err == nil && stale {
+		return nil
+	}
+	return s.dispatch(ctx, gmailPushPayload{EmailAddress: profile.EmailAddress, HistoryID: historyID}, emit)
+}
+
+// pull reads one batch from a Pub/Sub pull subscription. Messages are acked
+// once handled and their hook payload delivered or queued; a failed history
+// read or delivery leaves the message for redelivery, as a 5xx does for push.
+func (s *gmailWatchServer) pull(ctx context.Context, ps *pubsub.Service, subscription string, emit func(*gmailHookPayload)) error {
+	resp, err := ps.Projects.Subscriptions.Pull(subscription, &pubsub.PullRequest{
+		MaxMessages: defaultPullMaxMessages,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", subscription, err)
+	}
+
+	ackIDs := make([]string, 0, len(resp.ReceivedMessages))
+	for _, received := range resp.ReceivedMessages {
+		if received == nil || received.Message == nil {
+			continue
+		}
+		var envelope pubsubPushEnvelope
+		envelope.Message.Data = received.Message.Data
+		envelope.Message.MessageID = received.Message.MessageId
+		payload, err := decodeGmailPushPayload(&envelope)
+		switch {
+		case err != nil:
+			s.warnf("watch: invalid pull data: %v", err)
+		case payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account):
+			s.warnf("watch: ignoring push for %s", payload.EmailAddress)
+		default:
+			if err := s.dispatch(ctx, payload, emit); err != nil {
+				s.warnf("watch: handle push failed: %v", err)
+				continue
+			}
+		}
+		ackIDs = append(ackIDs, received.AckId)
+	}
+
+	if len(ackIDs) == 0 {
+		return nil
+	}
+	if _, err := ps.Projects.Subscriptions.Acknowledge(subscription, &pubsub.AcknowledgeRequest{
+		AckIds: ackIDs,
+	}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("ack %s: %w", subscription, err)
+	}
+	return nil
+}
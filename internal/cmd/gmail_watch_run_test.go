@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+// newGmailWatchRunTest wires a fake Gmail API (profile at historyId 200, one
+// new message m1) and a hook receiver, and returns the hook payloads seen.
+func newGmailWatchRunTest(t *testing.T) (hookURL string, hooks func() []gmailHookPayload) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	gmailSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users/me/profile"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": "200"})
+		case strings.Contains(r.URL.Path, "/users/me/history"):
+			if got := r.URL.Query().Get("startHistoryId"); got != "100" {
+				t.Errorf("startHistoryId = %q", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "200",
+				"history":   []map[string]any{{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}}}},
+			})
+		case strings.Contains(r.URL.Path, "/users/me/messages/m1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "threadId": "t1", "snippet": "hi"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(gmailSrv.Close)
+	gsvc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(gmailSrv.Client()),
+		option.WithEndpoint(gmailSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return gsvc, nil }
+
+	var mu sync.Mutex
+	var got []gmailHookPayload
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload gmailHookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		got = append(got, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(hookSrv.Close)
+
+	return hookSrv.URL, func() []gmailHookPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]gmailHookPayload(nil), got...)
+	}
+}
+
+func seedGmailWatchHistory(t *testing.T, historyID string) *gmailWatchStore {
+	t.Helper()
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.HistoryID = historyID
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return store
+}
+
+func gmailWatchRunContext(t *testing.T) context.Context {
+	t.Helper()
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	return ui.WithUI(context.Background(), u)
+}
+
+func TestGmailWatchRun_PollFiresHook(t *testing.T) {
+	hookURL, hooks := newGmailWatchRunTest(t)
+	seedGmailWatchHistory(t, "100")
+
+	args := []string{"--mode", "poll", "--once", "--hook-url", hookURL}
+	if err := runKong(t, &GmailWatchRunCmd{}, args, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	got := hooks()
+	if len(got) != 1 || got[0].HistoryID != "200" || len(got[0].Messages) != 1 || got[0].Messages[0].ID != "m1" {
+		t.Fatalf("unexpected hooks: %+v", got)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if state := store.Get(); state.HistoryID != "200" || state.LastDeliveryStatus != "ok" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	// A second poll at the same historyId is a no-op.
+	if err := runKong(t, &GmailWatchRunCmd{}, args, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(hooks()) != 1 {
+		t.Fatalf("expected no new hook, got %d", len(hooks()))
+	}
+}
+
+func TestGmailWatchRun_PollSeedsStateWithoutWatchStart(t *testing.T) {
+	hookURL, hooks := newGmailWatchRunTest(t)
+
+	if err := runKong(t, &GmailWatchRunCmd{}, []string{"--once", "--hook-url", hookURL}, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if state := store.Get(); state.HistoryID != "200" || state.Account != "a@b.com" {
+		t.Fatalf("unexpected seeded state: %+v", state)
+	}
+	if len(hooks()) != 0 {
+		t.Fatalf("seeding should not fire hooks: %+v", hooks())
+	}
+}
+
+func TestGmailWatchRun_PullAcksHandledMessages(t *testing.T) {
+	hookURL, hooks := newGmailWatchRunTest(t)
+	seedGmailWatchHistory(t, "100")
+
+	data := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	var acked []string
+	psSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/projects/p/subscriptions/gmail:pull"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"receivedMessages": []any{
+				map[string]any{"ackId": "a1", "message": map[string]any{"messageId": "p1", "data": data(`{"emailAddress":"a@b.com","historyId":200}`)}},
+				map[string]any{"ackId": "a2", "message": map[string]any{"messageId": "p2", "data": data(`{"emailAddress":"other@b.com","historyId":"300"}`)}},
+			}})
+		case strings.HasSuffix(r.URL.Path, "/v1/projects/p/subscriptions/gmail:acknowledge"):
+			var req pubsub.AcknowledgeRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			acked = append(acked, req.AckIds...)
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer psSrv.Close()
+	origPS := newPubSubService
+	t.Cleanup(func() { newPubSubService = origPS })
+	newPubSubService = func(ctx context.Context) (*pubsub.Service, error) {
+		return pubsub.NewService(ctx, option.WithoutAuthentication(), option.WithHTTPClient(psSrv.Client()), option.WithEndpoint(psSrv.URL+"/"))
+	}
+
+	args := []string{"--mode", "pull", "--subscription", "projects/p/subscriptions/gmail", "--once", "--hook-url", hookURL}
+	if err := runKong(t, &GmailWatchRunCmd{}, args, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := hooks(); len(got) != 1 || got[0].Messages[0].ID != "m1" {
+		t.Fatalf("unexpected hooks: %+v", got)
+	}
+	if strings.Join(acked, ",") != "a1,a2" {
+		t.Fatalf("acked = %v", acked)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if state := store.Get(); state.LastPushMessageID != "p1" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestGmailWatchRun_Validation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	flags := &RootFlags{Account: "a@b.com"}
+	for _, args := range [][]string{
+		{"--mode", "pull"},
+		{"--mode", "pull", "--subscription", "gmail"},
+		{"--mode", "poll", "--subscription", "projects/p/subscriptions/s"},
+		{"--mode", "poll", "--interval", "0"},
+		{"--interval", "soon"},
+	} {
+		err := runKong(t, &GmailWatchRunCmd{}, args, gmailWatchRunContext(t), flags)
+		if err == nil || ExitCode(err) != 2 {
+			t.Fatalf("%v: expected usage error, got %v", args, err)
+		}
+	}
+}
+
+func TestGmailWatchRun_PullLeavesUndeliveredMessages(t *testing.T) {
+	_, _ = newGmailWatchRunTest(t)
+	store := seedGmailWatchHistory(t, "100")
+
+	var mu sync.Mutex
+	hookStatus, hookCalls := http.StatusServiceUnavailable, 0
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hookCalls++
+		w.WriteHeader(hookStatus)
+	}))
+	defer hookSrv.Close()
+
+	var acked []string
+	psSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":pull"):
+			data := base64.StdEncoding.EncodeToString([]byte(`{"emailAddress":"a@b.com","historyId":"200"}`))
+			_ = json.NewEncoder(w).Encode(map[string]any{"receivedMessages": []any{
+				map[string]any{"ackId": "a1", "message": map[string]any{"messageId": "p1", "data": data}},
+			}})
+		case strings.HasSuffix(r.URL.Path, ":acknowledge"):
+			var req pubsub.AcknowledgeRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			acked = append(acked, req.AckIds...)
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer psSrv.Close()
+	ps, err := pubsub.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(psSrv.Client()), option.WithEndpoint(psSrv.URL+"/"))
+	if err != nil {
+		t.Fatalf("pubsub: %v", err)
+	}
+
+	// Without a delivery queue, a failed hook must not be acked, and the
+	// history position must not move past the undelivered changes.
+	s := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HookURL: hookSrv.URL, HookTimeout: 5 * time.Second},
+		store:      store,
+		newService: newGmailService,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+	if err := s.pull(context.Background(), ps, "projects/p/subscriptions/gmail", nil); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if len(acked) != 0 || hookCalls != 1 {
+		t.Fatalf("expected no ack after failed hook, acked=%v calls=%d", acked, hookCalls)
+	}
+	if state := store.Get(); state.HistoryID != "100" || state.LastPushMessageID != "" {
+		t.Fatalf("expected history rewound, got %+v", state)
+	}
+
+	// The redelivered message replays the same history once the hook works.
+	mu.Lock()
+	hookStatus = http.StatusOK
+	mu.Unlock()
+	if err := s.pull(context.Background(), ps, "projects/p/subscriptions/gmail", nil); err != nil {
+		t.Fatalf("second pull: %v", err)
+	}
+	if strings.Join(acked, ",") != "a1" || hookCalls != 2 || store.Get().HistoryID != "200" {
+		t.Fatalf("unexpected redelivery: acked=%v calls=%d state=%+v", acked, hookCalls, store.Get())
+	}
+
+	// With a queue, the failed delivery is persisted, so the message is acked.
+	if err := store.Update(func(st *gmailWatchState) error {
+		st.HistoryID, st.LastPushMessageID = "100", ""
+		return nil
+	}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	mu.Lock()
+	hookStatus = http.StatusServiceUnavailable
+	mu.Unlock()
+	if s.queue, err = newGmailWatchDeliveryQueue("a@b.com"); err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if err := s.pull(context.Background(), ps, "projects/p/subscriptions/gmail", nil); err != nil {
+		t.Fatalf("queued pull: %v", err)
+	}
+	pending, err := s.queue.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if strings.Join(acked, ",") != "a1,a1" || len(pending) != 1 {
+		t.Fatalf("expected ack with queued delivery, acked=%v pending=%d", acked, len(pending))
+	}
+}
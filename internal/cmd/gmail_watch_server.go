@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"crypto/subtle"
 	"encoding/base64"
@@ -32,43 +31,60 @@ type gmailWatchServer struct {
 	newService      func(context.Context, string) (*gmail.Service, error)
 	hookClient      *http.Client
 	excludeLabelIDs map[string]struct{}
+	queue           *gmailWatchDeliveryQueue
 	logf            func(string, ...any)
 	warnf           func(string, ...any)
 }
 
 func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if !pathMatches(s.cfg.Path, r.URL.Path) {
-		w.WriteHeader(http.StatusNotFound)
+		if !serveGmailWatchStatus(w, r, []*gmailWatchServer{s}) {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	payload, ok := s.readPush(w, r)
+	if !ok {
+		return
+	}
+	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account) {
+		s.warnf("watch: ignoring push for %s", payload.EmailAddress)
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	s.respondPush(w, r, payload)
+}
+
+// readPush checks method and auth and decodes the Pub/Sub envelope, writing
+// the error response itself when it returns false.
+func (s *gmailWatchServer) readPush(w http.ResponseWriter, r *http.Request) (gmailPushPayload, bool) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", http.MethodPost)
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return gmailPushPayload{}, false
 	}
 	if ok := s.authorize(r); !ok {
 		w.WriteHeader(http.StatusUnauthorized)
-		return
+		return gmailPushPayload{}, false
 	}
 
 	push, err := parsePubSubPush(r)
 	if err != nil {
 		s.warnf("watch: invalid push payload: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
-		return
+		return gmailPushPayload{}, false
 	}
 	payload, err := decodeGmailPushPayload(push)
 	if err != nil {
 		s.warnf("watch: invalid push data: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account) {
-		s.warnf("watch: ignoring push for %s", payload.EmailAddress)
-		w.WriteHeader(http.StatusAccepted)
-		return
+		return gmailPushPayload{}, false
 	}
+	return payload, true
+}
 
+// respondPush handles a push addressed to this server's account.
+func (s *gmailWatchServer) respondPush(w http.ResponseWriter, r *http.Request, payload gmailPushPayload) {
 	result, err := s.handlePush(r.Context(), payload)
 	if err != nil {
 		if errors.Is(err, errNoNewMessages) {
@@ -93,7 +109,7 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.sendHook(r.Context(), result); err != nil {
+	if err := s.deliver(r.Context(), result); err != nil {
 		s.warnf("watch: hook failed: %v", err)
 		w.WriteHeader(http.StatusOK)
 		return
@@ -339,33 +355,23 @@ func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPaylo
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.HookURL, bytes.NewReader(data))
+	sink, err := s.sink()
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if s.cfg.HookToken != "" {
-		req.Header.Set("Authorization", "Bearer "+s.cfg.HookToken)
-	}
-	resp, err := s.hookClient.Do(req)
-	if err != nil {
+	if err := sink.send(ctx, data); err != nil {
+		var statusErr *gmailHookStatusError
 		_ = s.store.Update(func(state *gmailWatchState) error {
 			state.LastDeliveryStatus = "error"
-			state.LastDeliveryAtMs = time.Now().UnixMilli()
 			state.LastDeliveryStatusNote = err.Error()
-			return nil
-		})
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		_ = s.store.Update(func(state *gmailWatchState) error {
-			state.LastDeliveryStatus = gmailWatchStatusHTTPError
+			if errors.As(err, &statusErr) {
+				state.LastDeliveryStatus = gmailWatchStatusHTTPError
+				state.LastDeliveryStatusNote = fmt.Sprintf("status %d", statusErr.StatusCode)
+			}
 			state.LastDeliveryAtMs = time.Now().UnixMilli()
-			state.LastDeliveryStatusNote = fmt.Sprintf("status %d", resp.StatusCode)
 			return nil
 		})
-		return fmt.Errorf("hook status %d", resp.StatusCode)
+		return err
 	}
 	_ = s.store.Update(func(state *gmailWatchState) error {
 		state.LastDeliveryStatus = "ok"
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+)
+
+// Hook targets other than http(s) URLs. The target is stored in the hook's
+// URL field, so saved watch state needs no new schema.
+const (
+	gmailSinkStdout     = "stdout"
+	gmailSinkExecPrefix = "exec:"
+	gmailSinkFilePrefix = "file:"
+	gmailSinkUnixPrefix = "unix:"
+
+	gmailSinkStderrLimit = 512
+)
+
+// gmailWatchStdout is where the stdout sink writes; swapped in tests.
+var (
+	gmailWatchStdout   io.Writer = os.Stdout
+	gmailWatchStdoutMu sync.Mutex
+)
+
+// gmailWatchSink delivers one encoded hook payload.
+type gmailWatchSink interface {
+	send(ctx context.Context, data []byte) error
+}
+
+// validateGmailWatchSink checks a --hook-url value up front so a typo fails at
+// watch start rather than on the first delivery.
+func validateGmailWatchSink(target, token string) error {
+	target = strings.TrimSpace(target)
+	lower := strings.ToLower(target)
+	switch {
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		return nil
+	case lower == gmailSinkStdout:
+	case strings.HasPrefix(lower, gmailSinkExecPrefix),
+		strings.HasPrefix(lower, gmailSinkFilePrefix),
+		strings.HasPrefix(lower, gmailSinkUnixPrefix):
+		kind, rest, _ := strings.Cut(target, ":")
+		if strings.TrimSpace(rest) == "" {
+			return usagef("--hook-url %s: requires a value after %q", strings.ToLower(kind), strings.ToLower(kind)+":")
+		}
+	default:
+		return usagef("unsupported --hook-url %q (expected http(s)://..., exec:<command>, file:<path>, unix:<socket> or stdout)", target)
+	}
+	if token != "" {
+		return usage("--hook-token only applies to http(s) hooks")
+	}
+	return nil
+}
+
+// sink resolves the configured hook target.
+func (s *gmailWatchServer) sink() (gmailWatchSink, error) {
+	target := strings.TrimSpace(s.cfg.HookURL)
+	kind, rest, _ := strings.Cut(target, ":")
+	timeout := s.cfg.HookTimeout
+	if timeout <= 0 {
+		timeout = defaultHookRequestTimeoutSec * time.Second
+	}
+	switch strings.ToLower(kind) {
+	case "http", "https":
+		return &gmailHTTPSink{url: target, token: s.cfg.HookToken, client: s.hookClient}, nil
+	case gmailSinkStdout:
+		if rest == "" {
+			return gmailStdoutSink{}, nil
+		}
+	case strings.TrimSuffix(gmailSinkExecPrefix, ":"):
+		return &gmailExecSink{command: strings.TrimSpace(rest), account: s.cfg.Account, timeout: timeout}, nil
+	case strings.TrimSuffix(gmailSinkFilePrefix, ":"):
+		path, err := config.ExpandPath(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, err
+		}
+		return gmailFileSink{path: path}, nil
+	case strings.TrimSuffix(gmailSinkUnixPrefix, ":"):
+		path, err := config.ExpandPath(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, err
+		}
+		return gmailUnixSink{path: path, timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("unsupported hook target %q", target)
+}
+
+type gmailHTTPSink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (k *gmailHTTPSink) send(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+	client := k.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &gmailHookStatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// gmailExecSink runs a shell command with the payload on stdin. A non-zero
+// exit is a failed delivery, retried like an HTTP error.
+type gmailExecSink struct {
+	command string
+	account string
+	timeout time.Duration
+}
+
+func (k *gmailExecSink) send(ctx context.Context, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, k.command) //nolint:gosec // user-configured hook command
+	cmd.Stdin = bytes.NewReader(ndjsonLine(data))
+	cmd.Env = append(os.Environ(), "GOG_WATCH_ACCOUNT="+k.account)
+	// Don't wait on grandchildren that inherited stderr once the shell is killed.
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("exec hook timed out after %s", k.timeout)
+		}
+		if msg, _ := truncateUTF8Bytes(strings.TrimSpace(stderr.String()), gmailSinkStderrLimit); msg != "" {
+			return fmt.Errorf("exec hook: %w: %s", err, msg)
+		}
+		return fmt.Errorf("exec hook: %w", err)
+	}
+	return nil
+}
+
+// gmailFileSink appends each payload as one NDJSON line.
+type gmailFileSink struct {
+	path string
+}
+
+func (k gmailFileSink) send(_ context.Context, data []byte) error {
+	f, err := os.OpenFile(k.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600) //nolint:gosec // user-configured hook path
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(ndjsonLine(data)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type gmailStdoutSink struct{}
+
+func (gmailStdoutSink) send(_ context.Context, data []byte) error {
+	gmailWatchStdoutMu.Lock()
+	defer gmailWatchStdoutMu.Unlock()
+	_, err := gmailWatchStdout.Write(ndjsonLine(data))
+	return err
+}
+
+// gmailUnixSink writes one NDJSON line per connection to a Unix stream socket.
+type gmailUnixSink struct {
+	path    string
+	timeout time.Duration
+}
+
+func (k gmailUnixSink) send(ctx context.Context, data []byte) error {
+	dialer := net.Dialer{Timeout: k.timeout}
+	conn, err := dialer.DialContext(ctx, "unix", k.path)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(k.timeout))
+	if _, err := conn.Write(ndjsonLine(data)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	return conn.Close()
+}
+
+func ndjsonLine(data []byte) []byte {
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, bytes.TrimRight(data, "\n")...)
+	return append(line, '\n')
+}
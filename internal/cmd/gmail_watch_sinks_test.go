@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newGmailWatchSinkServer(t *testing.T, target string) *gmailWatchServer {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	return &gmailWatchServer{
+		cfg:   gmailWatchServeConfig{Account: "a@b.com", HookURL: target, HookTimeout: 5 * time.Second},
+		store: store,
+		logf:  func(string, ...any) {},
+		warnf: func(string, ...any) {},
+	}
+}
+
+func decodeGmailSinkLines(t *testing.T, data []byte) []gmailHookPayload {
+	t.Helper()
+	var out []gmailHookPayload
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var p gmailHookPayload
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func TestValidateGmailWatchSink(t *testing.T) {
+	for _, target := range []string{"https://x.test/h", "stdout", "exec:./label.sh --fast", "file:~/mail.ndjson", "unix:/run/gog.sock"} {
+		if err := validateGmailWatchSink(target, ""); err != nil {
+			t.Errorf("%q: %v", target, err)
+		}
+	}
+	for _, tc := range []struct{ target, token string }{
+		{"ftp://x.test", ""},
+		{"exec:", ""},
+		{"file:  ", ""},
+		{"stdout", "secret"},
+		{"exec:true", "secret"},
+	} {
+		if err := validateGmailWatchSink(tc.target, tc.token); err == nil || ExitCode(err) != 2 {
+			t.Errorf("%q/%q: expected usage error, got %v", tc.target, tc.token, err)
+		}
+	}
+}
+
+func TestGmailWatchSink_FileAppendsNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.ndjson")
+	s := newGmailWatchSinkServer(t, "file:"+path)
+	for _, id := range []string{"1", "2"} {
+		if err := s.sendHook(context.Background(), &gmailHookPayload{Account: "a@b.com", HistoryID: id}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := decodeGmailSinkLines(t, data)
+	if len(got) != 2 || got[0].HistoryID != "1" || got[1].HistoryID != "2" {
+		t.Fatalf("unexpected lines: %q", data)
+	}
+	if state := s.store.Get(); state.LastDeliveryStatus != "ok" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestGmailWatchSink_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	orig := gmailWatchStdout
+	gmailWatchStdout = &buf
+	t.Cleanup(func() { gmailWatchStdout = orig })
+
+	s := newGmailWatchSinkServer(t, "stdout")
+	if err := s.sendHook(context.Background(), &gmailHookPayload{HistoryID: "9"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := decodeGmailSinkLines(t, buf.Bytes()); len(got) != 1 || got[0].HistoryID != "9" {
+		t.Fatalf("unexpected stdout: %q", buf.String())
+	}
+}
+
+func TestGmailWatchSink_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "out.json")
+	s := newGmailWatchSinkServer(t, `exec:cat > "`+out+`"; echo "$GOG_WATCH_ACCOUNT" >> "`+out+`"`)
+	if err := s.sendHook(context.Background(), &gmailHookPayload{HistoryID: "7"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	payload, account, _ := strings.Cut(string(data), "\n")
+	if !strings.Contains(payload, `"historyId":"7"`) || strings.TrimSpace(account) != "a@b.com" {
+		t.Fatalf("unexpected exec input: %q", data)
+	}
+
+	s.cfg.HookURL = "exec:echo nope >&2; exit 3"
+	err = s.sendHook(context.Background(), &gmailHookPayload{HistoryID: "8"})
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected exit error with stderr, got %v", err)
+	}
+	if state := s.store.Get(); state.LastDeliveryStatus != "error" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	s.cfg.HookURL = "exec:sleep 5"
+	s.cfg.HookTimeout = 50 * time.Millisecond
+	if err := s.sendHook(context.Background(), &gmailHookPayload{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestGmailWatchSink_UnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets")
+	}
+	dir, err := os.MkdirTemp("", "gogsock")
+	if err != nil {
+		t.Fatalf("tempdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "s")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadBytes('\n')
+		received <- line
+	}()
+
+	s := newGmailWatchSinkServer(t, "unix:"+sock)
+	if err := s.sendHook(context.Background(), &gmailHookPayload{HistoryID: "5"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	select {
+	case line := <-received:
+		if got := decodeGmailSinkLines(t, line); len(got) != 1 || got[0].HistoryID != "5" {
+			t.Fatalf("unexpected line: %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("socket received nothing")
+	}
+}
+
+func TestGmailWatchRun_FileSink(t *testing.T) {
+	_, _ = newGmailWatchRunTest(t)
+	seedGmailWatchHistory(t, "100")
+	path := filepath.Join(t.TempDir(), "mail.ndjson")
+
+	args := []string{"--once", "--sink", "file:" + path}
+	if err := runKong(t, &GmailWatchRunCmd{}, args, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := decodeGmailSinkLines(t, data)
+	if len(got) != 1 || len(got[0].Messages) != 1 || got[0].Messages[0].ID != "m1" {
+		t.Fatalf("unexpected payloads: %q", data)
+	}
+
+	if err := runKong(t, &GmailWatchRunCmd{}, []string{"--once", "--sink", "stdout", "--hook-token", "x"}, gmailWatchRunContext(t), &RootFlags{Account: "a@b.com"}); err == nil || ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for token on stdout sink, got %v", err)
+	}
+}
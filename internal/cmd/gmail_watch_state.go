@@ -15,6 +15,8 @@ import (
 	"github.com/jibankumarpanda/gogcli/internal/config"
 )
 
+var errGmailWatchStateNotFound = errors.New("watch state not found; run gmail watch start")
+
 type gmailWatchStore struct {
 	path  string
 	mu    sync.Mutex
@@ -70,7 +72,7 @@ func loadGmailWatchStore(account string) (*gmailWatchStore, error) {
 	data, err := os.ReadFile(store.path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, errors.New("watch state not found; run gmail watch start")
+			return nil, errGmailWatchStateNotFound
 		}
 		return nil, err
 	}
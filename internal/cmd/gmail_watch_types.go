@@ -37,6 +37,10 @@ type gmailWatchState struct {
 	LastDeliveryAtMs       int64           `json:"lastDeliveryAtMs,omitempty"`
 	LastDeliveryStatusNote string          `json:"lastDeliveryStatusNote,omitempty"`
 	LastPushMessageID      string          `json:"lastPushMessageId,omitempty"`
+	LastRenewStatus        string          `json:"lastRenewStatus,omitempty"`
+	LastRenewAtMs          int64           `json:"lastRenewAtMs,omitempty"`
+	LastRenewError         string          `json:"lastRenewError,omitempty"`
+	RenewFailures          int             `json:"renewFailures,omitempty"`
 }
 
 type gmailWatchServeConfig struct {
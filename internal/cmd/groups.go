@@ -64,6 +64,12 @@ func (c *GroupsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Memberships, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*cloudidentity.GroupRelation) error {
+			return writeNDJSON(ctx, groupItems(page))
+		})
+	}
+
 	var memberships []*cloudidentity.GroupRelation
 	nextPageToken := ""
 	if c.All {
@@ -81,22 +87,7 @@ func (c *GroupsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			GroupName   string `json:"groupName"`
-			DisplayName string `json:"displayName,omitempty"`
-			Role        string `json:"role,omitempty"`
-		}
-		items := make([]item, 0, len(memberships))
-		for _, m := range memberships {
-			if m == nil {
-				continue
-			}
-			items = append(items, item{
-				GroupName:   m.GroupKey.Id,
-				DisplayName: m.DisplayName,
-				Role:        getRelationType(m.RelationType),
-			})
-		}
+		items := groupItems(memberships)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"groups":        items,
 			"nextPageToken": nextPageToken,
@@ -206,6 +197,12 @@ func (c *GroupsMembersCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Memberships, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*cloudidentity.Membership) error {
+			return writeNDJSON(ctx, groupMemberItems(page))
+		})
+	}
+
 	var memberships []*cloudidentity.Membership
 	nextPageToken := ""
 	if c.All {
@@ -223,22 +220,7 @@ func (c *GroupsMembersCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Email string `json:"email"`
-			Role  string `json:"role"`
-			Type  string `json:"type"`
-		}
-		items := make([]item, 0, len(memberships))
-		for _, m := range memberships {
-			if m == nil || m.PreferredMemberKey == nil {
-				continue
-			}
-			items = append(items, item{
-				Email: m.PreferredMemberKey.Id,
-				Role:  getMemberRole(m.Roles),
-				Type:  m.Type,
-			})
-		}
+		items := groupMemberItems(memberships)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"members":       items,
 			"nextPageToken": nextPageToken,
@@ -385,3 +367,45 @@ func listGroupMemberships(ctx context.Context, svc *cloudidentity.Service, group
 	}
 	return collectAllPages("", fetch)
 }
+
+type groupMemberItem struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	Type  string `json:"type"`
+}
+
+func groupMemberItems(memberships []*cloudidentity.Membership) []groupMemberItem {
+	items := make([]groupMemberItem, 0, len(memberships))
+	for _, m := range memberships {
+		if m == nil || m.PreferredMemberKey == nil {
+			continue
+		}
+		items = append(items, groupMemberItem{
+			Email: m.PreferredMemberKey.Id,
+			Role:  getMemberRole(m.Roles),
+			Type:  m.Type,
+		})
+	}
+	return items
+}
+
+type groupItem struct {
+	GroupName   string `json:"groupName"`
+	DisplayName string `json:"displayName,omitempty"`
+	Role        string `json:"role,omitempty"`
+}
+
+func groupItems(memberships []*cloudidentity.GroupRelation) []groupItem {
+	items := make([]groupItem, 0, len(memberships))
+	for _, m := range memberships {
+		if m == nil {
+			continue
+		}
+		items = append(items, groupItem{
+			GroupName:   m.GroupKey.Id,
+			DisplayName: m.DisplayName,
+			Role:        getRelationType(m.RelationType),
+		})
+	}
+	return items
+}
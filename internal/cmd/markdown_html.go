@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"html"
+	"strings"
+	"unicode/utf16"
+)
+
+// markdownToHTML renders the markdown subset understood by ParseMarkdown as
+// a simple HTML fragment (used for email bodies).
+func markdownToHTML(text string) string {
+	var b strings.Builder
+	// Open lists, outermost first; the last <li> of each is left open so a
+	// nested list can go inside it.
+	var openLists []string
+	closeList := func() {
+		for len(openLists) > 0 {
+			b.WriteString("</li>\n</" + openLists[len(openLists)-1] + ">\n")
+			openLists = openLists[:len(openLists)-1]
+		}
+	}
+	list := func(tag string, level int, content string) {
+		for len(openLists) > level+1 {
+			b.WriteString("</li>\n</" + openLists[len(openLists)-1] + ">\n")
+			openLists = openLists[:len(openLists)-1]
+		}
+		if n := len(openLists); n == level+1 {
+			b.WriteString("</li>\n")
+			if openLists[n-1] != tag {
+				b.WriteString("</" + openLists[n-1] + ">\n<" + tag + ">\n")
+				openLists[n-1] = tag
+			}
+		}
+		for len(openLists) < level+1 {
+			b.WriteString("<" + tag + ">\n")
+			openLists = append(openLists, tag)
+		}
+		b.WriteString("<li>" + markdownInlineHTML(content))
+	}
+
+	for _, el := range ParseMarkdown(text) {
+		switch el.Type {
+		case MDListItem:
+			list("ul", el.Level, el.Content)
+			continue
+		case MDNumberedList:
+			list("ol", el.Level, el.Content)
+			continue
+		case MDTaskItem:
+			box := "☐ "
+			if el.Checked {
+				box = "☑ "
+			}
+			list("ul", el.Level, box+el.Content)
+			continue
+		}
+		closeList()
+		switch el.Type {
+		case MDHeading1, MDHeading2, MDHeading3, MDHeading4, MDHeading5, MDHeading6:
+			tag := "h" + string(rune('1'+int(el.Type-MDHeading1)))
+			b.WriteString("<" + tag + ">" + markdownInlineHTML(el.Content) + "</" + tag + ">\n")
+		case MDCodeBlock:
+			class := ""
+			if el.Language != "" {
+				class = ` class="language-` + html.EscapeString(el.Language) + `"`
+			}
+			b.WriteString("<pre><code" + class + ">" + html.EscapeString(el.Content) + "</code></pre>\n")
+		case MDBlockquote:
+			b.WriteString("<blockquote>" + markdownInlineHTML(el.Content) + "</blockquote>\n")
+		case MDHorizontalRule:
+			b.WriteString("<hr>\n")
+		case MDTable:
+			b.WriteString("<table>\n")
+			for i, row := range el.TableCells {
+				cell := "td"
+				if i == 0 {
+					cell = "th"
+				}
+				b.WriteString("<tr>")
+				for j, c := range row {
+					attr := ""
+					if j < len(el.TableAlign) && el.TableAlign[j] != "" {
+						attr = ` align="` + htmlTableAlign[el.TableAlign[j]] + `"`
+					}
+					b.WriteString("<" + cell + attr + ">" + markdownInlineHTML(c) + "</" + cell + ">")
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+		case MDFootnote:
+			b.WriteString("<p><sup>" + html.EscapeString(el.FootnoteID) + "</sup> " + markdownInlineHTML(el.Content) + "</p>\n")
+		default:
+			b.WriteString("<p>" + markdownInlineHTML(el.Content) + "</p>\n")
+		}
+	}
+	closeList()
+	return b.String()
+}
+
+var htmlTableAlign = map[string]string{"START": "left", "CENTER": "center", "END": "right"}
+
+// markdownInlineHTML renders bold, italic, strikethrough, code, links and
+// footnote references, escaping the rest. Hard breaks become <br>.
+func markdownInlineHTML(text string) string {
+	styles, plain := ParseInlineFormatting(text)
+	units := utf16.Encode([]rune(plain))
+	slice := func(start, end int64) string {
+		start, end = max(start, 0), min(end, int64(len(units)))
+		if start >= end {
+			return ""
+		}
+		return strings.ReplaceAll(html.EscapeString(string(utf16.Decode(units[start:end]))), "\n", "<br>\n")
+	}
+
+	var b strings.Builder
+	pos := int64(0)
+	for _, st := range styles {
+		if st.Start < pos {
+			continue
+		}
+		b.WriteString(slice(pos, st.Start))
+		inner := slice(st.Start, st.End)
+		switch {
+		case st.Footnote != "":
+			inner = "<sup>" + html.EscapeString(st.Footnote) + "</sup>"
+		case st.Link != "":
+			inner = `<a href="` + html.EscapeString(st.Link) + `">` + inner + "</a>"
+		case st.Code:
+			inner = "<code>" + inner + "</code>"
+		}
+		if st.Strikethrough {
+			inner = "<s>" + inner + "</s>"
+		}
+		if st.Italic {
+			inner = "<em>" + inner + "</em>"
+		}
+		if st.Bold {
+			inner = "<strong>" + inner + "</strong>"
+		}
+		b.WriteString(inner)
+		pos = st.End
+	}
+	b.WriteString(slice(pos, int64(len(units))))
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// markdownInline wraps a run of text in markdown emphasis, code and link
+// syntax. Surrounding whitespace stays outside the markers so they still
+// parse. Links win over other styles since the importer doesn't nest them.
+func markdownInline(text string, bold, italic, code bool, link string) string {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, core)]
+	trail := text[len(lead)+len(core):]
+
+	if !code || link != "" {
+		core = escapeMarkdownText(core)
+	}
+	switch {
+	case link != "":
+		core = "[" + core + "](" + link + ")"
+	case code:
+		core = "`" + core + "`"
+	default:
+		if italic {
+			core = "*" + core + "*"
+		}
+		if bold {
+			core = "**" + core + "**"
+		}
+	}
+	return lead + core + trail
+}
+
+// markdownEscaper backslash-escapes characters that would read back as
+// inline markup.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "|", `\|`, "~", `\~`,
+)
+
+func escapeMarkdownText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeMarkdownLineStart escapes the start of a line that would otherwise
+// read back as a heading, quote, list item, rule or slide notes marker.
+func escapeMarkdownLineStart(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	lead := line[:len(line)-len(rest)]
+	if rest == "" {
+		return line
+	}
+	if level, _ := parseHeading(rest); level > 0 || rest[0] == '>' || isHorizontalRule(rest) || strings.TrimSpace(rest) == "???" {
+		return lead + `\` + rest
+	}
+	if m := mdListItemRe.FindStringSubmatch(rest); m != nil {
+		if n := len(m[2]); m[2][0] >= '0' && m[2][0] <= '9' {
+			return lead + rest[:n-1] + `\` + rest[n-1:]
+		}
+		return lead + `\` + rest
+	}
+	return line
+}
+
+func isMonospaceFont(family string) bool {
+	switch strings.ToLower(strings.TrimSpace(family)) {
+	case "courier new", "courier", "consolas", "roboto mono", "source code pro", "inconsolata", "monospace", "jetbrains mono", "fira code", "ubuntu mono":
+		return true
+	}
+	return false
+}
+
+// renderMarkdownTable renders rows (header first) as a pipe table, padding
+// short rows to cols cells and escaping pipes inside cells.
+func renderMarkdownTable(rows [][]string, cols int) string {
+	if len(rows) == 0 || cols == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = escapeTablePipes(row[j])
+			}
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// escapeTablePipes escapes the pipes in a cell that aren't escaped already;
+// inline text arrives escaped, code spans don't.
+func escapeTablePipes(cell string) string {
+	var b strings.Builder
+	escaped := false
+	for i := 0; i < len(cell); i++ {
+		c := cell[i]
+		if c == '|' && !escaped {
+			b.WriteByte('\\')
+		}
+		escaped = c == '\\' && !escaped
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// downloadURLToFile fetches a short-lived content URL (slide thumbnails, doc
+// images) that doesn't need the caller's credentials and writes it to path.
+// It returns the response's content type.
+func downloadURLToFile(ctx context.Context, url string, path string) (string, error) {
+	if url == "" {
+		return "", errors.New("no content URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), writeFileAtomic(path, data)
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
 )
 
 const emptyResultsExitCode = 3
@@ -17,27 +22,103 @@ func failEmptyExit(failEmpty bool) error {
 // collectAllPages keeps calling fetch until it returns an empty next page token.
 // It guards against pagination loops by tracking seen page tokens.
 func collectAllPages[T any](startPageToken string, fetch func(pageToken string) ([]T, string, error)) ([]T, error) {
+	var out []T
+	err := walkPages(startPageToken, fetch, func(items []T) error {
+		out = append(out, items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// walkPages hands each page to visit as soon as it arrives.
+func walkPages[T any](startPageToken string, fetch func(pageToken string) ([]T, string, error), visit func([]T) error) error {
 	pageToken := strings.TrimSpace(startPageToken)
 	seen := map[string]bool{}
 
-	var out []T
 	for i := 0; i < 10_000; i++ {
 		if seen[pageToken] {
-			return nil, fmt.Errorf("pagination loop: repeated page token %q", pageToken)
+			return fmt.Errorf("pagination loop: repeated page token %q", pageToken)
 		}
 		seen[pageToken] = true
 
 		items, next, err := fetch(pageToken)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := visit(items); err != nil {
+			return err
 		}
-		out = append(out, items...)
 
 		next = strings.TrimSpace(next)
 		if next == "" {
-			return out, nil
+			return nil
 		}
 		pageToken = next
 	}
-	return nil, fmt.Errorf("pagination exceeded max pages")
+	return fmt.Errorf("pagination exceeded max pages")
+}
+
+// streamPages is the --ndjson counterpart of collectAllPages: pages go to emit
+// as they arrive instead of being buffered. Without all, only the first page is
+// fetched and its next page token is hinted on stderr.
+func streamPages[T any](ctx context.Context, all bool, startPageToken string, failEmpty bool, fetch func(pageToken string) ([]T, string, error), emit func([]T) error) error {
+	count := 0
+	if all {
+		err := walkPages(startPageToken, fetch, func(items []T) error {
+			count += len(items)
+			return emit(items)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		items, nextPageToken, err := fetch(startPageToken)
+		if err != nil {
+			return err
+		}
+		count = len(items)
+		if err := emit(items); err != nil {
+			return err
+		}
+		printNextPageHint(ui.FromContext(ctx), nextPageToken)
+	}
+
+	if count == 0 {
+		return failEmptyExit(failEmpty)
+	}
+	return nil
+}
+
+// streamPagesNDJSON streams the API items as-is, one JSON line per item.
+func streamPagesNDJSON[T any](ctx context.Context, all bool, startPageToken string, failEmpty bool, fetch func(pageToken string) ([]T, string, error)) error {
+	return streamPages(ctx, all, startPageToken, failEmpty, fetch, func(items []T) error {
+		return writeNDJSON(ctx, items)
+	})
+}
+
+func writeNDJSON[T any](ctx context.Context, items []T) error {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]any, len(items))
+	for i, it := range items {
+		out[i] = it
+	}
+	return outfmt.WriteNDJSON(ctx, os.Stdout, out...)
+}
+
+// writeNDJSONPage emits an already-fetched page for commands that can't stream
+// (e.g. client-side filtered scans).
+func writeNDJSONPage[T any](ctx context.Context, items []T, nextPageToken string, failEmpty bool) error {
+	if err := writeNDJSON(ctx, items); err != nil {
+		return err
+	}
+	printNextPageHint(ui.FromContext(ctx), nextPageToken)
+	if len(items) == 0 {
+		return failEmptyExit(failEmpty)
+	}
+	return nil
 }
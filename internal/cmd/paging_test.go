@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func fakePages(pages map[string][]string, next map[string]string, calls *[]string) func(string) ([]string, string, error) {
+	return func(pageToken string) ([]string, string, error) {
+		*calls = append(*calls, pageToken)
+		return pages[pageToken], next[pageToken], nil
+	}
+}
+
+func TestCollectAllPages_FollowsTokens(t *testing.T) {
+	var calls []string
+	fetch := fakePages(
+		map[string][]string{"": {"a", "b"}, "p2": {"c"}},
+		map[string]string{"": "p2"},
+		&calls,
+	)
+
+	items, err := collectAllPages("", fetch)
+	if err != nil {
+		t.Fatalf("collectAllPages: %v", err)
+	}
+	if strings.Join(items, ",") != "a,b,c" {
+		t.Fatalf("unexpected items: %v", items)
+	}
+	if strings.Join(calls, ",") != ",p2" {
+		t.Fatalf("unexpected calls: %q", calls)
+	}
+}
+
+func TestCollectAllPages_LoopGuard(t *testing.T) {
+	var calls []string
+	fetch := fakePages(
+		map[string][]string{"": {"a"}, "p2": {"b"}},
+		map[string]string{"": "p2", "p2": "p2"},
+		&calls,
+	)
+
+	if _, err := collectAllPages("", fetch); err == nil || !strings.Contains(err.Error(), "pagination loop") {
+		t.Fatalf("expected pagination loop error, got %v", err)
+	}
+}
+
+func TestStreamPagesNDJSON_All(t *testing.T) {
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithNDJSON(ui.WithUI(context.Background(), u), outfmt.NDJSON{})
+
+	var calls []string
+	fetch := fakePages(
+		map[string][]string{"": {"a", "b"}, "p2": {"c"}},
+		map[string]string{"": "p2"},
+		&calls,
+	)
+
+	out := captureStdout(t, func() {
+		if err := streamPagesNDJSON(ctx, true, "", false, fetch); err != nil {
+			t.Fatalf("streamPagesNDJSON: %v", err)
+		}
+	})
+	if out != "\"a\"\n\"b\"\n\"c\"\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestStreamPagesNDJSON_SinglePageHint(t *testing.T) {
+	var stderr strings.Builder
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: &stderr, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithNDJSON(ui.WithUI(context.Background(), u), outfmt.NDJSON{})
+
+	var calls []string
+	fetch := fakePages(
+		map[string][]string{"": {"a"}},
+		map[string]string{"": "p2"},
+		&calls,
+	)
+
+	out := captureStdout(t, func() {
+		if err := streamPagesNDJSON(ctx, false, "", false, fetch); err != nil {
+			t.Fatalf("streamPagesNDJSON: %v", err)
+		}
+	})
+	if out != "\"a\"\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected one fetch, got %q", calls)
+	}
+	if !strings.Contains(stderr.String(), "p2") {
+		t.Fatalf("expected next page hint on stderr, got %q", stderr.String())
+	}
+}
+
+func TestStreamPagesNDJSON_FailEmpty(t *testing.T) {
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := outfmt.WithNDJSON(ui.WithUI(context.Background(), u), outfmt.NDJSON{})
+
+	var calls []string
+	fetch := fakePages(map[string][]string{}, map[string]string{}, &calls)
+
+	out := captureStdout(t, func() {
+		err = streamPagesNDJSON(ctx, true, "", true, fetch)
+	})
+	if out != "" {
+		t.Fatalf("expected no output, got %q", out)
+	}
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != emptyResultsExitCode {
+		t.Fatalf("expected empty-results exit, got %v", err)
+	}
+}
@@ -109,6 +109,12 @@ func (c *PeopleSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.People, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPages(ctx, c.All, c.Page, c.FailEmpty, fetch, func(page []*people.Person) error {
+			return writeNDJSON(ctx, personListItems(page))
+		})
+	}
+
 	var peopleList []*people.Person
 	nextPageToken := ""
 	if c.All {
@@ -126,22 +132,7 @@ func (c *PeopleSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		type item struct {
-			Resource string `json:"resource"`
-			Name     string `json:"name,omitempty"`
-			Email    string `json:"email,omitempty"`
-		}
-		items := make([]item, 0, len(peopleList))
-		for _, p := range peopleList {
-			if p == nil {
-				continue
-			}
-			items = append(items, item{
-				Resource: p.ResourceName,
-				Name:     primaryName(p),
-				Email:    primaryEmail(p),
-			})
-		}
+		items := personListItems(peopleList)
 		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"people":        items,
 			"nextPageToken": nextPageToken,
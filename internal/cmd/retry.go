@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/httpretry"
+)
+
+// defaultRetries resolves the --retries default: GOG_RETRIES, then the
+// `retries` config key, then the built-in default.
+func defaultRetries() string {
+	if v := strings.TrimSpace(os.Getenv("GOG_RETRIES")); v != "" {
+		return v
+	}
+	if cfg, err := config.ReadConfig(); err == nil && cfg.Retries != nil {
+		return strconv.Itoa(*cfg.Retries)
+	}
+	return strconv.Itoa(httpretry.DefaultMaxRetries)
+}
+
+type retryTransportKey struct{}
+
+// withRetryTransport sets up the retrying transport for Google API calls.
+// Clients built in this package (--endpoint, Pub/Sub) use it directly via
+// retryTransport. The googleapi constructors get it as the oauth2.HTTPClient
+// context value, which oauth2 uses as the base transport under the token.
+func withRetryTransport(ctx context.Context, retries int) context.Context {
+	if retries <= 0 {
+		return ctx
+	}
+	policy := httpretry.DefaultPolicy()
+	policy.MaxRetries = retries
+	transport := httpretry.New(http.DefaultTransport, policy)
+	ctx = context.WithValue(ctx, retryTransportKey{}, transport)
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+}
+
+// retryTransport is the base transport for Google API clients: the retrying
+// one from withRetryTransport, or http.DefaultTransport when retries are off.
+func retryTransport(ctx context.Context) http.RoundTripper {
+	if t, ok := ctx.Value(retryTransportKey{}).(*httpretry.Transport); ok && t != nil {
+		return t
+	}
+	return http.DefaultTransport
+}
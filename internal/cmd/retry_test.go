@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+// The per-account constructors build their clients with oauth2.NewClient on
+// the command context; a 503 through such a client must be retried without
+// --endpoint.
+func TestExecute_RetriesThroughAccountClient(t *testing.T) {
+	var batchCalls, labelCalls atomic.Int32
+	var auth atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/labels"):
+			if labelCalls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, `{"error":{"code":503,"message":"backend"}}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"labels":[{"id":"STARRED","name":"STARRED","type":"system"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/messages/batchModify"):
+			if batchCalls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, `{"error":{"code":503,"message":"backend"}}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+	newGmailService = func(ctx context.Context, account string) (*gmail.Service, error) {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "tok-" + account})
+		return gmail.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, ts)), option.WithEndpoint(srv.URL+"/"))
+	}
+
+	_ = captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{"--json", "--retries", "2", "--account", "a@example.com", "gmail", "batch", "modify", "m1", "--add", "STARRED"}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if labelCalls.Load() != 2 || batchCalls.Load() != 2 {
+		t.Fatalf("expected each 503 to be retried once, got labels=%d batchModify=%d", labelCalls.Load(), batchCalls.Load())
+	}
+	if got, _ := auth.Load().(string); got != "Bearer tok-a@example.com" {
+		t.Fatalf("unexpected Authorization %q", got)
+	}
+}
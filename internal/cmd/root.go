@@ -30,9 +30,12 @@ const (
 type RootFlags struct {
 	Color          string `help:"Color output: auto|always|never" default:"${color}"`
 	Account        string `help:"Account email for API commands (gmail/calendar/chat/classroom/drive/docs/slides/contacts/tasks/people/sheets/forms/appscript)" aliases:"acct" short:"a"`
+	Accounts       string `name:"accounts" help:"Run the command once per account (comma-separated emails or aliases), concurrently; output is merged"`
+	AllAccounts    bool   `name:"all-accounts" help:"Run the command once per account with a stored token, concurrently; output is merged"`
 	Client         string `help:"OAuth client name (selects stored credentials + token bucket)" default:"${client}"`
 	EnableCommands string `help:"Comma-separated list of enabled top-level commands (restricts CLI)" default:"${enabled_commands}"`
 	JSON           bool   `help:"Output JSON to stdout (best for scripting)" default:"${json}" aliases:"machine" short:"j"`
+	NDJSON         bool   `name:"ndjson" help:"Output JSON Lines (one object per line; list commands stream items as pages arrive)" aliases:"jsonl,json-lines"`
 	Plain          bool   `help:"Output stable, parseable text to stdout (TSV; no colors)" default:"${plain}" aliases:"tsv" short:"p"`
 	ResultsOnly    bool   `name:"results-only" help:"In JSON mode, emit only the primary result (drops envelope fields like nextPageToken)"`
 	Select         string `name:"select" aliases:"pick,project" help:"In JSON mode, select comma-separated fields (best-effort; supports dot paths). Desire path: use --fields for most commands."`
@@ -40,6 +43,8 @@ type RootFlags struct {
 	Force          bool   `help:"Skip confirmations for destructive commands" aliases:"yes,assume-yes" short:"y"`
 	NoInput        bool   `help:"Never prompt; fail instead (useful for CI)" aliases:"non-interactive,noninteractive"`
 	Verbose        bool   `help:"Enable verbose logging" short:"v"`
+	Retries        int    `name:"retries" help:"Max retries for transient Google API errors (429/5xx/rate limits); 0 disables" default:"${retries}"`
+	Endpoint       string `name:"endpoint" help:"Send Google API requests to this base URL (e.g. a fakegoogle server) with fake auth instead of stored tokens" default:"${endpoint}"`
 }
 
 type CLI struct {
@@ -136,6 +141,13 @@ func Execute(args []string) (err error) {
 		cli.JSON = true
 	}
 
+	if cli.NDJSON {
+		if cli.Plain {
+			return newUsageError(errors.New("--ndjson cannot be combined with --plain"))
+		}
+		cli.JSON = true
+	}
+
 	mode, err := outfmt.FromFlags(cli.JSON, cli.Plain)
 	if err != nil {
 		return newUsageError(err)
@@ -147,8 +159,24 @@ func Execute(args []string) (err error) {
 		ResultsOnly: cli.ResultsOnly,
 		Select:      splitCommaList(cli.Select),
 	})
+	if cli.NDJSON {
+		ctx = outfmt.WithNDJSON(ctx, outfmt.NDJSON{Select: splitCommaList(cli.Select)})
+	}
 	ctx = authclient.WithClient(ctx, cli.Client)
 
+	if cli.Retries < 0 {
+		return newUsageError(errors.New("--retries must be >= 0"))
+	}
+	ctx = withRetryTransport(ctx, cli.Retries)
+
+	if strings.TrimSpace(cli.Endpoint) != "" {
+		restore, endpointErr := useAPIEndpoint(cli.Endpoint)
+		if endpointErr != nil {
+			return newUsageError(endpointErr)
+		}
+		defer restore()
+	}
+
 	uiColor := cli.Color
 	if outfmt.IsJSON(ctx) || outfmt.IsPlain(ctx) {
 		uiColor = colorNever
@@ -164,10 +192,21 @@ func Execute(args []string) (err error) {
 	}
 	ctx = ui.WithUI(ctx, u)
 
-	kctx.BindTo(ctx, (*context.Context)(nil))
-	kctx.Bind(&cli.RootFlags)
+	multiAccount := strings.TrimSpace(cli.Accounts) != "" || cli.AllAccounts
+	if multiAccount && strings.TrimSpace(cli.Account) != "" {
+		return newUsageError(errors.New("--account cannot be combined with --accounts/--all-accounts"))
+	}
+	if multiAccount && !handlesOwnAccounts(kctx) {
+		var accounts []string
+		if accounts, err = fanoutAccounts(&cli.RootFlags); err == nil {
+			err = runFanout(ctx, args, accounts)
+		}
+	} else {
+		kctx.BindTo(ctx, (*context.Context)(nil))
+		kctx.Bind(&cli.RootFlags)
 
-	err = kctx.Run()
+		err = kctx.Run()
+	}
 	if err == nil {
 		return nil
 	}
@@ -254,7 +293,7 @@ func isCalendarEventsCommand(args []string) bool {
 
 func globalFlagTakesValue(flag string) bool {
 	switch flag {
-	case "--color", "--account", "--acct", "--client", "--enable-commands", "--select", "--pick", "--project", "-a":
+	case "--color", "--account", "--acct", "--client", "--enable-commands", "--select", "--pick", "--project", "--retries", "--endpoint", "--accounts", "-a":
 		return true
 	default:
 		return false
@@ -301,8 +340,10 @@ func newParser(description string) (*kong.Kong, *CLI, error) {
 		"calendar_weekday": envOr("GOG_CALENDAR_WEEKDAY", "false"),
 		"client":           envOr("GOG_CLIENT", ""),
 		"enabled_commands": envOr("GOG_ENABLE_COMMANDS", ""),
+		"endpoint":         envOr("GOG_API_ENDPOINT", ""),
 		"json":             boolString(envMode.JSON),
 		"plain":            boolString(envMode.Plain),
+		"retries":          defaultRetries(),
 		"version":          VersionString(),
 	}
 
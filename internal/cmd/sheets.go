@@ -27,6 +27,7 @@ type SheetsCmd struct {
 	Get      SheetsGetCmd      `cmd:"" name:"get" aliases:"read,show" help:"Get values from a range"`
 	Update   SheetsUpdateCmd   `cmd:"" name:"update" aliases:"edit,set" help:"Update values in a range"`
 	Append   SheetsAppendCmd   `cmd:"" name:"append" aliases:"add" help:"Append values to a range"`
+	Import   SheetsImportCmd   `cmd:"" name:"import" help:"Import rows from a CSV, TSV or JSON file"`
 	Insert   SheetsInsertCmd   `cmd:"" name:"insert" help:"Insert empty rows or columns into a sheet"`
 	Clear    SheetsClearCmd    `cmd:"" name:"clear" help:"Clear values in a range"`
 	Format   SheetsFormatCmd   `cmd:"" name:"format" help:"Apply cell formatting to a range"`
@@ -73,6 +74,7 @@ type SheetsGetCmd struct {
 	Range             string `arg:"" name:"range" help:"Range (eg. Sheet1!A1:B10)"`
 	MajorDimension    string `name:"dimension" help:"Major dimension: ROWS or COLUMNS"`
 	ValueRenderOption string `name:"render" help:"Value render option: FORMATTED_VALUE, UNFORMATTED_VALUE, or FORMULA"`
+	As                string `name:"as" help:"Write values as csv|tsv|json-records (records are keyed by the header row); overrides --json/--plain"`
 }
 
 func (c *SheetsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -90,6 +92,14 @@ func (c *SheetsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if strings.TrimSpace(rangeSpec) == "" {
 		return usage("empty range")
 	}
+	switch c.As {
+	case "", "csv", "tsv", "json-records":
+	default:
+		return usagef("invalid --as %q (want csv|tsv|json-records)", c.As)
+	}
+	if c.As == "json-records" && strings.EqualFold(strings.TrimSpace(c.MajorDimension), "COLUMNS") {
+		return usage("--as json-records needs --dimension ROWS")
+	}
 
 	svc, err := newSheetsService(ctx, account)
 	if err != nil {
@@ -109,6 +119,10 @@ func (c *SheetsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	if c.As != "" {
+		return writeSheetValuesAs(os.Stdout, c.As, resp.Values)
+	}
+
 	if outfmt.IsJSON(ctx) {
 		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
 			"range":  resp.Range,
@@ -0,0 +1,773 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type SheetsImportCmd struct {
+	SpreadsheetID string   `arg:"" name:"spreadsheetId" help:"Spreadsheet ID"`
+	Range         string   `arg:"" name:"range" help:"Target sheet and top-left cell (eg. Sheet1 or Sheet1!B2)"`
+	File          string   `name:"file" short:"f" required:"" help:"Input file (.csv, .tsv or .json); - reads stdin (requires --format)"`
+	Format        string   `name:"format" help:"Input format: csv|tsv|json (default: from file extension)"`
+	NoHeader      bool     `name:"no-header" help:"Input has no header row; write rows positionally"`
+	Map           []string `name:"map" help:"Rename an input column to a sheet header: 'input=Sheet Header' (repeatable)"`
+	Replace       bool     `name:"replace" help:"Clear the target area and write header + rows from its top-left cell (default: append below existing rows)"`
+	NoInfer       bool     `name:"no-infer" help:"Write every value as text (no number/date/boolean inference)"`
+	ChunkRows     int      `name:"chunk-rows" help:"Rows per values batchUpdate request" default:"5000"`
+}
+
+// importTable is a parsed input file. Header is nil with --no-header.
+// Infer marks values as typed for USER_ENTERED writes (text is escaped);
+// otherwise every value is written RAW.
+type importTable struct {
+	Header []string
+	Rows   [][]any
+	Infer  bool
+}
+
+func (t *importTable) text(s string) any {
+	if t.Infer {
+		return sheetsText(s)
+	}
+	return s
+}
+
+func (c *SheetsImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	spreadsheetID := normalizeGoogleID(strings.TrimSpace(c.SpreadsheetID))
+	if spreadsheetID == "" {
+		return usage("empty spreadsheetId")
+	}
+	sheetTitle, startCol, startRow, err := parseImportTarget(c.Range)
+	if err != nil {
+		return usage(err.Error())
+	}
+	if c.ChunkRows <= 0 {
+		return usage("--chunk-rows must be > 0")
+	}
+	if c.NoHeader && len(c.Map) > 0 {
+		return usage("--map requires a header row (drop --no-header)")
+	}
+	format, err := sheetsImportFormat(c.File, c.Format)
+	if err != nil {
+		return err
+	}
+
+	data, err := readImportFile(c.File)
+	if err != nil {
+		return err
+	}
+	table, err := parseImportTable(data, format, !c.NoHeader, !c.NoInfer)
+	if err != nil {
+		return err
+	}
+	if table.Header != nil {
+		if err = applyImportHeaderMap(table.Header, c.Map); err != nil {
+			return err
+		}
+	}
+	if len(table.Rows) == 0 && table.Header == nil {
+		return usage("input has no rows")
+	}
+
+	valueInput := "USER_ENTERED"
+	if c.NoInfer {
+		valueInput = "RAW"
+	}
+
+	if err = dryRunExit(ctx, flags, "sheets.import", map[string]any{
+		"spreadsheet_id":     spreadsheetID,
+		"range":              cleanRange(c.Range),
+		"format":             format,
+		"header":             table.Header,
+		"rows":               len(table.Rows),
+		"replace":            c.Replace,
+		"value_input_option": valueInput,
+		"chunk_rows":         c.ChunkRows,
+	}); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newSheetsService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	props, err := fetchImportSheet(ctx, svc, spreadsheetID, sheetTitle)
+	if err != nil {
+		return err
+	}
+	sheetTitle = props.Title
+	gridRows, gridCols := int64(1000), int64(26)
+	if props.GridProperties != nil {
+		gridRows, gridCols = props.GridProperties.RowCount, props.GridProperties.ColumnCount
+	}
+
+	lastCol, err := colIndexToLetters(max(int(gridCols), startCol))
+	if err != nil {
+		return err
+	}
+	areaRange := formatA1Cell(sheetTitle, startRow, startCol) + ":" + lastCol
+
+	var existing [][]any
+	if c.Replace {
+		if _, err = svc.Spreadsheets.Values.Clear(spreadsheetID, areaRange, &sheets.ClearValuesRequest{}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("clear %s: %w", areaRange, err)
+		}
+	} else {
+		resp, getErr := svc.Spreadsheets.Values.Get(spreadsheetID, areaRange).Context(ctx).Do()
+		if getErr != nil {
+			return fmt.Errorf("read %s: %w", areaRange, getErr)
+		}
+		existing = resp.Values
+	}
+
+	rows, headerWritten, err := alignImportRows(table, existing)
+	if err != nil {
+		return err
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	if len(rows) == 0 || width == 0 {
+		u.Err().Println("No rows to import")
+		return nil
+	}
+
+	firstRow := startRow + len(existing)
+	needRows := int64(firstRow + len(rows) - 1)
+	needCols := int64(startCol + width - 1)
+	if err = growSheetGrid(ctx, svc, spreadsheetID, props.SheetId, needRows-gridRows, needCols-gridCols); err != nil {
+		return err
+	}
+
+	var updatedCells int64
+	requests := 0
+	for off := 0; off < len(rows); off += c.ChunkRows {
+		chunk := rows[off:min(off+c.ChunkRows, len(rows))]
+		chunkRange := sheetsRangeA1(sheetTitle, firstRow+off, startCol, firstRow+off+len(chunk)-1, startCol+width-1)
+		resp, batchErr := svc.Spreadsheets.Values.BatchUpdate(spreadsheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: valueInput,
+			Data:             []*sheets.ValueRange{{Range: chunkRange, Values: chunk}},
+		}).Context(ctx).Do()
+		if batchErr != nil {
+			return fmt.Errorf("write %s: %w", chunkRange, batchErr)
+		}
+		updatedCells += resp.TotalUpdatedCells
+		requests++
+	}
+
+	writtenRange := sheetsRangeA1(sheetTitle, firstRow, startCol, firstRow+len(rows)-1, startCol+width-1)
+	dataRows := len(rows)
+	if headerWritten {
+		dataRows--
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"spreadsheetId": spreadsheetID,
+			"range":         writtenRange,
+			"rows":          dataRows,
+			"columns":       width,
+			"headerWritten": headerWritten,
+			"updatedCells":  updatedCells,
+			"requests":      requests,
+		})
+	}
+
+	u.Out().Printf("Imported %d rows (%d cells) into %s in %d requests", dataRows, updatedCells, writtenRange, requests)
+	return nil
+}
+
+var importCellRefRe = regexp.MustCompile(`^([A-Za-z]{0,3})([0-9]*)$`)
+
+// parseImportTarget splits "Sheet1!B2", "Sheet1", "B2" or "'My Sheet'!A:F"
+// into the sheet title (empty = first sheet) and the 1-based top-left cell.
+func parseImportTarget(rangeSpec string) (string, int, int, error) {
+	raw := cleanRange(strings.TrimSpace(rangeSpec))
+	if raw == "" {
+		return "", 0, 0, errors.New("empty range")
+	}
+	sheetTitle, cells, err := splitA1Sheet(raw)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if !strings.Contains(raw, "!") {
+		if m := importCellRefRe.FindStringSubmatch(strings.SplitN(raw, ":", 2)[0]); m == nil || (m[1] == "" && m[2] == "") {
+			// A bare sheet name.
+			name, nameErr := unquoteSheetName(raw)
+			return name, 1, 1, nameErr
+		}
+	}
+
+	start := strings.ReplaceAll(strings.SplitN(cells, ":", 2)[0], "$", "")
+	m := importCellRefRe.FindStringSubmatch(strings.TrimSpace(start))
+	if m == nil || (m[1] == "" && m[2] == "") {
+		return "", 0, 0, fmt.Errorf("invalid range %q", rangeSpec)
+	}
+	col, row := 1, 1
+	if m[1] != "" {
+		if col, err = colLettersToIndex(m[1]); err != nil {
+			return "", 0, 0, err
+		}
+	}
+	if m[2] != "" {
+		if row, err = strconv.Atoi(m[2]); err != nil || row <= 0 {
+			return "", 0, 0, fmt.Errorf("invalid row in %q", rangeSpec)
+		}
+	}
+	return sheetTitle, col, row, nil
+}
+
+func sheetsImportFormat(path, format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(strings.TrimSpace(path))) {
+		case ".csv":
+			format = "csv"
+		case ".tsv", ".tab":
+			format = "tsv"
+		case ".json":
+			format = "json"
+		default:
+			return "", usage("cannot infer input format from file name; pass --format csv|tsv|json")
+		}
+	}
+	switch format {
+	case "csv", "tsv", "json":
+		return format, nil
+	default:
+		return "", usagef("invalid --format %q (want csv|tsv|json)", format)
+	}
+}
+
+func readImportFile(path string) ([]byte, error) {
+	path = strings.TrimSpace(path)
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(expanded) //nolint:gosec // user-provided path
+}
+
+// parseImportTable parses CSV/TSV (cells inferred when infer is set) or JSON
+// (an array of objects, or an array of arrays whose first row is the header).
+func parseImportTable(data []byte, format string, header, infer bool) (*importTable, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var (
+		table *importTable
+		err   error
+	)
+	if format == "json" {
+		table, err = parseImportJSON(data, header, infer)
+	} else {
+		table, err = parseImportDelimited(data, format == "tsv", header, infer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	table.Infer = infer
+	if table.Header != nil {
+		seen := make(map[string]bool, len(table.Header))
+		for i, name := range table.Header {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, fmt.Errorf("header column %d is empty", i+1)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate header column %q", name)
+			}
+			seen[name] = true
+			table.Header[i] = name
+		}
+	}
+	return table, nil
+}
+
+func parseImportDelimited(data []byte, tsv, header, infer bool) (*importTable, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	if tsv {
+		r.Comma = '\t'
+		r.LazyQuotes = true
+	}
+	table := &importTable{}
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse input: %w", err)
+		}
+		if header && table.Header == nil {
+			table.Header = rec
+			continue
+		}
+		row := make([]any, len(rec))
+		for i, cell := range rec {
+			if infer {
+				row[i] = inferSheetCell(cell)
+			} else {
+				row[i] = cell
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+func parseImportJSON(data []byte, header, infer bool) (*importTable, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parse input: want a JSON array of objects or arrays: %w", err)
+	}
+	table := &importTable{}
+	if len(items) == 0 {
+		return table, nil
+	}
+
+	if trimmed := bytes.TrimSpace(items[0]); len(trimmed) > 0 && trimmed[0] == '[' {
+		for i, raw := range items {
+			var cells []json.RawMessage
+			if err := json.Unmarshal(raw, &cells); err != nil {
+				return nil, fmt.Errorf("parse input row %d: %w", i+1, err)
+			}
+			row := make([]any, len(cells))
+			for j, cell := range cells {
+				row[j] = jsonImportValue(cell, infer)
+			}
+			if header && table.Header == nil {
+				table.Header = make([]string, len(row))
+				for j, v := range row {
+					table.Header[j] = fmt.Sprint(v)
+				}
+				continue
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		return table, nil
+	}
+
+	if !header {
+		return nil, usage("--no-header needs JSON arrays of arrays (records carry their own keys)")
+	}
+	index := map[string]int{}
+	records := make([]map[string]json.RawMessage, 0, len(items))
+	for i, raw := range items {
+		keys, err := jsonObjectKeys(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse input record %d: %w", i+1, err)
+		}
+		var rec map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, fmt.Errorf("parse input record %d: %w", i+1, err)
+		}
+		for _, k := range keys {
+			if _, ok := index[k]; !ok {
+				index[k] = len(table.Header)
+				table.Header = append(table.Header, k)
+			}
+		}
+		records = append(records, rec)
+	}
+	for _, rec := range records {
+		row := make([]any, len(table.Header))
+		for i := range row {
+			row[i] = ""
+		}
+		for k, v := range rec {
+			row[index[k]] = jsonImportValue(v, infer)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// jsonObjectKeys returns the keys of a JSON object in document order.
+func jsonObjectKeys(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, errors.New("want a JSON object")
+	}
+	var keys []string
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err = dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// jsonImportValue keeps JSON numbers and booleans typed; strings are text
+// unless they are dates (when inferring), and nested values are written as
+// compact JSON.
+func jsonImportValue(raw json.RawMessage, infer bool) any {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return string(raw)
+	}
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return x
+	case json.Number:
+		if n, err := x.Int64(); err == nil && n > -maxExactFloatInt && n < maxExactFloatInt {
+			return n
+		}
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+		return x.String()
+	case string:
+		if !infer {
+			return x
+		}
+		if d, ok := inferSheetDate(x); ok {
+			return d
+		}
+		return sheetsText(x)
+	default:
+		var buf bytes.Buffer
+		_ = json.Compact(&buf, raw)
+		if !infer {
+			return buf.String()
+		}
+		return sheetsText(buf.String())
+	}
+}
+
+// maxExactFloatInt bounds integers that survive a trip through a float64
+// (Sheets stores numbers as doubles).
+const maxExactFloatInt = 1 << 53
+
+var sheetDateLayouts = []struct {
+	layout, out string
+}{
+	{"2006-01-02", "2006-01-02"},
+	{"2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05"},
+	{"2006-01-02T15:04:05", "2006-01-02 15:04:05"},
+	{"2006-01-02 15:04:05", "2006-01-02 15:04:05"},
+	{"2006-01-02 15:04", "2006-01-02 15:04:00"},
+}
+
+func inferSheetDate(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < len("2006-01-02") {
+		return "", false
+	}
+	for _, l := range sheetDateLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			return t.Format(l.out), true
+		}
+	}
+	return "", false
+}
+
+// inferSheetCell types a CSV/TSV cell for USER_ENTERED writes: numbers and
+// booleans become JSON values, ISO dates are normalized so Sheets parses them
+// as dates, and anything else is sent as literal text.
+func inferSheetCell(s string) any {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return ""
+	}
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, ok := inferSheetNumber(trimmed); ok {
+		return n
+	}
+	if d, ok := inferSheetDate(trimmed); ok {
+		return d
+	}
+	return sheetsText(s)
+}
+
+var sheetNumberRe = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// inferSheetNumber accepts plain decimal numbers. Leading zeros ("007",
+// zip codes) and integers too long for a double stay text.
+func inferSheetNumber(s string) (any, bool) {
+	if !sheetNumberRe.MatchString(s) {
+		return nil, false
+	}
+	if !strings.ContainsAny(s, ".eE") {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= -maxExactFloatInt || n >= maxExactFloatInt {
+			return nil, false
+		}
+		return n, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return f, true
+}
+
+// sheetsText marks a string as literal text for USER_ENTERED writes, so
+// values like "=A1", "50%" or "1/2" are not reinterpreted.
+func sheetsText(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "'" + s
+}
+
+// applyImportHeaderMap renames header columns in place from "input=Sheet
+// Header" pairs.
+func applyImportHeaderMap(header []string, mappings []string) error {
+	for _, m := range mappings {
+		from, to, ok := strings.Cut(m, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return usagef("invalid --map %q (want input=Sheet Header)", m)
+		}
+		found := false
+		for i, name := range header {
+			if name == from {
+				header[i] = to
+				found = true
+			}
+		}
+		if !found {
+			return usagef("--map %q: no input column named %q", m, from)
+		}
+	}
+	return nil
+}
+
+// alignImportRows builds the rows to write. With a header and an empty
+// target, the header row is written first. When the target already has rows,
+// its first row is the sheet header and input columns are placed under the
+// matching header cells.
+func alignImportRows(table *importTable, existing [][]any) ([][]any, bool, error) {
+	if table.Header == nil {
+		return table.Rows, false, nil
+	}
+	header := make([]any, len(table.Header))
+	for i, name := range table.Header {
+		header[i] = table.text(name)
+	}
+	if len(existing) == 0 {
+		return append([][]any{header}, table.Rows...), true, nil
+	}
+
+	sheetCols := map[string]int{}
+	for i, cell := range existing[0] {
+		name := strings.TrimSpace(fmt.Sprint(cell))
+		if name == "" {
+			continue
+		}
+		if _, dup := sheetCols[name]; !dup {
+			sheetCols[name] = i
+		}
+		if _, dup := sheetCols[strings.ToLower(name)]; !dup {
+			sheetCols[strings.ToLower(name)] = i
+		}
+	}
+	target := make([]int, len(table.Header))
+	width := 0
+	var missing []string
+	for i, name := range table.Header {
+		col, ok := sheetCols[name]
+		if !ok {
+			col, ok = sheetCols[strings.ToLower(name)]
+		}
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		target[i] = col
+		width = max(width, col+1)
+	}
+	if len(missing) > 0 {
+		return nil, false, usagef("input columns not in the sheet header: %s (use --map input=Header or --replace)", strings.Join(missing, ", "))
+	}
+
+	out := make([][]any, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		aligned := make([]any, width)
+		for i := range aligned {
+			aligned[i] = ""
+		}
+		for i, v := range row {
+			if i < len(target) {
+				aligned[target[i]] = v
+			}
+		}
+		out = append(out, aligned)
+	}
+	return out, false, nil
+}
+
+// fetchImportSheet returns the properties of the named sheet, or of the
+// first sheet when title is empty.
+func fetchImportSheet(ctx context.Context, svc *sheets.Service, spreadsheetID, title string) (*sheets.SheetProperties, error) {
+	resp, err := svc.Spreadsheets.Get(spreadsheetID).
+		Fields("sheets(properties(sheetId,title,gridProperties(rowCount,columnCount)))").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("get spreadsheet metadata: %w", err)
+	}
+	for _, sh := range resp.Sheets {
+		if sh.Properties == nil {
+			continue
+		}
+		if title == "" || sh.Properties.Title == title {
+			return sh.Properties, nil
+		}
+	}
+	if title == "" {
+		return nil, errors.New("spreadsheet has no sheets")
+	}
+	return nil, usagef("sheet %q not found", title)
+}
+
+// growSheetGrid appends rows/columns so that value writes stay inside the
+// grid; values batchUpdate does not expand sheets on its own.
+func growSheetGrid(ctx context.Context, svc *sheets.Service, spreadsheetID string, sheetID, addRows, addCols int64) error {
+	var reqs []*sheets.Request
+	if addRows > 0 {
+		reqs = append(reqs, &sheets.Request{AppendDimension: &sheets.AppendDimensionRequest{SheetId: sheetID, Dimension: "ROWS", Length: addRows}})
+	}
+	if addCols > 0 {
+		reqs = append(reqs, &sheets.Request{AppendDimension: &sheets.AppendDimensionRequest{SheetId: sheetID, Dimension: "COLUMNS", Length: addCols}})
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	if _, err := svc.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("grow sheet grid: %w", err)
+	}
+	return nil
+}
+
+// sheetsRangeA1 formats a 1-based inclusive cell rectangle.
+func sheetsRangeA1(sheetTitle string, startRow, startCol, endRow, endCol int) string {
+	endLetters, _ := colIndexToLetters(endCol)
+	return formatA1Cell(sheetTitle, startRow, startCol) + ":" + fmt.Sprintf("%s%d", endLetters, endRow)
+}
+
+// writeSheetValuesAs writes a value range as CSV, TSV or header-keyed JSON
+// records for `sheets get --as`.
+func writeSheetValuesAs(w io.Writer, as string, values [][]any) error {
+	switch as {
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if as == "tsv" {
+			cw.Comma = '\t'
+		}
+		// The API trims trailing empty cells; pad rows to a rectangle.
+		width := 0
+		for _, row := range values {
+			width = max(width, len(row))
+		}
+		for _, row := range values {
+			rec := make([]string, width)
+			for i, cell := range row {
+				rec[i] = fmt.Sprint(cell)
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json-records":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sheetValuesRecords(values))
+	default:
+		return usagef("invalid --as %q (want csv|tsv|json-records)", as)
+	}
+}
+
+// sheetValuesRecords maps rows to objects keyed by the first row. Blank or
+// repeated header cells get positional keys ("column_3") or a numeric suffix
+// ("name_2"); short rows are padded with "".
+func sheetValuesRecords(values [][]any) []map[string]any {
+	records := []map[string]any{}
+	if len(values) == 0 {
+		return records
+	}
+	width := 0
+	for _, row := range values {
+		width = max(width, len(row))
+	}
+	keys := make([]string, width)
+	used := map[string]int{}
+	for i := range keys {
+		name := ""
+		if i < len(values[0]) {
+			name = strings.TrimSpace(fmt.Sprint(values[0][i]))
+		}
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		keys[i] = name
+	}
+	for _, row := range values[1:] {
+		rec := make(map[string]any, width)
+		for i, key := range keys {
+			if i < len(row) {
+				rec[key] = row[i]
+			} else {
+				rec[key] = ""
+			}
+		}
+		records = append(records, rec)
+	}
+	return records
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jibankumarpanda/gogcli/internal/fakegoogle"
+)
+
+func TestInferSheetCell(t *testing.T) {
+	cases := map[string]any{
+		"42":                   int64(42),
+		"-3.5":                 -3.5,
+		"1e3":                  1000.0,
+		"007":                  "'007",
+		"12345678901234567890": "'12345678901234567890",
+		"TRUE":                 true,
+		"false":                false,
+		"2024-03-01":           "2024-03-01",
+		"2024-03-01T10:30:00Z": "2024-03-01 10:30:00",
+		"=SUM(A1:A2)":          "'=SUM(A1:A2)",
+		"50%":                  "'50%",
+		"":                     "",
+	}
+	for in, want := range cases {
+		if got := inferSheetCell(in); got != want {
+			t.Errorf("inferSheetCell(%q) = %#v, want %#v", in, got, want)
+		}
+	}
+}
+
+func TestParseImportTable_JSONRecordsKeepKeyOrder(t *testing.T) {
+	table, err := parseImportTable([]byte(`[{"name":"Ada","age":36,"tags":["x"]},{"zip":"02139","name":"Bob","age":null}]`), "json", true, true)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(table.Header, []string{"name", "age", "tags", "zip"}) {
+		t.Fatalf("header: %q", table.Header)
+	}
+	want := [][]any{
+		{"'Ada", int64(36), `'["x"]`, ""},
+		{"'Bob", "", "", "'02139"},
+	}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Fatalf("rows: %#v", table.Rows)
+	}
+}
+
+func TestParseImportTable_TSVWithBOMNoInfer(t *testing.T) {
+	table, err := parseImportTable([]byte("\xef\xbb\xbfid\tnote\n1\t=x\n"), "tsv", true, false)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(table.Header, []string{"id", "note"}) || !reflect.DeepEqual(table.Rows, [][]any{{"1", "=x"}}) {
+		t.Fatalf("unexpected table: %#v", table)
+	}
+	if _, err = parseImportTable([]byte("a,a\n1,2\n"), "csv", true, true); err == nil {
+		t.Fatalf("expected duplicate header error")
+	}
+}
+
+func TestParseImportTarget(t *testing.T) {
+	cases := []struct {
+		in       string
+		sheet    string
+		col, row int
+	}{
+		{"Sheet1", "Sheet1", 1, 1},
+		{"Sheet1!B2", "Sheet1", 2, 2},
+		{"'My Data'!C:F", "My Data", 3, 1},
+		{`Data\!A5`, "Data", 1, 5},
+		{"B3", "", 2, 3},
+	}
+	for _, tc := range cases {
+		sheet, col, row, err := parseImportTarget(tc.in)
+		if err != nil || sheet != tc.sheet || col != tc.col || row != tc.row {
+			t.Errorf("parseImportTarget(%q) = %q,%d,%d,%v", tc.in, sheet, col, row, err)
+		}
+	}
+}
+
+func TestSheetValuesRecords(t *testing.T) {
+	got := sheetValuesRecords([][]any{{"name", "", "name"}, {"a", "b", "c"}, {"d"}})
+	want := []map[string]any{
+		{"name": "a", "column_2": "b", "name_2": "c"},
+		{"name": "d", "column_2": "", "name_2": ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestSheetsImport_FakeGoogle(t *testing.T) {
+	srv := httptest.NewServer(fakegoogle.New())
+	t.Cleanup(srv.Close)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out string
+		_ = captureStderr(t, func() {
+			out = captureStdout(t, func() {
+				full := append([]string{"--endpoint", srv.URL, "--account", "a@example.com"}, args...)
+				if err := Execute(full); err != nil {
+					t.Fatalf("Execute %v: %v", args, err)
+				}
+			})
+		})
+		return out
+	}
+
+	var created struct {
+		SpreadsheetID string `json:"spreadsheetId"`
+	}
+	if err := json.Unmarshal([]byte(run("--json", "sheets", "create", "Pipeline", "--sheets", "Data")), &created); err != nil || created.SpreadsheetID == "" {
+		t.Fatalf("create: %v", err)
+	}
+	id := created.SpreadsheetID
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	if err := os.WriteFile(first, []byte("name,amount,paid,due\nAda,10.5,true,2024-01-31\nBob,007,false,\nCy,3,TRUE,\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var imported map[string]any
+	if err := json.Unmarshal([]byte(run("--json", "sheets", "import", id, "Data", "--file", first, "--chunk-rows", "2")), &imported); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if imported["rows"] != 3.0 || imported["requests"] != 2.0 || imported["headerWritten"] != true || imported["range"] != "Data!A1:D4" {
+		t.Fatalf("unexpected import result: %v", imported)
+	}
+
+	// Appends below existing rows, matching columns by sheet header.
+	second := filepath.Join(dir, "second.json")
+	if err := os.WriteFile(second, []byte(`[{"Due":"2024-02-01","who":"Dee","amount":1}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	run("sheets", "import", id, "Data", "--file", second, "--map", "who=name")
+
+	csvOut := run("sheets", "get", id, "Data!A1:D5", "--as", "csv")
+	wantCSV := "name,amount,paid,due\nAda,10.5,TRUE,2024-01-31\nBob,007,FALSE,\nCy,3,TRUE,\nDee,1,,2024-02-01\n"
+	if csvOut != wantCSV {
+		t.Fatalf("csv:\n%s\nwant:\n%s", csvOut, wantCSV)
+	}
+
+	var records []map[string]any
+	if err := json.Unmarshal([]byte(run("--json", "sheets", "get", id, "Data!A1:D5", "--as", "json-records", "--render", "UNFORMATTED_VALUE")), &records); err != nil {
+		t.Fatalf("records: %v", err)
+	}
+	if len(records) != 4 || records[0]["amount"] != 10.5 || records[1]["amount"] != "007" || records[3]["name"] != "Dee" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+
+	errOut := captureStderr(t, func() {
+		err := Execute([]string{"--endpoint", srv.URL, "--account", "a@example.com", "sheets", "import", id, "Data", "--file", second})
+		if ExitCode(err) != 2 {
+			t.Fatalf("expected usage error for unmapped column, got %v", err)
+		}
+	})
+	if !strings.Contains(errOut, "who") {
+		t.Fatalf("expected unmapped column in error, got %q", errOut)
+	}
+}
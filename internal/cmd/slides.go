@@ -20,11 +20,13 @@ var debugSlides = false
 var newSlidesService = googleapi.NewSlides
 
 type SlidesCmd struct {
-	Export             SlidesExportCmd             `cmd:"" name:"export" aliases:"download,dl" help:"Export a Google Slides deck (pdf|pptx)"`
+	Export             SlidesExportCmd             `cmd:"" name:"export" aliases:"download,dl" help:"Export a Google Slides deck (pdf|pptx|md)"`
+	Thumbnails         SlidesThumbnailsCmd         `cmd:"" name:"thumbnails" help:"Download a PNG thumbnail of every slide"`
 	Info               SlidesInfoCmd               `cmd:"" name:"info" aliases:"get,show" help:"Get Google Slides presentation metadata"`
 	Create             SlidesCreateCmd             `cmd:"" name:"create" aliases:"add,new" help:"Create a Google Slides presentation"`
 	CreateFromMarkdown SlidesCreateFromMarkdownCmd `cmd:"" name:"create-from-markdown" help:"Create a Google Slides presentation from markdown"`
 	Copy               SlidesCopyCmd               `cmd:"" name:"copy" aliases:"cp,duplicate" help:"Copy a Google Slides presentation"`
+	Fill               SlidesFillCmd               `cmd:"" name:"fill" help:"Copy a template deck and fill {{placeholders}}, images and repeated slides from JSON"`
 	AddSlide           SlidesAddSlideCmd           `cmd:"" name:"add-slide" help:"Add a slide with a full-bleed image and optional speaker notes"`
 	ListSlides         SlidesListSlidesCmd         `cmd:"" name:"list-slides" help:"List all slides with their object IDs"`
 	DeleteSlide        SlidesDeleteSlideCmd        `cmd:"" name:"delete-slide" help:"Delete a slide by object ID"`
@@ -36,10 +38,13 @@ type SlidesCmd struct {
 type SlidesExportCmd struct {
 	PresentationID string         `arg:"" name:"presentationId" help:"Presentation ID"`
 	Output         OutputPathFlag `embed:""`
-	Format         string         `name:"format" help:"Export format: pdf|pptx" default:"pptx"`
+	Format         string         `name:"format" help:"Export format: pdf|pptx|md (md: titles, text, tables and notes; --out - for stdout)" default:"pptx"`
 }
 
 func (c *SlidesExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if strings.EqualFold(strings.TrimSpace(c.Format), "md") {
+		return exportSlidesMarkdown(ctx, flags, c.PresentationID, c.Output.Path)
+	}
 	return exportViaDrive(ctx, flags, exportViaDriveOptions{
 		ArgName:       "presentationId",
 		ExpectedMime:  "application/vnd.google-apps.presentation",
@@ -142,11 +147,14 @@ func (c *SlidesCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type SlidesCreateFromMarkdownCmd struct {
-	Title       string `arg:"" name:"title" help:"Presentation title"`
-	Content     string `name:"content" help:"Markdown content (inline)"`
-	ContentFile string `name:"content-file" help:"Read markdown content from file"`
-	Parent      string `name:"parent" help:"Destination folder ID"`
-	Debug       bool   `name:"debug" help:"Show debug output"`
+	Title       string   `arg:"" name:"title" help:"Presentation title"`
+	Content     string   `name:"content" help:"Markdown content (inline)"`
+	ContentFile string   `name:"content-file" help:"Read markdown content from file"`
+	Parent      string   `name:"parent" help:"Destination folder ID"`
+	Template    string   `name:"template" help:"Presentation ID to copy first, inheriting its theme, masters and layouts"`
+	LayoutMap   []string `name:"layout-map" help:"Map a slide kind to a layout by ID, display name or name: 'TITLE_AND_BODY=Content' (repeatable)"`
+	Master      string   `name:"master" help:"Master (ID or display name) whose layouts are used"`
+	Debug       bool     `name:"debug" help:"Show debug output"`
 }
 
 func (c *SlidesCreateFromMarkdownCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -161,10 +169,17 @@ func (c *SlidesCreateFromMarkdownCmd) Run(ctx context.Context, flags *RootFlags)
 		return usage("empty title")
 	}
 
+	layoutMap, err := parseSlidesLayoutMap(c.LayoutMap)
+	if err != nil {
+		return err
+	}
+
 	// Get markdown content
 	var markdown string
+	imageBase := ""
 	switch {
 	case c.ContentFile != "":
+		imageBase = c.ContentFile
 		var data []byte
 		data, err = os.ReadFile(c.ContentFile)
 		if err != nil {
@@ -186,22 +201,27 @@ func (c *SlidesCreateFromMarkdownCmd) Run(ctx context.Context, flags *RootFlags)
 	if err != nil {
 		return err
 	}
+	driveSvc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
 
 	// Create presentation from markdown
-	presentation, err := CreatePresentationFromMarkdown(title, markdown, slidesSvc)
+	template := normalizeGoogleID(strings.TrimSpace(c.Template))
+	presentation, err := CreatePresentationFromMarkdown(ctx, title, markdown, slidesSvc, driveSvc, slidesMarkdownOptions{
+		Template:  template,
+		Parent:    strings.TrimSpace(c.Parent),
+		LayoutMap: layoutMap,
+		Master:    c.Master,
+		ImageBase: imageBase,
+	})
 	if err != nil {
 		return err
 	}
 
-	// Move to parent folder if specified
-	if c.Parent != "" {
-		var parentDriveSvc *drive.Service
-		parentDriveSvc, err = newDriveService(ctx, account)
-		if err != nil {
-			return err
-		}
-
-		_, err = parentDriveSvc.Files.Update(presentation.PresentationId, &drive.File{}).
+	// Move to parent folder if specified (a template copy is created there)
+	if c.Parent != "" && template == "" {
+		_, err = driveSvc.Files.Update(presentation.PresentationId, &drive.File{}).
 			AddParents(c.Parent).
 			SupportsAllDrives(true).
 			Context(ctx).
@@ -212,12 +232,6 @@ func (c *SlidesCreateFromMarkdownCmd) Run(ctx context.Context, flags *RootFlags)
 	}
 
 	// Get presentation link
-	var driveSvc *drive.Service
-	driveSvc, err = newDriveService(ctx, account)
-	if err != nil {
-		return err
-	}
-
 	file, err := driveSvc.Files.Get(presentation.PresentationId).
 		Fields("id, name, webViewLink").
 		SupportsAllDrives(true).
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/slides/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+// exportSlidesMarkdown writes a deck as markdown in the dialect read by
+// create-from-markdown. An --out of "-" writes to stdout.
+func exportSlidesMarkdown(ctx context.Context, flags *RootFlags, id string, outPath string) error {
+	u := ui.FromContext(ctx)
+
+	id = normalizeGoogleID(strings.TrimSpace(id))
+	if id == "" {
+		return usage("empty presentationId")
+	}
+	outPath = strings.TrimSpace(outPath)
+	if outPath != "" && outPath != "-" {
+		expanded, err := config.ExpandPath(outPath)
+		if err != nil {
+			return err
+		}
+		outPath = expanded
+	}
+
+	if err := dryRunExit(ctx, flags, "slides.export", map[string]any{
+		"id":     id,
+		"out":    outPath,
+		"format": "md",
+	}); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newSlidesService(ctx, account)
+	if err != nil {
+		return err
+	}
+	pres, err := svc.Presentations.Get(id).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("get presentation: %w", err)
+	}
+
+	md := presentationToMarkdown(pres)
+	if outPath == "-" {
+		_, err = os.Stdout.WriteString(md)
+		return err
+	}
+
+	destPath, err := resolveDriveDownloadDestPath(&drive.File{Id: pres.PresentationId, Name: pres.Title}, outPath)
+	if err != nil {
+		return err
+	}
+	destPath = replaceExt(destPath, ".md")
+	if err := writeFileAtomic(destPath, []byte(md)); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": destPath, "size": len(md), "slides": len(pres.Slides)})
+	}
+	u.Out().Printf("path\t%s", destPath)
+	u.Out().Printf("size\t%s", formatDriveSize(int64(len(md))))
+	u.Out().Printf("slides\t%d", len(pres.Slides))
+	return nil
+}
+
+// presentationToMarkdown renders every slide's title, text, tables, images and
+// speaker notes, separating slides with "---".
+func presentationToMarkdown(pres *slides.Presentation) string {
+	var parts []string
+	for _, slide := range pres.Slides {
+		if slide == nil {
+			continue
+		}
+		if md := slideToMarkdown(slide); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n---\n\n") + "\n"
+}
+
+func slideToMarkdown(slide *slides.Page) string {
+	var title string
+	var blocks []string
+
+	elements := flattenPageElements(slide.PageElements)
+	// Reading order: top to bottom, then left to right.
+	sort.SliceStable(elements, func(i, j int) bool {
+		yi, xi := pageElementPosition(elements[i])
+		yj, xj := pageElementPosition(elements[j])
+		if yi != yj {
+			return yi < yj
+		}
+		return xi < xj
+	})
+
+	for _, el := range elements {
+		switch {
+		case el.Shape != nil && el.Shape.Text != nil:
+			lines := slidesTextToMarkdown(el.Shape.Text)
+			if len(lines) == 0 {
+				continue
+			}
+			if ph := el.Shape.Placeholder; title == "" && ph != nil && (ph.Type == "TITLE" || ph.Type == "CENTERED_TITLE") {
+				prefix := "## "
+				if ph.Type == "CENTERED_TITLE" {
+					prefix = "# "
+				}
+				title = prefix + strings.Join(lines, " ")
+				continue
+			}
+			blocks = append(blocks, strings.Join(lines, "\n"))
+		case el.Table != nil:
+			if table := slidesTableToMarkdown(el.Table); table != "" {
+				blocks = append(blocks, table)
+			}
+		case el.Image != nil:
+			src := el.Image.SourceUrl
+			if src == "" {
+				src = el.Image.ContentUrl
+			}
+			if src == "" {
+				continue
+			}
+			alt := el.Description
+			if alt == "" {
+				alt = el.Title
+			}
+			blocks = append(blocks, fmt.Sprintf("![%s](%s)", alt, src))
+		}
+	}
+
+	if title != "" {
+		blocks = append([]string{title}, blocks...)
+	}
+	if notes := slideSpeakerNotes(slide); notes != "" {
+		blocks = append(blocks, "???\n"+notes)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func flattenPageElements(elements []*slides.PageElement) []*slides.PageElement {
+	var out []*slides.PageElement
+	for _, el := range elements {
+		if el == nil {
+			continue
+		}
+		if el.ElementGroup != nil {
+			out = append(out, flattenPageElements(el.ElementGroup.Children)...)
+			continue
+		}
+		out = append(out, el)
+	}
+	return out
+}
+
+func pageElementPosition(el *slides.PageElement) (float64, float64) {
+	if el.Transform == nil {
+		return 0, 0
+	}
+	return el.Transform.TranslateY, el.Transform.TranslateX
+}
+
+// slideSpeakerNotes returns the plain text of a slide's speaker notes shape.
+func slideSpeakerNotes(slide *slides.Page) string {
+	if slide.SlideProperties == nil || slide.SlideProperties.NotesPage == nil {
+		return ""
+	}
+	np := slide.SlideProperties.NotesPage
+	notesID := ""
+	if np.NotesProperties != nil {
+		notesID = np.NotesProperties.SpeakerNotesObjectId
+	}
+	for _, el := range np.PageElements {
+		if el == nil || el.Shape == nil || el.Shape.Text == nil {
+			continue
+		}
+		isNotes := el.ObjectId == notesID
+		if notesID == "" {
+			isNotes = el.Shape.Placeholder != nil && el.Shape.Placeholder.Type == placeholderTypeBody
+		}
+		if !isNotes {
+			continue
+		}
+		var sb strings.Builder
+		for _, te := range el.Shape.Text.TextElements {
+			if te.TextRun != nil {
+				sb.WriteString(te.TextRun.Content)
+			}
+		}
+		return strings.TrimSpace(sb.String())
+	}
+	return ""
+}
+
+// slidesTextToMarkdown converts a shape's text into markdown lines: bullets
+// keep their nesting, runs keep bold/italic/links, and paragraphs set in a
+// monospace font become fenced code.
+func slidesTextToMarkdown(text *slides.TextContent) []string {
+	type paragraph struct {
+		bullet *slides.Bullet
+		text   string
+		plain  string
+		code   bool
+	}
+	var paras []paragraph
+	var cur *paragraph
+	for _, te := range text.TextElements {
+		if te == nil {
+			continue
+		}
+		if te.ParagraphMarker != nil {
+			paras = append(paras, paragraph{bullet: te.ParagraphMarker.Bullet, code: true})
+			cur = &paras[len(paras)-1]
+			continue
+		}
+		if te.TextRun == nil {
+			continue
+		}
+		if cur == nil {
+			paras = append(paras, paragraph{code: true})
+			cur = &paras[len(paras)-1]
+		}
+		content := strings.TrimRight(te.TextRun.Content, "\n")
+		if content == "" {
+			continue
+		}
+		style := te.TextRun.Style
+		if style == nil {
+			style = &slides.TextStyle{}
+		}
+		monospace := isMonospaceFont(style.FontFamily)
+		cur.code = cur.code && monospace
+		cur.plain += content
+		cur.text += markdownInline(content, style.Bold, style.Italic, monospace, slidesLinkURL(style.Link))
+	}
+
+	var lines []string
+	var fence []string
+	flushFence := func() {
+		if len(fence) > 0 {
+			lines = append(lines, "```")
+			lines = append(lines, fence...)
+			lines = append(lines, "```")
+			fence = nil
+		}
+	}
+	for i := range paras {
+		p := paras[i]
+		if p.text == "" {
+			continue
+		}
+		if p.code && p.bullet == nil {
+			fence = append(fence, p.plain)
+			continue
+		}
+		flushFence()
+		if p.bullet == nil {
+			lines = append(lines, escapeMarkdownLineStart(p.text))
+			continue
+		}
+		marker := "-"
+		if g := strings.TrimSpace(p.bullet.Glyph); len(g) > 1 && (strings.HasSuffix(g, ".") || strings.HasSuffix(g, ")")) {
+			marker = "1."
+		}
+		lines = append(lines, strings.Repeat("  ", int(p.bullet.NestingLevel))+marker+" "+p.text)
+	}
+	flushFence()
+	return lines
+}
+
+func slidesLinkURL(link *slides.Link) string {
+	if link == nil {
+		return ""
+	}
+	return link.Url
+}
+
+func slidesTableToMarkdown(table *slides.Table) string {
+	var rows [][]string
+	cols := 0
+	for _, row := range table.TableRows {
+		if row == nil {
+			continue
+		}
+		var cells []string
+		for _, cell := range row.TableCells {
+			text := ""
+			if cell != nil && cell.Text != nil {
+				text = strings.Join(slidesTextToMarkdown(cell.Text), " ")
+			}
+			cells = append(cells, text)
+		}
+		cols = max(cols, len(cells))
+		rows = append(rows, cells)
+	}
+	return renderMarkdownTable(rows, cols)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/slides/v1"
+)
+
+func slidesTestText(paras ...*slides.TextElement) *slides.TextContent {
+	return &slides.TextContent{TextElements: paras}
+}
+
+func slidesTestRun(content string, style *slides.TextStyle) *slides.TextElement {
+	return &slides.TextElement{TextRun: &slides.TextRun{Content: content, Style: style}}
+}
+
+func slidesTestPara(bullet *slides.Bullet) *slides.TextElement {
+	return &slides.TextElement{ParagraphMarker: &slides.ParagraphMarker{Bullet: bullet}}
+}
+
+func TestPresentationToMarkdown_RoundTrips(t *testing.T) {
+	pres := &slides.Presentation{Slides: []*slides.Page{
+		{
+			PageElements: []*slides.PageElement{
+				{
+					Transform: &slides.AffineTransform{TranslateY: 300},
+					Shape: &slides.Shape{Text: slidesTestText(
+						slidesTestPara(&slides.Bullet{Glyph: "●"}),
+						slidesTestRun("Ship ", nil),
+						slidesTestRun("fast", &slides.TextStyle{Bold: true}),
+						slidesTestRun("\n", nil),
+						slidesTestPara(&slides.Bullet{Glyph: "○", NestingLevel: 1}),
+						slidesTestRun("see ", nil),
+						slidesTestRun("docs", &slides.TextStyle{Link: &slides.Link{Url: "https://x.test"}}),
+						slidesTestRun("\n", nil),
+						slidesTestPara(nil),
+						slidesTestRun("go test ./...\n", &slides.TextStyle{FontFamily: "Courier New"}),
+					)},
+				},
+				{
+					Transform: &slides.AffineTransform{TranslateY: 10},
+					Shape:     &slides.Shape{Placeholder: &slides.Placeholder{Type: "TITLE"}, Text: slidesTestText(slidesTestRun("Plan\n", nil))},
+				},
+			},
+			SlideProperties: &slides.SlideProperties{NotesPage: &slides.Page{
+				NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: "n1"},
+				PageElements: []*slides.PageElement{{
+					ObjectId: "n1",
+					Shape:    &slides.Shape{Text: slidesTestText(slidesTestRun("Mention the date\n", nil))},
+				}},
+			}},
+		},
+		{
+			PageElements: []*slides.PageElement{
+				{Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "TITLE"}, Text: slidesTestText(slidesTestRun("Numbers\n", nil))}},
+				{Table: &slides.Table{TableRows: []*slides.TableRow{
+					{TableCells: []*slides.TableCell{{Text: slidesTestText(slidesTestRun("Q\n", nil))}, {Text: slidesTestText(slidesTestRun("Rev\n", nil))}}},
+					{TableCells: []*slides.TableCell{{Text: slidesTestText(slidesTestRun("Q1\n", nil))}, {Text: slidesTestText(slidesTestRun("10\n", nil))}}},
+				}}},
+				{Description: "chart", Image: &slides.Image{SourceUrl: "https://x.test/c.png"}},
+			},
+		},
+	}}
+
+	md := presentationToMarkdown(pres)
+	want := "## Plan\n\n- Ship **fast**\n  - see [docs](https://x.test)\n```\ngo test ./...\n```\n\n???\nMention the date\n\n---\n\n" +
+		"## Numbers\n\n| Q | Rev |\n| --- | --- |\n| Q1 | 10 |\n\n![chart](https://x.test/c.png)\n"
+	if md != want {
+		t.Fatalf("markdown mismatch:\n%s\nwant:\n%s", md, want)
+	}
+
+	deck := ParseMarkdownToSlides(md)
+	if len(deck) != 2 || deck[0].Title != "Plan" || deck[0].Notes != "Mention the date" {
+		t.Fatalf("unexpected round trip: %+v", deck)
+	}
+	if got := deck[0].Elements[1].Bullets; !reflect.DeepEqual(got, []SlideBullet{{Text: "Ship **fast**"}, {Text: "see [docs](https://x.test)", Level: 1}}) {
+		t.Fatalf("bullets = %+v", got)
+	}
+	if deck[0].Elements[2].Type != "code" || deck[1].Elements[1].Type != "table" || deck[1].Elements[2].Content != "https://x.test/c.png" {
+		t.Fatalf("unexpected elements: %+v / %+v", deck[0].Elements, deck[1].Elements)
+	}
+}
+
+func TestPresentationToMarkdown_EscapesSlideMarkers(t *testing.T) {
+	pres := &slides.Presentation{Slides: []*slides.Page{{
+		PageElements: []*slides.PageElement{{Shape: &slides.Shape{Text: slidesTestText(
+			slidesTestPara(nil),
+			slidesTestRun("---\n", nil),
+			slidesTestPara(nil),
+			slidesTestRun("???\n", nil),
+			slidesTestPara(nil),
+			slidesTestRun("| not a table\n", nil),
+		)}}},
+	}}}
+
+	md := presentationToMarkdown(pres)
+	deck := ParseMarkdownToSlides(md)
+	if len(deck) != 1 || deck[0].Notes != "" || len(deck[0].Elements) != 3 {
+		t.Fatalf("unexpected round trip: %+v\nmarkdown:\n%s", deck, md)
+	}
+	for i, want := range []string{"---", "???", "| not a table"} {
+		el := deck[0].Elements[i]
+		if _, plain := ParseInlineFormatting(el.Content); el.Type != "body" || plain != want {
+			t.Errorf("element %d = %+v, want body %q", i, el, want)
+		}
+	}
+}
+
+func TestSlidesThumbnails_Run(t *testing.T) {
+	origSlides := newSlidesService
+	t.Cleanup(func() { newSlidesService = origSlides })
+
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/png/"):
+			_, _ = w.Write([]byte("png:" + strings.TrimPrefix(r.URL.Path, "/png/")))
+		case strings.HasSuffix(r.URL.Path, "/thumbnail"):
+			if r.URL.Query().Get("thumbnailProperties.thumbnailSize") != "SMALL" {
+				http.Error(w, "bad size", http.StatusBadRequest)
+				return
+			}
+			parts := strings.Split(r.URL.Path, "/")
+			page := parts[len(parts)-2]
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"contentUrl": srvURL + "/png/" + page, "width": 200, "height": 112})
+		case strings.HasSuffix(r.URL.Path, "/presentations/p1"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"presentationId": "p1", "slides": []any{
+				map[string]any{"objectId": "a"}, map[string]any{"objectId": "b"}, map[string]any{"objectId": "c"},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	svc, err := slides.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(srv.Client()), option.WithEndpoint(srv.URL+"/"))
+	if err != nil {
+		t.Fatalf("slides.NewService: %v", err)
+	}
+	newSlidesService = func(context.Context, string) (*slides.Service, error) { return svc, nil }
+
+	dir := t.TempDir()
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "slides", "thumbnails", "p1", "--out", dir, "--size", "SMALL"}); err != nil {
+			t.Fatalf("thumbnails: %v", err)
+		}
+	})
+	var result struct {
+		Thumbnails []slidesThumbnail `json:"thumbnails"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("parse: %v out=%q", err, out)
+	}
+	if len(result.Thumbnails) != 3 || result.Thumbnails[2].SlideID != "c" || result.Thumbnails[2].Width != 200 {
+		t.Fatalf("unexpected thumbnails: %+v", result.Thumbnails)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "002_b.png"))
+	if err != nil || string(data) != "png:b" {
+		t.Fatalf("thumbnail file = %q, %v", data, err)
+	}
+}
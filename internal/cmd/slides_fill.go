@@ -0,0 +1,451 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/slides/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+type SlidesFillCmd struct {
+	TemplateID string `arg:"" name:"templateId" help:"Template presentation ID"`
+	Data       string `name:"data" required:"" help:"Variables JSON file (or inline JSON, @file, - for stdin)"`
+	OutTitle   string `name:"out-title" required:"" help:"Title of the filled copy"`
+	Parent     string `name:"parent" help:"Destination folder ID"`
+	ImageFit   string `name:"image-fit" help:"How images fill placeholder shapes: inside|crop" default:"inside"`
+}
+
+// slidesFillData is the flattened form of a --data document:
+//
+//	{"customer": {"name": "Acme"}}          -> {{customer.name}}
+//	{"logo": {"image": "./logo.png"}}       -> shapes containing {{logo}} become the image
+//	{"products": [{"name": "A"}, ...]}      -> slides mentioning {{products.…}} repeat per item
+type slidesFillData struct {
+	Text   map[string]string
+	Images map[string]string
+	Lists  map[string][]slidesFillItem
+}
+
+type slidesFillItem struct {
+	Text   map[string]string
+	Images map[string]string
+}
+
+func (c *SlidesFillCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	templateID := normalizeGoogleID(strings.TrimSpace(c.TemplateID))
+	if templateID == "" {
+		return usage("empty templateId")
+	}
+	title := strings.TrimSpace(c.OutTitle)
+	if title == "" {
+		return usage("empty --out-title")
+	}
+	var fit string
+	switch strings.ToLower(strings.TrimSpace(c.ImageFit)) {
+	case "", "inside":
+		fit = "CENTER_INSIDE"
+	case "crop":
+		fit = "CENTER_CROP"
+	default:
+		return usagef("invalid --image-fit %q (expected inside|crop)", c.ImageFit)
+	}
+
+	raw, err := readSlidesFillData(c.Data)
+	if err != nil {
+		return err
+	}
+	data, err := parseSlidesFillData(raw)
+	if err != nil {
+		return usagef("invalid --data: %v", err)
+	}
+	parent := normalizeGoogleID(strings.TrimSpace(c.Parent))
+
+	if dryRunErr := dryRunExit(ctx, flags, "slides.fill", map[string]any{
+		"template_id": templateID,
+		"title":       title,
+		"parent":      parent,
+		"text_keys":   sortedKeys(data.Text),
+		"image_keys":  sortedKeys(data.Images),
+		"list_keys":   sortedKeys(data.Lists),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	driveSvc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+	slidesSvc, err := newSlidesService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	// Upload local images before copying so a bad path doesn't leave a stray deck.
+	imageURLs, uploaded, err := resolveFillImageURLs(ctx, driveSvc, data.imageSources())
+	defer func() { cleanupDriveFileIDsBestEffort(ctx, driveSvc, uploaded) }()
+	if err != nil {
+		return err
+	}
+
+	created, err := copyDriveFile(ctx, driveSvc, copyViaDriveOptions{
+		ExpectedMime: "application/vnd.google-apps.presentation",
+		KindLabel:    "Google Slides presentation",
+	}, templateID, title, parent)
+	if err != nil {
+		return fmt.Errorf("copy template: %w", err)
+	}
+
+	// A half-filled copy is worse than none: remove it if filling fails.
+	pres, err := slidesSvc.Presentations.Get(created.Id).Context(ctx).Do()
+	if err != nil {
+		deleteDriveFileBestEffort(ctx, driveSvc, created.Id)
+		return fmt.Errorf("get presentation: %w", err)
+	}
+
+	plan, err := buildSlidesFillRequests(pres, data, imageURLs, fit)
+	if err != nil {
+		deleteDriveFileBestEffort(ctx, driveSvc, created.Id)
+		return err
+	}
+
+	occurrences := map[string]int64{}
+	if len(plan.Requests) > 0 {
+		resp, batchErr := slidesSvc.Presentations.BatchUpdate(created.Id, &slides.BatchUpdatePresentationRequest{
+			Requests: plan.Requests,
+		}).Context(ctx).Do()
+		if batchErr != nil {
+			deleteDriveFileBestEffort(ctx, driveSvc, created.Id)
+			return fmt.Errorf("fill presentation: %w", batchErr)
+		}
+		for i, reply := range resp.Replies {
+			if i >= len(plan.Keys) || plan.Keys[i] == "" || reply == nil {
+				continue
+			}
+			switch {
+			case reply.ReplaceAllText != nil:
+				occurrences[plan.Keys[i]] += reply.ReplaceAllText.OccurrencesChanged
+			case reply.ReplaceAllShapesWithImage != nil:
+				occurrences[plan.Keys[i]] += reply.ReplaceAllShapesWithImage.OccurrencesChanged
+			}
+		}
+	}
+
+	var total int64
+	unused := make([]string, 0)
+	for _, key := range plan.orderedKeys() {
+		total += occurrences[key]
+		if occurrences[key] == 0 {
+			unused = append(unused, key)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			strFile:          created,
+			"replacements":   total,
+			"repeatedSlides": plan.Repeated,
+			"unused":         unused,
+		})
+	}
+	u.Out().Printf("id\t%s", created.Id)
+	u.Out().Printf("name\t%s", created.Name)
+	if created.WebViewLink != "" {
+		u.Out().Printf("link\t%s", created.WebViewLink)
+	}
+	u.Out().Printf("replacements\t%d", total)
+	if plan.Repeated > 0 {
+		u.Out().Printf("repeated_slides\t%d", plan.Repeated)
+	}
+	for _, key := range unused {
+		u.Err().Printf("warning: {{%s}} not found in template", key)
+	}
+	return nil
+}
+
+func readSlidesFillData(spec string) ([]byte, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "-" || strings.HasPrefix(spec, "@") || strings.HasPrefix(spec, "{") {
+		return resolveInlineOrFileBytes(spec)
+	}
+	path, err := config.ExpandPath(spec)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, fmt.Errorf("read --data: %w", err)
+	}
+	return b, nil
+}
+
+func parseSlidesFillData(b []byte) (slidesFillData, error) {
+	data := slidesFillData{Text: map[string]string{}, Images: map[string]string{}, Lists: map[string][]slidesFillItem{}}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return data, err
+	}
+	if doc == nil {
+		return data, fmt.Errorf("expected a JSON object")
+	}
+	for key, value := range doc {
+		items, ok := value.([]any)
+		if !ok {
+			if err := flattenSlidesFillValue(key, value, data.Text, data.Images); err != nil {
+				return data, err
+			}
+			continue
+		}
+		list := make([]slidesFillItem, 0, len(items))
+		for _, item := range items {
+			fi := slidesFillItem{Text: map[string]string{}, Images: map[string]string{}}
+			if err := flattenSlidesFillValue(key, item, fi.Text, fi.Images); err != nil {
+				return data, err
+			}
+			list = append(list, fi)
+		}
+		data.Lists[key] = list
+	}
+	return data, nil
+}
+
+func flattenSlidesFillValue(key string, value any, text, images map[string]string) error {
+	switch v := value.(type) {
+	case nil:
+		text[key] = ""
+	case string:
+		text[key] = v
+	case json.Number:
+		text[key] = v.String()
+	case bool:
+		text[key] = fmt.Sprint(v)
+	case map[string]any:
+		if src, ok := v["image"]; ok {
+			s, isString := src.(string)
+			if !isString || strings.TrimSpace(s) == "" {
+				return fmt.Errorf("%s: image must be a non-empty path or URL", key)
+			}
+			images[key] = strings.TrimSpace(s)
+			return nil
+		}
+		for k, inner := range v {
+			if err := flattenSlidesFillValue(key+"."+k, inner, text, images); err != nil {
+				return err
+			}
+		}
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return fmt.Errorf("%s: nested lists can only hold plain values", key)
+			}
+			parts = append(parts, fmt.Sprint(item))
+		}
+		text[key] = strings.Join(parts, ", ")
+	default:
+		return fmt.Errorf("%s: unsupported value %T", key, value)
+	}
+	return nil
+}
+
+func (d slidesFillData) imageSources() []string {
+	seen := map[string]bool{}
+	add := func(images map[string]string) {
+		for _, src := range images {
+			seen[src] = true
+		}
+	}
+	add(d.Images)
+	for _, items := range d.Lists {
+		for _, item := range items {
+			add(item.Images)
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// resolveFillImageURLs maps each image source to a URL the API can fetch,
+// uploading local files to Drive. The caller removes the returned uploads once
+// the images are placed, including on error.
+func resolveFillImageURLs(ctx context.Context, driveSvc *drive.Service, sources []string) (map[string]string, []string, error) {
+	urls := map[string]string{}
+	var uploaded []string
+	for _, src := range sources {
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			urls[src] = src
+			continue
+		}
+		path, err := config.ExpandPath(src)
+		if err != nil {
+			return nil, uploaded, err
+		}
+		url, fileID, err := uploadLocalImage(ctx, driveSvc, path)
+		if err != nil {
+			return nil, uploaded, err
+		}
+		uploaded = append(uploaded, fileID)
+		urls[src] = url
+	}
+	return urls, uploaded, nil
+}
+
+type slidesFillPlan struct {
+	Requests []*slides.Request
+	// Keys[i] names the data key that Requests[i] fills ("" for structural requests).
+	Keys     []string
+	Repeated int
+}
+
+func (p *slidesFillPlan) add(key string, req *slides.Request) {
+	p.Requests = append(p.Requests, req)
+	p.Keys = append(p.Keys, key)
+}
+
+func (p *slidesFillPlan) orderedKeys() []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(p.Keys))
+	for _, k := range p.Keys {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// buildSlidesFillRequests duplicates repeat slides (one copy per list item, in
+// order, replacing the template slide), fills per-copy placeholders, then runs
+// presentation-wide text and image replacement.
+func buildSlidesFillRequests(pres *slides.Presentation, data slidesFillData, imageURLs map[string]string, fit string) (slidesFillPlan, error) {
+	var plan slidesFillPlan
+
+	for _, page := range pres.Slides {
+		if page == nil {
+			continue
+		}
+		content := slidePageText(page)
+		var listKey string
+		for key := range data.Lists {
+			if strings.Contains(content, "{{"+key+"}}") || strings.Contains(content, "{{"+key+".") {
+				if listKey != "" {
+					return plan, usagef("slide %s repeats over both %q and %q", page.ObjectId, listKey, key)
+				}
+				listKey = key
+			}
+		}
+		if listKey == "" {
+			continue
+		}
+
+		items := data.Lists[listKey]
+		ids := make([]string, len(items))
+		// Each duplicate lands right after the source slide, so insert in reverse.
+		for i := len(items) - 1; i >= 0; i-- {
+			ids[i] = fmt.Sprintf("%s_fill_%d", page.ObjectId, i+1)
+			plan.add("", &slides.Request{DuplicateObject: &slides.DuplicateObjectRequest{
+				ObjectId:  page.ObjectId,
+				ObjectIds: map[string]string{page.ObjectId: ids[i]},
+			}})
+		}
+		plan.add("", &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: page.ObjectId}})
+		plan.Repeated += len(items)
+
+		for i, item := range items {
+			for _, key := range sortedKeys(item.Text) {
+				plan.add(key, slidesReplaceTextRequest(key, item.Text[key], []string{ids[i]}))
+			}
+			for _, key := range sortedKeys(item.Images) {
+				plan.add(key, slidesReplaceImageRequest(key, imageURLs[item.Images[key]], fit, []string{ids[i]}))
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(data.Text) {
+		plan.add(key, slidesReplaceTextRequest(key, data.Text[key], nil))
+	}
+	for _, key := range sortedKeys(data.Images) {
+		plan.add(key, slidesReplaceImageRequest(key, imageURLs[data.Images[key]], fit, nil))
+	}
+	return plan, nil
+}
+
+func slidesReplaceTextRequest(key, value string, pages []string) *slides.Request {
+	return &slides.Request{ReplaceAllText: &slides.ReplaceAllTextRequest{
+		ContainsText:  &slides.SubstringMatchCriteria{Text: "{{" + key + "}}", MatchCase: true},
+		ReplaceText:   value,
+		PageObjectIds: pages,
+	}}
+}
+
+func slidesReplaceImageRequest(key, url, fit string, pages []string) *slides.Request {
+	return &slides.Request{ReplaceAllShapesWithImage: &slides.ReplaceAllShapesWithImageRequest{
+		ContainsText:       &slides.SubstringMatchCriteria{Text: "{{" + key + "}}", MatchCase: true},
+		ImageUrl:           url,
+		ImageReplaceMethod: fit,
+		PageObjectIds:      pages,
+	}}
+}
+
+// slidePageText concatenates all text on a slide (shapes, tables, groups).
+func slidePageText(page *slides.Page) string {
+	var b strings.Builder
+	var walk func(elements []*slides.PageElement)
+	walkText := func(t *slides.TextContent) {
+		if t == nil {
+			return
+		}
+		for _, te := range t.TextElements {
+			if te != nil && te.TextRun != nil {
+				b.WriteString(te.TextRun.Content)
+			}
+		}
+	}
+	walk = func(elements []*slides.PageElement) {
+		for _, el := range elements {
+			if el == nil {
+				continue
+			}
+			if el.Shape != nil {
+				walkText(el.Shape.Text)
+			}
+			if el.Table != nil {
+				for _, row := range el.Table.TableRows {
+					for _, cell := range row.TableCells {
+						walkText(cell.Text)
+					}
+				}
+			}
+			if el.ElementGroup != nil {
+				walk(el.ElementGroup.Children)
+			}
+		}
+	}
+	walk(page.PageElements)
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+	"google.golang.org/api/slides/v1"
+
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+func TestParseSlidesFillData(t *testing.T) {
+	data, err := parseSlidesFillData([]byte(`{
+		"customer": {"name": "Acme", "seats": 1200, "tags": ["a", "b"]},
+		"logo": {"image": "./logo.png"},
+		"products": [{"name": "Widget", "shot": {"image": "https://x.test/w.png"}}, {"name": "Gadget"}]
+	}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	wantText := map[string]string{"customer.name": "Acme", "customer.seats": "1200", "customer.tags": "a, b"}
+	if !reflect.DeepEqual(data.Text, wantText) {
+		t.Fatalf("text: %v", data.Text)
+	}
+	if data.Images["logo"] != "./logo.png" {
+		t.Fatalf("images: %v", data.Images)
+	}
+	items := data.Lists["products"]
+	if len(items) != 2 || items[0].Text["products.name"] != "Widget" || items[0].Images["products.shot"] != "https://x.test/w.png" {
+		t.Fatalf("lists: %+v", data.Lists)
+	}
+	if got := data.imageSources(); !reflect.DeepEqual(got, []string{"./logo.png", "https://x.test/w.png"}) {
+		t.Fatalf("image sources: %v", got)
+	}
+
+	if _, err = parseSlidesFillData([]byte(`{"logo": {"image": 3}}`)); err == nil {
+		t.Fatalf("expected error for bad image")
+	}
+}
+
+func TestBuildSlidesFillRequests_RepeatsSlidePerItem(t *testing.T) {
+	textSlide := func(id, text string) *slides.Page {
+		return &slides.Page{ObjectId: id, PageElements: []*slides.PageElement{{
+			Shape: &slides.Shape{Text: &slides.TextContent{TextElements: []*slides.TextElement{{TextRun: &slides.TextRun{Content: text}}}}},
+		}}}
+	}
+	pres := &slides.Presentation{Slides: []*slides.Page{
+		textSlide("cover", "Proposal for {{customer.name}}"),
+		textSlide("product", "{{products.name}}"),
+	}}
+	data, err := parseSlidesFillData([]byte(`{"customer": {"name": "Acme"}, "products": [{"name": "A"}, {"name": "B"}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	plan, err := buildSlidesFillRequests(pres, data, nil, "CENTER_INSIDE")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if plan.Repeated != 2 || len(plan.Requests) != 6 {
+		t.Fatalf("unexpected plan: repeated=%d requests=%d", plan.Repeated, len(plan.Requests))
+	}
+	// Duplicates are issued last item first so the copies end up in data order.
+	if got := plan.Requests[0].DuplicateObject.ObjectIds["product"]; got != "product_fill_2" {
+		t.Fatalf("first duplicate id = %q", got)
+	}
+	if plan.Requests[2].DeleteObject == nil || plan.Requests[2].DeleteObject.ObjectId != "product" {
+		t.Fatalf("expected template slide delete, got %+v", plan.Requests[2])
+	}
+	item := plan.Requests[3].ReplaceAllText
+	if item.ReplaceText != "A" || !reflect.DeepEqual(item.PageObjectIds, []string{"product_fill_1"}) {
+		t.Fatalf("unexpected item replacement: %+v", item)
+	}
+	global := plan.Requests[5].ReplaceAllText
+	if global.ContainsText.Text != "{{customer.name}}" || global.PageObjectIds != nil || plan.Keys[5] != "customer.name" {
+		t.Fatalf("unexpected global replacement: %+v", global)
+	}
+}
+
+func TestSlidesFill_Run(t *testing.T) {
+	origSlides := newSlidesService
+	origDrive := newDriveService
+	t.Cleanup(func() {
+		newSlidesService = origSlides
+		newDriveService = origDrive
+	})
+
+	var batch slides.BatchUpdatePresentationRequest
+	slidesSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/presentations/copy1:batchUpdate") && r.Method == http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&batch)
+			replies := make([]map[string]any, len(batch.Requests))
+			for i, req := range batch.Requests {
+				switch {
+				case req.ReplaceAllText != nil && req.ReplaceAllText.ContainsText.Text == "{{name}}":
+					replies[i] = map[string]any{"replaceAllText": map[string]any{"occurrencesChanged": 2}}
+				case req.ReplaceAllShapesWithImage != nil:
+					replies[i] = map[string]any{"replaceAllShapesWithImage": map[string]any{"occurrencesChanged": 1}}
+				default:
+					replies[i] = map[string]any{}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"presentationId": "copy1", "replies": replies})
+		case strings.HasSuffix(r.URL.Path, "/presentations/copy1") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"presentationId": "copy1", "slides": []any{map[string]any{"objectId": "s1"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer slidesSrv.Close()
+
+	var copied, cleaned bool
+	driveSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/upload/") && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "img1", "webContentLink": "https://drive.test/img1"})
+		case strings.HasSuffix(r.URL.Path, "/files/img1/permissions"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "perm1"})
+		case strings.HasSuffix(r.URL.Path, "/files/img1") && r.Method == http.MethodDelete:
+			cleaned = true
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/files/tmpl1") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "tmpl1", "mimeType": "application/vnd.google-apps.presentation"})
+		case strings.HasSuffix(r.URL.Path, "/files/tmpl1/copy") && r.Method == http.MethodPost:
+			copied = true
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "copy1", "name": "Acme deck"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer driveSrv.Close()
+
+	slidesSvc, err := slides.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(slidesSrv.Client()), option.WithEndpoint(slidesSrv.URL+"/"))
+	if err != nil {
+		t.Fatalf("slides.NewService: %v", err)
+	}
+	newSlidesService = func(context.Context, string) (*slides.Service, error) { return slidesSvc, nil }
+	driveSvc, err := drive.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(driveSrv.Client()), option.WithEndpoint(driveSrv.URL+"/"))
+	if err != nil {
+		t.Fatalf("drive.NewService: %v", err)
+	}
+	newDriveService = func(context.Context, string) (*drive.Service, error) { return driveSvc, nil }
+
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	if err = os.WriteFile(logo, []byte("png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vars := filepath.Join(dir, "vars.json")
+	if err = os.WriteFile(vars, []byte(`{"name": "Acme", "date": "2025-01-01", "logo": {"image": "`+logo+`"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		u, uiErr := ui.New(ui.Options{Stdout: os.Stdout, Stderr: io.Discard, Color: "never"})
+		if uiErr != nil {
+			t.Fatalf("ui.New: %v", uiErr)
+		}
+		ctx := outfmt.WithMode(ui.WithUI(context.Background(), u), outfmt.Mode{JSON: true})
+		cmd := &SlidesFillCmd{TemplateID: "tmpl1", Data: vars, OutTitle: "Acme deck", ImageFit: "crop"}
+		if runErr := cmd.Run(ctx, &RootFlags{Account: "a@b.com"}); runErr != nil {
+			t.Fatalf("Run: %v", runErr)
+		}
+	})
+
+	var result struct {
+		Replacements int      `json:"replacements"`
+		Unused       []string `json:"unused"`
+	}
+	if err = json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("parse: %v out=%q", err, out)
+	}
+	if !copied || !cleaned || result.Replacements != 3 || !reflect.DeepEqual(result.Unused, []string{"date"}) {
+		t.Fatalf("copied=%v cleaned=%v result=%+v", copied, cleaned, result)
+	}
+	img := batch.Requests[len(batch.Requests)-1].ReplaceAllShapesWithImage
+	if img == nil || img.ImageUrl != "https://drive.test/img1" || img.ImageReplaceMethod != "CENTER_CROP" {
+		t.Fatalf("unexpected image request: %+v", img)
+	}
+}
+
+// useTemplateCopyDriveTest fakes the Drive calls for copying template tmpl1
+// to copy1 and reports whether copy1 was deleted afterwards.
+func useTemplateCopyDriveTest(t *testing.T, mimeType string) *bool {
+	t.Helper()
+	origDrive := newDriveService
+	t.Cleanup(func() { newDriveService = origDrive })
+
+	deleted := new(bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/files/tmpl1") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "tmpl1", "mimeType": mimeType})
+		case strings.HasSuffix(r.URL.Path, "/files/tmpl1/copy") && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "copy1", "name": "Copy"})
+		case strings.HasSuffix(r.URL.Path, "/files/copy1") && r.Method == http.MethodDelete:
+			*deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := drive.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(srv.Client()), option.WithEndpoint(srv.URL+"/"))
+	if err != nil {
+		t.Fatalf("drive.NewService: %v", err)
+	}
+	newDriveService = func(context.Context, string) (*drive.Service, error) { return svc, nil }
+	return deleted
+}
+
+func TestSlidesFill_DeletesCopyWhenFillFails(t *testing.T) {
+	origSlides := newSlidesService
+	t.Cleanup(func() { newSlidesService = origSlides })
+	deleted := useTemplateCopyDriveTest(t, "application/vnd.google-apps.presentation")
+
+	slidesSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/presentations/copy1:batchUpdate"):
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "bad request"}})
+		case strings.HasSuffix(r.URL.Path, "/presentations/copy1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"presentationId": "copy1", "slides": []any{map[string]any{"objectId": "s1"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer slidesSrv.Close()
+	slidesSvc, err := slides.NewService(context.Background(), option.WithoutAuthentication(), option.WithHTTPClient(slidesSrv.Client()), option.WithEndpoint(slidesSrv.URL+"/"))
+	if err != nil {
+		t.Fatalf("slides.NewService: %v", err)
+	}
+	newSlidesService = func(context.Context, string) (*slides.Service, error) { return slidesSvc, nil }
+
+	vars := filepath.Join(t.TempDir(), "vars.json")
+	if err = os.WriteFile(vars, []byte(`{"name": "Acme"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &SlidesFillCmd{TemplateID: "tmpl1", Data: vars, OutTitle: "Acme deck", ImageFit: "crop"}
+	if err = cmd.Run(context.Background(), &RootFlags{Account: "a@b.com"}); err == nil {
+		t.Fatalf("expected fill to fail")
+	}
+	if !*deleted {
+		t.Fatalf("expected the half-filled copy to be deleted")
+	}
+}
@@ -1,147 +1,459 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/slides/v1"
 )
 
-// SlidesToAPIRequests converts slide structures to Google Slides API batch update requests
-func SlidesToAPIRequests(slideData []Slide) ([]*slides.Request, map[int]string) {
+const (
+	slidesCodeFont          = "Courier New"
+	slidesBulletPreset      = "BULLET_DISC_CIRCLE_SQUARE"
+	slidesNumberedPreset    = "NUMBERED_DIGIT_ALPHA_ROMAN"
+	slidesDefaultPageWidth  = 9144000 // 10in in EMU
+	slidesDefaultPageHeight = 5143500 // 16:9
+)
+
+// slidesMarkdownOptions controls how a markdown deck is rendered.
+type slidesMarkdownOptions struct {
+	Template  string            // presentation to copy (theme, masters, layouts)
+	Parent    string            // destination folder for a template copy
+	LayoutMap map[string]string // SlideLayout -> layout object ID, display name or name
+	Master    string            // master object ID or display name to take layouts from
+	ImageBase string            // markdown file used to resolve relative image paths
+}
+
+// slidesLayoutChoice is a resolved layout plus the placeholders it offers.
+type slidesLayoutChoice struct {
+	Ref   *slides.LayoutReference
+	Title string // TITLE or CENTERED_TITLE ("" if the layout has none)
+	Body  string // BODY or SUBTITLE ("" if the layout has none)
+}
+
+// Placeholders of Google's predefined layouts, used when the presentation
+// doesn't expose its layouts.
+var predefinedSlideLayoutPlaceholders = map[SlideLayout][2]string{
+	LayoutTitleOnly:          {"CENTERED_TITLE", "SUBTITLE"},
+	LayoutTitleAndBody:       {"TITLE", "BODY"},
+	LayoutTitleAndTwoColumns: {"TITLE", "BODY"},
+	LayoutSectionHeader:      {"TITLE", ""},
+	LayoutTitleBar:           {"TITLE", ""},
+	LayoutBlank:              {"", ""},
+}
+
+// parseSlidesLayoutMap parses "KIND=Layout" pairs from --layout-map.
+func parseSlidesLayoutMap(pairs []string) (map[string]string, error) {
+	out := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		kind, layout, ok := strings.Cut(p, "=")
+		kind, layout = strings.ToUpper(strings.TrimSpace(kind)), strings.TrimSpace(layout)
+		if !ok || kind == "" || layout == "" {
+			return nil, usagef("invalid --layout-map %q (want KIND=Layout)", p)
+		}
+		out[kind] = layout
+	}
+	return out, nil
+}
+
+// resolveSlideLayouts picks a layout for every SlideLayout, honoring
+// --layout-map and --master against the presentation's layouts.
+func resolveSlideLayouts(pres *slides.Presentation, layoutMap map[string]string, master string) (map[SlideLayout]slidesLayoutChoice, error) {
+	masterID := ""
+	if master = strings.TrimSpace(master); master != "" {
+		for _, m := range pres.Masters {
+			if m == nil {
+				continue
+			}
+			if m.ObjectId == master || (m.MasterProperties != nil && strings.EqualFold(m.MasterProperties.DisplayName, master)) {
+				masterID = m.ObjectId
+				break
+			}
+		}
+		if masterID == "" {
+			return nil, usagef("master %q not found in presentation", master)
+		}
+	}
+
+	var candidates []*slides.Page
+	for _, l := range pres.Layouts {
+		if l == nil || l.LayoutProperties == nil {
+			continue
+		}
+		if masterID != "" && l.LayoutProperties.MasterObjectId != masterID {
+			continue
+		}
+		candidates = append(candidates, l)
+	}
+
+	for key := range layoutMap {
+		if _, ok := predefinedSlideLayoutPlaceholders[SlideLayout(key)]; !ok {
+			return nil, usagef("unknown layout %q in --layout-map (expected TITLE, TITLE_AND_BODY, TITLE_AND_TWO_COLUMNS, SECTION_HEADER, TITLE_ONLY or BLANK)", key)
+		}
+	}
+
+	out := make(map[SlideLayout]slidesLayoutChoice, len(predefinedSlideLayoutPlaceholders))
+	for layout, defaults := range predefinedSlideLayoutPlaceholders {
+		var found *slides.Page
+		target := strings.TrimSpace(layoutMap[string(layout)])
+		for _, l := range candidates {
+			props := l.LayoutProperties
+			if target != "" && (l.ObjectId == target || strings.EqualFold(props.DisplayName, target) || props.Name == target) ||
+				target == "" && props.Name == string(layout) {
+				found = l
+				break
+			}
+		}
+		if found == nil && target != "" {
+			names := make([]string, 0, len(candidates))
+			for _, l := range candidates {
+				names = append(names, l.LayoutProperties.DisplayName)
+			}
+			return nil, usagef("layout %q not found (available: %s)", target, strings.Join(names, ", "))
+		}
+		if found == nil {
+			out[layout] = slidesLayoutChoice{
+				Ref:   &slides.LayoutReference{PredefinedLayout: string(layout)},
+				Title: defaults[0],
+				Body:  defaults[1],
+			}
+			continue
+		}
+
+		present := map[string]bool{}
+		for _, el := range found.PageElements {
+			if el != nil && el.Shape != nil && el.Shape.Placeholder != nil {
+				present[el.Shape.Placeholder.Type] = true
+			}
+		}
+		choice := slidesLayoutChoice{Ref: &slides.LayoutReference{LayoutId: found.ObjectId}}
+		for _, t := range []string{"TITLE", "CENTERED_TITLE"} {
+			if present[t] {
+				choice.Title = t
+				break
+			}
+		}
+		bodyOrder := []string{"BODY", "SUBTITLE"}
+		if layout == LayoutTitleOnly {
+			bodyOrder = []string{"SUBTITLE", "BODY"}
+		}
+		for _, t := range bodyOrder {
+			if present[t] {
+				choice.Body = t
+				break
+			}
+		}
+		out[layout] = choice
+	}
+	return out, nil
+}
+
+// slideTextBlock is the flattened text of a shape together with the ranges
+// (UTF-16 offsets) that need styling or bullets.
+type slideTextBlock struct {
+	Text   string
+	Styles []TextStyle
+	Lists  []slideListRange
+}
+
+type slideListRange struct {
+	Start, End int64
+	Ordered    bool
+}
+
+func (b *slideTextBlock) appendParagraph(markdown string, prefix string) (int64, int64) {
+	if b.Text != "" {
+		b.Text += "\n"
+	}
+	start := utf16Len(b.Text)
+	styles, plain := ParseInlineFormatting(markdown)
+	shift := start + utf16Len(prefix)
+	for _, st := range styles {
+		st.Start += shift
+		st.End += shift
+		b.Styles = append(b.Styles, st)
+	}
+	b.Text += prefix + plain
+	return start, utf16Len(b.Text)
+}
+
+// composeSlideText renders body, list and code elements into one text block.
+// List nesting is encoded as leading tabs, which CreateParagraphBullets turns
+// into indentation levels.
+func composeSlideText(elements []SlideElement) slideTextBlock {
+	var b slideTextBlock
+	for _, el := range elements {
+		switch el.Type {
+		case "body":
+			b.appendParagraph(el.Content, "")
+		case "bullets":
+			var current *slideListRange
+			for _, item := range el.Bullets {
+				start, end := b.appendParagraph(item.Text, strings.Repeat("\t", item.Level))
+				if current == nil || current.Ordered != item.Ordered {
+					b.Lists = append(b.Lists, slideListRange{Start: start, End: end, Ordered: item.Ordered})
+					current = &b.Lists[len(b.Lists)-1]
+					continue
+				}
+				current.End = end
+			}
+		case "code":
+			if b.Text != "" {
+				b.Text += "\n"
+			}
+			start := utf16Len(b.Text)
+			b.Text += el.Content
+			b.Styles = append(b.Styles, TextStyle{Code: true, Start: start, End: utf16Len(b.Text)})
+		}
+	}
+	return b
+}
+
+// textRequests inserts the block into a shape (or table cell) and applies
+// inline styles, then bullets, which must come last since they strip tabs.
+func (b slideTextBlock) textRequests(objectID string, cell *slides.TableCellLocation) []*slides.Request {
+	if b.Text == "" {
+		return nil
+	}
+	reqs := []*slides.Request{{InsertText: &slides.InsertTextRequest{ObjectId: objectID, CellLocation: cell, Text: b.Text}}}
+	for _, st := range b.Styles {
+		if st.End <= st.Start {
+			continue
+		}
+		style := &slides.TextStyle{}
+		var fields []string
+		if st.Bold {
+			style.Bold = true
+			fields = append(fields, "bold")
+		}
+		if st.Italic {
+			style.Italic = true
+			fields = append(fields, "italic")
+		}
+		if st.Code {
+			style.FontFamily = slidesCodeFont
+			fields = append(fields, "fontFamily")
+		}
+		if st.Link != "" {
+			style.Link = &slides.Link{Url: st.Link}
+			fields = append(fields, "link")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		reqs = append(reqs, &slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId:     objectID,
+			CellLocation: cell,
+			TextRange:    slidesFixedRange(st.Start, st.End),
+			Style:        style,
+			Fields:       strings.Join(fields, ","),
+		}})
+	}
+	// Bullets strip leading tabs and shift later offsets, so go back to front.
+	for i := len(b.Lists) - 1; i >= 0; i-- {
+		list := b.Lists[i]
+		preset := slidesBulletPreset
+		if list.Ordered {
+			preset = slidesNumberedPreset
+		}
+		reqs = append(reqs, &slides.Request{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+			ObjectId:     objectID,
+			CellLocation: cell,
+			TextRange:    slidesFixedRange(list.Start, list.End),
+			BulletPreset: preset,
+		}})
+	}
+	return reqs
+}
+
+func slidesFixedRange(start, end int64) *slides.Range {
+	return &slides.Range{Type: "FIXED_RANGE", StartIndex: &start, EndIndex: &end}
+}
+
+// slidesBox is a position and size in EMU.
+type slidesBox struct{ X, Y, W, H float64 }
+
+func (b slidesBox) properties(pageID string) *slides.PageElementProperties {
+	return &slides.PageElementProperties{
+		PageObjectId: pageID,
+		Size: &slides.Size{
+			Width:  &slides.Dimension{Magnitude: b.W, Unit: "EMU"},
+			Height: &slides.Dimension{Magnitude: b.H, Unit: "EMU"},
+		},
+		Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: b.X, TranslateY: b.Y, Unit: "EMU"},
+	}
+}
+
+// SlidesToAPIRequests converts slide structures to Google Slides API batch
+// update requests. Titles and bodies go into the layout's placeholders so the
+// theme applies; images and tables are laid out in the content area.
+func SlidesToAPIRequests(slideData []Slide, layouts map[SlideLayout]slidesLayoutChoice, imageURLs map[string]string, pageW, pageH float64) ([]*slides.Request, map[int]string) {
 	var requests []*slides.Request
 	slideIDs := make(map[int]string)
 
+	titleBox := slidesBox{X: pageW * 0.05, Y: pageH * 0.05, W: pageW * 0.9, H: pageH * 0.15}
+	content := slidesBox{X: pageW * 0.05, Y: pageH * 0.24, W: pageW * 0.9, H: pageH * 0.7}
+
 	for i, slide := range slideData {
 		slideID := fmt.Sprintf("slide_%d", i+1)
-		slideIDs[i] = slideID
-
-		// Create blank slide
-		requests = append(requests, &slides.Request{
-			CreateSlide: &slides.CreateSlideRequest{
-				ObjectId: slideID,
-				SlideLayoutReference: &slides.LayoutReference{
-					PredefinedLayout: "BLANK",
-				},
-			},
-		})
-
-		// Add title box
 		titleID := fmt.Sprintf("title_%d", i+1)
-		requests = append(requests, &slides.Request{
-			CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  titleID,
-				ShapeType: "TEXT_BOX",
-				ElementProperties: &slides.PageElementProperties{
-					PageObjectId: slideID,
-					Transform: &slides.AffineTransform{
-						ScaleX:     1,
-						ScaleY:     1,
-						TranslateX: 72 * 0.5, // 0.5 inches from left
-						TranslateY: 72 * 0.5, // 0.5 inches from top
-						Unit:       "PT",
-					},
-					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 612 - 72, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 100, Unit: "PT"},
-					},
-				},
-			},
-		})
-
-		// Add title text
-		for _, elem := range slide.Elements {
-			if elem.Type == "title" {
-				requests = append(requests, &slides.Request{
-					InsertText: &slides.InsertTextRequest{
-						ObjectId:       titleID,
-						Text:           elem.Content,
-						InsertionIndex: 0,
-					},
-				})
-
-				// Make title bold
-				requests = append(requests, &slides.Request{
-					UpdateTextStyle: &slides.UpdateTextStyleRequest{
-						ObjectId: titleID,
-						TextRange: &slides.Range{
-							Type: "ALL",
-						},
-						Style: &slides.TextStyle{
-							Bold: true,
-							FontSize: &slides.Dimension{
-								Magnitude: 36,
-								Unit:      "PT",
-							},
-						},
-						Fields: "bold,fontSize",
-					},
-				})
-			}
-		}
-
-		// Add body box
 		bodyID := fmt.Sprintf("body_%d", i+1)
-		requests = append(requests, &slides.Request{
-			CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  bodyID,
-				ShapeType: "TEXT_BOX",
-				ElementProperties: &slides.PageElementProperties{
-					PageObjectId: slideID,
-					Transform: &slides.AffineTransform{
-						ScaleX:     1,
-						ScaleY:     1,
-						TranslateX: 72 * 0.5,
-						TranslateY: 72 * 1.5, // Below title
-						Unit:       "PT",
-					},
-					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 612 - 72, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 300, Unit: "PT"},
-					},
-				},
-			},
-		})
-
-		// Build body content
-		var bodyContent strings.Builder
-		for _, elem := range slide.Elements {
-			if elem.Type != "title" {
-				switch elem.Type {
-				case "body":
-					bodyContent.WriteString(elem.Content)
-					bodyContent.WriteString("\n")
-				case "bullets":
-					for _, item := range elem.Items {
-						bodyContent.WriteString("• ")
-						bodyContent.WriteString(item)
-						bodyContent.WriteString("\n")
-					}
-				case "code":
-					bodyContent.WriteString("```\n")
-					bodyContent.WriteString(elem.Content)
-					bodyContent.WriteString("\n```\n")
-				}
+		slideIDs[i] = slideID
+
+		choice, ok := layouts[slide.Layout]
+		if !ok {
+			choice = slidesLayoutChoice{Ref: &slides.LayoutReference{PredefinedLayout: string(slide.Layout)}}
+		}
+
+		var visuals []SlideElement
+		for _, el := range slide.Elements {
+			if el.Type == "image" || el.Type == "table" {
+				visuals = append(visuals, el)
 			}
 		}
+		body := composeSlideText(slide.Elements)
+		textArea, visualArea := content, content
+		if len(visuals) > 0 && body.Text != "" {
+			textArea.W = content.W * 0.48
+			visualArea.X = content.X + content.W*0.52
+			visualArea.W = content.W * 0.48
+		}
 
-		// Add body text if there's content
-		if bodyContent.Len() > 0 {
-			requests = append(requests, &slides.Request{
-				InsertText: &slides.InsertTextRequest{
-					ObjectId:       bodyID,
-					Text:           bodyContent.String(),
-					InsertionIndex: 0,
-				},
+		create := &slides.CreateSlideRequest{ObjectId: slideID, SlideLayoutReference: choice.Ref}
+		titleInPlaceholder := slide.Title != "" && choice.Title != ""
+		bodyInPlaceholder := body.Text != "" && choice.Body != "" && len(visuals) == 0
+		if titleInPlaceholder {
+			create.PlaceholderIdMappings = append(create.PlaceholderIdMappings, &slides.LayoutPlaceholderIdMapping{
+				LayoutPlaceholder: &slides.Placeholder{Type: choice.Title},
+				ObjectId:          titleID,
+			})
+		}
+		if bodyInPlaceholder {
+			create.PlaceholderIdMappings = append(create.PlaceholderIdMappings, &slides.LayoutPlaceholderIdMapping{
+				LayoutPlaceholder: &slides.Placeholder{Type: choice.Body},
+				ObjectId:          bodyID,
 			})
 		}
+		requests = append(requests, &slides.Request{CreateSlide: create})
+
+		if slide.Title != "" {
+			if !titleInPlaceholder {
+				requests = append(requests, slidesTextBoxRequest(titleID, slideID, titleBox))
+			}
+			var title slideTextBlock
+			title.appendParagraph(slide.Title, "")
+			requests = append(requests, title.textRequests(titleID, nil)...)
+		}
+
+		if body.Text != "" {
+			if !bodyInPlaceholder {
+				requests = append(requests, slidesTextBoxRequest(bodyID, slideID, textArea))
+			}
+			requests = append(requests, body.textRequests(bodyID, nil)...)
+		}
+
+		// Stack visuals vertically in their area.
+		for j, el := range visuals {
+			box := visualArea
+			box.H = visualArea.H / float64(len(visuals))
+			box.Y = visualArea.Y + box.H*float64(j)
+			objectID := fmt.Sprintf("%s_v%d", slideID, j+1)
+			switch el.Type {
+			case "image":
+				url := imageURLs[el.Content]
+				if url == "" {
+					continue
+				}
+				requests = append(requests, &slides.Request{CreateImage: &slides.CreateImageRequest{
+					ObjectId:          objectID,
+					Url:               url,
+					ElementProperties: box.properties(slideID),
+				}})
+			case "table":
+				requests = append(requests, slidesTableRequests(objectID, slideID, box, el.Rows)...)
+			}
+		}
 	}
 
 	return requests, slideIDs
 }
 
-// CreatePresentationFromMarkdown creates a Google Slides presentation from markdown
-func CreatePresentationFromMarkdown(title string, markdown string, service *slides.Service) (*slides.Presentation, error) {
+func slidesTextBoxRequest(objectID, pageID string, box slidesBox) *slides.Request {
+	return &slides.Request{CreateShape: &slides.CreateShapeRequest{
+		ObjectId:          objectID,
+		ShapeType:         "TEXT_BOX",
+		ElementProperties: box.properties(pageID),
+	}}
+}
+
+func slidesTableRequests(objectID, pageID string, box slidesBox, rows [][]string) []*slides.Request {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return nil
+	}
+	reqs := []*slides.Request{{CreateTable: &slides.CreateTableRequest{
+		ObjectId:          objectID,
+		ElementProperties: box.properties(pageID),
+		Rows:              int64(len(rows)),
+		Columns:           int64(cols),
+	}}}
+	for r, row := range rows {
+		for c, cell := range row {
+			var block slideTextBlock
+			block.appendParagraph(cell, "")
+			if r == 0 && block.Text != "" {
+				block.Styles = append(block.Styles, TextStyle{Bold: true, Start: 0, End: utf16Len(block.Text)})
+			}
+			reqs = append(reqs, block.textRequests(objectID, &slides.TableCellLocation{
+				RowIndex:        int64(r),
+				ColumnIndex:     int64(c),
+				ForceSendFields: []string{"RowIndex", "ColumnIndex"},
+			})...)
+		}
+	}
+	return reqs
+}
+
+// resolveSlideImages maps each image source in the deck to a URL the Slides
+// API can fetch, uploading local files through Drive. The returned file IDs
+// should be deleted once the deck is built.
+func resolveSlideImages(ctx context.Context, driveSvc *drive.Service, deck []Slide, base string) (map[string]string, []string, error) {
+	urls := map[string]string{}
+	var uploaded []string
+	for _, slide := range deck {
+		for _, el := range slide.Elements {
+			if el.Type != "image" || urls[el.Content] != "" {
+				continue
+			}
+			if strings.HasPrefix(el.Content, "http://") || strings.HasPrefix(el.Content, "https://") {
+				urls[el.Content] = el.Content
+				continue
+			}
+			path, err := resolveMarkdownImagePath(base, el.Content)
+			if err != nil {
+				return nil, uploaded, err
+			}
+			url, fileID, err := uploadLocalImage(ctx, driveSvc, path)
+			if err != nil {
+				return nil, uploaded, err
+			}
+			uploaded = append(uploaded, fileID)
+			urls[el.Content] = url
+		}
+	}
+	return urls, uploaded, nil
+}
+
+// CreatePresentationFromMarkdown creates a Google Slides presentation from
+// markdown, optionally on top of a copied template deck.
+func CreatePresentationFromMarkdown(ctx context.Context, title string, markdown string, service *slides.Service, driveSvc *drive.Service, opts slidesMarkdownOptions) (*slides.Presentation, error) {
 	// Parse markdown to slides
 	slidesData := ParseMarkdownToSlides(markdown)
 
@@ -149,34 +461,114 @@ func CreatePresentationFromMarkdown(title string, markdown string, service *slid
 		return nil, fmt.Errorf("no slides found in markdown")
 	}
 
-	// Create presentation
-	presentation, err := service.Presentations.Create(&slides.Presentation{
-		Title: title,
-	}).Do()
+	base := opts.ImageBase
+	if base == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		base = filepath.Join(cwd, "slides.md")
+	}
+	imageURLs, uploaded, err := resolveSlideImages(ctx, driveSvc, slidesData, base)
+	defer cleanupDriveFileIDsBestEffort(ctx, driveSvc, uploaded)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create presentation (or copy the template to inherit its theme)
+	presentationID := ""
+	if opts.Template != "" {
+		created, copyErr := copyDriveFile(ctx, driveSvc, copyViaDriveOptions{
+			ExpectedMime: "application/vnd.google-apps.presentation",
+			KindLabel:    "Google Slides presentation",
+		}, opts.Template, title, opts.Parent)
+		if copyErr != nil {
+			return nil, fmt.Errorf("failed to copy template: %w", copyErr)
+		}
+		presentationID = created.Id
+	} else {
+		created, createErr := service.Presentations.Create(&slides.Presentation{
+			Title: title,
+		}).Context(ctx).Do()
+		if createErr != nil {
+			return nil, fmt.Errorf("failed to create presentation: %w", createErr)
+		}
+		presentationID = created.PresentationId
+	}
+
+	presentation, err := service.Presentations.Get(presentationID).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create presentation: %w", err)
+		return nil, fmt.Errorf("get presentation: %w", err)
+	}
+	layouts, err := resolveSlideLayouts(presentation, opts.LayoutMap, opts.Master)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert to API requests
-	requests, slideIDs := SlidesToAPIRequests(slidesData)
+	pageW, pageH := float64(slidesDefaultPageWidth), float64(slidesDefaultPageHeight)
+	if ps := presentation.PageSize; ps != nil && ps.Width != nil && ps.Height != nil && ps.Width.Unit == "EMU" {
+		pageW, pageH = ps.Width.Magnitude, ps.Height.Magnitude
+	}
 
-	// Execute batch update
-	if len(requests) > 0 {
-		_, err = service.Presentations.BatchUpdate(presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
-			Requests: requests,
-		}).Do()
-		if err != nil {
-			return nil, fmt.Errorf("failed to populate slides: %w", err)
+	// Convert to API requests; the template's (or the default) slides go away.
+	requests, slideIDs := SlidesToAPIRequests(slidesData, layouts, imageURLs, pageW, pageH)
+	for _, existing := range presentation.Slides {
+		if existing != nil {
+			requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: existing.ObjectId}})
 		}
 	}
 
+	// Execute batch update
+	_, err = service.Presentations.BatchUpdate(presentationID, &slides.BatchUpdatePresentationRequest{
+		Requests: requests,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate slides: %w", err)
+	}
+
+	presentation, err = service.Presentations.Get(presentationID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("get presentation: %w", err)
+	}
+	if err := addMarkdownSpeakerNotes(ctx, service, presentation, slidesData, slideIDs); err != nil {
+		return nil, err
+	}
+
 	// Debug output
 	if debugSlides {
 		fmt.Printf("[DEBUG] Created presentation with %d slides\n", len(slidesData))
-		for i, slideID := range slideIDs {
-			fmt.Printf("  Slide %d: %s - %s\n", i+1, slideID, slidesData[i].Title)
+		for i := range slidesData {
+			fmt.Printf("  Slide %d: %s (%s) - %s\n", i+1, slideIDs[i], slidesData[i].Layout, slidesData[i].Title)
 		}
 	}
 
 	return presentation, nil
 }
+
+func addMarkdownSpeakerNotes(ctx context.Context, service *slides.Service, presentation *slides.Presentation, slidesData []Slide, slideIDs map[int]string) error {
+	notesIDs := map[string]string{}
+	for _, s := range presentation.Slides {
+		if s == nil || s.SlideProperties == nil || s.SlideProperties.NotesPage == nil || s.SlideProperties.NotesPage.NotesProperties == nil {
+			continue
+		}
+		notesIDs[s.ObjectId] = s.SlideProperties.NotesPage.NotesProperties.SpeakerNotesObjectId
+	}
+
+	var requests []*slides.Request
+	for i, slide := range slidesData {
+		notesID := notesIDs[slideIDs[i]]
+		if slide.Notes == "" || notesID == "" {
+			continue
+		}
+		requests = append(requests, &slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: notesID, Text: slide.Notes}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	if _, err := service.Presentations.BatchUpdate(presentation.PresentationId, &slides.BatchUpdatePresentationRequest{
+		Requests: requests,
+	}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("add speaker notes: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"regexp"
 	"strings"
 )
 
@@ -12,62 +13,66 @@ const (
 	LayoutTitleAndBody       SlideLayout = "TITLE_AND_BODY"
 	LayoutTitleAndTwoColumns SlideLayout = "TITLE_AND_TWO_COLUMNS"
 	LayoutSectionHeader      SlideLayout = "SECTION_HEADER"
+	LayoutTitleBar           SlideLayout = "TITLE_ONLY" // title placeholder at the top, free content area
 	LayoutBlank              SlideLayout = "BLANK"
 )
 
 // SlideElement represents an element on a slide
 type SlideElement struct {
-	Type     string // "title", "body", "bullets", "code"
-	Content  string
-	Items    []string // for bullet lists
-	IsBold   bool
-	IsItalic bool
+	Type    string        // "title", "body", "bullets", "code", "image", "table"
+	Content string        // markdown text (inline formatting kept), code, or image source
+	Bullets []SlideBullet // for bullet lists
+	Alt     string        // for images
+	Rows    [][]string    // for tables: rows of markdown cells, header first
+}
+
+// SlideBullet is one list item with its nesting level (0 = top level).
+type SlideBullet struct {
+	Text    string
+	Level   int
+	Ordered bool
 }
 
 // Slide represents a single slide
 type Slide struct {
-	Title    string
-	Layout   SlideLayout
-	Elements []SlideElement
+	Title      string
+	TitleLevel int // 1 for "#", 2 for "##"
+	Layout     SlideLayout
+	Elements   []SlideElement
+	Notes      string
 }
 
-// ParseMarkdownToSlides parses markdown into slide structures
+var slideBulletRe = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])\s+(.*)$`)
+
+// ParseMarkdownToSlides parses markdown into slide structures. Slides are
+// separated by "---" lines; a "???" line starts the slide's speaker notes.
 func ParseMarkdownToSlides(markdown string) []Slide {
 	var slides []Slide
+	var current []string
+	inFence := false
 
-	// Split by slide separators (--- on its own line)
-	lines := strings.Split(markdown, "\n")
-	var currentSlide strings.Builder
-	inSlide := false
-
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "---" {
-			if currentSlide.Len() > 0 {
-				slide := parseSlide(currentSlide.String())
-				if slide.Title != "" {
-					slides = append(slides, slide)
-				}
-				currentSlide.Reset()
-			}
-			inSlide = false
-		} else {
-			if !inSlide {
-				inSlide = true
-			}
-			if currentSlide.Len() > 0 {
-				currentSlide.WriteString("\n")
-			}
-			currentSlide.WriteString(line)
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		slide := parseSlide(strings.Join(current, "\n"))
+		if slide.Title != "" || len(slide.Elements) > 0 {
+			slides = append(slides, slide)
 		}
+		current = current[:0]
 	}
 
-	// Handle the last slide
-	if currentSlide.Len() > 0 {
-		slide := parseSlide(currentSlide.String())
-		if slide.Title != "" {
-			slides = append(slides, slide)
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.TrimSpace(line) == "---" {
+			flush()
+			continue
 		}
+		current = append(current, line)
 	}
+	flush()
 
 	return slides
 }
@@ -79,90 +84,134 @@ func parseSlide(text string) Slide {
 	}
 
 	lines := strings.Split(text, "\n")
-	var currentElement *SlideElement
-	var inCodeBlock bool
-	var codeContent strings.Builder
+	var codeContent []string
+	inCodeBlock := false
+	var bullets *SlideElement
+	var notes []string
+	inNotes := false
+
+	endBullets := func() {
+		if bullets != nil {
+			slide.Elements = append(slide.Elements, *bullets)
+			bullets = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if inNotes {
+			notes = append(notes, line)
+			continue
+		}
 
-	for _, line := range lines {
 		// Handle code blocks
-		if strings.HasPrefix(line, "```") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
 			if inCodeBlock {
-				// End code block
-				if currentElement != nil {
-					currentElement.Content = codeContent.String()
-					slide.Elements = append(slide.Elements, *currentElement)
-				}
-				inCodeBlock = false
-				currentElement = nil
-				codeContent.Reset()
+				slide.Elements = append(slide.Elements, SlideElement{
+					Type:    "code",
+					Content: strings.Join(codeContent, "\n"),
+				})
+				codeContent = nil
 			} else {
-				// Start code block
-				inCodeBlock = true
-				currentElement = &SlideElement{
-					Type: "code",
-				}
+				endBullets()
 			}
+			inCodeBlock = !inCodeBlock
 			continue
 		}
-
 		if inCodeBlock {
-			if codeContent.Len() > 0 {
-				codeContent.WriteString("\n")
-			}
-			codeContent.WriteString(line)
+			codeContent = append(codeContent, line)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "???" {
+			endBullets()
+			inNotes = true
 			continue
 		}
 
 		// Skip empty lines
-		if strings.TrimSpace(line) == "" {
+		if trimmed == "" {
+			continue
+		}
+
+		// Bullet points (indentation sets the nesting level)
+		if m := slideBulletRe.FindStringSubmatch(line); m != nil {
+			indent := strings.ReplaceAll(m[1], "\t", "  ")
+			item := SlideBullet{
+				Text:    strings.TrimSpace(m[3]),
+				Level:   len(indent) / 2,
+				Ordered: m[2][0] >= '0' && m[2][0] <= '9',
+			}
+			if bullets == nil {
+				bullets = &SlideElement{Type: "bullets"}
+			}
+			bullets.Bullets = append(bullets.Bullets, item)
+			continue
+		}
+		endBullets()
+
+		// Slide title: the first "#" or "##" heading
+		if level, heading := parseHeading(trimmed); level > 0 {
+			if slide.Title == "" && level <= 2 {
+				slide.Title = heading
+				slide.TitleLevel = level
+				slide.Elements = append(slide.Elements, SlideElement{
+					Type:    "title",
+					Content: heading,
+				})
+			} else {
+				slide.Elements = append(slide.Elements, SlideElement{
+					Type:    "body",
+					Content: "**" + heading + "**",
+				})
+			}
 			continue
 		}
 
-		// Title (## heading for slides)
-		if strings.HasPrefix(line, "## ") {
-			title := strings.TrimPrefix(line, "## ")
-			// Remove formatting markers
-			title = stripInlineFormatting(title)
-			slide.Title = title
+		// Standalone image
+		if m := mdImageRe.FindStringSubmatch(trimmed); m != nil && m[0] == trimmed {
+			src := m[2]
+			if src == "" {
+				src = m[3]
+			}
 			slide.Elements = append(slide.Elements, SlideElement{
-				Type:    "title",
-				Content: title,
+				Type:    "image",
+				Content: src,
+				Alt:     m[1],
 			})
 			continue
 		}
 
-		// Bullet points
-		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
-			item := strings.TrimPrefix(strings.TrimPrefix(line, "- "), "* ")
-			item = stripInlineFormatting(item)
-
-			// Find or create bullets element
-			var bulletsElement *SlideElement
-			for i := range slide.Elements {
-				if slide.Elements[i].Type == "bullets" {
-					bulletsElement = &slide.Elements[i]
-					break
-				}
+		// Table
+		if strings.HasPrefix(trimmed, "|") {
+			start := i
+			for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "|") {
+				i++
 			}
-
-			if bulletsElement == nil {
+			if rows := parseMarkdownTable(lines[start : i+1]); len(rows) > 0 {
 				slide.Elements = append(slide.Elements, SlideElement{
-					Type:  "bullets",
-					Items: []string{item},
+					Type: "table",
+					Rows: rows,
 				})
-			} else {
-				bulletsElement.Items = append(bulletsElement.Items, item)
 			}
 			continue
 		}
 
 		// Regular paragraph
-		content := stripInlineFormatting(line)
 		slide.Elements = append(slide.Elements, SlideElement{
 			Type:    "body",
-			Content: content,
+			Content: strings.TrimSpace(strings.TrimPrefix(trimmed, ">")),
 		})
 	}
+	endBullets()
+	if inCodeBlock && len(codeContent) > 0 {
+		slide.Elements = append(slide.Elements, SlideElement{
+			Type:    "code",
+			Content: strings.Join(codeContent, "\n"),
+		})
+	}
+	slide.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
 
 	// Determine layout based on content
 	slide.Layout = determineLayout(slide)
@@ -170,58 +219,40 @@ func parseSlide(text string) Slide {
 	return slide
 }
 
-// stripInlineFormatting removes markdown formatting from text
-func stripInlineFormatting(text string) string {
-	// Remove bold/italic markers
-	text = strings.ReplaceAll(text, "**", "")
-	text = strings.ReplaceAll(text, "__", "")
-	text = strings.ReplaceAll(text, "*", "")
-	text = strings.ReplaceAll(text, "_", "")
-
-	// Remove code markers
-	text = strings.ReplaceAll(text, "`", "")
-
-	// Remove links but keep text [text](url) -> text
-	// Simple approach: just remove brackets and parens for now
-
-	return text
-}
-
 // determineLayout chooses the best layout for a slide
 func determineLayout(slide Slide) SlideLayout {
 	hasTitle := false
-	hasBullets := false
-	hasBody := false
-	hasCode := false
+	hasText := false
+	hasOnlyParagraphs := true
+	hasVisual := false
 
 	for _, elem := range slide.Elements {
 		switch elem.Type {
 		case "title":
 			hasTitle = true
-		case "bullets":
-			hasBullets = true
 		case "body":
-			hasBody = true
-		case "code":
-			hasCode = true
+			hasText = true
+		case "bullets", "code":
+			hasText = true
+			hasOnlyParagraphs = false
+		case "image", "table":
+			hasVisual = true
 		}
 	}
 
+	switch {
 	// No title = blank layout
-	if !hasTitle {
+	case !hasTitle:
 		return LayoutBlank
-	}
-
-	// Code slides often need more space
-	if hasCode {
-		return LayoutTitleAndBody
-	}
-
-	// Bullets or body = title + body
-	if hasBullets || hasBody {
+	// Images and tables get a free content area under the title
+	case hasVisual:
+		return LayoutTitleBar
+	case !hasText && slide.TitleLevel == 1:
+		return LayoutSectionHeader
+	// Just a title (or a "#" title with a subtitle) = title slide
+	case !hasText, slide.TitleLevel == 1 && hasOnlyParagraphs:
+		return LayoutTitleOnly
+	default:
 		return LayoutTitleAndBody
 	}
-
-	// Just a title = title only
-	return LayoutTitleOnly
 }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestParseMarkdownToSlides(t *testing.T) {
+	deck := ParseMarkdownToSlides("# Launch\n\nQ3 **plan**\n\n---\n\n## Agenda\n\n- one\n  - nested\n1. first\n\n???\nSay hi\n---\n## Chart\n\n![growth](./chart.png)\n\n| A | B |\n|---|---|\n| 1 | 2 |\n\n---\n\n```\na\n---\nb\n```\n")
+	if len(deck) != 4 {
+		t.Fatalf("expected 4 slides, got %d: %+v", len(deck), deck)
+	}
+
+	if deck[0].Layout != LayoutTitleOnly || deck[0].Title != "Launch" || deck[0].Elements[1].Content != "Q3 **plan**" {
+		t.Fatalf("unexpected title slide: %+v", deck[0])
+	}
+
+	agenda := deck[1]
+	if agenda.Layout != LayoutTitleAndBody || agenda.Notes != "Say hi" {
+		t.Fatalf("unexpected agenda slide: %+v", agenda)
+	}
+	wantBullets := []SlideBullet{{Text: "one"}, {Text: "nested", Level: 1}, {Text: "first", Ordered: true}}
+	if !reflect.DeepEqual(agenda.Elements[1].Bullets, wantBullets) {
+		t.Fatalf("bullets = %+v", agenda.Elements[1].Bullets)
+	}
+
+	chart := deck[2]
+	if chart.Layout != LayoutTitleBar || chart.Elements[1].Type != "image" || chart.Elements[1].Content != "./chart.png" || chart.Elements[1].Alt != "growth" {
+		t.Fatalf("unexpected chart slide: %+v", chart)
+	}
+	if !reflect.DeepEqual(chart.Elements[2].Rows, [][]string{{"A", "B"}, {"1", "2"}}) {
+		t.Fatalf("table rows = %v", chart.Elements[2].Rows)
+	}
+
+	// "---" inside a code fence doesn't split slides.
+	if deck[3].Layout != LayoutBlank || deck[3].Elements[0].Content != "a\n---\nb" {
+		t.Fatalf("unexpected code slide: %+v", deck[3])
+	}
+}
+
+func TestComposeSlideText_StylesAndBullets(t *testing.T) {
+	block := composeSlideText([]SlideElement{
+		{Type: "body", Content: "See [docs](https://x.test)"},
+		{Type: "bullets", Bullets: []SlideBullet{{Text: "**a**"}, {Text: "b", Level: 1}, {Text: "c", Ordered: true}}},
+	})
+	if block.Text != "See docs\na\n\tb\nc" {
+		t.Fatalf("text = %q", block.Text)
+	}
+	if len(block.Styles) != 2 || block.Styles[0].Link != "https://x.test" || block.Styles[1].Start != 9 || !block.Styles[1].Bold {
+		t.Fatalf("styles = %+v", block.Styles)
+	}
+	want := []slideListRange{{Start: 9, End: 13}, {Start: 14, End: 15, Ordered: true}}
+	if !reflect.DeepEqual(block.Lists, want) {
+		t.Fatalf("lists = %+v", block.Lists)
+	}
+
+	reqs := block.textRequests("body_1", nil)
+	// Insert, two styles, then bullets back to front so tab removal can't shift later ranges.
+	if len(reqs) != 5 || reqs[3].CreateParagraphBullets.BulletPreset != slidesNumberedPreset || reqs[4].CreateParagraphBullets.BulletPreset != slidesBulletPreset {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestResolveSlideLayouts(t *testing.T) {
+	layout := func(id, master, name, display string, placeholders ...string) *slides.Page {
+		p := &slides.Page{ObjectId: id, LayoutProperties: &slides.LayoutProperties{MasterObjectId: master, Name: name, DisplayName: display}}
+		for _, ph := range placeholders {
+			p.PageElements = append(p.PageElements, &slides.PageElement{Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: ph}}})
+		}
+		return p
+	}
+	pres := &slides.Presentation{
+		Masters: []*slides.Page{
+			{ObjectId: "m1", MasterProperties: &slides.MasterProperties{DisplayName: "Light"}},
+			{ObjectId: "m2", MasterProperties: &slides.MasterProperties{DisplayName: "Dark"}},
+		},
+		Layouts: []*slides.Page{
+			layout("l1", "m1", "TITLE_AND_BODY", "Title and body", "TITLE", "BODY"),
+			layout("l2", "m2", "TITLE_AND_BODY", "Title and body", "TITLE", "BODY"),
+			layout("l3", "m2", "CUSTOM", "Big Statement", "CENTERED_TITLE", "SUBTITLE"),
+		},
+	}
+
+	got, err := resolveSlideLayouts(pres, map[string]string{"TITLE": "big statement"}, "Dark")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got[LayoutTitleAndBody].Ref.LayoutId != "l2" || got[LayoutTitleAndBody].Body != "BODY" {
+		t.Fatalf("body layout = %+v", got[LayoutTitleAndBody])
+	}
+	if title := got[LayoutTitleOnly]; title.Ref.LayoutId != "l3" || title.Title != "CENTERED_TITLE" || title.Body != "SUBTITLE" {
+		t.Fatalf("title layout = %+v", title)
+	}
+	if got[LayoutBlank].Ref.PredefinedLayout != "BLANK" {
+		t.Fatalf("blank layout = %+v", got[LayoutBlank].Ref)
+	}
+
+	if _, err = resolveSlideLayouts(pres, map[string]string{"TITLE": "Nope"}, ""); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for unknown layout, got %v", err)
+	}
+	if _, err = resolveSlideLayouts(pres, map[string]string{"HERO": "l1"}, ""); ExitCode(err) != 2 {
+		t.Fatalf("expected usage error for unknown kind, got %v", err)
+	}
+}
+
+func TestSlidesToAPIRequests_PlaceholdersAndVisuals(t *testing.T) {
+	deck := ParseMarkdownToSlides("## Results\n\n- up\n\n![c](https://x.test/c.png)\n")
+	layouts := map[SlideLayout]slidesLayoutChoice{
+		LayoutTitleBar: {Ref: &slides.LayoutReference{LayoutId: "l9"}, Title: "TITLE"},
+	}
+	reqs, ids := SlidesToAPIRequests(deck, layouts, map[string]string{"https://x.test/c.png": "https://x.test/c.png"}, 9144000, 5143500)
+	if ids[0] != "slide_1" {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	create := reqs[0].CreateSlide
+	if create.SlideLayoutReference.LayoutId != "l9" || len(create.PlaceholderIdMappings) != 1 || create.PlaceholderIdMappings[0].ObjectId != "title_1" {
+		t.Fatalf("unexpected create: %+v", create)
+	}
+	var textBox, image *slides.Request
+	for _, r := range reqs {
+		if r.CreateShape != nil && r.CreateShape.ObjectId == "body_1" {
+			textBox = r
+		}
+		if r.CreateImage != nil {
+			image = r
+		}
+	}
+	if textBox == nil || image == nil {
+		t.Fatalf("expected body text box and image, got %+v", reqs)
+	}
+	// Text on the left half, the image on the right.
+	if image.CreateImage.ElementProperties.Transform.TranslateX <= textBox.CreateShape.ElementProperties.Transform.TranslateX {
+		t.Fatalf("image not placed right of text")
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jibankumarpanda/gogcli/internal/config"
+	"github.com/jibankumarpanda/gogcli/internal/outfmt"
+	"github.com/jibankumarpanda/gogcli/internal/ui"
+)
+
+const slidesThumbnailConcurrency = 4
+
+type SlidesThumbnailsCmd struct {
+	PresentationID string `arg:"" name:"presentationId" help:"Presentation ID"`
+	Out            string `name:"out" aliases:"output,out-dir" help:"Directory to write PNGs into" required:""`
+	Size           string `name:"size" help:"Thumbnail size: SMALL|MEDIUM|LARGE" enum:"SMALL,MEDIUM,LARGE" default:"LARGE"`
+}
+
+type slidesThumbnail struct {
+	Index   int    `json:"index"`
+	SlideID string `json:"slideId"`
+	Path    string `json:"path"`
+	Width   int64  `json:"width"`
+	Height  int64  `json:"height"`
+}
+
+func (c *SlidesThumbnailsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	id := normalizeGoogleID(strings.TrimSpace(c.PresentationID))
+	if id == "" {
+		return usage("empty presentationId")
+	}
+	outDir, err := config.ExpandPath(strings.TrimSpace(c.Out))
+	if err != nil {
+		return err
+	}
+	if outDir == "" {
+		return usage("empty --out")
+	}
+
+	if dryErr := dryRunExit(ctx, flags, "slides.thumbnails", map[string]any{
+		"id":   id,
+		"out":  outDir,
+		"size": c.Size,
+	}); dryErr != nil {
+		return dryErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newSlidesService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	pres, err := svc.Presentations.Get(id).Fields("presentationId,slides.objectId").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("get presentation: %w", err)
+	}
+	if len(pres.Slides) == 0 {
+		return fmt.Errorf("presentation %s has no slides", id)
+	}
+	if err = os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+
+	results := make([]slidesThumbnail, len(pres.Slides))
+	errs := make([]error, len(pres.Slides))
+	sem := make(chan struct{}, slidesThumbnailConcurrency)
+	var wg sync.WaitGroup
+	for i, slide := range pres.Slides {
+		if slide == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, slideID string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+
+			thumb, thumbErr := svc.Presentations.Pages.GetThumbnail(id, slideID).
+				ThumbnailPropertiesThumbnailSize(c.Size).
+				ThumbnailPropertiesMimeType("PNG").
+				Context(ctx).
+				Do()
+			if thumbErr != nil {
+				errs[idx] = fmt.Errorf("slide %s: %w", slideID, thumbErr)
+				return
+			}
+			path := filepath.Join(outDir, fmt.Sprintf("%03d_%s.png", idx+1, sanitizeAttachmentFilename(slideID, "slide")))
+			if _, dlErr := downloadURLToFile(ctx, thumb.ContentUrl, path); dlErr != nil {
+				errs[idx] = fmt.Errorf("slide %s: %w", slideID, dlErr)
+				return
+			}
+			results[idx] = slidesThumbnail{Index: idx + 1, SlideID: slideID, Path: path, Width: thumb.Width, Height: thumb.Height}
+		}(i, slide.ObjectId)
+	}
+	wg.Wait()
+
+	var written []slidesThumbnail
+	for i, r := range results {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if r.Path != "" {
+			written = append(written, r)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"presentationId": id, "thumbnails": written})
+	}
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "#\tSLIDE\tSIZE\tPATH")
+	for _, r := range written {
+		fmt.Fprintf(w, "%d\t%s\t%dx%d\t%s\n", r.Index, r.SlideID, r.Width, r.Height, r.Path)
+	}
+	flush()
+	u.Err().Printf("Wrote %d thumbnails to %s", len(written), outDir)
+	return nil
+}
@@ -89,6 +89,10 @@ func (c *TasksListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Items, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var items []*tasks.Task
 	nextPageToken := ""
 	if c.All {
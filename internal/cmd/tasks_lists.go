@@ -48,6 +48,10 @@ func (c *TasksListsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return resp.Items, resp.NextPageToken, nil
 	}
 
+	if outfmt.IsNDJSON(ctx) {
+		return streamPagesNDJSON(ctx, c.All, c.Page, c.FailEmpty, fetch)
+	}
+
 	var items []*tasks.TaskList
 	nextPageToken := ""
 	if c.All {
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,6 +13,7 @@ type Key string
 const (
 	KeyTimezone       Key = "timezone"
 	KeyKeyringBackend Key = "keyring_backend"
+	KeyRetries        Key = "retries"
 )
 
 type KeySpec struct {
@@ -25,6 +27,7 @@ type KeySpec struct {
 var keyOrder = []Key{
 	KeyTimezone,
 	KeyKeyringBackend,
+	KeyRetries,
 }
 
 var keySpecs = map[Key]KeySpec{
@@ -63,6 +66,31 @@ var keySpecs = map[Key]KeySpec{
 			return "(not set, using auto)"
 		},
 	},
+	KeyRetries: {
+		Key: KeyRetries,
+		Get: func(cfg File) string {
+			if cfg.Retries == nil {
+				return ""
+			}
+
+			return strconv.Itoa(*cfg.Retries)
+		},
+		Set: func(cfg *File, value string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid retries %q (use a non-negative integer; 0 disables retries)", value)
+			}
+			cfg.Retries = &n
+
+			return nil
+		},
+		Unset: func(cfg *File) {
+			cfg.Retries = nil
+		},
+		EmptyHint: func() string {
+			return "(not set, using 3)"
+		},
+	},
 }
 
 var (
@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestRetriesKey(t *testing.T) {
+	var cfg File
+
+	if got := GetValue(cfg, KeyRetries); got != "" {
+		t.Fatalf("expected empty retries, got %q", got)
+	}
+
+	if err := SetValue(&cfg, KeyRetries, " 5 "); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	if got := GetValue(cfg, KeyRetries); got != "5" {
+		t.Fatalf("expected 5, got %q", got)
+	}
+
+	if err := SetValue(&cfg, KeyRetries, "0"); err != nil {
+		t.Fatalf("SetValue zero: %v", err)
+	}
+
+	if got := GetValue(cfg, KeyRetries); got != "0" {
+		t.Fatalf("expected 0, got %q", got)
+	}
+
+	for _, bad := range []string{"-1", "many", ""} {
+		if err := SetValue(&cfg, KeyRetries, bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+
+	if err := UnsetValue(&cfg, KeyRetries); err != nil {
+		t.Fatalf("UnsetValue: %v", err)
+	}
+
+	if cfg.Retries != nil {
+		t.Fatalf("expected retries to be cleared")
+	}
+}
@@ -135,6 +135,42 @@ func GmailWatchDir() (string, error) {
 	return filepath.Join(dir, "state", "gmail-watch"), nil
 }
 
+func DriveSyncDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "drive-sync"), nil
+}
+
+func GmailExportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-export"), nil
+}
+
+func GmailImportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-import"), nil
+}
+
+func GmailMergeDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-merge"), nil
+}
+
 func KeepServiceAccountPath(email string) (string, error) {
 	dir, err := Dir()
 	if err != nil {
@@ -241,6 +277,58 @@ func EnsureGmailWatchDir() (string, error) {
 	return dir, nil
 }
 
+func EnsureDriveSyncDir() (string, error) {
+	dir, err := DriveSyncDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure drive sync dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailExportDir() (string, error) {
+	dir, err := GmailExportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail export dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailImportDir() (string, error) {
+	dir, err := GmailImportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail import dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailMergeDir() (string, error) {
+	dir, err := GmailMergeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail merge dir: %w", err)
+	}
+
+	return dir, nil
+}
+
 // ExpandPath expands ~ at the beginning of a path to the user's home directory.
 // This is needed because ~ is a shell feature and is not expanded when paths
 // are quoted (e.g., --out "~/Downloads/file.pdf").
@@ -61,6 +61,42 @@ func TestPaths_CreateDirs(t *testing.T) {
 		t.Fatalf("expected watch dir: %v", statErr)
 	}
 
+	syncDir, err := EnsureDriveSyncDir()
+	if err != nil {
+		t.Fatalf("EnsureDriveSyncDir: %v", err)
+	}
+
+	if _, statErr := os.Stat(syncDir); statErr != nil {
+		t.Fatalf("expected drive sync dir: %v", statErr)
+	}
+
+	exportDir, err := EnsureGmailExportDir()
+	if err != nil {
+		t.Fatalf("EnsureGmailExportDir: %v", err)
+	}
+
+	if _, statErr := os.Stat(exportDir); statErr != nil {
+		t.Fatalf("expected gmail export dir: %v", statErr)
+	}
+
+	importDir, err := EnsureGmailImportDir()
+	if err != nil {
+		t.Fatalf("EnsureGmailImportDir: %v", err)
+	}
+
+	if _, statErr := os.Stat(importDir); statErr != nil {
+		t.Fatalf("expected gmail import dir: %v", statErr)
+	}
+
+	mergeDir, err := EnsureGmailMergeDir()
+	if err != nil {
+		t.Fatalf("EnsureGmailMergeDir: %v", err)
+	}
+
+	if _, statErr := os.Stat(mergeDir); statErr != nil {
+		t.Fatalf("expected gmail merge dir: %v", statErr)
+	}
+
 	credsPath, err := ClientCredentialsPath()
 	if err != nil {
 		t.Fatalf("ClientCredentialsPath: %v", err)
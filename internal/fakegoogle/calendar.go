@@ -0,0 +1,381 @@
+package fakegoogle
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// DefaultTimeZone is the time zone of every account's primary calendar.
+const DefaultTimeZone = "UTC"
+
+type calendarState struct {
+	primary   string
+	calendars map[string]*calendar.Calendar
+	order     []string
+	events    map[string]map[string]*calendar.Event
+}
+
+func (s *Server) calendar(r *http.Request) *calendarState {
+	a := s.account(r)
+	if a.calendar == nil {
+		a.calendar = &calendarState{
+			primary:   a.email,
+			calendars: map[string]*calendar.Calendar{},
+			events:    map[string]map[string]*calendar.Event{},
+		}
+		a.calendar.add(&calendar.Calendar{Id: a.email, Summary: a.email, TimeZone: DefaultTimeZone})
+	}
+	return a.calendar
+}
+
+func (s *Server) routeCalendar() {
+	const base = "/calendar/v3"
+	m := s.mux
+	m.HandleFunc("GET "+base+"/users/me/calendarList", s.calendarListList)
+	m.HandleFunc("GET "+base+"/users/me/calendarList/{calendarId}", s.calendarListGet)
+	m.HandleFunc("POST "+base+"/calendars", s.calendarsInsert)
+	m.HandleFunc("GET "+base+"/calendars/{calendarId}", s.calendarsGet)
+
+	m.HandleFunc("GET "+base+"/calendars/{calendarId}/events", s.calendarEventsList)
+	m.HandleFunc("POST "+base+"/calendars/{calendarId}/events", s.calendarEventsInsert)
+	m.HandleFunc("POST "+base+"/calendars/{calendarId}/events/import", s.calendarEventsImport)
+	m.HandleFunc("GET "+base+"/calendars/{calendarId}/events/{eventId}", s.calendarEventsGet)
+	m.HandleFunc("PATCH "+base+"/calendars/{calendarId}/events/{eventId}", s.calendarEventsUpdate(true))
+	m.HandleFunc("PUT "+base+"/calendars/{calendarId}/events/{eventId}", s.calendarEventsUpdate(false))
+	m.HandleFunc("DELETE "+base+"/calendars/{calendarId}/events/{eventId}", s.calendarEventsDelete)
+}
+
+func (c *calendarState) add(cal *calendar.Calendar) {
+	c.calendars[cal.Id] = cal
+	c.order = append(c.order, cal.Id)
+	c.events[cal.Id] = map[string]*calendar.Event{}
+}
+
+// lookup resolves "primary" and reports a 404 for unknown calendars.
+func (c *calendarState) lookup(w http.ResponseWriter, id string) *calendar.Calendar {
+	if id == "primary" {
+		id = c.primary
+	}
+	cal, ok := c.calendars[id]
+	if !ok {
+		notFound(w, "Calendar", id)
+		return nil
+	}
+	return cal
+}
+
+func (c *calendarState) entry(cal *calendar.Calendar) *calendar.CalendarListEntry {
+	return &calendar.CalendarListEntry{
+		Id:         cal.Id,
+		Summary:    cal.Summary,
+		TimeZone:   cal.TimeZone,
+		Primary:    cal.Id == c.primary,
+		AccessRole: "owner",
+	}
+}
+
+func (s *Server) calendarListList(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	items := make([]*calendar.CalendarListEntry, 0, len(c.order))
+	for _, id := range c.order {
+		items = append(items, c.entry(c.calendars[id]))
+	}
+	out, next := page(items, r, 100)
+	writeJSON(w, http.StatusOK, &calendar.CalendarList{Items: out, NextPageToken: next})
+}
+
+func (s *Server) calendarListGet(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	if cal := c.lookup(w, r.PathValue("calendarId")); cal != nil {
+		writeJSON(w, http.StatusOK, c.entry(cal))
+	}
+}
+
+func (s *Server) calendarsGet(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	if cal := c.lookup(w, r.PathValue("calendarId")); cal != nil {
+		writeJSON(w, http.StatusOK, cal)
+	}
+}
+
+func (s *Server) calendarsInsert(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	var in calendar.Calendar
+	if err := decodeJSON(r, &in); err != nil || strings.TrimSpace(in.Summary) == "" {
+		badRequest(w, "Missing summary.")
+		return
+	}
+	in.Id = s.nextID("cal") + "@group.calendar.google.com"
+	if in.TimeZone == "" {
+		in.TimeZone = DefaultTimeZone
+	}
+	c.add(&in)
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func (s *Server) calendarEventsList(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	cal := c.lookup(w, r.PathValue("calendarId"))
+	if cal == nil {
+		return
+	}
+	q := r.URL.Query()
+	timeMin, _ := time.Parse(time.RFC3339, q.Get("timeMin"))
+	timeMax, _ := time.Parse(time.RFC3339, q.Get("timeMax"))
+	text := strings.ToLower(q.Get("q"))
+	var matched []*calendar.Event
+	for _, ev := range c.events[cal.Id] {
+		if ev.Status == "cancelled" && q.Get("showDeleted") != "true" {
+			continue
+		}
+		if uid := q.Get("iCalUID"); uid != "" && ev.ICalUID != uid {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(ev.Summary+" "+ev.Description+" "+ev.Location), text) {
+			continue
+		}
+		start, end := eventBounds(ev)
+		if !timeMin.IsZero() && !end.IsZero() && !end.After(timeMin) {
+			continue
+		}
+		if !timeMax.IsZero() && !start.IsZero() && !start.Before(timeMax) {
+			continue
+		}
+		matched = append(matched, ev)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		si, _ := eventBounds(matched[i])
+		sj, _ := eventBounds(matched[j])
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return matched[i].Id < matched[j].Id
+	})
+	items, next := page(matched, r, 250)
+	writeJSON(w, http.StatusOK, &calendar.Events{
+		Summary:       cal.Summary,
+		TimeZone:      cal.TimeZone,
+		Items:         items,
+		NextPageToken: next,
+	})
+}
+
+// eventBounds returns an event's start and end; an instance with no times
+// of its own (e.g. a cancelled occurrence) uses its original start.
+func eventBounds(ev *calendar.Event) (time.Time, time.Time) {
+	start := eventTime(ev.Start)
+	if start.IsZero() {
+		start = eventTime(ev.OriginalStartTime)
+	}
+	end := eventTime(ev.End)
+	if end.IsZero() {
+		end = start
+	}
+	return start, end
+}
+
+func eventTime(dt *calendar.EventDateTime) time.Time {
+	if dt == nil {
+		return time.Time{}
+	}
+	if dt.DateTime != "" {
+		t, _ := time.Parse(time.RFC3339, dt.DateTime)
+		return t
+	}
+	t, _ := time.Parse("2006-01-02", dt.Date)
+	return t
+}
+
+func (s *Server) calendarEventsGet(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	cal := c.lookup(w, r.PathValue("calendarId"))
+	if cal == nil {
+		return
+	}
+	ev, ok := c.events[cal.Id][r.PathValue("eventId")]
+	if !ok {
+		notFound(w, "Event", r.PathValue("eventId"))
+		return
+	}
+	writeJSON(w, http.StatusOK, ev)
+}
+
+func (s *Server) validEvent(w http.ResponseWriter, ev *calendar.Event) bool {
+	if ev.Status != "cancelled" && (eventTime(ev.Start).IsZero() || eventTime(ev.End).IsZero()) {
+		badRequest(w, "Missing or invalid start/end time.")
+		return false
+	}
+	return true
+}
+
+func (s *Server) stamp(ev *calendar.Event, created bool) {
+	now := s.now().Format(time.RFC3339Nano)
+	if created {
+		ev.Created = now
+	}
+	ev.Updated = now
+	ev.Sequence++
+	if ev.Status == "" {
+		ev.Status = "confirmed"
+	}
+	ev.Kind = "calendar#event"
+	ev.Etag = `"` + ev.Updated + `"`
+	ev.HtmlLink = "https://www.google.com/calendar/event?eid=" + ev.Id
+}
+
+func (s *Server) calendarEventsInsert(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	cal := c.lookup(w, r.PathValue("calendarId"))
+	if cal == nil {
+		return
+	}
+	var ev calendar.Event
+	if err := decodeJSON(r, &ev); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if !s.validEvent(w, &ev) {
+		return
+	}
+	if ev.Id == "" {
+		ev.Id = s.nextID("evt")
+	} else if _, exists := c.events[cal.Id][ev.Id]; exists {
+		writeError(w, http.StatusConflict, "duplicate", "The requested identifier already exists.")
+		return
+	}
+	if ev.ICalUID == "" {
+		ev.ICalUID = ev.Id + "@google.com"
+	}
+	if ev.Organizer == nil {
+		ev.Organizer = &calendar.EventOrganizer{Email: cal.Id, Self: cal.Id == c.primary}
+	}
+	s.stamp(&ev, true)
+	c.events[cal.Id][ev.Id] = &ev
+	writeJSON(w, http.StatusOK, &ev)
+}
+
+// calendarEventsImport adds a private copy of an event, replacing any event
+// with the same iCalUID and original start as the real API does.
+func (s *Server) calendarEventsImport(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	cal := c.lookup(w, r.PathValue("calendarId"))
+	if cal == nil {
+		return
+	}
+	var ev calendar.Event
+	if err := decodeJSON(r, &ev); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if ev.ICalUID == "" {
+		badRequest(w, "Missing iCalUID.")
+		return
+	}
+	if !s.validEvent(w, &ev) {
+		return
+	}
+	key := instanceKey(ev.OriginalStartTime)
+	created := true
+	ev.Id = s.nextID("evt")
+	for id, existing := range c.events[cal.Id] {
+		if existing.ICalUID == ev.ICalUID && instanceKey(existing.OriginalStartTime) == key {
+			ev.Id, ev.Created, ev.Sequence = id, existing.Created, existing.Sequence
+			created = false
+			break
+		}
+	}
+	if ev.OriginalStartTime != nil {
+		for id, existing := range c.events[cal.Id] {
+			if existing.ICalUID == ev.ICalUID && existing.OriginalStartTime == nil {
+				ev.RecurringEventId = id
+			}
+		}
+	}
+	s.stamp(&ev, created)
+	c.events[cal.Id][ev.Id] = &ev
+	writeJSON(w, http.StatusOK, &ev)
+}
+
+func instanceKey(dt *calendar.EventDateTime) string {
+	if dt == nil {
+		return ""
+	}
+	if dt.Date != "" {
+		return dt.Date
+	}
+	return eventTime(dt).UTC().Format(time.RFC3339)
+}
+
+// calendarEventsUpdate serves both patch (merge the fields sent) and update
+// (replace the event).
+func (s *Server) calendarEventsUpdate(patch bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := s.calendar(r)
+		cal := c.lookup(w, r.PathValue("calendarId"))
+		if cal == nil {
+			return
+		}
+		id := r.PathValue("eventId")
+		current, ok := c.events[cal.Id][id]
+		if !ok {
+			notFound(w, "Event", id)
+			return
+		}
+		var body map[string]json.RawMessage
+		if err := decodeJSON(r, &body); err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		merged := map[string]json.RawMessage{}
+		if patch {
+			b, _ := json.Marshal(current)
+			_ = json.Unmarshal(b, &merged)
+		}
+		for k, v := range body {
+			if string(v) == "null" {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+		b, _ := json.Marshal(merged)
+		var next calendar.Event
+		if err := json.Unmarshal(b, &next); err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		if !s.validEvent(w, &next) {
+			return
+		}
+		next.Id, next.ICalUID, next.Created, next.Sequence = current.Id, current.ICalUID, current.Created, current.Sequence
+		if next.Organizer == nil {
+			next.Organizer = current.Organizer
+		}
+		s.stamp(&next, false)
+		c.events[cal.Id][id] = &next
+		writeJSON(w, http.StatusOK, &next)
+	}
+}
+
+func (s *Server) calendarEventsDelete(w http.ResponseWriter, r *http.Request) {
+	c := s.calendar(r)
+	cal := c.lookup(w, r.PathValue("calendarId"))
+	if cal == nil {
+		return
+	}
+	id := r.PathValue("eventId")
+	ev, ok := c.events[cal.Id][id]
+	if !ok || ev.Status == "cancelled" {
+		writeError(w, http.StatusGone, "deleted", "Resource has been deleted")
+		return
+	}
+	// Deleted events stay visible with showDeleted, as in the real API.
+	ev.Status = "cancelled"
+	s.stamp(ev, false)
+	noContent(w)
+}
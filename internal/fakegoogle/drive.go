@@ -0,0 +1,467 @@
+package fakegoogle
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // Drive reports MD5 checksums
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// DriveRootID is the ID of every account's My Drive folder.
+const DriveRootID = "root"
+
+const driveFolderMimeType = "application/vnd.google-apps.folder"
+
+type driveState struct {
+	files   map[string]*driveFile
+	order   []string
+	version int64
+}
+
+type driveFile struct {
+	meta    *drive.File
+	content []byte
+	perms   []*drive.Permission
+}
+
+func (s *Server) drive(r *http.Request) *driveState {
+	a := s.account(r)
+	if a.drive == nil {
+		a.drive = &driveState{files: map[string]*driveFile{}}
+		a.drive.files[DriveRootID] = &driveFile{meta: &drive.File{
+			Id:       DriveRootID,
+			Name:     "My Drive",
+			MimeType: driveFolderMimeType,
+		}}
+	}
+	return a.drive
+}
+
+func (s *Server) routeDrive() {
+	const base = "/drive/v3"
+	m := s.mux
+	m.HandleFunc("GET "+base+"/files", s.driveFilesList)
+	m.HandleFunc("POST "+base+"/files", s.driveFilesCreate)
+	m.HandleFunc("POST /upload"+base+"/files", s.driveFilesCreate)
+	m.HandleFunc("GET "+base+"/files/{id}", s.driveFilesGet)
+	m.HandleFunc("PATCH "+base+"/files/{id}", s.driveFilesUpdate)
+	m.HandleFunc("PATCH /upload"+base+"/files/{id}", s.driveFilesUpdate)
+	m.HandleFunc("DELETE "+base+"/files/{id}", s.driveFilesDelete)
+	m.HandleFunc("POST "+base+"/files/{id}/copy", s.driveFilesCopy)
+	m.HandleFunc("GET "+base+"/files/{id}/export", s.driveFilesExport)
+
+	m.HandleFunc("GET "+base+"/files/{id}/permissions", s.drivePermissionsList)
+	m.HandleFunc("POST "+base+"/files/{id}/permissions", s.drivePermissionsCreate)
+	m.HandleFunc("DELETE "+base+"/files/{id}/permissions/{permId}", s.drivePermissionsDelete)
+
+	m.HandleFunc("GET "+base+"/drives", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, &drive.DriveList{Drives: []*drive.Drive{}})
+	})
+}
+
+func (d *driveState) get(w http.ResponseWriter, id string) *driveFile {
+	f, ok := d.files[id]
+	if !ok {
+		notFound(w, "File", id)
+		return nil
+	}
+	return f
+}
+
+func (d *driveState) setContent(f *driveFile, content []byte, now time.Time) {
+	f.content = content
+	sum := md5.Sum(content) //nolint:gosec // Drive reports MD5 checksums
+	f.meta.Md5Checksum = hex.EncodeToString(sum[:])
+	f.meta.Size = int64(len(content))
+	d.touch(f, now)
+}
+
+func (d *driveState) touch(f *driveFile, now time.Time) {
+	d.version++
+	f.meta.Version = d.version
+	f.meta.ModifiedTime = now.Format(time.RFC3339Nano)
+}
+
+func (s *Server) driveFilesList(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	q, err := parseDriveQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		badRequest(w, "Invalid Value: %v", err)
+		return
+	}
+	var matched []*drive.File
+	for _, id := range d.order {
+		if f := d.files[id]; q.match(f.meta) {
+			matched = append(matched, f.meta)
+		}
+	}
+	if strings.HasPrefix(r.URL.Query().Get("orderBy"), "name") {
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+	}
+	items, next := page(matched, r, 100)
+	writeJSON(w, http.StatusOK, &drive.FileList{Files: items, NextPageToken: next})
+}
+
+func (s *Server) driveFilesGet(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	if r.URL.Query().Get("alt") == "media" {
+		if strings.HasPrefix(f.meta.MimeType, "application/vnd.google-apps.") {
+			writeError(w, http.StatusForbidden, "fileNotDownloadable", "Only files with binary content can be downloaded. Use Export with Docs Editors files.")
+			return
+		}
+		w.Header().Set("Content-Type", f.meta.MimeType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(f.content)
+		return
+	}
+	writeJSON(w, http.StatusOK, f.meta)
+}
+
+func (s *Server) driveFilesExport(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	mimeType := r.URL.Query().Get("mimeType")
+	if mimeType == "" {
+		badRequest(w, "mimeType is required")
+		return
+	}
+	// The fake does not convert; exports return the stored content.
+	w.Header().Set("Content-Type", mimeType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(f.content)
+}
+
+func (s *Server) driveFilesCreate(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	finish := func(metadata, media []byte, contentType string, w http.ResponseWriter) {
+		var meta drive.File
+		if len(bytes.TrimSpace(metadata)) > 0 {
+			if err := json.Unmarshal(metadata, &meta); err != nil {
+				badRequest(w, "invalid file metadata: %v", err)
+				return
+			}
+		}
+		if len(meta.Parents) == 0 {
+			meta.Parents = []string{DriveRootID}
+		}
+		for _, p := range meta.Parents {
+			if d.get(w, p) == nil {
+				return
+			}
+		}
+		if meta.Name == "" {
+			meta.Name = "Untitled"
+		}
+		if meta.MimeType == "" {
+			meta.MimeType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+		}
+		if meta.MimeType == "" {
+			meta.MimeType = "application/octet-stream"
+		}
+		now := s.now()
+		meta.Id = s.nextID("file")
+		meta.Kind = "drive#file"
+		meta.CreatedTime = now.Format(time.RFC3339Nano)
+		meta.WebViewLink = "https://drive.google.com/file/d/" + meta.Id + "/view"
+		f := &driveFile{meta: &meta, perms: []*drive.Permission{{Id: "owner", Type: "user", Role: "owner", EmailAddress: s.account(r).email}}}
+		if meta.MimeType == driveFolderMimeType {
+			d.touch(f, now)
+		} else {
+			d.setContent(f, media, now)
+		}
+		d.files[meta.Id] = f
+		d.order = append(d.order, meta.Id)
+		writeJSON(w, http.StatusOK, f.meta)
+	}
+	if strings.HasPrefix(r.URL.Path, "/upload/") {
+		s.readUpload(w, r, finish)
+		return
+	}
+	var body json.RawMessage
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	finish(body, nil, "", w)
+}
+
+func (s *Server) driveFilesUpdate(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	q := r.URL.Query()
+	finish := func(metadata, media []byte, _ string, w http.ResponseWriter) {
+		var patch map[string]json.RawMessage
+		if len(bytes.TrimSpace(metadata)) > 0 {
+			if err := json.Unmarshal(metadata, &patch); err != nil {
+				badRequest(w, "invalid file metadata: %v", err)
+				return
+			}
+		}
+		if len(patch) > 0 {
+			// Merge only the fields that were sent, as PATCH semantics require.
+			current, _ := json.Marshal(f.meta)
+			var merged map[string]json.RawMessage
+			_ = json.Unmarshal(current, &merged)
+			for k, v := range patch {
+				switch k {
+				case "id", "parents", "kind":
+					continue
+				}
+				merged[k] = v
+			}
+			b, _ := json.Marshal(merged)
+			var next drive.File
+			if err := json.Unmarshal(b, &next); err != nil {
+				badRequest(w, "invalid file metadata: %v", err)
+				return
+			}
+			f.meta = &next
+		}
+		if add := q.Get("addParents"); add != "" {
+			for _, p := range strings.Split(add, ",") {
+				if d.get(w, p) == nil {
+					return
+				}
+				if !containsString(f.meta.Parents, p) {
+					f.meta.Parents = append(f.meta.Parents, p)
+				}
+			}
+		}
+		for _, p := range strings.Split(q.Get("removeParents"), ",") {
+			if i := indexString(f.meta.Parents, p); i >= 0 {
+				f.meta.Parents = append(f.meta.Parents[:i], f.meta.Parents[i+1:]...)
+			}
+		}
+		if media != nil {
+			d.setContent(f, media, s.now())
+		} else {
+			d.touch(f, s.now())
+		}
+		writeJSON(w, http.StatusOK, f.meta)
+	}
+	if strings.HasPrefix(r.URL.Path, "/upload/") {
+		s.readUpload(w, r, finish)
+		return
+	}
+	var body json.RawMessage
+	if err := decodeJSON(r, &body); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	finish(body, nil, "", w)
+}
+
+func (s *Server) driveFilesDelete(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	id := r.PathValue("id")
+	if d.get(w, id) == nil {
+		return
+	}
+	d.delete(id)
+	noContent(w)
+}
+
+// delete removes a file and, for folders, everything beneath it.
+func (d *driveState) delete(id string) {
+	for _, childID := range append([]string(nil), d.order...) {
+		if child, ok := d.files[childID]; ok && containsString(child.meta.Parents, id) {
+			d.delete(childID)
+		}
+	}
+	delete(d.files, id)
+	if i := indexString(d.order, id); i >= 0 {
+		d.order = append(d.order[:i], d.order[i+1:]...)
+	}
+}
+
+func (s *Server) driveFilesCopy(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	src := d.get(w, r.PathValue("id"))
+	if src == nil {
+		return
+	}
+	var in drive.File
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	meta := *src.meta
+	meta.Id = s.nextID("file")
+	meta.Name = "Copy of " + src.meta.Name
+	if in.Name != "" {
+		meta.Name = in.Name
+	}
+	if len(in.Parents) > 0 {
+		meta.Parents = in.Parents
+	}
+	meta.CreatedTime = s.now().Format(time.RFC3339Nano)
+	f := &driveFile{meta: &meta, perms: []*drive.Permission{{Id: "owner", Type: "user", Role: "owner", EmailAddress: s.account(r).email}}}
+	d.setContent(f, append([]byte(nil), src.content...), s.now())
+	d.files[meta.Id] = f
+	d.order = append(d.order, meta.Id)
+	writeJSON(w, http.StatusOK, f.meta)
+}
+
+func (s *Server) drivePermissionsList(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, &drive.PermissionList{Permissions: f.perms})
+}
+
+func (s *Server) drivePermissionsCreate(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	var in drive.Permission
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if in.Type == "" || in.Role == "" {
+		badRequest(w, "Permission type and role are required")
+		return
+	}
+	in.Id = s.nextID("perm")
+	f.perms = append(f.perms, &in)
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func (s *Server) drivePermissionsDelete(w http.ResponseWriter, r *http.Request) {
+	d := s.drive(r)
+	f := d.get(w, r.PathValue("id"))
+	if f == nil {
+		return
+	}
+	for i, p := range f.perms {
+		if p.Id == r.PathValue("permId") {
+			f.perms = append(f.perms[:i], f.perms[i+1:]...)
+			noContent(w)
+			return
+		}
+	}
+	notFound(w, "Permission", r.PathValue("permId"))
+}
+
+// --- query ---
+
+type driveClause struct {
+	field, op, value string
+}
+
+type driveQuery []driveClause
+
+// parseDriveQuery handles "and"-joined clauses over parents, trashed, name,
+// mimeType and fullText. Other fields are rejected so tests notice when gog
+// starts relying on something the fake does not understand.
+func parseDriveQuery(q string) (driveQuery, error) {
+	var out driveQuery
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return out, nil
+	}
+	for _, raw := range splitDriveAnd(q) {
+		c := strings.TrimSpace(raw)
+		if v, ok := strings.CutSuffix(c, " in parents"); ok {
+			out = append(out, driveClause{field: "parents", op: "in", value: unquoteDrive(v)})
+			continue
+		}
+		fields := strings.SplitN(c, " ", 3)
+		if len(fields) != 3 {
+			return nil, errString("unsupported query clause: " + c)
+		}
+		cl := driveClause{field: fields[0], op: fields[1], value: unquoteDrive(fields[2])}
+		switch cl.field {
+		case "trashed", "name", "mimeType", "fullText":
+		default:
+			return nil, errString("unsupported query field: " + cl.field)
+		}
+		out = append(out, cl)
+	}
+	return out, nil
+}
+
+func splitDriveAnd(q string) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i := 0; i < len(q); i++ {
+		switch {
+		case q[i] == '\\':
+			i++
+		case q[i] == '\'':
+			inQuote = !inQuote
+		case !inQuote && strings.HasPrefix(q[i:], " and "):
+			parts = append(parts, q[start:i])
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+	return append(parts, q[start:])
+}
+
+func unquoteDrive(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimSuffix(v, "'"), "'")
+	return strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(v)
+}
+
+func (q driveQuery) match(f *drive.File) bool {
+	if f.Id == DriveRootID {
+		return false
+	}
+	for _, c := range q {
+		var ok bool
+		switch c.field {
+		case "parents":
+			ok = containsString(f.Parents, c.value)
+		case "trashed":
+			ok = (c.value == "true") == f.Trashed
+		case "name":
+			ok = compareDrive(f.Name, c.op, c.value)
+		case "mimeType":
+			ok = compareDrive(f.MimeType, c.op, c.value)
+		case "fullText":
+			ok = strings.Contains(strings.ToLower(f.Name+" "+f.Description), strings.ToLower(c.value))
+		}
+		if c.op == "!=" && c.field == "trashed" {
+			ok = !ok
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func compareDrive(have, op, want string) bool {
+	switch op {
+	case "=":
+		return have == want
+	case "!=":
+		return have != want
+	case "contains":
+		return strings.Contains(strings.ToLower(have), strings.ToLower(want))
+	}
+	return false
+}
@@ -0,0 +1,1114 @@
+package fakegoogle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+var systemLabels = []string{
+	"INBOX", "SENT", "DRAFT", "SPAM", "TRASH", "UNREAD", "STARRED", "IMPORTANT",
+	"CATEGORY_PERSONAL", "CATEGORY_SOCIAL", "CATEGORY_PROMOTIONS", "CATEGORY_UPDATES", "CATEGORY_FORUMS",
+}
+
+type gmailState struct {
+	labels    map[string]*gmail.Label
+	messages  map[string]*gmailMessage
+	order     []string // message IDs, oldest first
+	drafts    map[string]string
+	filters   map[string]*gmail.Filter
+	history   []*gmail.History
+	historyID uint64
+}
+
+type gmailMessage struct {
+	id       string
+	threadID string
+	raw      []byte
+	labels   []string
+	internal int64
+	history  uint64
+}
+
+func (s *Server) gmail(r *http.Request) *gmailState {
+	a := s.account(r)
+	if a.gmail == nil {
+		st := &gmailState{
+			labels:    map[string]*gmail.Label{},
+			messages:  map[string]*gmailMessage{},
+			drafts:    map[string]string{},
+			filters:   map[string]*gmail.Filter{},
+			historyID: 1000,
+		}
+		for _, id := range systemLabels {
+			st.labels[id] = &gmail.Label{Id: id, Name: id, Type: "system"}
+		}
+		a.gmail = st
+	}
+	return a.gmail
+}
+
+func (s *Server) routeGmail() {
+	const base = "/gmail/v1/users/{user}"
+	const upload = "/upload" + base
+	m := s.mux
+	m.HandleFunc("GET "+base+"/profile", s.gmailProfile)
+
+	m.HandleFunc("GET "+base+"/labels", s.gmailLabelsList)
+	m.HandleFunc("POST "+base+"/labels", s.gmailLabelsCreate)
+	m.HandleFunc("GET "+base+"/labels/{id}", s.gmailLabelsGet)
+	m.HandleFunc("PATCH "+base+"/labels/{id}", s.gmailLabelsUpdate)
+	m.HandleFunc("PUT "+base+"/labels/{id}", s.gmailLabelsUpdate)
+	m.HandleFunc("DELETE "+base+"/labels/{id}", s.gmailLabelsDelete)
+
+	m.HandleFunc("GET "+base+"/messages", s.gmailMessagesList)
+	m.HandleFunc("GET "+base+"/messages/{id}", s.gmailMessagesGet)
+	m.HandleFunc("DELETE "+base+"/messages/{id}", s.gmailMessagesDelete)
+	m.HandleFunc("POST "+base+"/messages/{id}/modify", s.gmailMessagesModify)
+	m.HandleFunc("POST "+base+"/messages/{id}/trash", s.gmailMessagesTrash)
+	m.HandleFunc("POST "+base+"/messages/{id}/untrash", s.gmailMessagesUntrash)
+	m.HandleFunc("POST "+base+"/messages/batchModify", s.gmailMessagesBatchModify)
+	m.HandleFunc("POST "+base+"/messages/batchDelete", s.gmailMessagesBatchDelete)
+	for _, op := range []string{"", "/send", "/import"} {
+		m.HandleFunc("POST "+base+"/messages"+op, s.gmailMessagesAdd(op))
+		m.HandleFunc("POST "+upload+"/messages"+op, s.gmailMessagesAdd(op))
+	}
+
+	m.HandleFunc("GET "+base+"/threads", s.gmailThreadsList)
+	m.HandleFunc("GET "+base+"/threads/{id}", s.gmailThreadsGet)
+	m.HandleFunc("POST "+base+"/threads/{id}/modify", s.gmailThreadsModify)
+
+	m.HandleFunc("GET "+base+"/drafts", s.gmailDraftsList)
+	m.HandleFunc("GET "+base+"/drafts/{id}", s.gmailDraftsGet)
+	m.HandleFunc("DELETE "+base+"/drafts/{id}", s.gmailDraftsDelete)
+	m.HandleFunc("POST "+base+"/drafts", s.gmailDraftsSave(false))
+	m.HandleFunc("POST "+upload+"/drafts", s.gmailDraftsSave(false))
+	m.HandleFunc("PUT "+base+"/drafts/{id}", s.gmailDraftsSave(true))
+	m.HandleFunc("PUT "+upload+"/drafts/{id}", s.gmailDraftsSave(true))
+	m.HandleFunc("POST "+base+"/drafts/send", s.gmailDraftsSend)
+
+	m.HandleFunc("GET "+base+"/history", s.gmailHistoryList)
+
+	m.HandleFunc("GET "+base+"/settings/filters", s.gmailFiltersList)
+	m.HandleFunc("POST "+base+"/settings/filters", s.gmailFiltersCreate)
+	m.HandleFunc("GET "+base+"/settings/filters/{id}", s.gmailFiltersGet)
+	m.HandleFunc("DELETE "+base+"/settings/filters/{id}", s.gmailFiltersDelete)
+}
+
+// --- state helpers ---
+
+func (g *gmailState) record(h *gmail.History) uint64 {
+	g.historyID++
+	h.Id = g.historyID
+	g.history = append(g.history, h)
+	return g.historyID
+}
+
+func (g *gmailState) addMessage(m *gmailMessage) {
+	g.messages[m.id] = m
+	g.order = append(g.order, m.id)
+	m.history = g.record(&gmail.History{
+		MessagesAdded: []*gmail.HistoryMessageAdded{{Message: m.ref()}},
+	})
+}
+
+func (g *gmailState) removeMessage(id string) {
+	m, ok := g.messages[id]
+	if !ok {
+		return
+	}
+	delete(g.messages, id)
+	for i, v := range g.order {
+		if v == id {
+			g.order = append(g.order[:i], g.order[i+1:]...)
+			break
+		}
+	}
+	g.record(&gmail.History{
+		MessagesDeleted: []*gmail.HistoryMessageDeleted{{Message: m.ref()}},
+	})
+}
+
+func (g *gmailState) modify(m *gmailMessage, add, remove []string) {
+	var added, removed []string
+	for _, l := range add {
+		if !containsString(m.labels, l) {
+			m.labels = append(m.labels, l)
+			added = append(added, l)
+		}
+	}
+	for _, l := range remove {
+		if i := indexString(m.labels, l); i >= 0 {
+			m.labels = append(m.labels[:i], m.labels[i+1:]...)
+			removed = append(removed, l)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	h := &gmail.History{}
+	if len(added) > 0 {
+		h.LabelsAdded = []*gmail.HistoryLabelAdded{{Message: m.ref(), LabelIds: added}}
+	}
+	if len(removed) > 0 {
+		h.LabelsRemoved = []*gmail.HistoryLabelRemoved{{Message: m.ref(), LabelIds: removed}}
+	}
+	m.history = g.record(h)
+}
+
+func (g *gmailState) checkLabels(w http.ResponseWriter, ids ...[]string) bool {
+	for _, list := range ids {
+		for _, id := range list {
+			if _, ok := g.labels[id]; !ok {
+				badRequest(w, "Invalid label: %s", id)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *gmailState) messagesIn(threadID string) []*gmailMessage {
+	var out []*gmailMessage
+	for _, id := range g.order {
+		if m := g.messages[id]; m.threadID == threadID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// threadFor files a new message into the thread of the message it replies
+// to (by Message-ID), or starts a new thread.
+func (g *gmailState) threadFor(hdr mail.Header, fallback string) string {
+	refs := strings.Fields(hdr.Get("In-Reply-To") + " " + hdr.Get("References"))
+	for _, id := range g.order {
+		m := g.messages[id]
+		msgID := headerOf(m.raw).Get("Message-Id")
+		for _, ref := range refs {
+			if msgID != "" && ref == msgID {
+				return m.threadID
+			}
+		}
+	}
+	return fallback
+}
+
+func (m *gmailMessage) ref() *gmail.Message {
+	return &gmail.Message{Id: m.id, ThreadId: m.threadID, LabelIds: append([]string(nil), m.labels...)}
+}
+
+func containsString(list []string, v string) bool {
+	return indexString(list, v) >= 0
+}
+
+func indexString(list []string, v string) int {
+	for i, s := range list {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// --- profile & labels ---
+
+func (s *Server) gmailProfile(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	writeJSON(w, http.StatusOK, &gmail.Profile{
+		EmailAddress:  s.account(r).email,
+		MessagesTotal: int64(len(g.messages)),
+		ThreadsTotal:  int64(len(g.threadIDs())),
+		HistoryId:     g.historyID,
+	})
+}
+
+func (s *Server) gmailLabelsList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	out := make([]*gmail.Label, 0, len(g.labels))
+	for _, l := range g.labels {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Id < out[j].Id })
+	writeJSON(w, http.StatusOK, &gmail.ListLabelsResponse{Labels: out})
+}
+
+func (s *Server) gmailLabelsGet(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	l, ok := g.labels[r.PathValue("id")]
+	if !ok {
+		notFound(w, "label", r.PathValue("id"))
+		return
+	}
+	total, unread := int64(0), int64(0)
+	for _, m := range g.messages {
+		if containsString(m.labels, l.Id) {
+			total++
+			if containsString(m.labels, "UNREAD") {
+				unread++
+			}
+		}
+	}
+	out := *l
+	out.MessagesTotal, out.MessagesUnread = total, unread
+	writeJSON(w, http.StatusOK, &out)
+}
+
+func (s *Server) gmailLabelsCreate(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	var in gmail.Label
+	if err := decodeJSON(r, &in); err != nil || strings.TrimSpace(in.Name) == "" {
+		badRequest(w, "label name is required")
+		return
+	}
+	for _, l := range g.labels {
+		if strings.EqualFold(l.Name, in.Name) {
+			writeError(w, http.StatusConflict, "alreadyExists", "Label name exists or conflicts")
+			return
+		}
+	}
+	in.Id = s.nextID("Label_")
+	in.Type = "user"
+	g.labels[in.Id] = &in
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func (s *Server) gmailLabelsUpdate(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	l, ok := g.labels[r.PathValue("id")]
+	if !ok {
+		notFound(w, "label", r.PathValue("id"))
+		return
+	}
+	var in gmail.Label
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if in.Name != "" {
+		l.Name = in.Name
+	}
+	if in.LabelListVisibility != "" {
+		l.LabelListVisibility = in.LabelListVisibility
+	}
+	if in.MessageListVisibility != "" {
+		l.MessageListVisibility = in.MessageListVisibility
+	}
+	if in.Color != nil {
+		l.Color = in.Color
+	}
+	writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) gmailLabelsDelete(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	id := r.PathValue("id")
+	l, ok := g.labels[id]
+	if !ok {
+		notFound(w, "label", id)
+		return
+	}
+	if l.Type == "system" {
+		badRequest(w, "Invalid delete request")
+		return
+	}
+	delete(g.labels, id)
+	for _, m := range g.messages {
+		g.modify(m, nil, []string{id})
+	}
+	noContent(w)
+}
+
+// --- messages ---
+
+func (s *Server) gmailMessagesList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	matched := g.search(r)
+	items, next := page(matched, r, 100)
+	out := &gmail.ListMessagesResponse{NextPageToken: next, ResultSizeEstimate: int64(len(matched))}
+	for _, m := range items {
+		out.Messages = append(out.Messages, &gmail.Message{Id: m.id, ThreadId: m.threadID})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// search returns messages matching labelIds, includeSpamTrash and q, newest
+// first like Gmail.
+func (g *gmailState) search(r *http.Request) []*gmailMessage {
+	q := r.URL.Query()
+	labelIDs := q["labelIds"]
+	includeSpamTrash := q.Get("includeSpamTrash") == "true"
+	terms := parseGmailQuery(q.Get("q"))
+	var out []*gmailMessage
+	for i := len(g.order) - 1; i >= 0; i-- {
+		m := g.messages[g.order[i]]
+		if !includeSpamTrash && !terms.mentions("TRASH", "SPAM") &&
+			(containsString(m.labels, "TRASH") || containsString(m.labels, "SPAM")) {
+			continue
+		}
+		ok := true
+		for _, l := range labelIDs {
+			if !containsString(m.labels, l) {
+				ok = false
+				break
+			}
+		}
+		if ok && terms.match(g, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *Server) gmailMessagesGet(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	m, ok := g.messages[r.PathValue("id")]
+	if !ok {
+		notFound(w, "message", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, renderMessage(m, r.URL.Query().Get("format"), r.URL.Query()["metadataHeaders"]))
+}
+
+// gmailMessagesAdd handles insert (op ""), send and import, with either a
+// JSON body carrying "raw" or an RFC 822 media upload.
+func (s *Server) gmailMessagesAdd(op string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g := s.gmail(r)
+		finish := func(metadata, media []byte, _ string, w http.ResponseWriter) {
+			var meta gmail.Message
+			if len(bytes.TrimSpace(metadata)) > 0 {
+				if err := json.Unmarshal(metadata, &meta); err != nil {
+					badRequest(w, "invalid message metadata: %v", err)
+					return
+				}
+			}
+			raw := media
+			if meta.Raw != "" {
+				decoded, err := decodeBase64URL(meta.Raw)
+				if err != nil {
+					badRequest(w, "invalid raw message: %v", err)
+					return
+				}
+				raw = decoded
+			}
+			labels := meta.LabelIds
+			if op == "/send" {
+				labels = []string{"SENT"}
+			}
+			m, err := s.storeMessage(g, raw, labels, meta.ThreadId)
+			if err != nil {
+				badRequest(w, "%v", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, m.ref())
+		}
+		if strings.HasPrefix(r.URL.Path, "/upload/") {
+			s.readUpload(w, r, finish)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		finish(body, nil, "", w)
+	}
+}
+
+func (s *Server) storeMessage(g *gmailState, raw []byte, labels []string, threadID string) (*gmailMessage, error) {
+	if len(raw) == 0 {
+		return nil, errString("message has no content")
+	}
+	for _, l := range labels {
+		if _, ok := g.labels[l]; !ok {
+			return nil, errString("Invalid label: " + l)
+		}
+	}
+	hdr := headerOf(raw)
+	id := s.nextID("18c")
+	if threadID == "" {
+		threadID = g.threadFor(hdr, id)
+	}
+	internal := s.now().UnixMilli()
+	if d, err := hdr.Date(); err == nil {
+		internal = d.UnixMilli()
+	}
+	m := &gmailMessage{id: id, threadID: threadID, raw: raw, labels: append([]string(nil), labels...), internal: internal}
+	g.addMessage(m)
+	return m, nil
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+type gmailModifyRequest struct {
+	Ids            []string `json:"ids"`
+	AddLabelIds    []string `json:"addLabelIds"`
+	RemoveLabelIds []string `json:"removeLabelIds"`
+}
+
+func (s *Server) gmailMessagesModify(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	m, ok := g.messages[r.PathValue("id")]
+	if !ok {
+		notFound(w, "message", r.PathValue("id"))
+		return
+	}
+	var in gmailModifyRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if !g.checkLabels(w, in.AddLabelIds, in.RemoveLabelIds) {
+		return
+	}
+	g.modify(m, in.AddLabelIds, in.RemoveLabelIds)
+	writeJSON(w, http.StatusOK, m.ref())
+}
+
+func (s *Server) gmailMessagesBatchModify(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	var in gmailModifyRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if !g.checkLabels(w, in.AddLabelIds, in.RemoveLabelIds) {
+		return
+	}
+	for _, id := range in.Ids {
+		if m, ok := g.messages[id]; ok {
+			g.modify(m, in.AddLabelIds, in.RemoveLabelIds)
+		}
+	}
+	noContent(w)
+}
+
+func (s *Server) gmailMessagesBatchDelete(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	var in gmailModifyRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	for _, id := range in.Ids {
+		g.removeMessage(id)
+	}
+	noContent(w)
+}
+
+func (s *Server) gmailMessagesTrash(w http.ResponseWriter, r *http.Request) {
+	s.gmailMessageLabels(w, r, []string{"TRASH"}, []string{"INBOX"})
+}
+
+func (s *Server) gmailMessagesUntrash(w http.ResponseWriter, r *http.Request) {
+	s.gmailMessageLabels(w, r, nil, []string{"TRASH"})
+}
+
+func (s *Server) gmailMessageLabels(w http.ResponseWriter, r *http.Request, add, remove []string) {
+	g := s.gmail(r)
+	m, ok := g.messages[r.PathValue("id")]
+	if !ok {
+		notFound(w, "message", r.PathValue("id"))
+		return
+	}
+	g.modify(m, add, remove)
+	writeJSON(w, http.StatusOK, m.ref())
+}
+
+func (s *Server) gmailMessagesDelete(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	if _, ok := g.messages[r.PathValue("id")]; !ok {
+		notFound(w, "message", r.PathValue("id"))
+		return
+	}
+	g.removeMessage(r.PathValue("id"))
+	noContent(w)
+}
+
+// --- threads ---
+
+func (g *gmailState) threadIDs() []string {
+	seen := map[string]bool{}
+	var out []string
+	for i := len(g.order) - 1; i >= 0; i-- {
+		t := g.messages[g.order[i]].threadID
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (s *Server) gmailThreadsList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	seen := map[string]bool{}
+	var threads []*gmail.Thread
+	for _, m := range g.search(r) {
+		if seen[m.threadID] {
+			continue
+		}
+		seen[m.threadID] = true
+		threads = append(threads, &gmail.Thread{Id: m.threadID, Snippet: snippetOf(m.raw), HistoryId: m.history})
+	}
+	items, next := page(threads, r, 100)
+	writeJSON(w, http.StatusOK, &gmail.ListThreadsResponse{Threads: items, NextPageToken: next, ResultSizeEstimate: int64(len(threads))})
+}
+
+func (s *Server) gmailThreadsGet(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	msgs := g.messagesIn(r.PathValue("id"))
+	if len(msgs) == 0 {
+		notFound(w, "thread", r.PathValue("id"))
+		return
+	}
+	t := &gmail.Thread{Id: r.PathValue("id")}
+	for _, m := range msgs {
+		t.Messages = append(t.Messages, renderMessage(m, r.URL.Query().Get("format"), r.URL.Query()["metadataHeaders"]))
+		if m.history > t.HistoryId {
+			t.HistoryId = m.history
+		}
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) gmailThreadsModify(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	msgs := g.messagesIn(r.PathValue("id"))
+	if len(msgs) == 0 {
+		notFound(w, "thread", r.PathValue("id"))
+		return
+	}
+	var in gmailModifyRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if !g.checkLabels(w, in.AddLabelIds, in.RemoveLabelIds) {
+		return
+	}
+	t := &gmail.Thread{Id: r.PathValue("id")}
+	for _, m := range msgs {
+		g.modify(m, in.AddLabelIds, in.RemoveLabelIds)
+		t.Messages = append(t.Messages, m.ref())
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// --- drafts ---
+
+func (g *gmailState) draft(id string) *gmail.Draft {
+	msgID, ok := g.drafts[id]
+	if !ok {
+		return nil
+	}
+	return &gmail.Draft{Id: id, Message: g.messages[msgID].ref()}
+}
+
+func (s *Server) gmailDraftsList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	ids := make([]string, 0, len(g.drafts))
+	for id := range g.drafts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	drafts := make([]*gmail.Draft, 0, len(ids))
+	for _, id := range ids {
+		drafts = append(drafts, g.draft(id))
+	}
+	items, next := page(drafts, r, 100)
+	writeJSON(w, http.StatusOK, &gmail.ListDraftsResponse{Drafts: items, NextPageToken: next, ResultSizeEstimate: int64(len(drafts))})
+}
+
+func (s *Server) gmailDraftsGet(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	msgID, ok := g.drafts[r.PathValue("id")]
+	if !ok {
+		notFound(w, "draft", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, &gmail.Draft{
+		Id:      r.PathValue("id"),
+		Message: renderMessage(g.messages[msgID], r.URL.Query().Get("format"), nil),
+	})
+}
+
+func (s *Server) gmailDraftsDelete(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	msgID, ok := g.drafts[r.PathValue("id")]
+	if !ok {
+		notFound(w, "draft", r.PathValue("id"))
+		return
+	}
+	delete(g.drafts, r.PathValue("id"))
+	g.removeMessage(msgID)
+	noContent(w)
+}
+
+// gmailDraftsSave creates a draft, or replaces an existing draft's message
+// when update is set.
+func (s *Server) gmailDraftsSave(update bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g := s.gmail(r)
+		id := r.PathValue("id")
+		if update {
+			if _, ok := g.drafts[id]; !ok {
+				notFound(w, "draft", id)
+				return
+			}
+		}
+		finish := func(metadata, media []byte, _ string, w http.ResponseWriter) {
+			var in gmail.Draft
+			if len(bytes.TrimSpace(metadata)) > 0 {
+				if err := json.Unmarshal(metadata, &in); err != nil {
+					badRequest(w, "invalid draft: %v", err)
+					return
+				}
+			}
+			raw := media
+			threadID := ""
+			if in.Message != nil {
+				threadID = in.Message.ThreadId
+				if in.Message.Raw != "" {
+					decoded, err := decodeBase64URL(in.Message.Raw)
+					if err != nil {
+						badRequest(w, "invalid raw message: %v", err)
+						return
+					}
+					raw = decoded
+				}
+			}
+			if update {
+				g.removeMessage(g.drafts[id])
+			} else {
+				id = s.nextID("r")
+			}
+			m, err := s.storeMessage(g, raw, []string{"DRAFT"}, threadID)
+			if err != nil {
+				badRequest(w, "%v", err)
+				return
+			}
+			g.drafts[id] = m.id
+			writeJSON(w, http.StatusOK, g.draft(id))
+		}
+		if strings.HasPrefix(r.URL.Path, "/upload/") {
+			s.readUpload(w, r, finish)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		finish(body, nil, "", w)
+	}
+}
+
+func (s *Server) gmailDraftsSend(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	var in gmail.Draft
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	msgID, ok := g.drafts[in.Id]
+	if !ok {
+		notFound(w, "draft", in.Id)
+		return
+	}
+	delete(g.drafts, in.Id)
+	m := g.messages[msgID]
+	g.modify(m, []string{"SENT"}, []string{"DRAFT"})
+	writeJSON(w, http.StatusOK, m.ref())
+}
+
+// --- history ---
+
+func (s *Server) gmailHistoryList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	q := r.URL.Query()
+	start, err := strconv.ParseUint(q.Get("startHistoryId"), 10, 64)
+	if err != nil {
+		badRequest(w, "startHistoryId is required")
+		return
+	}
+	if len(g.history) > 0 && start < g.history[0].Id-1 {
+		notFound(w, "history", q.Get("startHistoryId"))
+		return
+	}
+	types := map[string]bool{}
+	for _, t := range q["historyTypes"] {
+		types[t] = true
+	}
+	labelID := q.Get("labelId")
+	var records []*gmail.History
+	for _, h := range g.history {
+		if h.Id <= start || !historyMatches(h, types, labelID) {
+			continue
+		}
+		records = append(records, h)
+	}
+	items, next := page(records, r, 100)
+	writeJSON(w, http.StatusOK, &gmail.ListHistoryResponse{History: items, NextPageToken: next, HistoryId: g.historyID})
+}
+
+func historyMatches(h *gmail.History, types map[string]bool, labelID string) bool {
+	if len(types) > 0 {
+		ok := (types["messageAdded"] && len(h.MessagesAdded) > 0) ||
+			(types["messageDeleted"] && len(h.MessagesDeleted) > 0) ||
+			(types["labelAdded"] && len(h.LabelsAdded) > 0) ||
+			(types["labelRemoved"] && len(h.LabelsRemoved) > 0)
+		if !ok {
+			return false
+		}
+	}
+	if labelID == "" {
+		return true
+	}
+	for _, a := range h.MessagesAdded {
+		if containsString(a.Message.LabelIds, labelID) {
+			return true
+		}
+	}
+	for _, a := range h.LabelsAdded {
+		if containsString(a.LabelIds, labelID) {
+			return true
+		}
+	}
+	for _, a := range h.LabelsRemoved {
+		if containsString(a.LabelIds, labelID) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- filters ---
+
+func (s *Server) gmailFiltersList(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	ids := make([]string, 0, len(g.filters))
+	for id := range g.filters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	out := &gmail.ListFiltersResponse{}
+	for _, id := range ids {
+		out.Filter = append(out.Filter, g.filters[id])
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) gmailFiltersGet(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	f, ok := g.filters[r.PathValue("id")]
+	if !ok {
+		notFound(w, "filter", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (s *Server) gmailFiltersCreate(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	var in gmail.Filter
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if in.Criteria == nil || in.Action == nil {
+		badRequest(w, "Filter must have criteria and action")
+		return
+	}
+	if !g.checkLabels(w, in.Action.AddLabelIds, in.Action.RemoveLabelIds) {
+		return
+	}
+	in.Id = s.nextID("ANe1Bm")
+	g.filters[in.Id] = &in
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func (s *Server) gmailFiltersDelete(w http.ResponseWriter, r *http.Request) {
+	g := s.gmail(r)
+	if _, ok := g.filters[r.PathValue("id")]; !ok {
+		notFound(w, "filter", r.PathValue("id"))
+		return
+	}
+	delete(g.filters, r.PathValue("id"))
+	noContent(w)
+}
+
+// --- query ---
+
+type gmailQuery struct {
+	labels []string
+	fields map[string][]string
+	text   []string
+}
+
+// parseGmailQuery understands the operators gog's own tests rely on:
+// in:, label:, is:, from:, to:, subject:, rfc822msgid:, plus bare words.
+func parseGmailQuery(q string) gmailQuery {
+	out := gmailQuery{fields: map[string][]string{}}
+	for _, tok := range strings.Fields(q) {
+		key, val, ok := strings.Cut(tok, ":")
+		if !ok {
+			out.text = append(out.text, strings.ToLower(strings.Trim(tok, `"`)))
+			continue
+		}
+		val = strings.Trim(val, `"`)
+		switch strings.ToLower(key) {
+		case "in", "label":
+			out.labels = append(out.labels, strings.ToUpper(val))
+		case "is":
+			switch strings.ToLower(val) {
+			case "unread", "starred", "important":
+				out.labels = append(out.labels, strings.ToUpper(val))
+			}
+		default:
+			out.fields[strings.ToLower(key)] = append(out.fields[strings.ToLower(key)], strings.ToLower(val))
+		}
+	}
+	return out
+}
+
+func (q gmailQuery) mentions(labels ...string) bool {
+	for _, l := range labels {
+		if containsString(q.labels, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q gmailQuery) match(g *gmailState, m *gmailMessage) bool {
+	for _, want := range q.labels {
+		if want == "ANYWHERE" {
+			continue
+		}
+		ok := containsString(m.labels, want)
+		if !ok {
+			for _, id := range m.labels {
+				if l := g.labels[id]; l != nil && strings.EqualFold(l.Name, want) {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	hdr := headerOf(m.raw)
+	for key, vals := range q.fields {
+		var have string
+		switch key {
+		case "from", "to", "subject", "cc":
+			have = strings.ToLower(hdr.Get(key))
+		case "rfc822msgid":
+			have = strings.ToLower(strings.Trim(hdr.Get("Message-Id"), "<>"))
+		default:
+			continue
+		}
+		for _, v := range vals {
+			if !strings.Contains(have, strings.Trim(v, "<>")) {
+				return false
+			}
+		}
+	}
+	if len(q.text) > 0 {
+		hay := strings.ToLower(string(m.raw))
+		for _, t := range q.text {
+			if !strings.Contains(hay, t) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// --- rendering ---
+
+func headerOf(raw []byte) mail.Header {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return mail.Header{}
+	}
+	return msg.Header
+}
+
+func snippetOf(raw []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+	part := parsePart(partHeaders(msg.Header), msg.Body, "")
+	text := firstText(part)
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > 200 {
+		text = text[:200]
+	}
+	return text
+}
+
+func firstText(p *gmail.MessagePart) string {
+	if strings.HasPrefix(p.MimeType, "text/") && p.Body != nil && p.Body.Data != "" {
+		b, _ := decodeBase64URL(p.Body.Data)
+		return string(b)
+	}
+	for _, c := range p.Parts {
+		if t := firstText(c); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+func renderMessage(m *gmailMessage, format string, metadataHeaders []string) *gmail.Message {
+	out := m.ref()
+	out.HistoryId = m.history
+	out.InternalDate = m.internal
+	out.SizeEstimate = int64(len(m.raw))
+	out.Snippet = snippetOf(m.raw)
+	switch format {
+	case "minimal":
+		return out
+	case "raw":
+		out.Raw = base64.URLEncoding.EncodeToString(m.raw)
+		return out
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(m.raw))
+	if err != nil {
+		return out
+	}
+	h := partHeaders(msg.Header)
+	if format == "metadata" {
+		out.Payload = &gmail.MessagePart{MimeType: mediaTypeOf(h), Headers: filterHeaders(h, metadataHeaders)}
+		return out
+	}
+	out.Payload = parsePart(h, msg.Body, "")
+	return out
+}
+
+type partHeader []*gmail.MessagePartHeader
+
+func (h partHeader) get(name string) string {
+	for _, v := range h {
+		if strings.EqualFold(v.Name, name) {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+func partHeaders(h map[string][]string) partHeader {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out partHeader
+	for _, k := range keys {
+		for _, v := range h[k] {
+			out = append(out, &gmail.MessagePartHeader{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func filterHeaders(h partHeader, names []string) []*gmail.MessagePartHeader {
+	if len(names) == 0 {
+		return h
+	}
+	var out []*gmail.MessagePartHeader
+	for _, v := range h {
+		for _, n := range names {
+			if strings.EqualFold(v.Name, n) {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func mediaTypeOf(h partHeader) string {
+	mt, _, err := mime.ParseMediaType(h.get("Content-Type"))
+	if err != nil || mt == "" {
+		return "text/plain"
+	}
+	return mt
+}
+
+// parsePart converts a MIME entity into Gmail's payload tree. Bodies are
+// decoded from their transfer encoding and re-encoded as base64url, as the
+// API returns them.
+func parsePart(h partHeader, body io.Reader, partID string) *gmail.MessagePart {
+	mt, params, _ := mime.ParseMediaType(h.get("Content-Type"))
+	if mt == "" {
+		mt = "text/plain"
+	}
+	p := &gmail.MessagePart{PartId: partID, MimeType: mt, Headers: h, Body: &gmail.MessagePartBody{}}
+	if _, dparams, err := mime.ParseMediaType(h.get("Content-Disposition")); err == nil {
+		p.Filename = dparams["filename"]
+	}
+	if p.Filename == "" {
+		p.Filename = params["name"]
+	}
+	if strings.HasPrefix(mt, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for i := 0; ; i++ {
+			child, err := mr.NextRawPart()
+			if err != nil {
+				break
+			}
+			id := strconv.Itoa(i)
+			if partID != "" {
+				id = partID + "." + id
+			}
+			p.Parts = append(p.Parts, parsePart(partHeaders(child.Header), child, id))
+		}
+		return p
+	}
+	data, _ := io.ReadAll(decodeTransfer(h.get("Content-Transfer-Encoding"), body))
+	p.Body.Size = int64(len(data))
+	p.Body.Data = base64.URLEncoding.EncodeToString(data)
+	return p
+}
+
+func decodeTransfer(enc string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(enc)) {
+	case "base64":
+		b, _ := io.ReadAll(r)
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(b)), ""))
+		if err != nil {
+			return bytes.NewReader(b)
+		}
+		return bytes.NewReader(decoded)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,314 @@
+// Package fakegoogle is an in-memory stand-in for the subsets of the Gmail,
+// Drive, Calendar, Tasks, and Sheets REST APIs that gog uses. It serves the
+// same URL paths as the real APIs (rooted at one host), so both the generated
+// Go clients and the gog binary (via --endpoint / GOG_API_ENDPOINT) can talk
+// to it.
+//
+// State is kept per account. Requests are attributed to the account named in
+// an "Authorization: Bearer fake:<email>" header; anything else maps to
+// DefaultAccount.
+package fakegoogle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAccount = "me@example.com"
+
+	bearerPrefix = "Bearer fake:"
+)
+
+// Server implements http.Handler. The zero value is not usable; call New.
+type Server struct {
+	mu       sync.Mutex
+	mux      *http.ServeMux
+	accounts map[string]*account
+	seq      int64
+	uploads  map[string]*pendingUpload
+
+	// Now returns the current time; tests may replace it.
+	Now func() time.Time
+}
+
+type account struct {
+	email    string
+	gmail    *gmailState
+	drive    *driveState
+	calendar *calendarState
+	tasks    *tasksState
+	sheets   *sheetsState
+}
+
+func New() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		accounts: map[string]*account{},
+		uploads:  map[string]*pendingUpload{},
+		Now:      time.Now,
+	}
+	s.routeGmail()
+	s.routeDrive()
+	s.routeCalendar()
+	s.routeTasks()
+	s.routeSheets()
+	s.mux.HandleFunc("POST /token", s.handleToken)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Resumable upload chunks go back to the session URL, whatever the
+	// method of the request that opened it.
+	if id := r.URL.Query().Get("upload_id"); id != "" && strings.HasPrefix(r.URL.Path, "/upload/") {
+		s.handleResumable(w, r, id)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleToken answers OAuth token refreshes so clients that insist on a
+// token exchange still work.
+func (s *Server) handleToken(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "fake",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) account(r *http.Request) *account {
+	email := DefaultAccount
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, bearerPrefix) {
+		if v := strings.TrimSpace(strings.TrimPrefix(h, bearerPrefix)); v != "" {
+			email = strings.ToLower(v)
+		}
+	}
+	a, ok := s.accounts[email]
+	if !ok {
+		a = &account{email: email}
+		s.accounts[email] = a
+	}
+	return a
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%06d", prefix, s.seq)
+}
+
+func (s *Server) now() time.Time {
+	return s.Now().UTC()
+}
+
+// --- responses ---
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+// writeError mirrors the Google API error envelope so googleapi.Error
+// parsing (and gog's error mapping) behaves as it does against Google.
+func writeError(w http.ResponseWriter, status int, reason, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    status,
+			"message": msg,
+			"errors":  []map[string]any{{"message": msg, "reason": reason, "domain": "global"}},
+		},
+	})
+}
+
+func notFound(w http.ResponseWriter, what, id string) {
+	writeError(w, http.StatusNotFound, "notFound", "%s not found: %s", what, id)
+}
+
+func badRequest(w http.ResponseWriter, format string, args ...any) {
+	writeError(w, http.StatusBadRequest, "badRequest", format, args...)
+}
+
+func noContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- requests ---
+
+func decodeJSON(r *http.Request, v any) error {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// pendingUpload holds the metadata of a resumable upload until its media
+// arrives.
+type pendingUpload struct {
+	metadata []byte
+	media    []byte
+	finish   func(metadata, media []byte, contentType string, w http.ResponseWriter)
+}
+
+// readUpload splits a media upload request into its JSON metadata and media
+// and passes them to finish. Resumable uploads are answered with a session
+// URL, and finish runs once handleResumable has received the last chunk.
+func (s *Server) readUpload(w http.ResponseWriter, r *http.Request, finish func(metadata, media []byte, contentType string, w http.ResponseWriter)) {
+	switch r.URL.Query().Get("uploadType") {
+	case "resumable":
+		meta, err := io.ReadAll(r.Body)
+		if err != nil {
+			badRequest(w, "read metadata: %v", err)
+			return
+		}
+		id := s.nextID("upload")
+		s.uploads[id] = &pendingUpload{metadata: meta, finish: finish}
+		q := r.URL.Query()
+		q.Set("upload_id", id)
+		loc := *r.URL
+		loc.RawQuery = q.Encode()
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		w.Header().Set("Location", scheme+"://"+r.Host+loc.RequestURI())
+		w.WriteHeader(http.StatusOK)
+	case "multipart":
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			badRequest(w, "invalid multipart content type: %v", err)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		metaPart, err := mr.NextPart()
+		if err != nil {
+			badRequest(w, "missing metadata part: %v", err)
+			return
+		}
+		meta, _ := io.ReadAll(metaPart)
+		mediaPart, err := mr.NextPart()
+		if err != nil {
+			badRequest(w, "missing media part: %v", err)
+			return
+		}
+		media, _ := io.ReadAll(mediaPart)
+		finish(meta, media, mediaPart.Header.Get("Content-Type"), w)
+	default:
+		media, err := io.ReadAll(r.Body)
+		if err != nil {
+			badRequest(w, "read media: %v", err)
+			return
+		}
+		finish(nil, media, r.Header.Get("Content-Type"), w)
+	}
+}
+
+func (s *Server) handleResumable(w http.ResponseWriter, r *http.Request, id string) {
+	up, ok := s.uploads[id]
+	if !ok {
+		notFound(w, "upload", id)
+		return
+	}
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		badRequest(w, "read chunk: %v", err)
+		return
+	}
+	up.media = append(up.media, chunk...)
+
+	// Content-Range: bytes first-last/total, or bytes */total to finalize.
+	done := true
+	if cr := r.Header.Get("Content-Range"); cr != "" {
+		total, last, parseErr := parseContentRange(cr)
+		if parseErr != nil {
+			badRequest(w, "%v", parseErr)
+			return
+		}
+		done = total >= 0 && last+1 >= total
+	}
+	if !done {
+		// Clients that send X-GUploader-No-308 (the Go client does) expect
+		// "incomplete" as a 200 with an override header instead of a 308.
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(up.media)-1))
+		if r.Header.Get("X-GUploader-No-308") == "yes" {
+			w.Header().Set("X-Http-Status-Code-Override", "308")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+	delete(s.uploads, id)
+	up.finish(up.metadata, up.media, r.Header.Get("Content-Type"), w)
+}
+
+func parseContentRange(v string) (total, last int64, err error) {
+	v = strings.TrimSpace(strings.TrimPrefix(v, "bytes"))
+	rng, totalStr, ok := strings.Cut(strings.TrimSpace(v), "/")
+	if !ok {
+		return 0, 0, errors.New("invalid Content-Range")
+	}
+	total = -1
+	if totalStr != "*" {
+		if total, err = strconv.ParseInt(totalStr, 10, 64); err != nil {
+			return 0, 0, errors.New("invalid Content-Range total")
+		}
+	}
+	last = -1
+	if rng != "*" {
+		_, lastStr, _ := strings.Cut(rng, "-")
+		if last, err = strconv.ParseInt(lastStr, 10, 64); err != nil {
+			return 0, 0, errors.New("invalid Content-Range range")
+		}
+	}
+	if rng == "*" {
+		last = total - 1
+	}
+	return total, last, nil
+}
+
+// --- paging ---
+
+// page returns one page of items using numeric offsets as page tokens.
+func page[T any](items []T, r *http.Request, defaultMax int) ([]T, string) {
+	start := 0
+	if tok := r.URL.Query().Get("pageToken"); tok != "" {
+		if n, err := strconv.Atoi(tok); err == nil && n >= 0 {
+			start = n
+		}
+	}
+	maxResults := defaultMax
+	for _, key := range []string{"maxResults", "pageSize"} {
+		if v := r.URL.Query().Get(key); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				maxResults = n
+			}
+		}
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + maxResults
+	if end >= len(items) {
+		return items[start:], ""
+	}
+	return items[start:end], strconv.Itoa(end)
+}
@@ -0,0 +1,301 @@
+package fakegoogle
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/tasks/v1"
+)
+
+// fakeTransport sends requests for Google hosts to the fake, acting as email.
+type fakeTransport struct {
+	host  string
+	email string
+}
+
+func (t fakeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	if strings.HasSuffix(r.URL.Host, ".googleapis.com") {
+		r.URL.Scheme, r.URL.Host, r.Host = "http", t.host, ""
+	}
+	r.Header.Set("Authorization", bearerPrefix+t.email)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newFake(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(New())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func clientOptions(srv *httptest.Server, email string) []option.ClientOption {
+	host := strings.TrimPrefix(srv.URL, "http://")
+	return []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: fakeTransport{host: host, email: email}})}
+}
+
+func TestGmail_ImportSearchModifyHistory(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	svc, err := gmail.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	profile, err := svc.Users.GetProfile("me").Do()
+	if err != nil {
+		t.Fatalf("profile: %v", err)
+	}
+	start := profile.HistoryId
+
+	raw := "From: Ann <ann@example.com>\r\nTo: a@example.com\r\nSubject: Quarterly report\r\nMessage-ID: <q1@example.com>\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nNumbers are=\r\n in.\r\n"
+	msg, err := svc.Users.Messages.Import("me", &gmail.Message{LabelIds: []string{"INBOX", "UNREAD"}}).
+		Media(strings.NewReader(raw), googleapi.ContentType("message/rfc822")).Do()
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	reply := "From: a@example.com\r\nTo: ann@example.com\r\nSubject: Re: Quarterly report\r\nIn-Reply-To: <q1@example.com>\r\n\r\nThanks\r\n"
+	sent, err := svc.Users.Messages.Send("me", &gmail.Message{Raw: base64URL(reply)}).Do()
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if sent.ThreadId != msg.ThreadId {
+		t.Fatalf("expected reply in thread %s, got %s", msg.ThreadId, sent.ThreadId)
+	}
+
+	list, err := svc.Users.Messages.List("me").Q("from:ann is:unread report").Do()
+	if err != nil || len(list.Messages) != 1 || list.Messages[0].Id != msg.Id {
+		t.Fatalf("search: %v %+v", err, list)
+	}
+
+	full, err := svc.Users.Messages.Get("me", msg.Id).Format("full").Do()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := decodeBase64URL(full.Payload.Body.Data)
+	if string(body) != "Numbers are in.\r\n" || full.Snippet != "Numbers are in." {
+		t.Fatalf("unexpected body %q / snippet %q", body, full.Snippet)
+	}
+
+	if _, err = svc.Users.Messages.Modify("me", msg.Id, &gmail.ModifyMessageRequest{RemoveLabelIds: []string{"UNREAD"}}).Do(); err != nil {
+		t.Fatalf("modify: %v", err)
+	}
+	if _, err = svc.Users.Messages.Modify("me", msg.Id, &gmail.ModifyMessageRequest{AddLabelIds: []string{"Nope"}}).Do(); !isStatus(err, http.StatusBadRequest) {
+		t.Fatalf("expected 400 for unknown label, got %v", err)
+	}
+
+	hist, err := svc.Users.History.List("me").StartHistoryId(start).HistoryTypes("labelRemoved").Do()
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(hist.History) != 1 || hist.History[0].LabelsRemoved[0].LabelIds[0] != "UNREAD" {
+		t.Fatalf("unexpected history: %+v", hist.History)
+	}
+
+	thread, err := svc.Users.Threads.Get("me", msg.ThreadId).Format("metadata").MetadataHeaders("Subject").Do()
+	if err != nil || len(thread.Messages) != 2 || len(thread.Messages[1].Payload.Headers) != 1 {
+		t.Fatalf("thread: %v %+v", err, thread)
+	}
+}
+
+func TestDrive_UploadListDownload(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	svc, err := drive.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	folder, err := svc.Files.Create(&drive.File{Name: "Reports", MimeType: driveFolderMimeType}).Do()
+	if err != nil {
+		t.Fatalf("create folder: %v", err)
+	}
+	small, err := svc.Files.Create(&drive.File{Name: "it's.txt", Parents: []string{folder.Id}}).
+		Media(strings.NewReader("hello"), googleapi.ContentType("text/plain")).Do()
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	// Large enough to force a chunked resumable upload.
+	big := bytes.Repeat([]byte("x"), 600*1024)
+	bigFile, err := svc.Files.Create(&drive.File{Name: "big.bin", Parents: []string{folder.Id}}).
+		Media(bytes.NewReader(big), googleapi.ChunkSize(256*1024)).Do()
+	if err != nil || bigFile.Size != int64(len(big)) {
+		t.Fatalf("resumable upload: %v %+v", err, bigFile)
+	}
+
+	list, err := svc.Files.List().Q("'" + folder.Id + "' in parents and name = 'it\\'s.txt' and trashed = false").Do()
+	if err != nil || len(list.Files) != 1 || list.Files[0].Id != small.Id || list.Files[0].Md5Checksum != "5d41402abc4b2a76b9719d911017c592" {
+		t.Fatalf("list: %v %+v", err, list)
+	}
+
+	resp, err := svc.Files.Get(small.Id).Download()
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(data) != "hello" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err = svc.Files.Delete(folder.Id).Do(); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = svc.Files.Get(small.Id).Do(); !isStatus(err, http.StatusNotFound) {
+		t.Fatalf("expected children deleted with folder, got %v", err)
+	}
+}
+
+func TestCalendar_ListRangeAndImport(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	svc, err := calendar.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	mk := func(summary, start, end string) *calendar.Event {
+		return &calendar.Event{Summary: summary, Start: &calendar.EventDateTime{DateTime: start}, End: &calendar.EventDateTime{DateTime: end}}
+	}
+	if _, err = svc.Events.Insert("primary", mk("Early", "2024-01-01T09:00:00Z", "2024-01-01T10:00:00Z")).Do(); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	late, err := svc.Events.Insert("primary", mk("Late", "2024-01-03T09:00:00Z", "2024-01-03T10:00:00Z")).Do()
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	list, err := svc.Events.List("a@example.com").TimeMin("2024-01-02T00:00:00Z").TimeMax("2024-01-04T00:00:00Z").Do()
+	if err != nil || len(list.Items) != 1 || list.Items[0].Id != late.Id {
+		t.Fatalf("list: %v %+v", err, list)
+	}
+
+	imp := mk("Imported", "2024-02-01T09:00:00Z", "2024-02-01T09:30:00Z")
+	imp.ICalUID = "ext-1@example.com"
+	first, err := svc.Events.Import("primary", imp).Do()
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	imp.Summary = "Imported (edited)"
+	second, err := svc.Events.Import("primary", imp).Do()
+	if err != nil || second.Id != first.Id {
+		t.Fatalf("expected re-import to update %s, got %v %+v", first.Id, err, second)
+	}
+	byUID, err := svc.Events.List("primary").ICalUID("ext-1@example.com").Do()
+	if err != nil || len(byUID.Items) != 1 || byUID.Items[0].Summary != "Imported (edited)" {
+		t.Fatalf("iCalUID list: %v %+v", err, byUID)
+	}
+
+	if err = svc.Events.Delete("primary", late.Id).Do(); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err = svc.Events.Delete("primary", late.Id).Do(); !isStatus(err, http.StatusGone) {
+		t.Fatalf("expected 410 on second delete, got %v", err)
+	}
+}
+
+func TestTasks_InsertOrderAndClear(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	svc, err := tasks.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	list, err := svc.Tasklists.Insert(&tasks.TaskList{Title: "Chores"}).Do()
+	if err != nil {
+		t.Fatalf("insert list: %v", err)
+	}
+	first, err := svc.Tasks.Insert(list.Id, &tasks.Task{Title: "first"}).Do()
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err = svc.Tasks.Insert(list.Id, &tasks.Task{Title: "second"}).Previous(first.Id).Do(); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err = svc.Tasks.Patch(list.Id, first.Id, &tasks.Task{Status: "completed"}).Do(); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if err = svc.Tasks.Clear(list.Id).Do(); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	got, err := svc.Tasks.List(list.Id).Do()
+	if err != nil || len(got.Items) != 1 || got.Items[0].Title != "second" {
+		t.Fatalf("list: %v %+v", err, got)
+	}
+}
+
+func TestSheets_UpdateAppendGet(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	svc, err := sheets.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	ss, err := svc.Spreadsheets.Create(&sheets.Spreadsheet{Properties: &sheets.SpreadsheetProperties{Title: "Budget"}}).Do()
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = svc.Spreadsheets.BatchUpdate(ss.SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: "Q 1"}}}},
+	}).Do(); err != nil {
+		t.Fatalf("addSheet: %v", err)
+	}
+	if _, err = svc.Spreadsheets.Values.Update(ss.SpreadsheetId, "'Q 1'!A1", &sheets.ValueRange{
+		Values: [][]any{{"item", "cost"}, {"rent", "1000"}},
+	}).ValueInputOption("USER_ENTERED").Do(); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	appended, err := svc.Spreadsheets.Values.Append(ss.SpreadsheetId, "'Q 1'!A:B", &sheets.ValueRange{
+		Values: [][]any{{"food", 250}},
+	}).ValueInputOption("RAW").Do()
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if appended.Updates.UpdatedRange != "'Q 1'!A3:B3" || appended.TableRange != "'Q 1'!A1:B2" {
+		t.Fatalf("unexpected append ranges: %+v %+v", appended, appended.Updates)
+	}
+	got, err := svc.Spreadsheets.Values.Get(ss.SpreadsheetId, "'Q 1'!A2:B").ValueRenderOption("UNFORMATTED_VALUE").Do()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Values) != 2 || got.Values[0][1] != float64(1000) || got.Values[1][0] != "food" {
+		t.Fatalf("unexpected values: %+v", got.Values)
+	}
+}
+
+func TestAccountsAreIsolated(t *testing.T) {
+	srv := newFake(t)
+	ctx := context.Background()
+	a, _ := tasks.NewService(ctx, clientOptions(srv, "a@example.com")...)
+	b, _ := tasks.NewService(ctx, clientOptions(srv, "b@example.com")...)
+	if _, err := a.Tasklists.Insert(&tasks.TaskList{Title: "A only"}).Do(); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	lists, err := b.Tasklists.List().Do()
+	if err != nil || len(lists.Items) != 1 || lists.Items[0].Id != "@default" {
+		t.Fatalf("expected only the default list for b, got %v %+v", err, lists)
+	}
+}
+
+func isStatus(err error, code int) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+func base64URL(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
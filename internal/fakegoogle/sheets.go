@@ -0,0 +1,583 @@
+package fakegoogle
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+type sheetsState struct {
+	spreadsheets map[string]*spreadsheet
+}
+
+type spreadsheet struct {
+	id     string
+	title  string
+	tabs   []*sheetTab
+	nextID int64
+}
+
+type sheetTab struct {
+	id    int64
+	title string
+	cells [][]any // row-major; ragged
+}
+
+func (s *Server) sheets(r *http.Request) *sheetsState {
+	a := s.account(r)
+	if a.sheets == nil {
+		a.sheets = &sheetsState{spreadsheets: map[string]*spreadsheet{}}
+	}
+	return a.sheets
+}
+
+// routeSheets registers a prefix handler: Sheets paths use custom verbs
+// ("{id}:batchUpdate", "values/{range}:append") that ServeMux patterns
+// cannot express.
+func (s *Server) routeSheets() {
+	s.mux.HandleFunc("/v4/spreadsheets", s.sheetsDispatch)
+	s.mux.HandleFunc("/v4/spreadsheets/", s.sheetsDispatch)
+}
+
+func (s *Server) sheetsDispatch(w http.ResponseWriter, r *http.Request) {
+	st := s.sheets(r)
+	rest := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/v4/spreadsheets"), "/")
+	if rest == "" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "method not allowed")
+			return
+		}
+		s.sheetsCreate(w, r, st)
+		return
+	}
+	segs := strings.Split(rest, "/")
+	for i, seg := range segs {
+		v, err := url.PathUnescape(seg)
+		if err != nil {
+			badRequest(w, "invalid path: %v", err)
+			return
+		}
+		segs[i] = v
+	}
+	id, verb, _ := strings.Cut(segs[0], ":")
+	ss, ok := st.spreadsheets[id]
+	if !ok {
+		notFound(w, "Spreadsheet", id)
+		return
+	}
+	switch {
+	case len(segs) == 1 && verb == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, ss.resource())
+	case len(segs) == 1 && verb == "batchUpdate" && r.Method == http.MethodPost:
+		s.sheetsBatchUpdate(w, r, ss)
+	case len(segs) == 2 && segs[1] == "values:batchGet" && r.Method == http.MethodGet:
+		s.sheetsValuesBatchGet(w, r, ss)
+	case len(segs) == 2 && segs[1] == "values:batchUpdate" && r.Method == http.MethodPost:
+		s.sheetsValuesBatchUpdate(w, r, ss)
+	case len(segs) == 2 && strings.HasPrefix(segs[1], "values"):
+		s.sheetsValues(w, r, ss, strings.Join(segs[1:], "/"))
+	case len(segs) == 3 && segs[1] == "values":
+		s.sheetsValues(w, r, ss, segs[2])
+	default:
+		notFound(w, "path", r.URL.Path)
+	}
+}
+
+func (s *Server) sheetsValues(w http.ResponseWriter, r *http.Request, ss *spreadsheet, rng string) {
+	switch {
+	case strings.HasSuffix(rng, ":append") && r.Method == http.MethodPost:
+		s.sheetsValuesWrite(w, r, ss, strings.TrimSuffix(rng, ":append"), true)
+	case strings.HasSuffix(rng, ":clear") && r.Method == http.MethodPost:
+		s.sheetsValuesClear(w, ss, strings.TrimSuffix(rng, ":clear"))
+	case r.Method == http.MethodPut:
+		s.sheetsValuesWrite(w, r, ss, rng, false)
+	case r.Method == http.MethodGet:
+		s.sheetsValuesGet(w, r, ss, rng)
+	default:
+		notFound(w, "path", r.URL.Path)
+	}
+}
+
+func (ss *spreadsheet) resource() *sheets.Spreadsheet {
+	out := &sheets.Spreadsheet{
+		SpreadsheetId:  ss.id,
+		SpreadsheetUrl: "https://docs.google.com/spreadsheets/d/" + ss.id + "/edit",
+		Properties:     &sheets.SpreadsheetProperties{Title: ss.title, TimeZone: DefaultTimeZone},
+	}
+	for i, tab := range ss.tabs {
+		rows, cols := 1000, 26
+		for _, row := range tab.cells {
+			cols = max(cols, len(row))
+		}
+		rows = max(rows, len(tab.cells))
+		out.Sheets = append(out.Sheets, &sheets.Sheet{Properties: &sheets.SheetProperties{
+			SheetId:        tab.id,
+			Title:          tab.title,
+			Index:          int64(i),
+			SheetType:      "GRID",
+			GridProperties: &sheets.GridProperties{RowCount: int64(rows), ColumnCount: int64(cols)},
+		}})
+	}
+	return out
+}
+
+func (ss *spreadsheet) addTab(title string) *sheetTab {
+	if title == "" {
+		title = fmt.Sprintf("Sheet%d", len(ss.tabs)+1)
+	}
+	tab := &sheetTab{id: ss.nextID, title: title}
+	ss.nextID++
+	ss.tabs = append(ss.tabs, tab)
+	return tab
+}
+
+func (ss *spreadsheet) tab(title string) *sheetTab {
+	for _, t := range ss.tabs {
+		if t.title == title {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) sheetsCreate(w http.ResponseWriter, r *http.Request, st *sheetsState) {
+	var in sheets.Spreadsheet
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	ss := &spreadsheet{id: s.nextID("sheet")}
+	if in.Properties != nil {
+		ss.title = in.Properties.Title
+	}
+	if ss.title == "" {
+		ss.title = "Untitled spreadsheet"
+	}
+	for _, sh := range in.Sheets {
+		if sh.Properties != nil {
+			ss.addTab(sh.Properties.Title)
+		}
+	}
+	if len(ss.tabs) == 0 {
+		ss.addTab("")
+	}
+	st.spreadsheets[ss.id] = ss
+	writeJSON(w, http.StatusOK, ss.resource())
+}
+
+// sheetsBatchUpdate applies addSheet, deleteSheet and sheet renames. Other
+// request kinds (formatting, validation, ...) are accepted and ignored.
+func (s *Server) sheetsBatchUpdate(w http.ResponseWriter, r *http.Request, ss *spreadsheet) {
+	var in sheets.BatchUpdateSpreadsheetRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	out := &sheets.BatchUpdateSpreadsheetResponse{SpreadsheetId: ss.id}
+	for _, req := range in.Requests {
+		reply := &sheets.Response{}
+		switch {
+		case req.AddSheet != nil:
+			title := ""
+			if req.AddSheet.Properties != nil {
+				title = req.AddSheet.Properties.Title
+			}
+			if title != "" && ss.tab(title) != nil {
+				badRequest(w, "Invalid requests[0].addSheet: A sheet with the name %q already exists.", title)
+				return
+			}
+			tab := ss.addTab(title)
+			reply.AddSheet = &sheets.AddSheetResponse{Properties: &sheets.SheetProperties{SheetId: tab.id, Title: tab.title, Index: int64(len(ss.tabs) - 1)}}
+		case req.DeleteSheet != nil:
+			idx := -1
+			for i, t := range ss.tabs {
+				if t.id == req.DeleteSheet.SheetId {
+					idx = i
+				}
+			}
+			if idx < 0 {
+				badRequest(w, "No grid with id: %d", req.DeleteSheet.SheetId)
+				return
+			}
+			ss.tabs = append(ss.tabs[:idx], ss.tabs[idx+1:]...)
+		case req.UpdateSheetProperties != nil && req.UpdateSheetProperties.Properties != nil:
+			p := req.UpdateSheetProperties.Properties
+			for _, t := range ss.tabs {
+				if t.id == p.SheetId && p.Title != "" {
+					t.title = p.Title
+				}
+			}
+		}
+		out.Replies = append(out.Replies, reply)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// --- values ---
+
+type gridRange struct {
+	tab                          *sheetTab
+	startRow, startCol           int
+	endRow, endCol               int // exclusive; -1 means unbounded
+	sheetTitle, rowSpec, colSpec string
+}
+
+// parseA1 resolves "Sheet!A1:B2", "Sheet", "A1:B2", "A:C" and "2:5" against
+// the spreadsheet. Unqualified ranges refer to the first sheet.
+func (ss *spreadsheet) parseA1(rng string) (*gridRange, error) {
+	title, cells, ok := strings.Cut(rng, "!")
+	if !ok {
+		if t := ss.tab(strings.Trim(rng, "'")); t != nil {
+			return &gridRange{tab: t, endRow: -1, endCol: -1, sheetTitle: t.title}, nil
+		}
+		title, cells = "", rng
+	}
+	title = strings.ReplaceAll(strings.Trim(title, "'"), "''", "'")
+	var tab *sheetTab
+	if title == "" {
+		if len(ss.tabs) == 0 {
+			return nil, errString("spreadsheet has no sheets")
+		}
+		tab = ss.tabs[0]
+	} else if tab = ss.tab(title); tab == nil {
+		return nil, errString("Unable to parse range: " + rng)
+	}
+	g := &gridRange{tab: tab, endRow: -1, endCol: -1, sheetTitle: tab.title}
+	if cells == "" {
+		return g, nil
+	}
+	from, to, hasTo := strings.Cut(cells, ":")
+	r0, c0, err := parseCell(from)
+	if err != nil {
+		return nil, errString("Unable to parse range: " + rng)
+	}
+	g.startRow, g.startCol = max(r0, 0), max(c0, 0)
+	if !hasTo {
+		if r0 >= 0 && c0 >= 0 {
+			g.endRow, g.endCol = r0+1, c0+1
+		}
+		return g, nil
+	}
+	r1, c1, err := parseCell(to)
+	if err != nil {
+		return nil, errString("Unable to parse range: " + rng)
+	}
+	if r1 >= 0 {
+		g.endRow = r1 + 1
+	}
+	if c1 >= 0 {
+		g.endCol = c1 + 1
+	}
+	return g, nil
+}
+
+// parseCell parses "B3" (row 2, col 1), "B" (row -1) or "3" (col -1).
+func parseCell(v string) (row, col int, err error) {
+	v = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(v), "$", ""))
+	i := 0
+	col = -1
+	for i < len(v) && v[i] >= 'A' && v[i] <= 'Z' {
+		if col < 0 {
+			col = 0
+		}
+		col = col*26 + int(v[i]-'A'+1)
+		i++
+	}
+	if col > 0 {
+		col--
+	}
+	row = -1
+	if i < len(v) {
+		n, convErr := strconv.Atoi(v[i:])
+		if convErr != nil || n < 1 {
+			return 0, 0, errString("invalid cell " + v)
+		}
+		row = n - 1
+	}
+	if row < 0 && col < 0 {
+		return 0, 0, errString("empty cell reference")
+	}
+	return row, col, nil
+}
+
+func columnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+func a1(title string, startRow, startCol, endRow, endCol int) string {
+	q := title
+	if strings.ContainsAny(title, " '!") {
+		q = "'" + strings.ReplaceAll(title, "'", "''") + "'"
+	}
+	return fmt.Sprintf("%s!%s%d:%s%d", q, columnName(startCol), startRow+1, columnName(endCol-1), endRow)
+}
+
+func (t *sheetTab) set(row, col int, v any) {
+	for len(t.cells) <= row {
+		t.cells = append(t.cells, nil)
+	}
+	for len(t.cells[row]) <= col {
+		t.cells[row] = append(t.cells[row], nil)
+	}
+	t.cells[row][col] = v
+}
+
+func (t *sheetTab) get(row, col int) any {
+	if row < len(t.cells) && col < len(t.cells[row]) {
+		return t.cells[row][col]
+	}
+	return nil
+}
+
+func (g *gridRange) bounds() (endRow, endCol int) {
+	endRow, endCol = g.endRow, g.endCol
+	if endRow < 0 {
+		endRow = len(g.tab.cells)
+	}
+	if endCol < 0 {
+		endCol = 0
+		for _, row := range g.tab.cells {
+			endCol = max(endCol, len(row))
+		}
+	}
+	return endRow, endCol
+}
+
+func (g *gridRange) values(render string) [][]any {
+	endRow, endCol := g.bounds()
+	var out [][]any
+	for row := g.startRow; row < endRow; row++ {
+		var line []any
+		for col := g.startCol; col < endCol; col++ {
+			line = append(line, renderCell(g.tab.get(row, col), render))
+		}
+		for len(line) > 0 && line[len(line)-1] == "" {
+			line = line[:len(line)-1]
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && len(out[len(out)-1]) == 0 {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func renderCell(v any, render string) any {
+	if v == nil {
+		return ""
+	}
+	if render == "UNFORMATTED_VALUE" {
+		return v
+	}
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(x))
+	}
+	return fmt.Sprint(v)
+}
+
+func (s *Server) sheetsValuesGet(w http.ResponseWriter, r *http.Request, ss *spreadsheet, rng string) {
+	g, err := ss.parseA1(rng)
+	if err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, g.valueRange(r))
+}
+
+func (g *gridRange) valueRange(r *http.Request) *sheets.ValueRange {
+	values := g.values(r.URL.Query().Get("valueRenderOption"))
+	if r.URL.Query().Get("majorDimension") == "COLUMNS" {
+		values = transpose(values)
+	}
+	endRow, endCol := g.bounds()
+	return &sheets.ValueRange{
+		Range:          a1(g.sheetTitle, g.startRow, g.startCol, max(endRow, g.startRow+1), max(endCol, g.startCol+1)),
+		MajorDimension: firstNonEmpty(r.URL.Query().Get("majorDimension"), "ROWS"),
+		Values:         values,
+	}
+}
+
+func (s *Server) sheetsValuesBatchGet(w http.ResponseWriter, r *http.Request, ss *spreadsheet) {
+	out := &sheets.BatchGetValuesResponse{SpreadsheetId: ss.id}
+	for _, rng := range r.URL.Query()["ranges"] {
+		g, err := ss.parseA1(rng)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		out.ValueRanges = append(out.ValueRanges, g.valueRange(r))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// sheetsValuesWrite handles update (write at the range's top-left) and
+// append (write below the last non-empty row of the range).
+func (s *Server) sheetsValuesWrite(w http.ResponseWriter, r *http.Request, ss *spreadsheet, rng string, appendRows bool) {
+	g, err := ss.parseA1(rng)
+	if err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	opt := r.URL.Query().Get("valueInputOption")
+	if opt != "RAW" && opt != "USER_ENTERED" {
+		badRequest(w, "Invalid valueInputOption: %q", opt)
+		return
+	}
+	var in sheets.ValueRange
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if !appendRows {
+		writeJSON(w, http.StatusOK, g.write(ss.id, g.startRow, &in, opt))
+		return
+	}
+	tableEnd := g.startRow
+	_, endCol := g.bounds()
+	for row := g.startRow; row < len(g.tab.cells); row++ {
+		for col := g.startCol; col < max(endCol, g.startCol+1); col++ {
+			if g.tab.get(row, col) != nil {
+				tableEnd = row + 1
+			}
+		}
+	}
+	out := &sheets.AppendValuesResponse{SpreadsheetId: ss.id, Updates: g.write(ss.id, tableEnd, &in, opt)}
+	if tableEnd > g.startRow {
+		out.TableRange = a1(g.sheetTitle, g.startRow, g.startCol, tableEnd, max(endCol, g.startCol+1))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// write stores in's values with their top-left cell at (startRow, g.startCol).
+func (g *gridRange) write(spreadsheetID string, startRow int, in *sheets.ValueRange, opt string) *sheets.UpdateValuesResponse {
+	values := in.Values
+	if in.MajorDimension == "COLUMNS" {
+		values = transpose(values)
+	}
+	cols := 0
+	for i, row := range values {
+		cols = max(cols, len(row))
+		for j, v := range row {
+			if opt == "USER_ENTERED" {
+				v = parseUserEntered(v)
+			}
+			g.tab.set(startRow+i, g.startCol+j, v)
+		}
+	}
+	updated := &sheets.UpdateValuesResponse{
+		SpreadsheetId:  spreadsheetID,
+		UpdatedRange:   a1(g.sheetTitle, startRow, g.startCol, startRow+max(len(values), 1), g.startCol+max(cols, 1)),
+		UpdatedRows:    int64(len(values)),
+		UpdatedColumns: int64(cols),
+	}
+	for _, row := range values {
+		updated.UpdatedCells += int64(len(row))
+	}
+	return updated
+}
+
+func (s *Server) sheetsValuesBatchUpdate(w http.ResponseWriter, r *http.Request, ss *spreadsheet) {
+	var in sheets.BatchUpdateValuesRequest
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	if in.ValueInputOption != "RAW" && in.ValueInputOption != "USER_ENTERED" {
+		badRequest(w, "Invalid valueInputOption: %q", in.ValueInputOption)
+		return
+	}
+	out := &sheets.BatchUpdateValuesResponse{SpreadsheetId: ss.id}
+	tabs := map[*sheetTab]bool{}
+	for _, vr := range in.Data {
+		g, err := ss.parseA1(vr.Range)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		updated := g.write(ss.id, g.startRow, vr, in.ValueInputOption)
+		out.Responses = append(out.Responses, updated)
+		out.TotalUpdatedRows += updated.UpdatedRows
+		out.TotalUpdatedColumns = max(out.TotalUpdatedColumns, updated.UpdatedColumns)
+		out.TotalUpdatedCells += updated.UpdatedCells
+		tabs[g.tab] = true
+	}
+	out.TotalUpdatedSheets = int64(len(tabs))
+	writeJSON(w, http.StatusOK, out)
+}
+
+// parseUserEntered converts numeric and boolean text the way the Sheets UI
+// would and drops a leading apostrophe (the literal-text marker); everything
+// else (including formulas) is stored verbatim.
+func parseUserEntered(v any) any {
+	str, ok := v.(string)
+	if !ok {
+		return v
+	}
+	if text, quoted := strings.CutPrefix(str, "'"); quoted {
+		return text
+	}
+	if f, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+		return f
+	}
+	switch strings.ToUpper(str) {
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	return str
+}
+
+func (s *Server) sheetsValuesClear(w http.ResponseWriter, ss *spreadsheet, rng string) {
+	g, err := ss.parseA1(rng)
+	if err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	endRow, endCol := g.bounds()
+	for row := g.startRow; row < endRow && row < len(g.tab.cells); row++ {
+		for col := g.startCol; col < endCol && col < len(g.tab.cells[row]); col++ {
+			g.tab.cells[row][col] = nil
+		}
+	}
+	writeJSON(w, http.StatusOK, &sheets.ClearValuesResponse{
+		SpreadsheetId: ss.id,
+		ClearedRange:  a1(g.sheetTitle, g.startRow, g.startCol, max(endRow, g.startRow+1), max(endCol, g.startCol+1)),
+	})
+}
+
+func transpose(values [][]any) [][]any {
+	var out [][]any
+	for i, row := range values {
+		for j, v := range row {
+			for len(out) <= j {
+				out = append(out, nil)
+			}
+			for len(out[j]) < i {
+				out[j] = append(out[j], "")
+			}
+			out[j] = append(out[j], v)
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,286 @@
+package fakegoogle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/tasks/v1"
+)
+
+type tasksState struct {
+	lists map[string]*tasks.TaskList
+	order []string
+	items map[string][]*tasks.Task // by list ID, in position order
+}
+
+func (s *Server) tasks(r *http.Request) *tasksState {
+	a := s.account(r)
+	if a.tasks == nil {
+		a.tasks = &tasksState{lists: map[string]*tasks.TaskList{}, items: map[string][]*tasks.Task{}}
+		a.tasks.add(&tasks.TaskList{Id: "@default", Title: "My Tasks", Updated: s.now().Format(time.RFC3339Nano)})
+	}
+	return a.tasks
+}
+
+func (s *Server) routeTasks() {
+	const base = "/tasks/v1"
+	m := s.mux
+	m.HandleFunc("GET "+base+"/users/@me/lists", s.tasklistsList)
+	m.HandleFunc("POST "+base+"/users/@me/lists", s.tasklistsInsert)
+	m.HandleFunc("GET "+base+"/users/@me/lists/{listId}", s.tasklistsGet)
+	m.HandleFunc("DELETE "+base+"/users/@me/lists/{listId}", s.tasklistsDelete)
+
+	m.HandleFunc("GET "+base+"/lists/{listId}/tasks", s.tasksList)
+	m.HandleFunc("POST "+base+"/lists/{listId}/tasks", s.tasksInsert)
+	m.HandleFunc("POST "+base+"/lists/{listId}/clear", s.tasksClear)
+	m.HandleFunc("GET "+base+"/lists/{listId}/tasks/{taskId}", s.tasksGet)
+	m.HandleFunc("PATCH "+base+"/lists/{listId}/tasks/{taskId}", s.tasksUpdate(true))
+	m.HandleFunc("PUT "+base+"/lists/{listId}/tasks/{taskId}", s.tasksUpdate(false))
+	m.HandleFunc("DELETE "+base+"/lists/{listId}/tasks/{taskId}", s.tasksDelete)
+}
+
+func (t *tasksState) add(l *tasks.TaskList) {
+	t.lists[l.Id] = l
+	t.order = append(t.order, l.Id)
+}
+
+func (t *tasksState) list(w http.ResponseWriter, id string) *tasks.TaskList {
+	l, ok := t.lists[id]
+	if !ok {
+		notFound(w, "Task list", id)
+		return nil
+	}
+	return l
+}
+
+func (t *tasksState) task(w http.ResponseWriter, listID, id string) (*tasks.Task, int) {
+	for i, task := range t.items[listID] {
+		if task.Id == id {
+			return task, i
+		}
+	}
+	notFound(w, "Task", id)
+	return nil, -1
+}
+
+func (s *Server) tasklistsList(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	lists := make([]*tasks.TaskList, 0, len(t.order))
+	for _, id := range t.order {
+		lists = append(lists, t.lists[id])
+	}
+	items, next := page(lists, r, 100)
+	writeJSON(w, http.StatusOK, &tasks.TaskLists{Items: items, NextPageToken: next})
+}
+
+func (s *Server) tasklistsGet(w http.ResponseWriter, r *http.Request) {
+	if l := s.tasks(r).list(w, r.PathValue("listId")); l != nil {
+		writeJSON(w, http.StatusOK, l)
+	}
+}
+
+func (s *Server) tasklistsInsert(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	var in tasks.TaskList
+	if err := decodeJSON(r, &in); err != nil || strings.TrimSpace(in.Title) == "" {
+		badRequest(w, "Missing task list title.")
+		return
+	}
+	in.Id = s.nextID("list")
+	in.Kind = "tasks#taskList"
+	in.Updated = s.now().Format(time.RFC3339Nano)
+	t.add(&in)
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func (s *Server) tasklistsDelete(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	id := r.PathValue("listId")
+	if t.list(w, id) == nil {
+		return
+	}
+	delete(t.lists, id)
+	delete(t.items, id)
+	if i := indexString(t.order, id); i >= 0 {
+		t.order = append(t.order[:i], t.order[i+1:]...)
+	}
+	noContent(w)
+}
+
+func (s *Server) tasksList(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	listID := r.PathValue("listId")
+	if t.list(w, listID) == nil {
+		return
+	}
+	q := r.URL.Query()
+	showCompleted := q.Get("showCompleted") != "false"
+	showHidden := q.Get("showHidden") == "true"
+	dueMin, _ := time.Parse(time.RFC3339, q.Get("dueMin"))
+	dueMax, _ := time.Parse(time.RFC3339, q.Get("dueMax"))
+	var matched []*tasks.Task
+	for _, task := range t.items[listID] {
+		if task.Deleted && q.Get("showDeleted") != "true" {
+			continue
+		}
+		if task.Hidden && !showHidden {
+			continue
+		}
+		if task.Status == "completed" && !showCompleted {
+			continue
+		}
+		if due, err := time.Parse(time.RFC3339, task.Due); err == nil {
+			if (!dueMin.IsZero() && due.Before(dueMin)) || (!dueMax.IsZero() && !due.Before(dueMax)) {
+				continue
+			}
+		}
+		matched = append(matched, task)
+	}
+	items, next := page(matched, r, 20)
+	writeJSON(w, http.StatusOK, &tasks.Tasks{Items: items, NextPageToken: next})
+}
+
+func (s *Server) tasksGet(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	if t.list(w, r.PathValue("listId")) == nil {
+		return
+	}
+	if task, _ := t.task(w, r.PathValue("listId"), r.PathValue("taskId")); task != nil {
+		writeJSON(w, http.StatusOK, task)
+	}
+}
+
+func (s *Server) stampTask(task *tasks.Task) {
+	task.Kind = "tasks#task"
+	task.Updated = s.now().Format(time.RFC3339Nano)
+	if task.Status == "" {
+		task.Status = "needsAction"
+	}
+	if task.Status == "completed" && task.Completed == nil {
+		done := task.Updated
+		task.Completed = &done
+	}
+	if task.Status == "needsAction" {
+		task.Completed = nil
+	}
+}
+
+// tasksInsert honours the parent and previous query parameters; without
+// previous the task goes first among its siblings.
+func (s *Server) tasksInsert(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	listID := r.PathValue("listId")
+	if t.list(w, listID) == nil {
+		return
+	}
+	var in tasks.Task
+	if err := decodeJSON(r, &in); err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	in.Id = s.nextID("task")
+	in.Parent = r.URL.Query().Get("parent")
+	s.stampTask(&in)
+	items := t.items[listID]
+	at := 0
+	if prev := r.URL.Query().Get("previous"); prev != "" {
+		_, i := t.task(w, listID, prev)
+		if i < 0 {
+			return
+		}
+		at = i + 1
+	} else if in.Parent != "" {
+		_, i := t.task(w, listID, in.Parent)
+		if i < 0 {
+			return
+		}
+		at = i + 1
+	}
+	items = append(items[:at], append([]*tasks.Task{&in}, items[at:]...)...)
+	t.items[listID] = items
+	renumber(items)
+	writeJSON(w, http.StatusOK, &in)
+}
+
+func renumber(items []*tasks.Task) {
+	for i, task := range items {
+		task.Position = fmt.Sprintf("%020d", i)
+	}
+}
+
+func (s *Server) tasksUpdate(patch bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t := s.tasks(r)
+		listID := r.PathValue("listId")
+		if t.list(w, listID) == nil {
+			return
+		}
+		current, i := t.task(w, listID, r.PathValue("taskId"))
+		if current == nil {
+			return
+		}
+		var body map[string]json.RawMessage
+		if err := decodeJSON(r, &body); err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		merged := map[string]json.RawMessage{}
+		if patch {
+			b, _ := json.Marshal(current)
+			_ = json.Unmarshal(b, &merged)
+		}
+		for k, v := range body {
+			if string(v) == "null" {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+		b, _ := json.Marshal(merged)
+		var next tasks.Task
+		if err := json.Unmarshal(b, &next); err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		next.Id, next.Parent, next.Position = current.Id, current.Parent, current.Position
+		if next.Status != current.Status && next.Status == "completed" {
+			next.Completed = nil
+		}
+		s.stampTask(&next)
+		t.items[listID][i] = &next
+		writeJSON(w, http.StatusOK, &next)
+	}
+}
+
+func (s *Server) tasksDelete(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	listID := r.PathValue("listId")
+	if t.list(w, listID) == nil {
+		return
+	}
+	task, i := t.task(w, listID, r.PathValue("taskId"))
+	if task == nil {
+		return
+	}
+	items := t.items[listID]
+	t.items[listID] = append(items[:i], items[i+1:]...)
+	noContent(w)
+}
+
+// tasksClear hides completed tasks, as the API's clear does.
+func (s *Server) tasksClear(w http.ResponseWriter, r *http.Request) {
+	t := s.tasks(r)
+	listID := r.PathValue("listId")
+	if t.list(w, listID) == nil {
+		return
+	}
+	for _, task := range t.items[listID] {
+		if task.Status == "completed" {
+			task.Hidden = true
+		}
+	}
+	noContent(w)
+}
@@ -96,6 +96,7 @@ var serviceInfoByService = map[Service]serviceInfo{
 			"https://www.googleapis.com/auth/chat.spaces",
 			"https://www.googleapis.com/auth/chat.messages",
 			"https://www.googleapis.com/auth/chat.memberships",
+			"https://www.googleapis.com/auth/chat.delete",
 			"https://www.googleapis.com/auth/chat.users.readstate.readonly",
 		},
 		user: true,
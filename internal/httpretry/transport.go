@@ -0,0 +1,302 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 30 * time.Second
+
+	// maxInspectBytes bounds how much of a 403 body we buffer to look for
+	// rate-limit reasons. Google error payloads are small.
+	maxInspectBytes = 64 << 10
+)
+
+// Policy controls how many times (and how patiently) a request is retried.
+type Policy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// Transport retries idempotent requests that fail with transient Google API
+// errors (429, 500, 502, 503, and 403 rate-limit reasons) using jittered
+// exponential backoff. A Retry-After response header takes precedence over
+// the computed delay.
+type Transport struct {
+	Base   http.RoundTripper
+	Policy Policy
+
+	// sleep and jitter are swapped out in tests.
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(max time.Duration) time.Duration
+}
+
+func New(base http.RoundTripper, policy Policy) *Transport {
+	return &Transport{Base: base, Policy: policy}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base()
+	if t.Policy.MaxRetries <= 0 || !isIdempotent(req) {
+		return base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempt >= t.Policy.MaxRetries || !shouldRetry(resp) {
+			return resp, nil
+		}
+
+		delay := t.delay(attempt, resp)
+
+		slog.Debug("retrying google api request",
+			"method", req.Method,
+			"url", redactURL(req),
+			"status", resp.StatusCode,
+			"attempt", attempt+1,
+			"max_retries", t.Policy.MaxRetries,
+			"delay", delay,
+		)
+
+		drainAndClose(resp)
+
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *Transport) delay(attempt int, resp *http.Response) time.Duration {
+	maxDelay := t.Policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+
+	if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return min(d, maxDelay)
+	}
+
+	baseDelay := t.Policy.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultBaseDelay
+	}
+
+	ceiling := maxDelay
+	if attempt < 30 {
+		ceiling = min(baseDelay<<attempt, maxDelay)
+	}
+
+	// "Equal jitter": keep half of the backoff, randomize the rest so
+	// concurrent callers don't retry in lockstep.
+	half := ceiling / 2
+
+	return half + t.randomize(ceiling-half)
+}
+
+func (t *Transport) randomize(maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+
+	if t.jitter != nil {
+		return t.jitter(maxJitter)
+	}
+
+	return rand.N(maxJitter + 1) //nolint:gosec // jitter does not need crypto randomness
+}
+
+func (t *Transport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// idempotentPOSTSuffixes are POST endpoints that set state rather than
+// create it, so repeating them is harmless.
+var idempotentPOSTSuffixes = []string{
+	"/messages/batchModify",
+	"/messages/batchDelete",
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	case http.MethodPost:
+		if !isIdempotentPOST(req) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	// A body we can't replay can't be retried.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return true
+}
+
+func isIdempotentPOST(req *http.Request) bool {
+	if req.URL == nil {
+		return false
+	}
+
+	for _, suffix := range idempotentPOSTSuffixes {
+		if strings.HasSuffix(req.URL.Path, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func shouldRetry(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable:
+		return true
+	case http.StatusForbidden:
+		return hasRateLimitReason(resp)
+	default:
+		return false
+	}
+}
+
+// hasRateLimitReason peeks at a Google JSON error body and reports whether it
+// carries a rate-limit reason. The body is restored so callers can still read it.
+func hasRateLimitReason(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxInspectBytes))
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	if err != nil {
+		return false
+	}
+
+	var payload struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return false
+	}
+
+	for _, e := range payload.Error.Errors {
+		switch strings.ToLower(strings.TrimSpace(e.Reason)) {
+		case "ratelimitexceeded", "userratelimitexceeded":
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter parses a Retry-After header value (delay-seconds or HTTP-date).
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return 0, false
+}
+
+func drainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxInspectBytes))
+	_ = resp.Body.Close()
+}
+
+// redactURL drops the query string; it may carry page tokens or API keys.
+func redactURL(req *http.Request) string {
+	if req.URL == nil {
+		return ""
+	}
+
+	u := *req.URL
+	u.RawQuery = ""
+
+	return u.String()
+}
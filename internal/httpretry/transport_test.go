@@ -0,0 +1,297 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestTransport(policy Policy, slept *[]time.Duration) *Transport {
+	tr := New(http.DefaultTransport, policy)
+	tr.jitter = func(time.Duration) time.Duration { return 0 }
+	tr.sleep = func(_ context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return nil
+	}
+
+	return tr
+}
+
+func TestTransport_RetriesTransientStatuses(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Minute}, &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status=%d", resp.StatusCode)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls=%d", got)
+	}
+
+	// Equal jitter with zero randomness: half of base<<attempt.
+	if len(slept) != 2 || slept[0] != 500*time.Millisecond || slept[1] != time.Second {
+		t.Fatalf("unexpected delays: %v", slept)
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 2}, &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("status=%d", resp.StatusCode)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls=%d", got)
+	}
+}
+
+func TestTransport_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 1, MaxDelay: time.Minute}, &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if len(slept) != 1 || slept[0] != 7*time.Second {
+		t.Fatalf("unexpected delays: %v", slept)
+	}
+}
+
+func TestTransport_ForbiddenOnlyRetriesRateLimitReasons(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+
+		if r.URL.Path == "/quota" {
+			_, _ = io.WriteString(w, `{"error":{"code":403,"errors":[{"reason":"userRateLimitExceeded"}]}}`)
+
+			return
+		}
+		_, _ = io.WriteString(w, `{"error":{"code":403,"errors":[{"reason":"insufficientPermissions"}]}}`)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 2}, &slept)}
+
+	resp, err := client.Get(srv.URL + "/denied")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if calls.Load() != 1 || !strings.Contains(string(body), "insufficientPermissions") {
+		t.Fatalf("calls=%d body=%q", calls.Load(), body)
+	}
+
+	calls.Store(0)
+
+	resp, err = client.Get(srv.URL + "/quota")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls.Load() != 3 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestTransport_SkipsNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 3}, &slept)}
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestTransport_RetriesIdempotentBatchPost(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"ids":["m1"]}` {
+			t.Errorf("body=%q", body)
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 3}, &slept)}
+
+	resp, err := client.Post(srv.URL+"/gmail/v1/users/me/messages/batchModify", "application/json", strings.NewReader(`{"ids":["m1"]}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent || calls.Load() != 2 {
+		t.Fatalf("status=%d calls=%d", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_ReplaysBodyOnPut(t *testing.T) {
+	var bodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 1}, &slept)}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != "payload" || bodies[1] != "payload" {
+		t.Fatalf("unexpected bodies: %q", bodies)
+	}
+}
+
+func TestTransport_ZeroRetriesDisables(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+
+	client := &http.Client{Transport: newTestTransport(Policy{MaxRetries: 0}, &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if d, ok := retryAfter("12", now); !ok || d != 12*time.Second {
+		t.Fatalf("seconds: %v %v", d, ok)
+	}
+
+	if d, ok := retryAfter(now.Add(3*time.Second).Format(http.TimeFormat), now); !ok || d != 3*time.Second {
+		t.Fatalf("date: %v %v", d, ok)
+	}
+
+	if _, ok := retryAfter("soon", now); ok {
+		t.Fatalf("expected invalid value to be ignored")
+	}
+}
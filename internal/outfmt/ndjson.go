@@ -0,0 +1,156 @@
+package outfmt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type ndjsonKey struct{}
+
+// NDJSON configures JSON Lines output: list commands write one compact JSON
+// value per item as pages arrive instead of a single buffered envelope.
+type NDJSON struct {
+	// Select keeps only these (dot-path) fields on each line.
+	Select []string
+}
+
+func WithNDJSON(ctx context.Context, opts NDJSON) context.Context {
+	return context.WithValue(ctx, ndjsonKey{}, opts)
+}
+
+func NDJSONFromContext(ctx context.Context) (NDJSON, bool) {
+	if ctx == nil {
+		return NDJSON{}, false
+	}
+
+	opts, ok := ctx.Value(ndjsonKey{}).(NDJSON)
+
+	return opts, ok
+}
+
+func IsNDJSON(ctx context.Context) bool {
+	_, ok := NDJSONFromContext(ctx)
+
+	return ok
+}
+
+// WriteNDJSON writes each item as a single JSON line, applying --select per line.
+func WriteNDJSON(ctx context.Context, w io.Writer, items ...any) error {
+	opts, _ := NDJSONFromContext(ctx)
+
+	bw := bufio.NewWriter(w)
+
+	for _, item := range items {
+		line, err := encodeNDJSONLine(item, opts.Select)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bw.Write(line); err != nil {
+			return fmt.Errorf("write ndjson: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write ndjson: %w", err)
+	}
+
+	return nil
+}
+
+func encodeNDJSONLine(item any, fields []string) ([]byte, error) {
+	if len(fields) > 0 {
+		selected, err := selectNDJSONFields(item, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		item = selected
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(item); err != nil {
+		return nil, fmt.Errorf("encode ndjson: %w", err)
+	}
+
+	// Encode already terminates the value with a single '\n'.
+	return buf.Bytes(), nil
+}
+
+func selectNDJSONFields(item any, fields []string) (any, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("encode ndjson: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("decode ndjson: %w", err)
+	}
+
+	obj, ok := generic.(map[string]any)
+	if !ok {
+		return generic, nil
+	}
+
+	out := map[string]any{}
+
+	for _, field := range fields {
+		path := strings.Split(strings.TrimSpace(field), ".")
+		if len(path) == 0 || path[0] == "" {
+			continue
+		}
+
+		if v, found := lookupPath(obj, path); found {
+			setPath(out, path, v)
+		}
+	}
+
+	return out, nil
+}
+
+func lookupPath(obj map[string]any, path []string) (any, bool) {
+	var cur any = obj
+
+	for _, part := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+func setPath(out map[string]any, path []string, v any) {
+	cur := out
+
+	for _, part := range path[:len(path)-1] {
+		next, ok := cur[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[part] = next
+		}
+
+		cur = next
+	}
+
+	cur[path[len(path)-1]] = v
+}
@@ -0,0 +1,78 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestNDJSONContext(t *testing.T) {
+	if IsNDJSON(context.Background()) {
+		t.Fatalf("expected NDJSON off by default")
+	}
+
+	ctx := WithNDJSON(context.Background(), NDJSON{Select: []string{"id"}})
+	if !IsNDJSON(ctx) {
+		t.Fatalf("expected NDJSON on")
+	}
+
+	opts, ok := NDJSONFromContext(ctx)
+	if !ok || len(opts.Select) != 1 || opts.Select[0] != "id" {
+		t.Fatalf("unexpected opts: %#v ok=%v", opts, ok)
+	}
+}
+
+func TestWriteNDJSON_OneLinePerItem(t *testing.T) {
+	ctx := WithNDJSON(context.Background(), NDJSON{})
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(ctx, &buf, map[string]any{"id": "a", "html": "<b>"}, map[string]any{"id": "b"}); err != nil {
+		t.Fatalf("WriteNDJSON: %v", err)
+	}
+
+	want := "{\"html\":\"<b>\",\"id\":\"a\"}\n{\"id\":\"b\"}\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteNDJSON_SelectPerLine(t *testing.T) {
+	ctx := WithNDJSON(context.Background(), NDJSON{Select: []string{"id", "owner.email", "missing"}})
+
+	type owner struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	type file struct {
+		ID    string `json:"id"`
+		Size  int64  `json:"size"`
+		Owner owner  `json:"owner"`
+	}
+
+	var buf bytes.Buffer
+	err := WriteNDJSON(ctx, &buf,
+		file{ID: "1", Size: 12345678901234, Owner: owner{Email: "a@b.com", Name: "A"}},
+		file{ID: "2"},
+	)
+	if err != nil {
+		t.Fatalf("WriteNDJSON: %v", err)
+	}
+
+	want := "{\"id\":\"1\",\"owner\":{\"email\":\"a@b.com\"}}\n{\"id\":\"2\",\"owner\":{\"email\":\"\"}}\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteNDJSON_SelectNonObject(t *testing.T) {
+	ctx := WithNDJSON(context.Background(), NDJSON{Select: []string{"id"}})
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(ctx, &buf, "plain", 42); err != nil {
+		t.Fatalf("WriteNDJSON: %v", err)
+	}
+
+	if got := buf.String(); got != "\"plain\"\n42\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}